@@ -85,6 +85,42 @@ class StateSync {
     this.ws.send(JSON.stringify({ type: "get_state" }));
   }
 
+  /**
+   * Follow a unit's journal on a host. Lines arrive as log_stream messages
+   * for this browser only; log_stream_started carries the stream ID.
+   *
+   * @param {string} hostId - Host to stream from
+   * @param {string} unit - systemd unit (Linux) or process name (macOS)
+   * @param {number} lines - Journal lines to show before following, 0 = agent default
+   */
+  startLogStream(hostId, unit, lines = 0) {
+    if (!this.isConnected) {
+      console.warn("[StateSync] Not connected, cannot start log stream");
+      return false;
+    }
+    this.ws.send(
+      JSON.stringify({
+        type: "log_stream_start",
+        payload: { host_id: hostId, unit, lines },
+      }),
+    );
+    return true;
+  }
+
+  /**
+   * Stop a log stream started by this browser.
+   *
+   * @param {string} streamId - From log_stream_started
+   */
+  stopLogStream(streamId) {
+    if (!this.isConnected) {
+      return;
+    }
+    this.ws.send(
+      JSON.stringify({ type: "log_stream_stop", payload: { stream_id: streamId } }),
+    );
+  }
+
   /**
    * Dispatch an op to the server.
    * This is the thin frontend's only job - dispatch ops, let server handle logic.
@@ -94,6 +130,9 @@ class StateSync {
    * @param {Object} options - Optional settings
    * @param {boolean} options.force - Skip pre-validation
    * @param {string} options.totp - TOTP code for protected ops
+   * @param {string} options.target - Deploy target (switch/rollback), default all
+   * @param {number} options.generation - rollback: this generation instead of the previous one
+   * @param {string} options.ref - pull/pull-switch: git ref to deploy and pin the host to
    * @param {string} options.csrfToken - CSRF token (required)
    */
   dispatchOp(opId, hostIds, options = {}) {
@@ -121,6 +160,9 @@ class StateSync {
         hosts: hostIds,
         force: options.force || false,
         totp: options.totp,
+        target: options.target,
+        generation: options.generation,
+        ref: options.ref,
       }),
     }).then((res) => {
       if (!res.ok) {
@@ -315,10 +357,16 @@ class StateSync {
       case "host_offline":
       case "command_queued":
       case "command_output":
+      case "command_output_batch":
+      case "command_progress":
+      case "test_report":
       case "command_complete":
       case "host_status_update":
       case "state_machine_log":
       case "toast":
+      case "log_stream_started":
+      case "log_stream":
+      case "log_stream_end":
         // Forward to legacy handlers via custom event
         window.dispatchEvent(
           new CustomEvent("nixfleet-legacy", { detail: msg }),
@@ -0,0 +1,79 @@
+/**
+ * Passkeys - WebAuthn helpers for the login page and the dashboard
+ *
+ * The server sends credential options with binary fields as base64url
+ * strings and expects the browser's results back the same way; the
+ * WebAuthn API itself works with ArrayBuffers.
+ */
+const Passkeys = {
+  supported() {
+    return !!(window.PublicKeyCredential && navigator.credentials);
+  },
+
+  _decode(s) {
+    const b64 = s.replace(/-/g, '+').replace(/_/g, '/');
+    return Uint8Array.from(atob(b64), (c) => c.charCodeAt(0)).buffer;
+  },
+
+  _encode(buf) {
+    let s = '';
+    for (const b of new Uint8Array(buf)) s += String.fromCharCode(b);
+    return btoa(s).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+  },
+
+  _descriptors(list) {
+    return (list || []).map((c) => ({ ...c, id: this._decode(c.id) }));
+  },
+
+  async _post(url, body, csrfToken) {
+    const headers = { 'Content-Type': 'application/json' };
+    if (csrfToken) headers['X-CSRF-Token'] = csrfToken;
+    const resp = await fetch(url, { method: 'POST', headers, body: body ? JSON.stringify(body) : undefined });
+    const data = await resp.json().catch(() => ({}));
+    if (!resp.ok) throw new Error(data.error || resp.statusText);
+    return data;
+  },
+
+  /** Registers a new passkey for the signed-in user. */
+  async register(name, csrfToken) {
+    const { publicKey } = await this._post('/webauthn/register/begin', null, csrfToken);
+    publicKey.challenge = this._decode(publicKey.challenge);
+    publicKey.user.id = this._decode(publicKey.user.id);
+    publicKey.excludeCredentials = this._descriptors(publicKey.excludeCredentials);
+    const cred = await navigator.credentials.create({ publicKey });
+    return this._post('/webauthn/register/finish', {
+      name,
+      credential: {
+        id: this._encode(cred.rawId),
+        clientDataJSON: this._encode(cred.response.clientDataJSON),
+        attestationObject: this._encode(cred.response.attestationObject),
+      },
+    }, csrfToken);
+  },
+
+  /** Runs navigator.credentials.get() for options from beginUrl. */
+  async assert(beginUrl, csrfToken) {
+    const { publicKey } = await this._post(beginUrl, null, csrfToken);
+    publicKey.challenge = this._decode(publicKey.challenge);
+    publicKey.allowCredentials = this._descriptors(publicKey.allowCredentials);
+    const cred = await navigator.credentials.get({ publicKey });
+    return {
+      id: this._encode(cred.rawId),
+      clientDataJSON: this._encode(cred.response.clientDataJSON),
+      authenticatorData: this._encode(cred.response.authenticatorData),
+      signature: this._encode(cred.response.signature),
+      userHandle: cred.response.userHandle ? this._encode(cred.response.userHandle) : '',
+    };
+  },
+
+  /** Signs in with a passkey; resolves once the session cookie is set. */
+  async login() {
+    const assertion = await this.assert('/login/webauthn/begin');
+    return this._post('/login/webauthn/finish', assertion);
+  },
+
+  /** Returns an assertion to send as "webauthn" with a TOTP-gated op. */
+  stepUp(csrfToken) {
+    return this.assert('/webauthn/step-up/begin', csrfToken);
+  },
+};
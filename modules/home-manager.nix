@@ -50,6 +50,24 @@ in
       '';
       example = "/Users/myuser/.ssh/nixfleet-deploy-key";
     };
+
+    darwinMode = lib.mkOption {
+      type = lib.types.enum [
+        "home-manager"
+        "nix-darwin"
+        "both"
+      ];
+      default = "home-manager";
+      description = ''
+        What the agent manages on macOS:
+        - home-manager: standalone homeConfigurations.<host> (home-manager switch)
+        - nix-darwin: darwinConfigurations.<host> (darwin-rebuild switch)
+        - both: darwin-rebuild switch, then home-manager switch
+        Switch, rollback and the System compartment follow this setting.
+        darwin-rebuild runs via sudo, so the user needs passwordless sudo for it.
+      '';
+      example = "nix-darwin";
+    };
   };
 
   config = lib.mkIf cfg.enable {
@@ -75,6 +93,15 @@ in
       The agent will manage its own isolated repository clone.
     '';
 
+    # Makes `nixfleet-agent status` etc. available in the shell
+    home.packages = [ agentScript ];
+
+    # The CLI finds the Linux user service's control socket via this
+    # (macOS uses the built-in default ~/.local/state/nixfleet-agent/agent.sock)
+    home.sessionVariables = lib.mkIf pkgs.stdenv.isLinux {
+      NIXFLEET_CONTROL_SOCKET = "\${XDG_RUNTIME_DIR}/nixfleet-agent/agent.sock";
+    };
+
     # macOS launchd agent
     launchd.agents.nixfleet-agent = lib.mkIf pkgs.stdenv.isDarwin {
       enable = true;
@@ -113,15 +140,26 @@ in
             export NIXFLEET_REPO_DIR="$HOME/.local/state/nixfleet-agent/repo"
             export NIXFLEET_INTERVAL="${toString cfg.interval}"
             export NIXFLEET_LOG_LEVEL="${cfg.logLevel}"
+            export NIXFLEET_TEST_TIMEOUT="${toString cfg.testTimeout}"
             export NIXFLEET_TOKEN="$(cat '${cfg.tokenFile}')"
             ${lib.optionalString (cfg.hostname != "") ''export NIXFLEET_HOSTNAME="${cfg.hostname}"''}
             ${lib.optionalString (
               cfg.nixpkgsVersion != ""
             ) ''export NIXFLEET_NIXPKGS_VERSION="${cfg.nixpkgsVersion}"''}
             ${lib.optionalString (cfg.themeColor != "") ''export NIXFLEET_THEME_COLOR="${cfg.themeColor}"''}
+            ${lib.optionalString (cfg.gcOlderThan != "") ''export NIXFLEET_GC_OLDER_THAN="${cfg.gcOlderThan}"''}
             ${lib.optionalString (cfg.sshKeyFile != null) ''export NIXFLEET_SSH_KEY="${cfg.sshKeyFile}"''}
+            ${lib.optionalString (cfg.configFile != null) ''export NIXFLEET_CONFIG="${cfg.configFile}"''}
+            ${lib.optionalString (cfg.targets != null) ''export NIXFLEET_TARGETS="${cfg.targets}"''}
+            ${lib.optionalString (
+              cfg.execCommands != { }
+            ) ''export NIXFLEET_EXEC_COMMANDS="${shared.mkExecCommandsFile cfg}"''}
+            ${lib.optionalString (
+              cfg.serviceUnits != [ ]
+            ) ''export NIXFLEET_SERVICE_UNITS="${lib.concatStringsSep "," cfg.serviceUnits}"''}
             export NIXFLEET_LOCATION="${cfg.location}"
             export NIXFLEET_DEVICE_TYPE="${cfg.deviceType}"
+            export NIXFLEET_DARWIN_MODE="${cfg.darwinMode}"
             exec ${agentScript}/bin/nixfleet-agent
           ''
         ];
@@ -146,6 +184,8 @@ in
       Service = {
         Type = "simple";
         ExecStart = "${agentScript}/bin/nixfleet-agent";
+        # SIGHUP re-reads the environment and configFile
+        ExecReload = "${pkgs.coreutils}/bin/kill -HUP $MAINPID";
         Restart = "always";
         RestartSec = 3;
         Environment = [
@@ -156,11 +196,21 @@ in
           "NIXFLEET_REPO_DIR=%h/.local/state/nixfleet-agent/repo"
           "NIXFLEET_INTERVAL=${toString cfg.interval}"
           "NIXFLEET_LOG_LEVEL=${cfg.logLevel}"
+          "NIXFLEET_TEST_TIMEOUT=${toString cfg.testTimeout}"
+          "NIXFLEET_CONTROL_SOCKET=%t/nixfleet-agent/agent.sock"
         ]
         ++ lib.optional (cfg.hostname != "") "NIXFLEET_HOSTNAME=${cfg.hostname}"
         ++ lib.optional (cfg.nixpkgsVersion != "") "NIXFLEET_NIXPKGS_VERSION=${cfg.nixpkgsVersion}"
         ++ lib.optional (cfg.themeColor != "") "NIXFLEET_THEME_COLOR=${cfg.themeColor}"
+        ++ lib.optional (cfg.gcOlderThan != "") "NIXFLEET_GC_OLDER_THAN=${cfg.gcOlderThan}"
         ++ lib.optional (cfg.sshKeyFile != null) "NIXFLEET_SSH_KEY=${cfg.sshKeyFile}"
+        ++ lib.optional (cfg.configFile != null) "NIXFLEET_CONFIG=${cfg.configFile}"
+        ++ lib.optional (
+          cfg.execCommands != { }
+        ) "NIXFLEET_EXEC_COMMANDS=${shared.mkExecCommandsFile cfg}"
+        ++ lib.optional (
+          cfg.serviceUnits != [ ]
+        ) "NIXFLEET_SERVICE_UNITS=${lib.concatStringsSep "," cfg.serviceUnits}"
         ++ [
           "NIXFLEET_LOCATION=${cfg.location}"
           "NIXFLEET_DEVICE_TYPE=${cfg.deviceType}"
@@ -27,6 +27,15 @@ let
   cfg = config.services.nixfleet-agent;
   shared = import ./shared.nix { inherit lib pkgs; };
   agentScript = shared.mkAgentScript { };
+
+  # service-restart/-stop/-start run `sudo systemctl <action> <unit>`
+  serviceCommands = lib.concatMap (
+    unit:
+    map (action: {
+      command = "/run/current-system/sw/bin/systemctl ${action} ${unit}";
+      options = [ "NOPASSWD" ];
+    }) [ "restart" "stop" "start" ]
+  ) cfg.serviceUnits;
 in
 {
   options.services.nixfleet-agent = shared.mkCommonOptions // {
@@ -100,7 +109,16 @@ in
             command = "/run/current-system/sw/bin/nixos-rebuild";
             options = [ "NOPASSWD" ];
           }
-        ];
+          {
+            command = "/run/current-system/sw/bin/nix-collect-garbage";
+            options = [ "NOPASSWD" ];
+          }
+          {
+            command = "/run/current-system/sw/bin/nix-store --optimise";
+            options = [ "NOPASSWD" ];
+          }
+        ]
+        ++ serviceCommands;
       }
     ];
     # sudo-rs uses a different config namespace
@@ -112,10 +130,22 @@ in
             command = "/run/current-system/sw/bin/nixos-rebuild";
             options = [ "NOPASSWD" ];
           }
-        ];
+          {
+            command = "/run/current-system/sw/bin/nix-collect-garbage";
+            options = [ "NOPASSWD" ];
+          }
+          {
+            command = "/run/current-system/sw/bin/nix-store --optimise";
+            options = [ "NOPASSWD" ];
+          }
+        ]
+        ++ serviceCommands;
       }
     ];
 
+    # Makes `nixfleet-agent status` etc. available in the shell
+    environment.systemPackages = [ agentScript ];
+
     systemd.services.nixfleet-agent = {
       description = "NixFleet Agent - Fleet management daemon";
       documentation = [ "https://github.com/markus-barta/nixfleet" ];
@@ -154,6 +184,8 @@ in
       serviceConfig = {
         Type = "simple";
         ExecStart = "${agentScript}/bin/nixfleet-agent";
+        # SIGHUP re-reads the environment and configFile
+        ExecReload = "${pkgs.coreutils}/bin/kill -HUP $MAINPID";
         Restart = "always";
         RestartSec = 3;
         # Belt-and-suspenders: if the agent does get stopped somehow and
@@ -171,9 +203,16 @@ in
         StateDirectory = "nixfleet-agent";
         StateDirectoryMode = "0700";
 
+        # Control socket for `nixfleet-agent status|last-output|run`
+        # (/run/nixfleet-agent/agent.sock, only accessible to cfg.user and root)
+        RuntimeDirectory = "nixfleet-agent";
+        RuntimeDirectoryMode = "0700";
+
         # Run as specified user with sudo
         User = cfg.user;
         Group = "users";
+        # Journal read access for log streams (Follow Journal in the dashboard)
+        SupplementaryGroups = [ "systemd-journal" ];
         # Note: We intentionally avoid strict sandboxing here because:
         # - NoNewPrivileges must be false for sudo/sudo-rs to work
         # - ProtectSystem=strict can interfere with sudo-rs setuid detection
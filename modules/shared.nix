@@ -13,6 +13,8 @@ let
       description = ''
         WebSocket URL of the NixFleet dashboard.
         For v2 agents, use wss:// protocol (e.g., wss://fleet.example.com/ws).
+        A comma-separated list names fallback dashboards, tried in order;
+        the agent returns to the first one as soon as it is reachable again.
       '';
       example = "wss://fleet.example.com/ws";
     };
@@ -134,8 +136,124 @@ let
       '';
       example = "laptop";
     };
+
+    gcOlderThan = lib.mkOption {
+      type = lib.types.str;
+      default = "";
+      description = ''
+        Retention for the dashboard "gc" op, passed to
+        nix-collect-garbage --delete-older-than. If empty, gc only
+        removes unreachable store paths and keeps all generations.
+      '';
+      example = "14d";
+    };
+
+    testTimeout = lib.mkOption {
+      type = lib.types.ints.positive;
+      default = 300;
+      description = ''
+        Timeout in seconds for each script in hosts/<hostname>/tests/.
+        A script that runs longer is killed (with its children) and
+        reported as an error; the remaining scripts still run.
+      '';
+      example = 600;
+    };
+
+    configFile = lib.mkOption {
+      type = lib.types.nullOr lib.types.str;
+      default = null;
+      description = ''
+        Optional agent config file (TOML, or JSON if it ends in .json).
+        Keys override the options above; a token_file key keeps the token out
+        of the environment. Reload with `systemctl reload nixfleet-agent`
+        (SIGHUP) to apply url, token, interval, logLevel, themeColor, location,
+        deviceType, gcOlderThan, testTimeout, execCommands and serviceUnits
+        without a restart.
+        Use a path outside the Nix store if you want to edit it in place.
+      '';
+      example = "/etc/nixfleet-agent/config.toml";
+    };
+
+    targets = lib.mkOption {
+      type = lib.types.nullOr lib.types.str;
+      default = null;
+      description = ''
+        Deploy targets, switched and tracked separately: comma-separated
+        `[name=]type[:attr[:user]]` with type nixos, nix-darwin or home-manager.
+        attr defaults to the hostname; home-manager targets with a user are
+        switched via `sudo -u <user> -i`. Default: the host's system only
+        (NixOS) or what darwinMode selects (macOS).
+      '';
+      example = "nixos, alice=home-manager:alice@hsb1:alice";
+    };
+
+    execCommands = lib.mkOption {
+      type = lib.types.attrsOf (
+        lib.types.submodule {
+          options = {
+            command = lib.mkOption {
+              type = lib.types.nonEmptyListOf lib.types.str;
+              description = ''
+                Program and arguments, run without a shell as the agent's user.
+                An argument may contain {param} placeholders.
+              '';
+            };
+            params = lib.mkOption {
+              type = lib.types.attrsOf lib.types.str;
+              default = { };
+              description = "Regexp each placeholder's value must match in full.";
+            };
+            timeout = lib.mkOption {
+              type = lib.types.ints.between 0 1800;
+              default = 0;
+              description = "Timeout in seconds; 0 means 300.";
+            };
+            description = lib.mkOption {
+              type = lib.types.str;
+              default = "";
+            };
+          };
+        }
+      );
+      default = { };
+      description = ''
+        Commands the dashboard's "exec" op may run on this host, by name.
+        Nothing else can be run; exec requires TOTP on the dashboard and is
+        recorded in its audit log. Values never start with "-".
+      '';
+      example = lib.literalExpression ''
+        {
+          journal = {
+            command = [ "journalctl" "-u" "{unit}" "-n" "200" "--no-pager" ];
+            params.unit = "[a-zA-Z0-9@._-]+";
+          };
+          verify-store = {
+            command = [ "nix-store" "--verify" ];
+            timeout = 1800;
+          };
+        }
+      '';
+    };
+
+    serviceUnits = lib.mkOption {
+      type = lib.types.listOf lib.types.str;
+      default = [ ];
+      description = ''
+        Units the dashboard's service-restart, service-stop and service-start
+        ops may control on this host (systemd units, or launchd labels on
+        macOS). Empty disables the service ops.
+      '';
+      example = [
+        "nginx.service"
+        "app.service"
+      ];
+    };
   };
 
+  # Allowlist file for execCommands (NIXFLEET_EXEC_COMMANDS)
+  mkExecCommandsFile =
+    cfg: pkgs.writeText "nixfleet-exec-commands.json" (builtins.toJSON cfg.execCommands);
+
   # Build the Go agent package
   # Note: The package is now built via buildGoModule, not a shell script
   mkAgentScript = _: pkgs.callPackage ../packages/nixfleet-agent-v2.nix { };
@@ -150,10 +268,20 @@ let
       NIXFLEET_BRANCH = cfg.branch;
       NIXFLEET_INTERVAL = toString cfg.interval;
       NIXFLEET_LOG_LEVEL = cfg.logLevel;
+      NIXFLEET_TEST_TIMEOUT = toString cfg.testTimeout;
     }
     // lib.optionalAttrs (cfg.hostname != "") { NIXFLEET_HOSTNAME = cfg.hostname; }
     // lib.optionalAttrs (cfg.nixpkgsVersion != "") { NIXFLEET_NIXPKGS_VERSION = cfg.nixpkgsVersion; }
     // lib.optionalAttrs (cfg.themeColor != "") { NIXFLEET_THEME_COLOR = cfg.themeColor; }
+    // lib.optionalAttrs (cfg.gcOlderThan != "") { NIXFLEET_GC_OLDER_THAN = cfg.gcOlderThan; }
+    // lib.optionalAttrs (cfg.configFile != null) { NIXFLEET_CONFIG = cfg.configFile; }
+    // lib.optionalAttrs (cfg.targets != null) { NIXFLEET_TARGETS = cfg.targets; }
+    // lib.optionalAttrs (cfg.execCommands != { }) {
+      NIXFLEET_EXEC_COMMANDS = "${mkExecCommandsFile cfg}";
+    }
+    // lib.optionalAttrs (cfg.serviceUnits != [ ]) {
+      NIXFLEET_SERVICE_UNITS = lib.concatStringsSep "," cfg.serviceUnits;
+    }
     // {
       NIXFLEET_LOCATION = cfg.location;
     }
@@ -162,5 +290,10 @@ let
     };
 in
 {
-  inherit mkCommonOptions mkAgentScript mkEnvironment;
+  inherit
+    mkCommonOptions
+    mkAgentScript
+    mkEnvironment
+    mkExecCommandsFile
+    ;
 }
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/agent"
+	"github.com/markus-barta/nixfleet/internal/config"
+)
+
+// runSubcommand handles `nixfleet-agent status|last-output|run`, which talk to
+// the running agent over its control socket. Returns the process exit code.
+func runSubcommand(name string, args []string) int {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	socket := fs.String("socket", config.ControlSocketFromEnv(), "control socket path")
+	asJSON := fs.Bool("json", false, "print raw JSON")
+	target := fs.String("target", "", "deploy target for switch/pull-switch (default: all targets)")
+	_ = fs.Parse(args)
+
+	if *socket == "" {
+		fmt.Fprintln(os.Stderr, "control socket is disabled (NIXFLEET_CONTROL_SOCKET=off)")
+		return 1
+	}
+	client := agent.NewControlClient(*socket)
+
+	switch name {
+	case "status":
+		return ctlStatus(client, *asJSON)
+	case "last-output":
+		return ctlLastOutput(client, *asJSON)
+	case "run":
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "usage: nixfleet-agent run [--socket PATH] [--target NAME] <pull|switch|pull-switch|test|gc|refresh-lock>")
+			return 2
+		}
+		return ctlRun(client, fs.Arg(0), *target)
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	printUsage()
+	return 2
+}
+
+func ctlStatus(client *agent.ControlClient, asJSON bool) int {
+	status, err := client.Status()
+	if err != nil {
+		return ctlError(err)
+	}
+	if asJSON {
+		return printJSON(status)
+	}
+
+	connection := "disconnected"
+	if status.Connected && status.Registered {
+		connection = "connected"
+	} else if status.Connected {
+		connection = "connected (registering)"
+	}
+
+	fmt.Printf("Host:        %s\n", status.Hostname)
+	fmt.Printf("Agent:       %s\n", status.Version)
+	fmt.Printf("Dashboard:   %s (%s)\n", status.DashboardURL, connection)
+	if len(status.DashboardURLs) > 1 {
+		fmt.Printf("Failover:    %s\n", strings.Join(status.DashboardURLs, ", "))
+	}
+	fmt.Printf("Generation:  %s\n", status.Generation)
+	if status.PendingCommand != "" {
+		fmt.Printf("Running:     %s (pid %d)\n", status.PendingCommand, status.CommandPID)
+	} else {
+		fmt.Printf("Running:     -\n")
+	}
+	if status.LastCommand != "" && status.LastExitCode != nil {
+		fmt.Printf("Last:        %s (exit %d)\n", status.LastCommand, *status.LastExitCode)
+	}
+	if u := status.UpdateStatus; u != nil {
+		fmt.Println()
+		fmt.Printf("Git:         %s %s\n", u.Git.Status, u.Git.Message)
+		fmt.Printf("Lock:        %s %s\n", u.Lock.Status, u.Lock.Message)
+		fmt.Printf("System:      %s %s\n", u.System.Status, u.System.Message)
+		if len(u.Targets) > 1 {
+			for _, t := range u.Targets {
+				fmt.Printf("  %-10s %s %s\n", t.Name+":", t.System.Status, t.System.Message)
+			}
+		}
+		fmt.Printf("Tests:       %s %s\n", u.Tests.Status, u.Tests.Message)
+	}
+	if status.Freshness.SourceCommit != "" {
+		fmt.Println()
+		fmt.Printf("Built from:  %s\n", status.Freshness.SourceCommit)
+	}
+	return 0
+}
+
+func ctlLastOutput(client *agent.ControlClient, asJSON bool) int {
+	out, err := client.LastOutput()
+	if err != nil {
+		return ctlError(err)
+	}
+	if asJSON {
+		return printJSON(out)
+	}
+
+	printOutputLines(out.Lines)
+	if out.Dropped > 0 {
+		fmt.Fprintf(os.Stderr, "(%d earlier lines dropped)\n", out.Dropped)
+	}
+	if out.ExitCode != nil {
+		fmt.Fprintf(os.Stderr, "%s finished with exit code %d\n", out.Command, *out.ExitCode)
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "%s is still running\n", out.Command)
+	return 0
+}
+
+// ctlRun starts a command and follows its output until it finishes,
+// exiting with the command's exit code.
+func ctlRun(client *agent.ControlClient, command, target string) int {
+	if err := client.Run(command, target); err != nil {
+		return ctlError(err)
+	}
+
+	// printed counts lines since the command started; the server only keeps
+	// the newest maxOutputLines, out.Dropped tells how many fell off the front
+	printed := 0
+	for {
+		out, err := client.LastOutput()
+		if err != nil {
+			return ctlError(err)
+		}
+		start := printed - out.Dropped
+		if start < 0 {
+			start = 0
+		}
+		if start < len(out.Lines) {
+			printOutputLines(out.Lines[start:])
+		}
+		printed = out.Dropped + len(out.Lines)
+
+		if out.ExitCode != nil {
+			return *out.ExitCode
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func printOutputLines(lines []agent.OutputLine) {
+	for _, l := range lines {
+		if l.Stream == "stderr" {
+			fmt.Fprintln(os.Stderr, l.Line)
+		} else {
+			fmt.Println(l.Line)
+		}
+	}
+}
+
+func printJSON(v any) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return ctlError(err)
+	}
+	return 0
+}
+
+func ctlError(err error) int {
+	msg := err.Error()
+	if strings.Contains(msg, "connect: no such file or directory") || strings.Contains(msg, "connect: connection refused") {
+		msg = "agent is not running (no control socket)"
+	} else if strings.Contains(msg, "connect: permission denied") {
+		msg = "permission denied on control socket (run as the agent user or root)"
+	}
+	fmt.Fprintf(os.Stderr, "❌ %s\n", msg)
+	return 1
+}
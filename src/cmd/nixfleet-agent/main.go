@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -21,6 +23,7 @@ func main() {
 	showVersion := flag.Bool("version", false, "print version and exit")
 	showHelp := flag.Bool("help", false, "show usage")
 	runCheck := flag.Bool("check", false, "validate config and test connectivity")
+	configPath := flag.String("config", os.Getenv("NIXFLEET_CONFIG"), "config file (TOML or JSON)")
 
 	// Short flags
 	flag.BoolVar(showVersion, "v", false, "print version and exit")
@@ -40,7 +43,12 @@ func main() {
 	}
 
 	if *runCheck {
-		os.Exit(runConfigCheck())
+		os.Exit(runConfigCheck(*configPath))
+	}
+
+	// Subcommands talk to the running agent over its control socket
+	if flag.NArg() > 0 {
+		os.Exit(runSubcommand(flag.Arg(0), flag.Args()[1:]))
 	}
 
 	// Set up logging
@@ -50,27 +58,19 @@ func main() {
 		Logger()
 
 	// Load configuration
-	cfg, err := config.LoadFromEnv()
+	cfg, err := config.Load(*configPath)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to load configuration")
 	}
 
 	// Set log level
-	switch cfg.LogLevel {
-	case "debug":
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	case "warn":
-		zerolog.SetGlobalLevel(zerolog.WarnLevel)
-	case "error":
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
-	default:
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
-	}
+	agent.SetLogLevel(cfg.LogLevel)
 
 	log.Info().
 		Str("version", agent.Version).
 		Str("hostname", cfg.Hostname).
-		Str("url", cfg.DashboardURL).
+		Strs("urls", cfg.DashboardURLs()).
+		Str("config_file", cfg.ConfigFile).
 		Msg("NixFleet Agent starting")
 
 	// Create agent
@@ -86,6 +86,21 @@ func main() {
 		a.Shutdown()
 	}()
 
+	// SIGHUP: reload config file and environment, keep running on errors
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+
+	go func() {
+		for range hupCh {
+			next, err := config.Load(*configPath)
+			if err != nil {
+				log.Error().Err(err).Msg("config reload failed, keeping current config")
+				continue
+			}
+			a.Reload(next)
+		}
+	}()
+
 	// Run agent
 	if err := a.Run(); err != nil {
 		log.Fatal().Err(err).Msg("agent failed")
@@ -94,6 +109,7 @@ func main() {
 
 func printUsage() {
 	fmt.Printf(`Usage: nixfleet-agent [options]
+       nixfleet-agent <command> [--socket PATH] [--json]
 
 NixFleet Agent %s - connects to NixFleet dashboard for fleet management.
 
@@ -101,9 +117,18 @@ Options:
   -v, --version   Print version and exit
   -h, --help      Print this help and exit
   --check         Validate config and test connectivity
+  --config PATH   Config file, TOML or JSON (default: $NIXFLEET_CONFIG)
+
+Commands (talk to the running agent via its control socket):
+  status          Show connection state, running command and update status
+  last-output     Print the output of the current or last command
+  run <command>   Run pull, switch, pull-switch, test, gc or refresh-lock
+                  and follow its output (exit code = command's exit code);
+                  --target NAME switches a single deploy target
 
 Environment variables:
-  NIXFLEET_URL              Dashboard WebSocket URL (required)
+  NIXFLEET_URL              Dashboard WebSocket URL (required); a comma-separated
+                            list adds fallbacks, tried in order
   NIXFLEET_TOKEN            Authentication token (required)
   NIXFLEET_REPO_URL         Git repository URL (for isolated mode)
   NIXFLEET_REPO_DIR         Local repository path
@@ -115,58 +140,131 @@ Environment variables:
   NIXFLEET_THEME_COLOR      Host theme color (hex, e.g. #7aa2f7)
   NIXFLEET_LOCATION         Location: home, work, cloud
   NIXFLEET_DEVICE_TYPE      Device type: server, desktop, laptop, gaming
+  NIXFLEET_GC_OLDER_THAN    Delete generations older than this on gc (e.g. 14d)
+  NIXFLEET_TEST_TIMEOUT     Per test script timeout in seconds (default: 300)
+  NIXFLEET_DARWIN_MODE      macOS: home-manager (default), nix-darwin or both
+  NIXFLEET_TARGETS          Deploy targets, e.g. "nixos, alice=home-manager:alice@ws1:alice"
+  NIXFLEET_EXEC_COMMANDS    JSON file of commands the exec op may run
+  NIXFLEET_SERVICE_UNITS    Units the service-* ops may control, e.g. "nginx.service,app.service"
+  NIXFLEET_CONTROL_SOCKET   Control socket path ("off" disables it)
+  NIXFLEET_CONFIG           Config file path (same as --config)
+  HTTPS_PROXY, NO_PROXY     Proxy for the dashboard connection (wss://);
+                            HTTP_PROXY for ws://
+
+Config file:
+  Optional. Keys override the environment: url, token, token_file, repo_url,
+  repo_dir, branch, ssh_key, interval, log_level, hostname, nixpkgs_version,
+  theme_color, location, device_type, gc_older_than, test_timeout,
+  darwin_mode, targets, control_socket, exec_commands, service_units.
+  Send SIGHUP to reload; url, token, interval, log_level, theme_color,
+  location, device_type, gc_older_than, test_timeout, exec_commands and
+  service_units apply without a restart.
 `, agent.Version)
 }
 
-func runConfigCheck() int {
+func runConfigCheck(configPath string) int {
 	fmt.Println("Checking configuration...")
 	fmt.Println()
 
 	// Load config
-	cfg, err := config.LoadFromEnv()
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		fmt.Printf("❌ Config error: %v\n", err)
 		return 1
 	}
 
 	fmt.Println("✓ Config OK")
+	if cfg.ConfigFile != "" {
+		fmt.Printf("  File:        %s\n", cfg.ConfigFile)
+	}
 	fmt.Printf("  Hostname:    %s\n", cfg.Hostname)
 	fmt.Printf("  Dashboard:   %s\n", cfg.DashboardURL)
+	for _, url := range cfg.FallbackURLs {
+		fmt.Printf("  Fallback:    %s\n", url)
+	}
 	fmt.Printf("  Repo Dir:    %s\n", cfg.RepoDir)
 	if cfg.RepoURL != "" {
 		fmt.Printf("  Repo URL:    %s\n", cfg.RepoURL)
 	}
+	if runtime.GOOS == "darwin" {
+		fmt.Printf("  Darwin Mode: %s\n", cfg.DarwinMode)
+	}
+	fmt.Printf("  Targets:     %s\n", config.FormatTargets(cfg.DeployTargets()))
 	fmt.Printf("  Branch:      %s\n", cfg.Branch)
+	if len(cfg.ExecCommands) > 0 {
+		names := make([]string, 0, len(cfg.ExecCommands))
+		for name := range cfg.ExecCommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Printf("  Exec:        %s\n", strings.Join(names, ", "))
+	}
+	if len(cfg.ServiceUnits) > 0 {
+		fmt.Printf("  Services:    %s\n", strings.Join(cfg.ServiceUnits, ", "))
+	}
+	fmt.Println()
+
+	// Test connectivity in failover order: the agent uses the first
+	// endpoint that answers
+	fmt.Println("Testing dashboard connectivity...")
+	active := ""
+	for _, url := range cfg.DashboardURLs() {
+		fmt.Printf("  %s ", url)
+		latency, proxy, err := checkEndpoint(url)
+		if proxy != "" {
+			fmt.Printf("(via proxy %s) ", proxy)
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		fmt.Printf("✓ OK (latency: %dms)", latency.Milliseconds())
+		if active == "" {
+			active = url
+			fmt.Print(" ← active")
+		}
+		fmt.Println()
+	}
 	fmt.Println()
 
-	// Test connectivity
-	fmt.Print("Testing dashboard connectivity... ")
+	if active == "" {
+		fmt.Println("❌ No dashboard reachable")
+		return 1
+	}
+	fmt.Printf("✓ Active endpoint: %s\n", active)
+	return 0
+}
 
+// checkEndpoint sends an HTTP request to a dashboard's WebSocket URL, through
+// the proxy from HTTPS_PROXY/NO_PROXY like the agent, which it also returns.
+func checkEndpoint(wsURL string) (latency time.Duration, proxy string, err error) {
 	// Convert WebSocket URL to HTTP for health check
-	httpURL := cfg.DashboardURL
+	httpURL := wsURL
 	httpURL = strings.Replace(httpURL, "wss://", "https://", 1)
 	httpURL = strings.Replace(httpURL, "ws://", "http://", 1)
 	// Strip /ws/agent suffix if present
 	httpURL = strings.TrimSuffix(httpURL, "/ws/agent")
 	httpURL = strings.TrimSuffix(httpURL, "/ws")
 
+	req, err := http.NewRequest(http.MethodGet, httpURL, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	if u, err := http.ProxyFromEnvironment(req); err == nil && u != nil {
+		proxy = u.Redacted()
+	}
+
 	client := &http.Client{Timeout: 10 * time.Second}
 	start := time.Now()
-	resp, err := client.Get(httpURL)
-	latency := time.Since(start)
-
+	resp, err := client.Do(req)
+	latency = time.Since(start)
 	if err != nil {
-		fmt.Printf("❌ Failed\n")
-		fmt.Printf("  Error: %v\n", err)
-		return 1
+		return latency, proxy, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		fmt.Printf("❌ Failed (HTTP %d)\n", resp.StatusCode)
-		return 1
+		return latency, proxy, fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
-
-	fmt.Printf("✓ OK (latency: %dms)\n", latency.Milliseconds())
-	return 0
+	return latency, proxy, nil
 }
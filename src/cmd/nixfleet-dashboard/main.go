@@ -14,6 +14,11 @@ import (
 )
 
 func main() {
+	// `nixfleet-dashboard token ...` manages API tokens, then exits
+	if len(os.Args) > 1 && os.Args[1] == "token" {
+		os.Exit(runTokenCommand(os.Args[2:]))
+	}
+
 	// Set up logging
 	log := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).
 		With().Timestamp().Logger()
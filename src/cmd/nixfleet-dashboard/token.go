@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/dashboard"
+	"github.com/markus-barta/nixfleet/internal/store"
+	"github.com/rs/zerolog"
+)
+
+const tokenUsage = `usage: nixfleet-dashboard token <command>
+
+  create [--scope read|dispatch|admin] [--kind service|personal]
+         [--hosts SELECTOR] [--expires-days N] NAME
+                       create a token and print its secret (shown once)
+  list                 list tokens
+  revoke NAME          delete a token
+
+Run on the dashboard host with its environment (NIXFLEET_DB_PATH or
+NIXFLEET_DATA_DIR); the database is opened directly.`
+
+// runTokenCommand handles `nixfleet-dashboard token ...`. Returns the process
+// exit code.
+func runTokenCommand(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprintln(os.Stderr, tokenUsage)
+		return 2
+	}
+
+	cfg, err := dashboard.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	db, err := dashboard.InitDatabase(cfg.DatabasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = db.Close() }()
+	events := store.New(zerolog.New(os.Stderr), db)
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("create", flag.ExitOnError)
+		scope := fs.String("scope", dashboard.ScopeRead, "read, dispatch or admin")
+		kind := fs.String("kind", "service", "service or personal")
+		hosts := fs.String("hosts", "", "host selector the token is restricted to, e.g. hosts:web1,web2")
+		expires := fs.Int("expires-days", 0, "days until the token expires (0 = never)")
+		_ = fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, tokenUsage)
+			return 2
+		}
+
+		token, secret, err := dashboard.CreateAPIToken(db, dashboard.NewAPIToken{
+			Name:         fs.Arg(0),
+			Kind:         *kind,
+			Scope:        *scope,
+			HostSelector: *hosts,
+			ExpiresDays:  *expires,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		dashboard.LogAPITokenCreated(events, "cli", token)
+		fmt.Fprintf(os.Stderr, "Created %s token %q (scope %s). Store the secret now, it is not shown again:\n",
+			token.Kind, token.Name, token.Scope)
+		fmt.Println(secret)
+		return 0
+
+	case "list":
+		tokens, err := dashboard.ListAPITokens(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tKIND\tSCOPE\tHOSTS\tEXPIRES\tLAST USED")
+		for _, t := range tokens {
+			hosts := t.HostSelector
+			if hosts == "" {
+				hosts = "all"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", t.Name, t.Kind, t.Scope, hosts,
+				formatTokenTime(t.ExpiresAt, "never"), formatTokenTime(t.LastUsedAt, "-"))
+		}
+		_ = tw.Flush()
+		return 0
+
+	case "revoke":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, tokenUsage)
+			return 2
+		}
+		if err := dashboard.RevokeAPIToken(db, args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 1
+		}
+		dashboard.LogAPITokenRevoked(events, "cli", args[1])
+		fmt.Printf("Revoked %s\n", args[1])
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown token command %q\n\n%s\n", args[0], tokenUsage)
+	return 2
+}
+
+func formatTokenTime(t *time.Time, unset string) string {
+	if t == nil {
+		return unset
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
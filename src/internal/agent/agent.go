@@ -3,7 +3,9 @@ package agent
 
 import (
 	"context"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/markus-barta/nixfleet/internal/config"
 	"github.com/markus-barta/nixfleet/internal/protocol"
@@ -21,8 +23,12 @@ type Agent struct {
 	// State
 	mu             sync.RWMutex
 	registered     bool
+	batchOutput    bool   // dashboard accepts output_batch (see output_batch.go)
+	flakeLockSent  string // lock hash whose flake inputs the dashboard has (see flakelock.go)
 	pendingCommand *string
+	commandReq     protocol.CommandPayload // request of pendingCommand (ID, args)
 	commandPID     *int
+	logStreams     map[string]*logStream // by stream ID (see logstream.go)
 
 	// System info (cached)
 	generation     string
@@ -31,6 +37,32 @@ type Agent struct {
 
 	// Update status checker
 	statusChecker *StatusChecker
+
+	// Nix store size (measured in background, see disk.go)
+	storeMu        sync.Mutex
+	storeBytes     int64
+	storeCheckedAt time.Time
+	storeMeasuring bool
+
+	// Host facts (collected every factsInterval, see facts.go)
+	factsMu          sync.Mutex
+	facts            *protocol.HostFacts
+	factsCollectedAt time.Time
+	factsSent        *protocol.HostFacts // facts the dashboard has
+
+		// Failed systemd units (cached, see services.go)
+	servicesMu        sync.Mutex
+	services          *protocol.ServicesStatus
+	servicesCheckedAt time.Time
+
+	// Output of the current/last command (served on the control socket)
+	output outputRecorder
+
+	// Output lines waiting to be sent as one output_batch
+	outputBatch outputBatcher
+
+	// New heartbeat interval after a config reload (see reload.go)
+	intervalCh chan time.Duration
 }
 
 // New creates a new agent with the given configuration.
@@ -41,8 +73,11 @@ func New(cfg *config.Config, log zerolog.Logger) *Agent {
 		log:    log.With().Str("component", "agent").Logger(),
 		ctx:    ctx,
 		cancel: cancel,
+
+		intervalCh: make(chan time.Duration, 1),
 	}
 	a.statusChecker = NewStatusChecker(a)
+	a.outputBatch.send = a.sendOutputBatch
 	// Run initial status checks immediately so first heartbeat has data
 	a.statusChecker.ForceRefresh(ctx)
 	return a
@@ -52,7 +87,7 @@ func New(cfg *config.Config, log zerolog.Logger) *Agent {
 func (a *Agent) Run() error {
 	a.log.Info().
 		Str("hostname", a.cfg.Hostname).
-		Str("url", a.cfg.DashboardURL).
+		Strs("urls", a.cfg.DashboardURLs()).
 		Str("repo_dir", a.cfg.RepoDir).
 		Bool("isolated_mode", a.cfg.RepoURL != "").
 		Msg("starting agent")
@@ -86,6 +121,13 @@ func (a *Agent) Run() error {
 		a.messageLoop()
 	}()
 
+	// Local control socket (nixfleet-agent status/last-output/run)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.controlLoop()
+	}()
+
 	// WebSocket connection loop (blocks until shutdown)
 	a.ws.Run(a.ctx)
 
@@ -111,6 +153,14 @@ func (a *Agent) Shutdown() {
 func (a *Agent) OnConnected() {
 	a.log.Info().Msg("connected to dashboard")
 
+	a.sendRegistration()
+}
+
+// sendRegistration announces the host to the dashboard. Also used after a
+// config reload to push changed metadata (location, device type, ...).
+func (a *Agent) sendRegistration() {
+	cfg := a.currentConfig()
+
 	// Get binary freshness data (P2810)
 	freshness := GetFreshness()
 
@@ -122,22 +172,31 @@ func (a *Agent) OnConnected() {
 		OSVersion:         a.osVersion,
 		NixpkgsVersion:    a.nixpkgsVersion,
 		Generation:        a.generation,
-		ThemeColor:        a.cfg.ThemeColor,
-		HeartbeatInterval: int(a.cfg.HeartbeatInterval.Seconds()),
-		Location:          a.cfg.Location,
-		DeviceType:        a.cfg.DeviceType,
+		ThemeColor:        cfg.ThemeColor,
+		HeartbeatInterval: int(cfg.HeartbeatInterval.Seconds()),
+		Location:          cfg.Location,
+		DeviceType:        cfg.DeviceType,
 		RepoURL:           a.cfg.RepoURL,
 		RepoDir:           a.cfg.RepoDir,
+		DarwinMode:        a.darwinMode(),
+		DashboardURL:      a.ws.ActiveURL(),
 		// P2810: 3-layer binary freshness
 		SourceCommit: freshness.SourceCommit,
 		StorePath:    freshness.StorePath,
 		BinaryHash:   freshness.BinaryHash,
+		// Capability negotiation
+		ProtocolVersion: protocol.ProtocolVersion,
+		Capabilities:    capabilities(),
 	}
+	payload.Capabilities.Exec = cfg.ExecCommands
+	payload.Capabilities.ServiceUnits = cfg.ServiceUnits
+	payload.Facts = a.currentFacts()
 
 	if err := a.ws.SendMessage(protocol.TypeRegister, payload); err != nil {
 		a.log.Error().Err(err).Msg("failed to send registration")
 		return
 	}
+	a.markFactsSent(payload.Facts)
 
 	a.log.Debug().
 		Str("source_commit", freshness.SourceCommit).
@@ -149,7 +208,10 @@ func (a *Agent) OnConnected() {
 func (a *Agent) OnDisconnected() {
 	a.mu.Lock()
 	a.registered = false
+	a.batchOutput = false
+	a.flakeLockSent = ""
 	a.mu.Unlock()
+	a.stopLogStreams()
 	a.log.Warn().Msg("disconnected from dashboard")
 }
 
@@ -164,8 +226,9 @@ func (a *Agent) OnMessage(msg *protocol.Message) {
 		}
 		a.mu.Lock()
 		a.registered = true
+		a.batchOutput = slices.Contains(payload.Features, protocol.FeatureOutputBatch)
 		a.mu.Unlock()
-		a.log.Info().Str("host_id", payload.HostID).Msg("registered with dashboard")
+		a.log.Info().Str("host_id", payload.HostID).Strs("features", payload.Features).Msg("registered with dashboard")
 
 		// Send first heartbeat immediately
 		a.sendHeartbeat()
@@ -176,7 +239,7 @@ func (a *Agent) OnMessage(msg *protocol.Message) {
 			a.log.Error().Err(err).Msg("failed to parse command payload")
 			return
 		}
-		a.handleCommand(payload.Command)
+		a.handleCommand(payload)
 
 	case protocol.TypeKillCommand:
 		// P2800: Handle kill command from dashboard
@@ -187,6 +250,22 @@ func (a *Agent) OnMessage(msg *protocol.Message) {
 		}
 		a.handleKillCommand(payload.Signal, payload.PID)
 
+	case protocol.TypeLogStreamStart:
+		var payload protocol.LogStreamStartPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			a.log.Error().Err(err).Msg("failed to parse log_stream_start payload")
+			return
+		}
+		a.handleLogStreamStart(payload)
+
+	case protocol.TypeLogStreamStop:
+		var payload protocol.LogStreamStopPayload
+		if err := msg.ParsePayload(&payload); err != nil {
+			a.log.Error().Err(err).Msg("failed to parse log_stream_stop payload")
+			return
+		}
+		a.handleLogStreamStop(payload.StreamID)
+
 	default:
 		a.log.Warn().Str("type", msg.Type).Msg("unknown message type")
 	}
@@ -2,16 +2,17 @@ package agent
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 	"sync"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/markus-barta/nixfleet/internal/config"
 	"github.com/markus-barta/nixfleet/internal/protocol"
 )
 
@@ -21,9 +22,35 @@ func timeAfter(seconds int) <-chan time.Time {
 	return time.After(time.Duration(seconds) * time.Second)
 }
 
+// capabilities lists what this agent supports; the dashboard blocks ops it
+// would not handle instead of sending them. Keep Commands in sync with
+// handleCommand and executeCommand.
+func capabilities() *protocol.Capabilities {
+	return &protocol.Capabilities{
+		Commands: []string{
+			"pull", "switch", "pull-switch", "test", "rollback", "stop", "restart", "reboot",
+			"update", "force-update", "check-version", "gc", "exec",
+			"service-restart", "service-stop", "service-start",
+			"refresh-git", "refresh-lock", "refresh-system", "refresh-all",
+		},
+		MessageTypes: []string{
+			protocol.TypeRegister, protocol.TypeHeartbeat, protocol.TypeOutput, protocol.TypeStatus,
+			protocol.TypeRejected, protocol.TypeTestProgress, protocol.TypeTestReport,
+			protocol.TypeOperationProgress, protocol.TypeCommandProgress, protocol.TypeOutputBatch,
+			protocol.TypeLogStream, protocol.TypeLogStreamEnd,
+		},
+		Features: []string{
+			protocol.FeatureKill, protocol.FeatureFreshness, protocol.FeatureStructuredTests,
+			protocol.FeatureCommandIDs, protocol.FeatureTargets, protocol.FeatureRollbackGeneration,
+			protocol.FeatureGitRef, protocol.FeatureOutputBatch, protocol.FeatureLogStream,
+		},
+	}
+}
+
 // handleCommand processes an incoming command.
-func (a *Agent) handleCommand(command string) {
-	a.log.Info().Str("command", command).Msg("received command")
+func (a *Agent) handleCommand(req protocol.CommandPayload) {
+	command := req.Command
+	a.log.Info().Str("command", command).Str("command_id", req.ID).Interface("args", req.Args).Msg("received command")
 
 	// Special commands that work even when busy
 	switch command {
@@ -60,6 +87,7 @@ func (a *Agent) handleCommand(command string) {
 			Msg("command rejected: already busy")
 
 		payload := protocol.CommandRejectedPayload{
+			CommandID:      req.ID,
 			Reason:         "command already running",
 			CurrentCommand: currentCmd,
 			CurrentPID:     currentPID,
@@ -71,25 +99,46 @@ func (a *Agent) handleCommand(command string) {
 	}
 
 	// Execute command in goroutine
-	go a.executeCommand(command)
+	go a.executeCommand(req)
 }
 
-// executeCommand runs a command and streams output.
-func (a *Agent) executeCommand(command string) {
+// executeCommand runs a command and streams output. Output, progress and
+// status messages carry req.ID so the dashboard can match them.
+func (a *Agent) executeCommand(req protocol.CommandPayload) {
+	command, target := req.Command, req.Args.Target
+
 	// Set busy state
 	a.mu.Lock()
 	a.pendingCommand = &command
+	a.commandReq = req
 	a.mu.Unlock()
+	a.output.begin(command)
 
 	defer func() {
 		a.mu.Lock()
 		a.pendingCommand = nil
+		a.commandReq = protocol.CommandPayload{}
 		a.commandPID = nil
 		a.mu.Unlock()
 	}()
 
+	// Deploy targets this command acts on (all unless one is named)
+	targets, err := a.resolveTargets(target)
+	if err == nil {
+		err = checkCommandArgs(command, req.Args, targets)
+	}
+	if err != nil {
+		a.sendStatus("error", command, 1, err.Error())
+		return
+	}
+
+	// Legacy mode pulls the checked-out branch in place
+	if req.Args.Ref != "" && a.cfg.RepoURL == "" {
+		a.sendStatus("error", command, 1, "deploying a git ref requires isolated mode (NIXFLEET_REPO_URL)")
+		return
+	}
+
 	var cmd *exec.Cmd
-	var err error
 
 	switch command {
 	case "pull":
@@ -97,7 +146,7 @@ func (a *Agent) executeCommand(command string) {
 		a.sendOperationProgress("pull", "in_progress", 0, 4)
 		if a.cfg.RepoURL != "" {
 			// Isolated mode: fetch + reset --hard (clean slate)
-			if err := a.runIsolatedPull(); err != nil {
+			if err := a.runIsolatedPull(req.Args.Ref); err != nil {
 				a.sendOperationProgress("pull", "error", 0, 4)
 				a.sendStatus("error", command, 1, err.Error())
 				return
@@ -113,15 +162,15 @@ func (a *Agent) executeCommand(command string) {
 		// P2800: Signal system phase starting
 		a.sendOperationProgress("system", "in_progress", 0, 3)
 		// P1100: Set working state so compartment shows blue pulse
-		a.statusChecker.SetSystemWorking()
-		cmd, err = a.buildSwitchCommand()
+		a.statusChecker.SetSystemWorking(target)
+		cmd, err = a.buildSwitchCommand(targets)
 	case "pull-switch":
 		// P2800: Signal pull phase starting
 		a.sendOperationProgress("pull", "in_progress", 0, 4)
 		// Pull first
 		if a.cfg.RepoURL != "" {
 			// Isolated mode: fetch + reset --hard (clean slate)
-			if err := a.runIsolatedPull(); err != nil {
+			if err := a.runIsolatedPull(req.Args.Ref); err != nil {
 				a.sendOperationProgress("pull", "error", 0, 4)
 				a.sendStatus("error", command, 1, err.Error())
 				return
@@ -137,21 +186,21 @@ func (a *Agent) executeCommand(command string) {
 		a.sendOperationProgress("pull", "complete", 4, 4)
 		a.sendOperationProgress("system", "in_progress", 0, 3)
 		// P1100: Set working state so compartment shows blue pulse
-		a.statusChecker.SetSystemWorking()
+		a.statusChecker.SetSystemWorking(target)
 		// Force refresh status after pull so switch sees updated state
 		a.statusChecker.ForceRefresh(a.ctx)
 		// Then switch
-		cmd, err = a.buildSwitchCommand()
+		cmd, err = a.buildSwitchCommand(targets)
 	case "test":
-		// P2800: Signal tests phase starting
-		a.sendOperationProgress("tests", "in_progress", 0, 8)
 		// P1100: Set working state so compartment shows blue pulse
 		a.statusChecker.SetTestsWorking()
-		cmd, err = a.buildTestCommand()
+		// Each script runs separately with its own timeout and result
+		a.handleTests()
+		return
 	case "rollback":
 		// P4600: Rollback to previous generation
 		a.sendOutput("🔄 Rolling back to previous generation...", "stdout")
-		cmd, err = a.buildRollbackCommand()
+		cmd, err = a.buildRollbackCommand(targets, req.Args.Generation)
 	case "update":
 		cmd, err = a.buildUpdateCommand()
 
@@ -172,7 +221,14 @@ func (a *Agent) executeCommand(command string) {
 		return
 	case "refresh-system":
 		a.sendOutput("Refreshing system status (this may take some time)...", "stdout")
-		a.statusChecker.RefreshSystem(a.ctx)
+		a.statusChecker.RefreshSystem(a.ctx, targets)
+		if cfg := a.currentConfig(); len(cfg.DeployTargets()) > 1 {
+			for _, ts := range a.statusChecker.GetTargetStatuses() {
+				if ts.System.Status != "" {
+					a.sendOutput(fmt.Sprintf("  %s (%s): %s (%s)", ts.Name, ts.Type, ts.System.Status, ts.System.Message), "stdout")
+				}
+			}
+		}
 		sysStatus := a.statusChecker.GetSystemStatus()
 		a.sendOutput(fmt.Sprintf("✓ System status: %s (%s)", sysStatus.Status, sysStatus.Message), "stdout")
 		a.sendStatus("ok", command, 0, sysStatus.Status)
@@ -198,6 +254,21 @@ func (a *Agent) executeCommand(command string) {
 		a.handleCheckVersion()
 		return
 
+	// Nix store garbage collection + optimise
+	case "gc":
+		a.handleGC()
+		return
+
+	// Allowlisted ad-hoc command
+	case "exec":
+		a.handleExec(req.Args)
+		return
+
+	// Restart/stop/start an allowlisted unit
+	case "service-restart", "service-stop", "service-start":
+		a.handleServiceOp(command, req.Args)
+		return
+
 	default:
 		a.log.Error().Str("command", command).Msg("unknown command")
 		a.sendStatus("error", command, 1, "unknown command")
@@ -210,8 +281,13 @@ func (a *Agent) executeCommand(command string) {
 		return
 	}
 
-	// Run with output streaming
-	exitCode := a.runWithStreaming(cmd)
+	// Run with output streaming; switches report nix build progress
+	var exitCode int
+	if command == "switch" || command == "pull-switch" {
+		exitCode = a.runNixBuild(cmd)
+	} else {
+		exitCode = a.runWithStreaming(cmd)
+	}
 
 	status := "ok"
 	message := ""
@@ -236,7 +312,7 @@ func (a *Agent) executeCommand(command string) {
 		} else {
 			a.sendOperationProgress("system", "error", 0, 3)
 			// P3800: Switch failed → set system to error
-			a.statusChecker.SetSystemError(fmt.Sprintf("Switch failed (exit %d)", exitCode))
+			a.statusChecker.SetTargetSystem(target, "error", fmt.Sprintf("Switch failed (exit %d)", exitCode))
 		}
 	case "pull-switch":
 		// Pull already marked complete, now mark system
@@ -245,26 +321,16 @@ func (a *Agent) executeCommand(command string) {
 		} else {
 			a.sendOperationProgress("system", "error", 0, 3)
 			// P3800: Switch portion failed → set system to error
-			a.statusChecker.SetSystemError(fmt.Sprintf("Pull+Switch failed (exit %d)", exitCode))
-		}
-	case "test":
-		if exitCode == 0 {
-			a.sendOperationProgress("tests", "complete", 8, 8)
-			// P3900: Set tests status to ok
-			a.statusChecker.SetTestsOk("All tests passed")
-		} else {
-			a.sendOperationProgress("tests", "error", 0, 8)
-			// P3900: Set tests status to error
-			a.statusChecker.SetTestsError(fmt.Sprintf("Tests failed (exit %d)", exitCode))
+			a.statusChecker.SetTargetSystem(target, "error", fmt.Sprintf("Pull+Switch failed (exit %d)", exitCode))
 		}
 	case "rollback":
 		// P4600: Rollback updates system status
 		if exitCode == 0 {
-			a.statusChecker.SetSystemOk("Rollback successful (exit 0)")
+			a.statusChecker.SetTargetSystem(target, "ok", "Rollback successful (exit 0)")
 			// Tests need re-run after rollback
 			a.statusChecker.SetTestsOutdated("Tests not run — run Test to verify system after rollback")
 		} else {
-			a.statusChecker.SetSystemError(fmt.Sprintf("Rollback failed (exit %d)", exitCode))
+			a.statusChecker.SetTargetSystem(target, "error", fmt.Sprintf("Rollback failed (exit %d)", exitCode))
 		}
 	}
 
@@ -307,7 +373,7 @@ func (a *Agent) executeCommand(command string) {
 		case "switch":
 			// Goal: system should now be current
 			// Infer System=ok from exit code (avoids expensive nix build --dry-run)
-			a.statusChecker.SetSystemOk("Switch successful (exit 0)")
+			a.statusChecker.SetTargetSystem(target, "ok", "Switch successful (exit 0)")
 			// P3900: Mark tests as outdated after switch (need to verify system works)
 			a.statusChecker.SetTestsOutdated("Tests not run — run Test to verify system")
 			a.sendOutput("", "stdout")
@@ -317,7 +383,7 @@ func (a *Agent) executeCommand(command string) {
 		case "pull-switch":
 			// Goal: both should be current
 			// Infer System=ok from exit code (avoids expensive nix build --dry-run)
-			a.statusChecker.SetSystemOk("Pull+Switch successful (exit 0)")
+			a.statusChecker.SetTargetSystem(target, "ok", "Pull+Switch successful (exit 0)")
 			// P3900: Mark tests as outdated after switch (need to verify system works)
 			a.statusChecker.SetTestsOutdated("Tests not run — run Test to verify system")
 			a.sendOutput("", "stdout")
@@ -357,6 +423,29 @@ func (a *Agent) executeCommand(command string) {
 
 // runWithStreaming runs a command and streams stdout/stderr.
 func (a *Agent) runWithStreaming(cmd *exec.Cmd) int {
+	return a.runWithLineHook(cmd, nil)
+}
+
+// runWithLineHook is runWithStreaming with an optional callback that sees every
+// output line (stdout and stderr) after it has been streamed. The hook may be
+// called from two goroutines concurrently.
+func (a *Agent) runWithLineHook(cmd *exec.Cmd, hook func(line string)) int {
+	return a.runWithLineHandler(cmd, func(line, stream string) {
+		a.sendOutput(line, stream)
+		if hook != nil {
+			hook(line)
+		}
+	})
+}
+
+// maxLineLength bounds an output line; nix's internal-json error messages
+// can be longer than bufio.Scanner's default 64 KiB.
+const maxLineLength = 1024 * 1024
+
+// runWithLineHandler runs a command and passes every output line with its
+// stream ("stdout" or "stderr") to handle, which decides what to send. It
+// may be called from two goroutines concurrently.
+func (a *Agent) runWithLineHandler(cmd *exec.Cmd, handle func(line, stream string)) int {
 	// Set up pipes
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -390,22 +479,25 @@ func (a *Agent) runWithStreaming(cmd *exec.Cmd) int {
 	go func() {
 		defer wg.Done()
 		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(nil, maxLineLength)
 		for scanner.Scan() {
-			a.sendOutput(scanner.Text(), "stdout")
+			handle(scanner.Text(), "stdout")
 		}
 	}()
 
 	go func() {
 		defer wg.Done()
 		scanner := bufio.NewScanner(stderr)
+		scanner.Buffer(nil, maxLineLength)
 		for scanner.Scan() {
-			a.sendOutput(scanner.Text(), "stderr")
+			handle(scanner.Text(), "stderr")
 		}
 	}()
 
-	// Wait for command to complete, then drain remaining output.
-	err = cmd.Wait()
+	// Drain output before Wait: Wait closes the pipes, and lines still
+	// buffered in them would be lost.
 	wg.Wait()
+	err = cmd.Wait()
 
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
@@ -445,28 +537,75 @@ func (a *Agent) runCommandWithOutput(name string, args []string) error {
 	return cmd.Wait()
 }
 
-// sendOutput sends a line of command output.
+// currentCommandID returns the ID of the running command ("" if none, or
+// if it was not sent by the dashboard).
+func (a *Agent) currentCommandID() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.commandReq.ID
+}
+
+// sendOutput sends a line of command output, batched if the dashboard
+// supports it.
 func (a *Agent) sendOutput(line, stream string) {
+	a.mu.RLock()
+	req, batch := a.commandReq, a.batchOutput
+	a.mu.RUnlock()
+
+	line = truncateOutputLine(line)
+	a.output.record(line, stream)
+	if batch {
+		a.outputBatch.add(req.ID, req.Command, line, stream, time.Now())
+		return
+	}
+	// Lines batched before a reconnect go first
+	a.outputBatch.flush()
+
 	payload := protocol.OutputPayload{
-		Line:   line,
-		Stream: stream,
+		CommandID: req.ID,
+		Line:      line,
+		Stream:    stream,
+		Command:   req.Command,
 	}
 	if err := a.ws.SendMessage(protocol.TypeOutput, payload); err != nil {
 		a.log.Debug().Err(err).Msg("failed to send output")
 	}
 }
 
+// sendOutputBatch sends batched output lines (see outputBatcher).
+func (a *Agent) sendOutputBatch(batch protocol.OutputBatchPayload) {
+	if err := a.ws.SendMessage(protocol.TypeOutputBatch, batch); err != nil {
+		a.log.Debug().Err(err).Int("lines", len(batch.Lines)).Msg("failed to send output batch")
+	}
+}
+
 // sendStatus sends command completion status.
 func (a *Agent) sendStatus(status, command string, exitCode int, message string) {
 	// Refresh generation after command (especially important after pull)
 	generation := a.detectGeneration()
+	a.output.finish(command, status, exitCode)
+	// The status must not overtake the command's last output lines
+	a.outputBatch.flush()
+
+	// Echo the running command's ID and target, unless this status is about
+	// another command (e.g. "stop" while a switch runs)
+	a.mu.RLock()
+	req := a.commandReq
+	a.mu.RUnlock()
+	var commandID, target, ref string
+	if req.Command == command {
+		commandID, target, ref = req.ID, req.Args.Target, req.Args.Ref
+	}
 
 	payload := protocol.StatusPayload{
+		CommandID:  commandID,
 		Status:     status,
 		Command:    command,
 		ExitCode:   exitCode,
 		Message:    message,
 		Generation: generation,
+		Target:     target,
+		Ref:        ref,
 	}
 	if err := a.ws.SendMessage(protocol.TypeStatus, payload); err != nil {
 		a.log.Error().Err(err).Msg("failed to send status")
@@ -510,7 +649,8 @@ func (a *Agent) sendOperationProgress(phase, status string, current, total int)
 	}
 
 	payload := protocol.OperationProgressPayload{
-		Progress: progress,
+		CommandID: a.currentCommandID(),
+		Progress:  progress,
 	}
 
 	a.log.Debug().
@@ -651,8 +791,6 @@ func (a *Agent) handleForceUpdate() {
 		repoDir = os.ExpandEnv("$HOME/Code/nixcfg")
 	}
 
-	hostname, _ := os.Hostname()
-
 	a.sendOutput("", "stdout")
 	a.sendOutput("╔═══════════════════════════════════════════════════════════╗", "stdout")
 	a.sendOutput("║  P7200: FORCE UNCACHED UPDATE                             ║", "stdout")
@@ -693,21 +831,12 @@ func (a *Agent) handleForceUpdate() {
 	a.sendOutput("Using --option narinfo-cache-negative-ttl 0 to force fresh build", "stdout")
 	a.sendOutput("", "stdout")
 
-	var rebuildCmd *exec.Cmd
-	if runtime.GOOS == "darwin" {
-		// macOS: home-manager switch
-		rebuildCmd = exec.Command("home-manager", "switch",
-			"--flake", fmt.Sprintf(".#%s", hostname),
-			"--option", "narinfo-cache-negative-ttl", "0")
-	} else {
-		// NixOS: nixos-rebuild switch
-		rebuildCmd = exec.Command("sudo", "nixos-rebuild", "switch",
-			"--flake", fmt.Sprintf(".#%s", hostname),
-			"--option", "narinfo-cache-negative-ttl", "0")
-	}
-	rebuildCmd.Dir = repoDir
+	// Every deploy target, system first (nixos-rebuild, darwin-rebuild, home-manager)
+	cfg := a.currentConfig()
+	rebuildCmd := switchCommand(context.Background(), repoDir, cfg.DeployTargets(),
+		"--option", "narinfo-cache-negative-ttl", "0")
 
-	exitCode := a.runWithStreaming(rebuildCmd)
+	exitCode := a.runNixBuild(rebuildCmd)
 	if exitCode != 0 {
 		a.sendOutput(fmt.Sprintf("❌ Rebuild failed with exit code %d", exitCode), "stderr")
 		a.sendStatus("error", command, exitCode, "rebuild failed")
@@ -848,26 +977,28 @@ func (a *Agent) handleKillCommand(signal string, requestedPID int) {
 
 // runIsolatedPull performs a clean-slate pull for isolated mode:
 // git fetch + git reset --hard + git clean -fd
-func (a *Agent) runIsolatedPull() error {
-	a.log.Info().Msg("running isolated pull (fetch + reset --hard)")
-
-	// Step 1: git fetch origin <branch>
-	fetchArgs := []string{"git", "-C", a.cfg.RepoDir, "fetch", "origin", a.cfg.Branch}
-	fetchCmd := exec.CommandContext(a.ctx, fetchArgs[0], fetchArgs[1:]...)
-
-	// Set SSH key if configured
-	if a.cfg.SSHKey != "" {
-		fetchCmd.Env = append(os.Environ(),
-			"GIT_SSH_COMMAND=ssh -i "+a.cfg.SSHKey+" -o StrictHostKeyChecking=no",
-		)
+// ref (commit SHA, tag or branch) replaces the configured branch tip.
+func (a *Agent) runIsolatedPull(ref string) error {
+	a.log.Info().Str("ref", ref).Msg("running isolated pull (fetch + reset --hard)")
+
+	// Step 1: git fetch origin <branch|ref>
+	fetchRef := a.cfg.Branch
+	resetTo := "origin/" + a.cfg.Branch
+	if ref != "" {
+		fetchRef = ref
+		resetTo = "FETCH_HEAD"
 	}
-
-	if err := a.runCmdWithStreaming(fetchCmd, "fetch"); err != nil {
+	err := a.runCmdWithStreaming(a.gitFetchCommand("origin", fetchRef), "fetch")
+	if err != nil && ref != "" && protocol.AbbreviatedCommit(ref) {
+		// Not a tag or branch: resolve it as a short commit SHA
+		resetTo, err = a.resolveAbbreviatedCommit(ref)
+	}
+	if err != nil {
 		return err
 	}
 
-	// Step 2: git reset --hard origin/<branch>
-	resetArgs := []string{"git", "-C", a.cfg.RepoDir, "reset", "--hard", "origin/" + a.cfg.Branch}
+	// Step 2: git reset --hard origin/<branch> (or the fetched ref)
+	resetArgs := []string{"git", "-C", a.cfg.RepoDir, "reset", "--hard", resetTo}
 	resetCmd := exec.CommandContext(a.ctx, resetArgs[0], resetArgs[1:]...)
 	if err := a.runCmdWithStreaming(resetCmd, "reset"); err != nil {
 		return err
@@ -885,6 +1016,33 @@ func (a *Agent) runIsolatedPull() error {
 	return nil
 }
 
+// gitFetchCommand builds git fetch in the repo, with the SSH key if configured.
+func (a *Agent) gitFetchCommand(args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(a.ctx, "git", append([]string{"-C", a.cfg.RepoDir, "fetch"}, args...)...)
+	if a.cfg.SSHKey != "" {
+		cmd.Env = append(os.Environ(),
+			"GIT_SSH_COMMAND=ssh -i "+a.cfg.SSHKey+" -o StrictHostKeyChecking=no",
+		)
+	}
+	return cmd
+}
+
+// resolveAbbreviatedCommit fetches all branches and tags and resolves ref as
+// a short commit SHA among them; remotes only serve full object names.
+func (a *Agent) resolveAbbreviatedCommit(ref string) (string, error) {
+	a.sendOutput(fmt.Sprintf("%s is not a tag or branch, resolving it as a commit", ref), "stdout")
+	fetch := a.gitFetchCommand("--tags", "origin", "+refs/heads/*:refs/remotes/origin/*")
+	if err := a.runCmdWithStreaming(fetch, "fetch"); err != nil {
+		return "", err
+	}
+	out, err := exec.CommandContext(a.ctx, "git", "-C", a.cfg.RepoDir,
+		"rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("git ref %s is not a tag, branch or commit on the remote", ref)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 // runCmdWithStreaming runs a command and streams output to the dashboard.
 func (a *Agent) runCmdWithStreaming(cmd *exec.Cmd, label string) error {
 	stdout, _ := cmd.StdoutPipe()
@@ -931,11 +1089,11 @@ func (a *Agent) buildPullCommand() (*exec.Cmd, error) {
 	return cmd, nil
 }
 
-func (a *Agent) buildSwitchCommand() (*exec.Cmd, error) {
+func (a *Agent) buildSwitchCommand(targets []config.Target) (*exec.Cmd, error) {
 	var cmd *exec.Cmd
 
 	if runtime.GOOS == "darwin" {
-		// macOS: home-manager switch
+		// macOS: darwin-rebuild and/or home-manager switch
 		//
 		// CRITICAL: home-manager switch calls "launchctl bootout" which kills THIS agent.
 		// If the switch process is in our process group, it dies too.
@@ -946,11 +1104,9 @@ func (a *Agent) buildSwitchCommand() (*exec.Cmd, error) {
 		// 3. Still runs as our child (we can still stream stdout/stderr)
 		//
 		// The switch will continue even after launchd kills us, and the new agent
-		// will reconnect once the switch completes.
-		//
-		flakeRef := a.cfg.RepoDir + "#" + a.cfg.Hostname
-		cmd = exec.Command("home-manager", "switch", "--flake", flakeRef)
-		cmd.Dir = a.cfg.RepoDir
+		// will reconnect once the switch completes. For the same reason it is not
+		// tied to the agent's context.
+		cmd = switchCommand(context.Background(), a.cfg.RepoDir, targets)
 
 		// Create new session - this is the key to surviving agent death
 		cmd.SysProcAttr = &syscall.SysProcAttr{
@@ -958,67 +1114,25 @@ func (a *Agent) buildSwitchCommand() (*exec.Cmd, error) {
 		}
 
 		a.log.Info().
-			Str("flake", flakeRef).
+			Str("targets", config.FormatTargets(targets)).
 			Msg("starting switch with new session (survives agent restart)")
 
 	} else {
-		// NixOS: sudo nixos-rebuild switch
+		// NixOS: sudo nixos-rebuild switch (plus per-user home-manager targets)
 		// This runs as root in a separate session, so it survives agent restart
-		args := []string{
-			"sudo", "nixos-rebuild", "switch",
-			"--flake", a.cfg.RepoDir + "#" + a.cfg.Hostname,
-		}
-		cmd = exec.CommandContext(a.ctx, args[0], args[1:]...)
-		cmd.Dir = a.cfg.RepoDir
-	}
-
-	return cmd, nil
-}
-
-func (a *Agent) buildTestCommand() (*exec.Cmd, error) {
-	// Run test suite from hosts/<hostname>/tests/
-	testDir := a.cfg.RepoDir + "/hosts/" + a.cfg.Hostname + "/tests"
-
-	// Find and run test scripts
-	// P5999: Source Nix environment on macOS so tests have PATH configured
-	var shellCmd string
-	if runtime.GOOS == "darwin" {
-		// IMPORTANT: `/bin/sh` on macOS can exit immediately when `.` fails to open the file,
-		// even when wrapped in `|| true`. So guard the source with a file check.
-		shellCmd = "if [ -f \"$HOME/.nix-profile/etc/profile.d/nix.sh\" ]; then . \"$HOME/.nix-profile/etc/profile.d/nix.sh\"; fi; " +
-			"for f in " + testDir + "/*.sh; do [ -x \"$f\" ] && \"$f\"; done"
-	} else {
-		shellCmd = "for f in " + testDir + "/*.sh; do [ -x \"$f\" ] && \"$f\"; done"
+		cmd = switchCommand(a.ctx, a.cfg.RepoDir, targets)
 	}
 
-	cmd := exec.CommandContext(a.ctx, "sh", "-c", shellCmd)
-	cmd.Dir = a.cfg.RepoDir
 	return cmd, nil
 }
 
-// P4600: buildRollbackCommand builds the rollback command for the current OS.
+// P4600: buildRollbackCommand builds the rollback command for the given targets.
 // NixOS: nixos-rebuild --rollback switch
-// macOS: Activate previous Home Manager generation
-func (a *Agent) buildRollbackCommand() (*exec.Cmd, error) {
-	if runtime.GOOS == "darwin" {
-		// macOS: Find and activate previous Home Manager generation
-		// List generations, find the second-to-last one
-		profilePath := filepath.Join(os.Getenv("HOME"), ".local/state/nix/profiles/home-manager")
-		cmd := exec.CommandContext(a.ctx, "sh", "-c",
-			// Get the previous generation (second to last)
-			`prev=$(ls -1 ~/.local/state/nix/profiles/home-manager-*-link 2>/dev/null | tail -2 | head -1) && `+
-				`if [ -n "$prev" ] && [ -L "$prev" ]; then `+
-				`echo "Rolling back to: $prev" && "$prev/activate"; `+
-				`else echo "No previous generation found" && exit 1; fi`,
-		)
-		_ = profilePath // referenced in comment
-		return cmd, nil
-	}
-
-	// NixOS: Use the built-in rollback mechanism
-	cmd := exec.CommandContext(a.ctx, "sudo", "nixos-rebuild", "--rollback", "switch")
-	cmd.Dir = a.cfg.RepoDir
-	return cmd, nil
+// nix-darwin: darwin-rebuild switch --rollback
+// home-manager: activate the previous Home Manager generation
+// A non-zero generation activates that generation of the (single) target instead.
+func (a *Agent) buildRollbackCommand(targets []config.Target, generation int) (*exec.Cmd, error) {
+	return rollbackCommand(a.ctx, a.cfg.RepoDir, targets, generation), nil
 }
 
 func (a *Agent) buildUpdateCommand() (*exec.Cmd, error) {
@@ -0,0 +1,364 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// LOCAL CONTROL SOCKET
+// A unix-domain socket with a small JSON API so someone logged into the host
+// can see what the agent is doing (and trigger ops) without the dashboard.
+// Used by `nixfleet-agent status|last-output|run`.
+// ═══════════════════════════════════════════════════════════════════════════
+
+// maxOutputLines caps the output kept for `last-output`.
+const maxOutputLines = 2000
+
+// localCommands are the commands `nixfleet-agent run` may trigger.
+// Disruptive commands (reboot, restart, stop) stay dashboard-only.
+var localCommands = map[string]bool{
+	"pull":         true,
+	"switch":       true,
+	"pull-switch":  true,
+	"test":         true,
+	"gc":           true,
+	"refresh-lock": true,
+}
+
+// ControlStatus is returned by GET /status.
+type ControlStatus struct {
+	Hostname       string                  `json:"hostname"`
+	Version        string                  `json:"version"`
+	DashboardURL   string                  `json:"dashboard_url"`            // active endpoint
+	DashboardURLs  []string                `json:"dashboard_urls,omitempty"` // all endpoints, in failover order
+	Connected      bool                    `json:"connected"`
+	Registered     bool                    `json:"registered"`
+	PendingCommand string                  `json:"pending_command,omitempty"`
+	CommandPID     int                     `json:"command_pid,omitempty"`
+	Generation     string                  `json:"generation"`
+	UpdateStatus   *protocol.UpdateStatus  `json:"update_status,omitempty"`
+	Freshness      protocol.AgentFreshness `json:"freshness"`
+	LastCommand    string                  `json:"last_command,omitempty"`
+	LastExitCode   *int                    `json:"last_exit_code,omitempty"`
+}
+
+// CommandOutput is the captured output of the most recent command (GET /last-output).
+type CommandOutput struct {
+	Command    string       `json:"command"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"` // nil while running
+	ExitCode   *int         `json:"exit_code,omitempty"`
+	Status     string       `json:"status,omitempty"` // "ok" or "error" once finished
+	Lines      []OutputLine `json:"lines"`
+	Dropped    int          `json:"dropped,omitempty"` // oldest lines dropped to stay under maxOutputLines
+}
+
+// OutputLine is one captured line of command output.
+type OutputLine struct {
+	Line   string `json:"line"`
+	Stream string `json:"stream"`
+}
+
+// outputRecorder keeps the output of the current/last command in memory.
+type outputRecorder struct {
+	mu   sync.Mutex
+	last *CommandOutput
+}
+
+func (r *outputRecorder) begin(command string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last = &CommandOutput{Command: command, StartedAt: time.Now(), Lines: []OutputLine{}}
+}
+
+func (r *outputRecorder) record(line, stream string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.last == nil || r.last.FinishedAt != nil {
+		return
+	}
+	if len(r.last.Lines) >= maxOutputLines {
+		r.last.Lines = r.last.Lines[1:]
+		r.last.Dropped++
+	}
+	r.last.Lines = append(r.last.Lines, OutputLine{Line: line, Stream: stream})
+}
+
+func (r *outputRecorder) finish(command, status string, exitCode int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.last == nil || r.last.FinishedAt != nil || r.last.Command != command {
+		return
+	}
+	now := time.Now()
+	r.last.FinishedAt = &now
+	r.last.ExitCode = &exitCode
+	r.last.Status = status
+}
+
+// snapshot returns a copy safe to serialize outside the lock (nil if no command ran yet).
+func (r *outputRecorder) snapshot() *CommandOutput {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.last == nil {
+		return nil
+	}
+	out := *r.last
+	out.Lines = append([]OutputLine(nil), r.last.Lines...)
+	return &out
+}
+
+// controlLoop serves the control socket until the agent shuts down.
+func (a *Agent) controlLoop() {
+	path := a.cfg.ControlSocket
+	if path == "" {
+		return
+	}
+
+	listener, err := listenControlSocket(path)
+	if err != nil {
+		a.log.Warn().Err(err).Str("socket", path).Msg("control socket unavailable")
+		return
+	}
+
+	srv := &http.Server{
+		Handler:           a.controlHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-a.ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	a.log.Info().Str("socket", path).Msg("control socket listening")
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		a.log.Error().Err(err).Msg("control socket failed")
+	}
+	_ = os.Remove(path)
+}
+
+// listenControlSocket creates the socket, replacing a stale one from a previous run.
+// The socket is only accessible to the agent user (and root).
+func listenControlSocket(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		_ = os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// controlHandler returns the JSON API served on the control socket.
+func (a *Agent) controlHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", a.handleControlStatus)
+	mux.HandleFunc("GET /last-output", a.handleControlLastOutput)
+	mux.HandleFunc("POST /run", a.handleControlRun)
+	return mux
+}
+
+func (a *Agent) handleControlStatus(w http.ResponseWriter, _ *http.Request) {
+	a.mu.RLock()
+	status := ControlStatus{
+		Hostname:      a.cfg.Hostname,
+		Version:       Version,
+		DashboardURL:  a.cfg.DashboardURL,
+		DashboardURLs: a.cfg.DashboardURLs(),
+		Registered:    a.registered,
+		Generation:    a.generation,
+	}
+	if a.pendingCommand != nil {
+		status.PendingCommand = *a.pendingCommand
+	}
+	if a.commandPID != nil {
+		status.CommandPID = *a.commandPID
+	}
+	a.mu.RUnlock()
+
+	if a.ws != nil {
+		status.Connected = a.ws.IsConnected()
+		status.DashboardURL = a.ws.ActiveURL()
+	}
+	// Cached values only - never trigger checks from the CLI
+	if a.statusChecker != nil {
+		status.UpdateStatus = &protocol.UpdateStatus{
+			Lock:   a.statusChecker.GetLockStatus(),
+			System: a.statusChecker.GetSystemStatus(),
+			Tests:  a.statusChecker.GetTestsStatus(),
+
+			Targets: a.statusChecker.GetTargetStatuses(),
+		}
+	}
+	status.Freshness = GetFreshness().ToProtocol()
+	if last := a.output.snapshot(); last != nil {
+		status.LastCommand = last.Command
+		status.LastExitCode = last.ExitCode
+	}
+
+	writeControlJSON(w, http.StatusOK, status)
+}
+
+func (a *Agent) handleControlLastOutput(w http.ResponseWriter, _ *http.Request) {
+	last := a.output.snapshot()
+	if last == nil {
+		writeControlJSON(w, http.StatusNotFound, map[string]string{"error": "no command has run since the agent started"})
+		return
+	}
+	writeControlJSON(w, http.StatusOK, last)
+}
+
+func (a *Agent) handleControlRun(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Command string `json:"command"`
+		Target  string `json:"target,omitempty"` // deploy target, "" = all
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeControlJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if !localCommands[req.Command] {
+		writeControlJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("command %q cannot be run locally", req.Command)})
+		return
+	}
+
+	a.mu.Lock()
+	if a.pendingCommand != nil {
+		current := *a.pendingCommand
+		a.mu.Unlock()
+		writeControlJSON(w, http.StatusConflict, map[string]string{"error": "busy: " + current + " is running"})
+		return
+	}
+	// Claim the slot before returning so an immediate status/last-output shows the command
+	command := req.Command
+	a.pendingCommand = &command
+	a.mu.Unlock()
+	a.output.begin(command)
+
+	a.log.Info().Str("command", command).Str("target", req.Target).Msg("command requested via control socket")
+	go a.executeCommand(protocol.CommandPayload{Command: command, Args: protocol.CommandArgs{Target: req.Target}})
+
+	writeControlJSON(w, http.StatusAccepted, map[string]string{"status": "started", "command": command})
+}
+
+func writeControlJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// CLIENT (used by the CLI subcommands)
+// ═══════════════════════════════════════════════════════════════════════════
+
+// ControlClient talks to a running agent over its control socket.
+type ControlClient struct {
+	http *http.Client
+}
+
+// NewControlClient returns a client for the socket at path.
+func NewControlClient(path string) *ControlClient {
+	return &ControlClient{
+		http: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the agent's current state.
+func (c *ControlClient) Status() (*ControlStatus, error) {
+	var status ControlStatus
+	if err := c.do(http.MethodGet, "/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// LastOutput returns the output of the current or most recent command.
+func (c *ControlClient) LastOutput() (*CommandOutput, error) {
+	var out CommandOutput
+	if err := c.do(http.MethodGet, "/last-output", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Run asks the agent to execute a command on a deploy target ("" = all).
+// It returns once the command started.
+func (c *ControlClient) Run(command, target string) error {
+	return c.do(http.MethodPost, "/run", map[string]string{"command": command, "target": target}, nil)
+}
+
+func (c *ControlClient) do(method, path string, body, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	// The host part is ignored, the transport always dials the socket
+	req, err := http.NewRequest(method, "http://agent"+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return errors.New(apiErr.Error)
+		}
+		return fmt.Errorf("agent returned HTTP %d", resp.StatusCode)
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/config"
+	"github.com/rs/zerolog"
+)
+
+func TestOutputRecorder(t *testing.T) {
+	var r outputRecorder
+	if r.snapshot() != nil {
+		t.Fatal("expected no output before the first command")
+	}
+
+	r.begin("pull")
+	for i := 0; i < maxOutputLines+5; i++ {
+		r.record("line", "stdout")
+	}
+	out := r.snapshot()
+	if len(out.Lines) != maxOutputLines || out.Dropped != 5 {
+		t.Errorf("got %d lines, %d dropped; want %d, 5", len(out.Lines), out.Dropped, maxOutputLines)
+	}
+	if out.ExitCode != nil {
+		t.Error("running command should have no exit code")
+	}
+
+	// Status for a different command (e.g. a concurrent stop) must not end the capture
+	r.finish("stop", "ok", 0)
+	if r.snapshot().ExitCode != nil {
+		t.Error("finish for another command ended the capture")
+	}
+
+	r.finish("pull", "error", 1)
+	r.record("late", "stdout")
+	out = r.snapshot()
+	if out.ExitCode == nil || *out.ExitCode != 1 || out.Status != "error" {
+		t.Errorf("finish not recorded: %+v", out)
+	}
+	if out.Lines[len(out.Lines)-1].Line == "late" {
+		t.Error("lines after finish should be ignored")
+	}
+}
+
+func TestControlSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &Agent{
+		cfg:    &config.Config{Hostname: "testhost", ControlSocket: socket},
+		log:    zerolog.Nop(),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		a.controlLoop()
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	client := NewControlClient(socket)
+	var status *ControlStatus
+	var err error
+	// Wait for the listener to come up
+	for i := 0; i < 100; i++ {
+		if status, err = client.Status(); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Hostname != "testhost" || status.PendingCommand != "" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	if _, err := client.LastOutput(); err == nil || !strings.Contains(err.Error(), "no command") {
+		t.Errorf("expected 'no command' error, got %v", err)
+	}
+
+	if err := client.Run("reboot", ""); err == nil {
+		t.Error("reboot must not be runnable locally")
+	}
+
+	busy := "switch"
+	a.pendingCommand = &busy
+	if err := client.Run("pull", ""); err == nil || !strings.Contains(err.Error(), "busy") {
+		t.Errorf("expected busy error, got %v", err)
+	}
+
+	// Wrong method is rejected by the mux
+	resp, err := client.http.Post("http://agent/status", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /status: got %d, want 405", resp.StatusCode)
+	}
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// macOS: nix-darwin targets (standalone home-manager is in targets.go)
+// ═══════════════════════════════════════════════════════════════════════════
+
+// darwinRebuildPath returns darwin-rebuild's absolute path, so it still
+// resolves under sudo (which resets PATH on macOS).
+func darwinRebuildPath() string {
+	if p, err := exec.LookPath("darwin-rebuild"); err == nil {
+		if abs, err := filepath.Abs(p); err == nil {
+			return abs
+		}
+	}
+	return "/run/current-system/sw/bin/darwin-rebuild"
+}
+
+// asRoot prefixes args with sudo unless the agent already runs as root.
+// darwin-rebuild activation needs root.
+func asRoot(args ...string) []string {
+	if os.Geteuid() == 0 {
+		return args
+	}
+	return append([]string{"sudo"}, args...)
+}
+
+// checkNixDarwinStatus compares /run/current-system with what
+// darwinConfigurations.<attr>.system would build.
+func (s *StatusChecker) checkNixDarwinStatus(ctx context.Context, repoDir, attr string) protocol.StatusCheck {
+	currentPath, err := filepath.EvalSymlinks("/run/current-system")
+	if err != nil {
+		return protocol.StatusCheck{
+			Status:    "unknown",
+			Message:   "No nix-darwin system found",
+			CheckedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+	flakeRef := repoDir + "#darwinConfigurations." + attr + ".system"
+	return compareFlakeOutput(ctx, repoDir, flakeRef, currentPath, "nix-darwin system")
+}
@@ -0,0 +1,218 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+const (
+	// nixStorePath is the store whose filesystem we watch for disk pressure.
+	nixStorePath = "/nix/store"
+
+	// storeSizeInterval is how often the (expensive) store size walk is repeated.
+	storeSizeInterval = 1 * time.Hour
+
+	// storeSizeTimeout bounds a single `du` run on very large stores.
+	storeSizeTimeout = 10 * time.Minute
+)
+
+// readDiskUsage returns filesystem usage for /nix/store.
+// Filesystem numbers come from statfs (cheap, every heartbeat); the store size
+// is measured in the background and cached, see refreshStoreSize.
+func (a *Agent) readDiskUsage() *protocol.DiskUsage {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(nixStorePath, &st); err != nil {
+		a.log.Debug().Err(err).Msg("statfs on nix store failed")
+		return nil
+	}
+
+	bsize := int64(st.Bsize)
+	total := int64(st.Blocks) * bsize
+	free := int64(st.Bavail) * bsize
+	used := total - int64(st.Bfree)*bsize
+
+	var usedPercent float64
+	if total > 0 {
+		usedPercent = float64(used) / float64(total) * 100
+	}
+
+	a.storeMu.Lock()
+	storeBytes := a.storeBytes
+	stale := !a.storeMeasuring && time.Since(a.storeCheckedAt) > storeSizeInterval
+	a.storeMu.Unlock()
+
+	if stale {
+		go a.refreshStoreSize()
+	}
+
+	return &protocol.DiskUsage{
+		Path:        nixStorePath,
+		TotalBytes:  total,
+		FreeBytes:   free,
+		UsedPercent: usedPercent,
+		StoreBytes:  storeBytes,
+	}
+}
+
+// refreshStoreSize measures the size of /nix/store with `du`.
+// Only one measurement runs at a time; concurrent calls return immediately.
+func (a *Agent) refreshStoreSize() {
+	a.storeMu.Lock()
+	if a.storeMeasuring {
+		a.storeMu.Unlock()
+		return
+	}
+	a.storeMeasuring = true
+	a.storeMu.Unlock()
+
+	defer func() {
+		a.storeMu.Lock()
+		a.storeMeasuring = false
+		a.storeCheckedAt = time.Now()
+		a.storeMu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(a.ctx, storeSizeTimeout)
+	defer cancel()
+
+	// -s summary, -k 1024-byte blocks (portable between GNU and BSD du)
+	out, err := exec.CommandContext(ctx, "du", "-sk", nixStorePath).Output()
+	if err != nil && len(out) == 0 {
+		a.log.Debug().Err(err).Msg("failed to measure nix store size")
+		return
+	}
+
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return
+	}
+	kb, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return
+	}
+
+	a.storeMu.Lock()
+	a.storeBytes = kb * 1024
+	a.storeMu.Unlock()
+
+	a.log.Debug().Int64("store_bytes", kb*1024).Msg("nix store size measured")
+}
+
+// handleGC runs nix-collect-garbage (optionally with --delete-older-than)
+// followed by a store optimise, streaming output and reporting bytes freed.
+func (a *Agent) handleGC() {
+	command := "gc"
+	before := a.readDiskUsage()
+
+	var freed atomic.Int64
+	countFreed := func(line string) {
+		if n, ok := parseFreedBytes(line); ok {
+			freed.Add(n)
+		}
+	}
+
+	// Step 1: collect garbage
+	olderThan := a.currentConfig().GCDeleteOlderThan
+	gcArgs := []string{"nix-collect-garbage"}
+	if olderThan != "" {
+		gcArgs = append(gcArgs, "--delete-older-than", olderThan)
+		a.sendOutput(fmt.Sprintf("🧹 Collecting garbage (deleting generations older than %s)...", olderThan), "stdout")
+	} else {
+		a.sendOutput("🧹 Collecting garbage (unreachable store paths only)...", "stdout")
+	}
+
+	if exitCode := a.runWithLineHook(a.buildNixStoreCommand(gcArgs), countFreed); exitCode != 0 {
+		a.sendOutput(fmt.Sprintf("❌ nix-collect-garbage failed (exit %d)", exitCode), "stderr")
+		a.sendStatus("error", command, exitCode, "nix-collect-garbage failed")
+		return
+	}
+
+	// Step 2: deduplicate via hard links.
+	// nix-store --optimise is `nix store optimise` without needing nix-command,
+	// and is what the NixOS module whitelists for sudo.
+	a.sendOutput("", "stdout")
+	a.sendOutput("🔗 Optimising store (hard-linking identical files)...", "stdout")
+	if exitCode := a.runWithLineHook(a.buildNixStoreCommand([]string{"nix-store", "--optimise"}), countFreed); exitCode != 0 {
+		a.sendOutput(fmt.Sprintf("❌ nix store optimise failed (exit %d)", exitCode), "stderr")
+		a.sendStatus("error", command, exitCode, "nix store optimise failed")
+		return
+	}
+
+	// Prefer what nix reported; fall back to the filesystem delta
+	total := freed.Load()
+	after := a.readDiskUsage()
+	if total == 0 && before != nil && after != nil && after.FreeBytes > before.FreeBytes {
+		total = after.FreeBytes - before.FreeBytes
+	}
+
+	a.sendOutput("", "stdout")
+	a.sendOutput(fmt.Sprintf("✅ Garbage collection complete: %s freed", formatBytes(total)), "stdout")
+	if after != nil {
+		a.sendOutput(fmt.Sprintf("💾 %s free on %s (%.0f%% used)", formatBytes(after.FreeBytes), after.Path, after.UsedPercent), "stdout")
+	}
+	a.sendStatus("ok", command, 0, fmt.Sprintf("%s freed", formatBytes(total)))
+
+	// Store size changed - remeasure, then push fresh numbers to the dashboard
+	go a.refreshStoreSize()
+	a.sendHeartbeat()
+}
+
+// buildNixStoreCommand wraps a store-mutating nix command.
+// On NixOS the system profiles belong to root, so run via sudo (like nixos-rebuild).
+// On macOS (Home Manager) the agent runs as the profile owner.
+func (a *Agent) buildNixStoreCommand(args []string) *exec.Cmd {
+	if runtime.GOOS != "darwin" {
+		args = append([]string{"sudo"}, args...)
+	}
+	return exec.CommandContext(a.ctx, args[0], args[1:]...)
+}
+
+// freedPattern matches nix's summary lines, e.g.
+//
+//	"1234 store paths deleted, 567.89 MiB freed"
+//	"12.34 MiB freed by hard-linking 56 files"
+var freedPattern = regexp.MustCompile(`([0-9]+(?:\.[0-9]+)?) (B|KiB|MiB|GiB|TiB) freed`)
+
+// parseFreedBytes extracts the number of bytes freed from a nix output line.
+func parseFreedBytes(line string) (int64, bool) {
+	m := freedPattern.FindStringSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+	val, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	mult := map[string]float64{
+		"B":   1,
+		"KiB": 1 << 10,
+		"MiB": 1 << 20,
+		"GiB": 1 << 30,
+		"TiB": 1 << 40,
+	}[m[2]]
+	return int64(val * mult), true
+}
+
+// formatBytes renders a byte count with binary units (matches nix's output style).
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.2f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
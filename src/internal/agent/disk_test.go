@@ -0,0 +1,43 @@
+package agent
+
+import "testing"
+
+func TestParseFreedBytes(t *testing.T) {
+	tests := []struct {
+		line string
+		want int64
+		ok   bool
+	}{
+		{"1234 store paths deleted, 567.50 MiB freed", 595066880, true},
+		{"0 store paths deleted, 0.00 MiB freed", 0, true},
+		{"2.00 GiB freed by hard-linking 42 files", 2 << 30, true},
+		{"512 B freed", 512, true},
+		{"deleting '/nix/store/abc-foo'", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseFreedBytes(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseFreedBytes(%q) = %d, %v; want %d, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.00 KiB"},
+		{1536 << 20, "1.50 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q; want %q", tt.n, got, tt.want)
+		}
+	}
+}
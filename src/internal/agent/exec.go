@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// EXEC - allowlisted ad-hoc commands (NIXFLEET_EXEC_COMMANDS)
+// ═══════════════════════════════════════════════════════════════════════════
+
+// execKillGrace bounds how long a timed-out command's leftover children may
+// keep its output pipes open.
+const execKillGrace = 5 * time.Second
+
+// handleExec runs an allowlisted command and streams its output. The
+// dashboard only names the command and its params; what runs is decided by
+// the allowlist on this host. The status message carries the command line.
+func (a *Agent) handleExec(args protocol.CommandArgs) {
+	command := "exec"
+
+	def, ok := a.currentConfig().ExecCommands[args.Exec]
+	if !ok {
+		a.sendStatus("error", command, 1, fmt.Sprintf("%q is not an allowlisted exec command on this host", args.Exec))
+		return
+	}
+	argv, err := def.Expand(args.Params)
+	if err != nil {
+		a.sendStatus("error", command, 1, fmt.Sprintf("%s: %v", args.Exec, err))
+		return
+	}
+	line := protocol.FormatCommandLine(argv)
+	timeout := def.TimeoutDuration()
+
+	a.log.Info().Str("exec", args.Exec).Str("command_line", line).Dur("timeout", timeout).Msg("running exec command")
+	a.sendOutput("$ "+line, "stdout")
+
+	ctx, cancel := context.WithTimeout(a.ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	// Own process group so the timeout (and STOP) takes the command's children too
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) }
+	cmd.WaitDelay = execKillGrace
+
+	exitCode := a.runWithStreaming(cmd)
+	switch {
+	case cmd.ProcessState == nil:
+		a.sendOutput(fmt.Sprintf("❌ Could not start %s", argv[0]), "stderr")
+		a.sendStatus("error", command, 127, line+": could not start")
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		a.sendOutput(fmt.Sprintf("⏱️ Timed out after %s", timeout), "stderr")
+		a.sendStatus("error", command, 124, fmt.Sprintf("%s: timed out after %s", line, timeout))
+	case killedBySignal(cmd.ProcessState):
+		a.sendStatus("error", command, 130, line+": killed")
+	case exitCode != 0:
+		a.sendStatus("error", command, exitCode, fmt.Sprintf("%s: exit %d", line, exitCode))
+	default:
+		a.sendStatus("ok", command, 0, line)
+	}
+}
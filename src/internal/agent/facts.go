@@ -0,0 +1,372 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+const (
+	// factsInterval is how often the host facts are collected again. Changed
+	// facts go out with the next heartbeat.
+	factsInterval = 1 * time.Hour
+
+	// factsTimeout bounds each helper command (sysctl, ioreg, systemd-detect-virt).
+	factsTimeout = 5 * time.Second
+)
+
+// currentFacts returns the host facts, collected again when older than
+// factsInterval. The pointer only changes when the facts do.
+func (a *Agent) currentFacts() *protocol.HostFacts {
+	a.factsMu.Lock()
+	defer a.factsMu.Unlock()
+
+	if a.facts != nil && time.Since(a.factsCollectedAt) < factsInterval {
+		return a.facts
+	}
+	facts := a.collectFacts()
+	a.factsCollectedAt = time.Now()
+	if a.facts == nil || !reflect.DeepEqual(facts, a.facts) {
+		if a.facts != nil {
+			a.log.Info().Msg("host facts changed")
+		}
+		a.facts = facts
+	}
+	return a.facts
+}
+
+// unsentFacts returns the host facts if the dashboard does not have them
+// yet, nil otherwise. Call markFactsSent once they were sent.
+func (a *Agent) unsentFacts() *protocol.HostFacts {
+	facts := a.currentFacts()
+	a.factsMu.Lock()
+	defer a.factsMu.Unlock()
+	if facts == a.factsSent {
+		return nil
+	}
+	return facts
+}
+
+func (a *Agent) markFactsSent(facts *protocol.HostFacts) {
+	if facts == nil {
+		return
+	}
+	a.factsMu.Lock()
+	a.factsSent = facts
+	a.factsMu.Unlock()
+}
+
+// collectFacts reads the host's hardware and OS inventory. Anything that
+// cannot be read is left empty.
+func (a *Agent) collectFacts() *protocol.HostFacts {
+	arch := nixArch(runtime.GOARCH)
+	facts := &protocol.HostFacts{
+		CPUCores:   runtime.NumCPU(),
+		Arch:       arch,
+		System:     arch + "-" + runtime.GOOS,
+		Disks:      []protocol.DiskFact{},
+		Interfaces: readInterfaces(),
+	}
+	if runtime.GOOS == "darwin" {
+		a.collectDarwinFacts(facts)
+	} else {
+		a.collectLinuxFacts(facts)
+	}
+	return facts
+}
+
+func (a *Agent) collectLinuxFacts(facts *protocol.HostFacts) {
+	if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+		facts.CPUModel = parseCPUModel(string(data))
+	}
+	if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+		facts.MemoryBytes = parseMemTotal(string(data))
+	}
+	facts.Kernel = readTrimmed("/proc/sys/kernel/osrelease")
+	if data, err := os.ReadFile("/proc/stat"); err == nil {
+		if btime := parseBootTime(string(data)); btime > 0 {
+			facts.BootTime = time.Unix(btime, 0).UTC().Format(time.RFC3339)
+		}
+	}
+
+	// systemd-detect-virt prints "none" and exits 1 on bare metal
+	out, _ := a.factsCommand("systemd-detect-virt")
+	if virt := strings.TrimSpace(out); virt != "none" {
+		facts.Virtualization = virt
+	}
+
+	// DMI on PCs and servers, the device tree on ARM boards. The serials
+	// are root-only on most machines.
+	facts.Vendor = dmiValue(readTrimmed("/sys/class/dmi/id/sys_vendor"))
+	facts.Model = dmiValue(readTrimmed("/sys/class/dmi/id/product_name"))
+	if facts.Model == "" {
+		facts.Model = readTrimmed("/proc/device-tree/model")
+	}
+	facts.SerialNumber = dmiValue(readTrimmed("/sys/class/dmi/id/product_serial"))
+	if facts.SerialNumber == "" {
+		facts.SerialNumber = readTrimmed("/proc/device-tree/serial-number")
+	}
+	facts.BoardSerial = dmiValue(readTrimmed("/sys/class/dmi/id/board_serial"))
+
+	if data, err := os.ReadFile("/proc/mounts"); err == nil {
+		facts.Disks = statDisks(parseProcMounts(string(data)))
+	}
+}
+
+func (a *Agent) collectDarwinFacts(facts *protocol.HostFacts) {
+	sysctl := func(name string) string {
+		out, err := a.factsCommand("sysctl", "-n", name)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(out)
+	}
+	facts.CPUModel = sysctl("machdep.cpu.brand_string")
+	facts.MemoryBytes, _ = strconv.ParseInt(sysctl("hw.memsize"), 10, 64)
+	facts.Kernel = sysctl("kern.osrelease")
+	if btime := parseSysctlBootTime(sysctl("kern.boottime")); btime > 0 {
+		facts.BootTime = time.Unix(btime, 0).UTC().Format(time.RFC3339)
+	}
+	if sysctl("kern.hv_vmm_present") == "1" {
+		facts.Virtualization = "apple"
+	}
+	facts.Vendor = "Apple Inc."
+	facts.Model = sysctl("hw.model")
+	if out, err := a.factsCommand("ioreg", "-rd1", "-c", "IOPlatformExpertDevice"); err == nil {
+		facts.SerialNumber = parseIORegString(out, "IOPlatformSerialNumber")
+	}
+	if out, err := a.factsCommand("mount"); err == nil {
+		facts.Disks = statDisks(parseDarwinMounts(out))
+	}
+}
+
+func (a *Agent) factsCommand(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(a.ctx, factsTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	return string(out), err
+}
+
+// nixArch maps GOARCH to the CPU part of a Nix system double.
+func nixArch(goarch string) string {
+	switch goarch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "386":
+		return "i686"
+	case "arm":
+		return "armv7l"
+	}
+	return goarch
+}
+
+// readTrimmed reads a small sysfs/procfs file, "" if unreadable. Device
+// tree strings end in NUL.
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(data), "\x00"))
+}
+
+// dmiPlaceholders are values vendors leave in DMI fields they don't fill.
+var dmiPlaceholders = regexp.MustCompile(`(?i)^(to be filled by o\.e\.m\.|default string|not specified|not applicable|system (product name|serial number)|none|0+|x+)$`)
+
+// dmiValue returns v unless it is a vendor placeholder.
+func dmiValue(v string) string {
+	if dmiPlaceholders.MatchString(v) {
+		return ""
+	}
+	return v
+}
+
+// parseCPUModel returns the first CPU model in /proc/cpuinfo: "model name"
+// on x86, "Model" or "Hardware" on ARM boards, "cpu model" elsewhere.
+func parseCPUModel(cpuinfo string) string {
+	found := map[string]string{}
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if _, seen := found[key]; !seen && value != "" {
+			found[key] = value
+		}
+	}
+	for _, key := range []string{"model name", "Model", "Hardware", "cpu model"} {
+		if v := found[key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseMemTotal returns MemTotal from /proc/meminfo in bytes.
+func parseMemTotal(meminfo string) int64 {
+	for _, line := range strings.Split(meminfo, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, _ := strconv.ParseInt(fields[1], 10, 64)
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// parseBootTime returns btime (Unix seconds) from /proc/stat.
+func parseBootTime(stat string) int64 {
+	for _, line := range strings.Split(stat, "\n") {
+		if rest, ok := strings.CutPrefix(line, "btime "); ok {
+			btime, _ := strconv.ParseInt(strings.TrimSpace(rest), 10, 64)
+			return btime
+		}
+	}
+	return 0
+}
+
+// parseSysctlBootTime parses `sysctl -n kern.boottime`,
+// e.g. "{ sec = 1700000000, usec = 12345 } Tue Nov 14 22:13:20 2023".
+func parseSysctlBootTime(v string) int64 {
+	_, rest, ok := strings.Cut(v, "sec = ")
+	if !ok {
+		return 0
+	}
+	sec, _, _ := strings.Cut(rest, ",")
+	btime, _ := strconv.ParseInt(strings.TrimSpace(sec), 10, 64)
+	return btime
+}
+
+// parseIORegString returns a string property from `ioreg` output, e.g.
+// `"IOPlatformSerialNumber" = "C02XXXXXXXXX"`.
+func parseIORegString(out, key string) string {
+	for _, line := range strings.Split(out, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), " = ")
+		if ok && k == `"`+key+`"` {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+// localFSTypes are the filesystems reported as disks; everything else in
+// /proc/mounts is virtual, network or overlay.
+var localFSTypes = map[string]bool{
+	"ext2": true, "ext3": true, "ext4": true, "xfs": true, "btrfs": true,
+	"zfs": true, "bcachefs": true, "f2fs": true, "vfat": true, "exfat": true,
+	"ntfs": true, "ntfs3": true, "apfs": true, "hfs": true,
+}
+
+// parseProcMounts returns the local filesystems in /proc/mounts, one per
+// device: btrfs subvolumes and bind mounts (like /nix/store) repeat theirs.
+func parseProcMounts(mounts string) []protocol.DiskFact {
+	var disks []protocol.DiskFact
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(mounts))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || !localFSTypes[fields[2]] || seen[fields[0]] {
+			continue
+		}
+		seen[fields[0]] = true
+		disks = append(disks, protocol.DiskFact{
+			Mount:  unescapeMount(fields[1]),
+			Device: fields[0],
+			FSType: fields[2],
+		})
+	}
+	return disks
+}
+
+// unescapeMount decodes the octal escapes /proc/mounts uses, e.g. "\040".
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// darwinMountLine matches `mount` output on macOS,
+// e.g. "/dev/disk3s1s1 on / (apfs, sealed, local, read-only, journaled)".
+var darwinMountLine = regexp.MustCompile(`^(/dev/\S+) on (.+) \(([^,)]+)(.*)\)$`)
+
+// parseDarwinMounts returns the local volumes in macOS `mount` output,
+// without the system's own /System/Volumes/* volumes.
+func parseDarwinMounts(out string) []protocol.DiskFact {
+	var disks []protocol.DiskFact
+	for _, line := range strings.Split(out, "\n") {
+		m := darwinMountLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || !strings.Contains(m[4], "local") || strings.HasPrefix(m[2], "/System/Volumes/") {
+			continue
+		}
+		disks = append(disks, protocol.DiskFact{Mount: m[2], Device: m[1], FSType: m[3]})
+	}
+	return disks
+}
+
+// statDisks fills in each filesystem's size, dropping those statfs fails on.
+func statDisks(disks []protocol.DiskFact) []protocol.DiskFact {
+	result := make([]protocol.DiskFact, 0, len(disks))
+	for _, d := range disks {
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(d.Mount, &st); err != nil {
+			continue
+		}
+		d.TotalBytes = int64(st.Blocks) * int64(st.Bsize)
+		result = append(result, d)
+	}
+	return result
+}
+
+// readInterfaces lists the network interfaces other than loopback, with
+// their addresses. Virtual bridges and VPN tunnels are included.
+func readInterfaces() []protocol.InterfaceFact {
+	result := []protocol.InterfaceFact{}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return result
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		fact := protocol.InterfaceFact{
+			Name:      iface.Name,
+			MAC:       iface.HardwareAddr.String(),
+			Up:        iface.Flags&net.FlagUp != 0,
+			Addresses: []string{},
+		}
+		if addrs, err := iface.Addrs(); err == nil {
+			for _, addr := range addrs {
+				fact.Addresses = append(fact.Addresses, addr.String())
+			}
+		}
+		result = append(result, fact)
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+package agent
+
+import "testing"
+
+func TestParseProcMounts(t *testing.T) {
+	mounts := `/dev/nvme0n1p2 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid,nodev,noexec,relatime 0 0
+tmpfs /run tmpfs rw,nosuid,nodev,size=3270716k,mode=755 0 0
+/dev/nvme0n1p2 /nix/store ext4 ro,relatime 0 0
+/dev/nvme0n1p1 /boot vfat rw,relatime,fmask=0022 0 0
+rpool/data /mnt/my\040data zfs rw,xattr,noacl 0 0
+server:/export /mnt/nfs nfs4 rw,relatime 0 0
+`
+	disks := parseProcMounts(mounts)
+	if len(disks) != 3 {
+		t.Fatalf("got %d disks: %+v", len(disks), disks)
+	}
+	if disks[0].Mount != "/" || disks[0].Device != "/dev/nvme0n1p2" || disks[0].FSType != "ext4" {
+		t.Errorf("root = %+v", disks[0])
+	}
+	if disks[1].Mount != "/boot" || disks[2].Mount != "/mnt/my data" || disks[2].FSType != "zfs" {
+		t.Errorf("disks = %+v", disks)
+	}
+}
+
+func TestParseDarwinMounts(t *testing.T) {
+	out := `/dev/disk3s1s1 on / (apfs, sealed, local, read-only, journaled)
+devfs on /dev (devfs, local, nobrowse)
+/dev/disk3s6 on /System/Volumes/VM (apfs, local, noexec, journaled, noatime, nobrowse)
+/dev/disk3s5 on /System/Volumes/Data (apfs, local, journaled, nobrowse, protect)
+/dev/disk3s7 on /nix (apfs, local, nodev, nosuid, journaled, noowners, nobrowse)
+/dev/disk5s1 on /Volumes/Backup (hfs, local, nodev, nosuid, journaled, noowners)
+//user@nas/share on /Volumes/share (smbfs, nodev, nosuid, mounted by user)
+`
+	disks := parseDarwinMounts(out)
+	if len(disks) != 3 || disks[0].Mount != "/" || disks[1].Mount != "/nix" || disks[2].FSType != "hfs" {
+		t.Errorf("disks = %+v", disks)
+	}
+}
+
+func TestParseFactValues(t *testing.T) {
+	x86 := "processor\t: 0\nvendor_id\t: GenuineIntel\nmodel name\t: Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz\n\nprocessor\t: 1\nmodel name\t: Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz\n"
+	if got := parseCPUModel(x86); got != "Intel(R) Core(TM) i7-8700 CPU @ 3.20GHz" {
+		t.Errorf("x86 cpu = %q", got)
+	}
+	pi := "processor\t: 0\nBogoMIPS\t: 108.00\nCPU part\t: 0xd08\n\nHardware\t: BCM2835\nModel\t\t: Raspberry Pi 4 Model B Rev 1.4\n"
+	if got := parseCPUModel(pi); got != "Raspberry Pi 4 Model B Rev 1.4" {
+		t.Errorf("arm cpu = %q", got)
+	}
+	if got := parseMemTotal("MemTotal:       32657576 kB\nMemFree:         1234 kB\n"); got != 32657576*1024 {
+		t.Errorf("memory = %d", got)
+	}
+	if got := parseBootTime("cpu  1 2 3\nintr 0\nbtime 1700000000\nprocesses 42\n"); got != 1700000000 {
+		t.Errorf("btime = %d", got)
+	}
+	if got := parseSysctlBootTime("{ sec = 1700000000, usec = 12345 } Tue Nov 14 22:13:20 2023"); got != 1700000000 {
+		t.Errorf("darwin btime = %d", got)
+	}
+	ioreg := "+-o J314sAP  <class IOPlatformExpertDevice>\n    {\n      \"IOPlatformUUID\" = \"0000-1111\"\n      \"IOPlatformSerialNumber\" = \"C02ABC123XYZ\"\n    }\n"
+	if got := parseIORegString(ioreg, "IOPlatformSerialNumber"); got != "C02ABC123XYZ" {
+		t.Errorf("serial = %q", got)
+	}
+	for _, v := range []string{"To Be Filled By O.E.M.", "Default string", "System Serial Number", "0000000", ""} {
+		if got := dmiValue(v); got != "" {
+			t.Errorf("dmiValue(%q) = %q, want empty", v, got)
+		}
+	}
+	if got := dmiValue("PF2XYZ01"); got != "PF2XYZ01" {
+		t.Errorf("dmiValue(serial) = %q", got)
+	}
+}
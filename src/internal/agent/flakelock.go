@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// FLAKE INPUT INVENTORY (flake.lock)
+// ═══════════════════════════════════════════════════════════════════════════
+
+// flakeLock is the part of flake.lock (version 5 to 7) the inventory needs.
+type flakeLock struct {
+	Nodes map[string]flakeLockNode `json:"nodes"`
+	Root  string                   `json:"root"`
+}
+
+type flakeLockNode struct {
+	// Input name → node name, or a "follows" path of input names from the root
+	Inputs   map[string]json.RawMessage `json:"inputs"`
+	Locked   flakeLockRef               `json:"locked"`
+	Original flakeLockRef               `json:"original"`
+}
+
+type flakeLockRef struct {
+	Type         string `json:"type"`
+	Owner        string `json:"owner"`
+	Repo         string `json:"repo"`
+	URL          string `json:"url"`
+	Path         string `json:"path"`
+	Ref          string `json:"ref"`
+	Rev          string `json:"rev"`
+	LastModified int64  `json:"lastModified"`
+	NarHash      string `json:"narHash"`
+}
+
+// maxFollowsDepth bounds follows resolution, so a malformed lock cannot loop.
+const maxFollowsDepth = 32
+
+// parseFlakeLock returns the direct inputs of the root flake, sorted by
+// name. Inputs that follow another input report the node they resolve to.
+func parseFlakeLock(data []byte) ([]protocol.FlakeInput, error) {
+	var lock flakeLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+	if lock.Root == "" {
+		lock.Root = "root"
+	}
+	root, ok := lock.Nodes[lock.Root]
+	if !ok {
+		return nil, fmt.Errorf("root node %q missing", lock.Root)
+	}
+
+	inputs := make([]protocol.FlakeInput, 0, len(root.Inputs))
+	for name := range root.Inputs {
+		node, err := lock.resolve(lock.Root, []string{name}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+		locked, original := lock.Nodes[node].Locked, lock.Nodes[node].Original
+		url := locked.URL
+		if url == "" {
+			url = locked.Path
+		}
+		inputs = append(inputs, protocol.FlakeInput{
+			Name:         name,
+			Type:         locked.Type,
+			Owner:        locked.Owner,
+			Repo:         locked.Repo,
+			URL:          url,
+			Ref:          original.Ref,
+			Pinned:       original.Rev != "",
+			Rev:          locked.Rev,
+			LastModified: locked.LastModified,
+			NarHash:      locked.NarHash,
+		})
+	}
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].Name < inputs[j].Name })
+	return inputs, nil
+}
+
+// resolve walks path (input names) from node and returns the node it ends at.
+func (l *flakeLock) resolve(node string, path []string, depth int) (string, error) {
+	if depth > maxFollowsDepth {
+		return "", errors.New("follows nested too deeply")
+	}
+	for _, input := range path {
+		raw, ok := l.Nodes[node].Inputs[input]
+		if !ok {
+			return "", fmt.Errorf("node %q has no input %q", node, input)
+		}
+		var target string
+		if err := json.Unmarshal(raw, &target); err == nil {
+			node = target
+			continue
+		}
+		var follows []string
+		if err := json.Unmarshal(raw, &follows); err != nil {
+			return "", fmt.Errorf("input %q: %w", input, err)
+		}
+		// Follows paths start at the root flake
+		next, err := l.resolve(l.Root, follows, depth+1)
+		if err != nil {
+			return "", err
+		}
+		node = next
+	}
+	if _, ok := l.Nodes[node]; !ok {
+		return "", fmt.Errorf("node %q missing", node)
+	}
+	return node, nil
+}
+
+// readFlakeInputs parses the flake.lock in the repo, or returns nil if there
+// is none or it cannot be parsed.
+func (a *Agent) readFlakeInputs() []protocol.FlakeInput {
+	if a.cfg.RepoDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(a.cfg.RepoDir, "flake.lock"))
+	if err != nil {
+		return nil
+	}
+	inputs, err := parseFlakeLock(data)
+	if err != nil {
+		a.log.Warn().Err(err).Msg("failed to parse flake.lock")
+		return nil
+	}
+	return inputs
+}
@@ -0,0 +1,64 @@
+package agent
+
+import "testing"
+
+func TestParseFlakeLock(t *testing.T) {
+	lock := `{
+  "nodes": {
+    "home-manager": {
+      "inputs": {"nixpkgs": ["nixpkgs"]},
+      "locked": {"lastModified": 1717000000, "narHash": "sha256-hm", "owner": "nix-community", "repo": "home-manager", "rev": "hmrev", "type": "github"},
+      "original": {"owner": "nix-community", "repo": "home-manager", "type": "github"}
+    },
+    "nixpkgs": {
+      "locked": {"lastModified": 1718000000, "narHash": "sha256-np", "owner": "NixOS", "repo": "nixpkgs", "rev": "nprev", "type": "github"},
+      "original": {"owner": "NixOS", "ref": "nixos-unstable", "repo": "nixpkgs", "type": "github"}
+    },
+    "secrets": {
+      "locked": {"lastModified": 1716000000, "narHash": "sha256-sec", "rev": "secrev", "type": "git", "url": "ssh://git@example.com/secrets"},
+      "original": {"type": "git", "url": "ssh://git@example.com/secrets"}
+    },
+    "root": {
+      "inputs": {"home-manager": "home-manager", "nixpkgs": "nixpkgs", "secrets": "secrets", "pkgs": ["home-manager", "nixpkgs"]}
+    }
+  },
+  "root": "root",
+  "version": 7
+}`
+	inputs, err := parseFlakeLock([]byte(lock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 4 {
+		t.Fatalf("got %d inputs: %+v", len(inputs), inputs)
+	}
+	names := []string{"home-manager", "nixpkgs", "pkgs", "secrets"}
+	for i, in := range inputs {
+		if in.Name != names[i] {
+			t.Errorf("input %d = %s, want %s", i, in.Name, names[i])
+		}
+	}
+	np := inputs[1]
+	if np.Type != "github" || np.Owner != "NixOS" || np.Repo != "nixpkgs" || np.Ref != "nixos-unstable" ||
+		np.Rev != "nprev" || np.LastModified != 1718000000 || np.NarHash != "sha256-np" {
+		t.Errorf("nixpkgs = %+v", np)
+	}
+	// pkgs follows home-manager/nixpkgs, which follows nixpkgs
+	if inputs[2].Rev != "nprev" {
+		t.Errorf("follows not resolved: %+v", inputs[2])
+	}
+	if inputs[3].URL != "ssh://git@example.com/secrets" {
+		t.Errorf("secrets = %+v", inputs[3])
+	}
+
+	for _, bad := range []string{
+		`not json`,
+		`{"nodes": {}, "root": "root"}`,
+		`{"nodes": {"root": {"inputs": {"a": "missing"}}}, "root": "root"}`,
+		`{"nodes": {"root": {"inputs": {"a": ["a"]}}}, "root": "root"}`,
+	} {
+		if _, err := parseFlakeLock([]byte(bad)); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
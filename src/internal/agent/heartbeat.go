@@ -16,13 +16,15 @@ import (
 // heartbeatLoop sends periodic heartbeats.
 // It continues sending heartbeats even during command execution (T02 critical requirement).
 func (a *Agent) heartbeatLoop() {
-	ticker := time.NewTicker(a.cfg.HeartbeatInterval)
+	ticker := time.NewTicker(a.currentConfig().HeartbeatInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-a.ctx.Done():
 			return
+		case interval := <-a.intervalCh:
+			ticker.Reset(interval)
 		case <-ticker.C:
 			if a.ws.IsConnected() && a.IsRegistered() {
 				a.sendHeartbeat()
@@ -53,6 +55,17 @@ func (a *Agent) sendHeartbeat() {
 	// P3700: Compute lock hash for version-based Lock compartment tracking
 	lockHash := a.computeLockHash()
 
+	// Flake inputs only when the lock changed since the dashboard got them
+	a.mu.RLock()
+	lockSent := a.flakeLockSent
+	a.mu.RUnlock()
+	var flakeInputs []protocol.FlakeInput
+	if lockHash != "" && lockHash != lockSent {
+		flakeInputs = a.readFlakeInputs()
+	}
+
+	rebootRequired, rebootReason := a.checkRebootRequired()
+
 	payload := protocol.HeartbeatPayload{
 		Generation:     a.generation,
 		NixpkgsVersion: a.nixpkgsVersion,
@@ -65,13 +78,26 @@ func (a *Agent) sendHeartbeat() {
 		StorePath:    freshness.StorePath,
 		BinaryHash:   freshness.BinaryHash,
 		// P3700: Lock version tracking
-		LockHash: lockHash,
+		LockHash:    lockHash,
+		FlakeInputs: flakeInputs,
+		Disk:        a.readDiskUsage(),
+		Services:    a.readFailedUnits(),
+		// Kernel/initrd changed since boot
+		RebootRequired: rebootRequired,
+		RebootReason:   rebootReason,
+		Facts:          a.unsentFacts(),
 	}
 
 	if err := a.ws.SendMessage(protocol.TypeHeartbeat, payload); err != nil {
 		a.log.Debug().Err(err).Msg("failed to send heartbeat")
 		return
 	}
+	if lockHash != lockSent {
+		a.mu.Lock()
+		a.flakeLockSent = lockHash
+		a.mu.Unlock()
+	}
+	a.markFactsSent(payload.Facts)
 
 	a.log.Debug().
 		Str("generation", payload.Generation).
@@ -147,6 +173,14 @@ func (a *Agent) detectHostType() string {
 	return "nixos"
 }
 
+// darwinMode returns the configured macOS mode, empty on other platforms.
+func (a *Agent) darwinMode() string {
+	if runtime.GOOS != "darwin" {
+		return ""
+	}
+	return a.cfg.DarwinMode
+}
+
 // detectOSVersion returns the OS version string.
 func (a *Agent) detectOSVersion() string {
 	if runtime.GOOS == "darwin" {
@@ -0,0 +1,211 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// LOG STREAMS - live tail of a unit's log, next to commands
+// ═══════════════════════════════════════════════════════════════════════════
+
+const (
+	maxLogStreams         = 4   // concurrent sessions per agent
+	defaultLogStreamLines = 100 // journal lines shown before following
+	maxLogStreamLines     = 1000
+
+	logStreamInterval = 200 * time.Millisecond // longest a line waits
+	logStreamMaxLines = 200
+)
+
+// logStream is a running log stream session.
+type logStream struct {
+	unit   string
+	cancel context.CancelFunc
+}
+
+// logStreamArgv returns the command that follows unit's log on this OS.
+func logStreamArgv(unit string, lines int) []string {
+	if runtime.GOOS == "darwin" {
+		predicate := fmt.Sprintf(`process == "%s" OR subsystem == "%s"`, unit, unit)
+		return []string{"/usr/bin/log", "stream", "--style", "compact", "--predicate", predicate}
+	}
+	return []string{"journalctl", "--follow", "--unit", unit, "--lines", fmt.Sprint(lines), "--no-pager", "--output", "short-iso"}
+}
+
+// handleLogStreamStart starts a session. It does not take the command slot:
+// sessions run while the agent is busy and are only ended by a stop, by the
+// tail exiting, or by losing the dashboard connection.
+func (a *Agent) handleLogStreamStart(p protocol.LogStreamStartPayload) {
+	fail := func(msg string) {
+		a.log.Warn().Str("stream_id", p.StreamID).Str("unit", p.Unit).Msg("log stream refused: " + msg)
+		a.sendLogStreamEnd(p.StreamID, msg)
+	}
+	if p.StreamID == "" {
+		a.log.Warn().Msg("log_stream_start without stream_id")
+		return
+	}
+	if !protocol.ValidUnitName(p.Unit) {
+		fail(fmt.Sprintf("invalid unit name %q", p.Unit))
+		return
+	}
+	lines := p.Lines
+	if lines <= 0 {
+		lines = defaultLogStreamLines
+	}
+	lines = min(lines, maxLogStreamLines)
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.mu.Lock()
+	if a.logStreams == nil {
+		a.logStreams = make(map[string]*logStream)
+	}
+	switch {
+	case a.logStreams[p.StreamID] != nil:
+		a.mu.Unlock()
+		cancel()
+		fail("stream already running")
+		return
+	case len(a.logStreams) >= maxLogStreams:
+		a.mu.Unlock()
+		cancel()
+		fail(fmt.Sprintf("too many log streams (max %d)", maxLogStreams))
+		return
+	}
+	a.logStreams[p.StreamID] = &logStream{unit: p.Unit, cancel: cancel}
+	a.mu.Unlock()
+
+	go a.runLogStream(ctx, p.StreamID, logStreamArgv(p.Unit, lines))
+}
+
+// handleLogStreamStop ends a session; the tail's exit sends log_stream_end.
+func (a *Agent) handleLogStreamStop(streamID string) {
+	a.mu.RLock()
+	s := a.logStreams[streamID]
+	a.mu.RUnlock()
+	if s == nil {
+		a.log.Debug().Str("stream_id", streamID).Msg("log_stream_stop for unknown stream")
+		return
+	}
+	s.cancel()
+}
+
+// stopLogStreams ends all sessions, e.g. when the dashboard connection is
+// lost: nobody is left to read them.
+func (a *Agent) stopLogStreams() {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, s := range a.logStreams {
+		s.cancel()
+	}
+}
+
+// runLogStream runs the tail until it exits or ctx is cancelled, sending its
+// output in log_stream batches.
+func (a *Agent) runLogStream(ctx context.Context, streamID string, argv []string) {
+	defer func() {
+		a.mu.Lock()
+		if s := a.logStreams[streamID]; s != nil {
+			s.cancel()
+			delete(a.logStreams, streamID)
+		}
+		a.mu.Unlock()
+	}()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) }
+	cmd.WaitDelay = execKillGrace
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		a.sendLogStreamEnd(streamID, err.Error())
+		return
+	}
+	cmd.Stderr = cmd.Stdout // journalctl's errors (no such unit, no permission) belong in the stream
+	if err := cmd.Start(); err != nil {
+		a.sendLogStreamEnd(streamID, fmt.Sprintf("could not start %s: %v", argv[0], err))
+		return
+	}
+	a.log.Info().Str("stream_id", streamID).Strs("argv", argv).Msg("log stream started")
+
+	// Reading stops at a line over maxLineLength; the tail is then killed
+	// instead of left blocked on a full pipe
+	var readErr error
+	lines := make(chan string, logStreamMaxLines)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(nil, maxLineLength)
+		for scanner.Scan() {
+			lines <- truncateOutputLine(scanner.Text())
+		}
+		readErr = scanner.Err()
+	}()
+
+	var batch []string
+	flush := func() {
+		if len(batch) > 0 {
+			a.sendLogStreamLines(streamID, batch)
+			batch = nil
+		}
+	}
+	ticker := time.NewTicker(logStreamInterval)
+	defer ticker.Stop()
+	for open := true; open; {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				open = false
+				break
+			}
+			batch = append(batch, line)
+			if len(batch) >= logStreamMaxLines {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+	flush()
+	if readErr != nil {
+		cancel()
+	}
+
+	err = cmd.Wait()
+	msg := ""
+	switch {
+	case readErr != nil:
+		msg = fmt.Sprintf("reading %s output: %v", argv[0], readErr)
+	case ctx.Err() != nil:
+		// stopped
+	case err != nil:
+		msg = fmt.Sprintf("%s exited: %v", argv[0], err)
+	default:
+		msg = argv[0] + " exited"
+	}
+	a.log.Info().Str("stream_id", streamID).Str("error", msg).Msg("log stream ended")
+	a.sendLogStreamEnd(streamID, msg)
+}
+
+func (a *Agent) sendLogStreamLines(streamID string, lines []string) {
+	payload := protocol.LogStreamPayload{StreamID: streamID, Lines: lines}
+	if err := a.ws.SendMessage(protocol.TypeLogStream, payload); err != nil {
+		a.log.Debug().Err(err).Msg("failed to send log stream lines")
+	}
+}
+
+func (a *Agent) sendLogStreamEnd(streamID, msg string) {
+	payload := protocol.LogStreamEndPayload{StreamID: streamID, Error: msg}
+	if err := a.ws.SendMessage(protocol.TypeLogStreamEnd, payload); err != nil {
+		a.log.Debug().Err(err).Msg("failed to send log stream end")
+	}
+}
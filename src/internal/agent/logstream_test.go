@@ -0,0 +1,31 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/config"
+	"github.com/rs/zerolog"
+)
+
+func TestRunLogStreamEndsOnOverlongLine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := &config.Config{Hostname: "testhost"}
+	a := &Agent{cfg: cfg, log: zerolog.Nop(), ctx: ctx, cancel: cancel}
+	a.ws = NewWebSocketClient(cfg, a.log, a) // never connected: output is dropped
+
+	// One line over maxLineLength, then a tail that never exits by itself
+	script := `head -c 2000000 /dev/zero | tr '\0' x; echo; exec sleep 60`
+	done := make(chan struct{})
+	go func() {
+		a.runLogStream(ctx, "s1", []string{"sh", "-c", script})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("log stream still running after an overlong line")
+	}
+}
@@ -0,0 +1,253 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// NIX BUILD PROGRESS (--log-format internal-json)
+// ═══════════════════════════════════════════════════════════════════════════
+
+// nixLogArgs make nix write structured "@nix {...}" log lines, which carry
+// the build and download counters behind nix's own progress bar.
+var nixLogArgs = []string{"--log-format", "internal-json"}
+
+// nixLogPrefix starts every internal-json log line.
+const nixLogPrefix = "@nix "
+
+// Activity and result types of nix's internal-json log (libutil/logging.hh).
+const (
+	nixActFileTransfer = 101
+	nixActBuilds       = 104
+
+	nixResProgress    = 105
+	nixResSetExpected = 106
+)
+
+// nixLogLevelInfo is the most verbose level nix prints by default; chattier
+// messages are dropped like they are without internal-json.
+const nixLogLevelInfo = 3
+
+// progressInterval limits how often command progress is sent.
+const progressInterval = 500 * time.Millisecond
+
+// nixLogEvent is one "@nix" line.
+type nixLogEvent struct {
+	Action string            `json:"action"` // "start", "stop", "result", "msg"
+	ID     uint64            `json:"id"`
+	Level  int               `json:"level"`
+	Type   int               `json:"type"`
+	Text   string            `json:"text"`
+	Msg    string            `json:"msg"`
+	Fields []json.RawMessage `json:"fields"`
+}
+
+// nixActivity is the latest progress of a running activity.
+type nixActivity struct {
+	typ            int
+	done, expected int64
+}
+
+// nixLog turns nix's internal-json log back into readable output and
+// tracks build and download progress. Safe for concurrent use.
+type nixLog struct {
+	mu         sync.Mutex
+	activities map[uint64]*nixActivity
+	setExpect  map[uint64]map[int]int64 // activity → expected totals it announced, by type
+
+	phase      string
+	phaseStart time.Time
+	lastSent   time.Time
+	last       protocol.CommandProgressPayload
+}
+
+func newNixLog() *nixLog {
+	return &nixLog{
+		activities: make(map[uint64]*nixActivity),
+		setExpect:  make(map[uint64]map[int]int64),
+	}
+}
+
+// parse handles one output line. It returns the lines to show (the line
+// itself unless it is an internal-json event) and whether progress changed.
+func (l *nixLog) parse(line string) (output []string, changed bool) {
+	if !strings.HasPrefix(line, nixLogPrefix) {
+		return []string{line}, false
+	}
+	var ev nixLogEvent
+	if err := json.Unmarshal([]byte(line[len(nixLogPrefix):]), &ev); err != nil {
+		return []string{line}, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch ev.Action {
+	case "msg":
+		if ev.Level <= nixLogLevelInfo {
+			output = strings.Split(strings.TrimRight(ev.Msg, "\n"), "\n")
+		}
+	case "start":
+		l.activities[ev.ID] = &nixActivity{typ: ev.Type}
+		if ev.Text != "" && ev.Level <= nixLogLevelInfo {
+			output = []string{ev.Text + "..."}
+		}
+	case "stop":
+		act, ok := l.activities[ev.ID]
+		if ok && act.expected > act.done {
+			// A finished transfer or build set is done, whatever it last reported
+			act.done = act.expected
+			changed = true
+		}
+		delete(l.setExpect, ev.ID)
+		if ok && act.typ != nixActBuilds && act.typ != nixActFileTransfer {
+			delete(l.activities, ev.ID)
+		}
+	case "result":
+		changed = l.result(ev)
+	}
+	return output, changed
+}
+
+// result applies a progress or expected-total result; build log lines and
+// phase changes are not shown, as without -L.
+func (l *nixLog) result(ev nixLogEvent) bool {
+	fields := make([]int64, len(ev.Fields))
+	for i, f := range ev.Fields {
+		if err := json.Unmarshal(f, &fields[i]); err != nil {
+			return false
+		}
+	}
+	switch ev.Type {
+	case nixResProgress:
+		act, ok := l.activities[ev.ID]
+		if !ok || len(fields) < 2 {
+			return false
+		}
+		// Fields are done, expected, running, failed
+		act.done, act.expected = fields[0], fields[1]
+		return true
+	case nixResSetExpected:
+		if len(fields) < 2 {
+			return false
+		}
+		if l.setExpect[ev.ID] == nil {
+			l.setExpect[ev.ID] = make(map[int]int64)
+		}
+		l.setExpect[ev.ID][int(fields[0])] = fields[1]
+		return true
+	}
+	return false
+}
+
+// stats sums done and expected over all activities of type typ, like nix's
+// progress bar: the total is whichever is larger of what the activities
+// report and what their parents announced.
+func (l *nixLog) stats(typ int) (done, expected int64) {
+	var announced int64
+	for _, act := range l.activities {
+		if act.typ == typ {
+			done += act.done
+			expected += act.expected
+		}
+	}
+	for _, byType := range l.setExpect {
+		announced += byType[typ]
+	}
+	if announced > expected {
+		expected = announced
+	}
+	return done, expected
+}
+
+// progress returns the current progress, or false if nothing is being built
+// or downloaded, nothing changed, or the last update was sent less than
+// progressInterval ago.
+func (l *nixLog) progress(now time.Time) (protocol.CommandProgressPayload, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSent) < progressInterval {
+		return protocol.CommandProgressPayload{}, false
+	}
+
+	built, builds := l.stats(nixActBuilds)
+	downloaded, downloads := l.stats(nixActFileTransfer)
+
+	var p protocol.CommandProgressPayload
+	var parts []string
+	if builds > 0 {
+		parts = append(parts, fmt.Sprintf("building %d/%d derivations", built, builds))
+	}
+	if downloads > 0 {
+		parts = append(parts, fmt.Sprintf("downloading %s / %s", formatBytes(downloaded), formatBytes(downloads)))
+	}
+	switch {
+	case builds > 0 && built < builds:
+		p.Phase, p.Current, p.Total = "build", built, builds
+	case downloads > 0:
+		p.Phase, p.Current, p.Total = "download", downloaded, downloads
+	case builds > 0:
+		p.Phase, p.Current, p.Total = "build", built, builds
+	default:
+		return p, false
+	}
+	p.Description = strings.Join(parts, ", ")
+
+	if p.Phase != l.phase {
+		l.phase, l.phaseStart = p.Phase, now
+	}
+	if p == l.last {
+		return p, false
+	}
+	l.last = p
+	l.lastSent = now
+	p.ETASeconds = eta(now.Sub(l.phaseStart), p.Current, p.Total)
+	return p, true
+}
+
+// eta extrapolates the time left from the rate so far. Zero until there
+// is enough progress to tell.
+func eta(elapsed time.Duration, current, total int64) int {
+	if current <= 0 || current >= total || elapsed < 2*time.Second {
+		return 0
+	}
+	left := time.Duration(float64(elapsed) * float64(total-current) / float64(current))
+	return int(left.Round(time.Second) / time.Second)
+}
+
+// runNixBuild runs a command that builds with nixLogArgs: internal-json
+// lines are shown as nix would print them, and their progress is sent as
+// command_progress. Other lines stream unchanged.
+func (a *Agent) runNixBuild(cmd *exec.Cmd) int {
+	log := newNixLog()
+	return a.runWithLineHandler(cmd, func(line, stream string) {
+		output, changed := log.parse(line)
+		for _, out := range output {
+			a.sendOutput(out, stream)
+		}
+		if changed {
+			if p, ok := log.progress(time.Now()); ok {
+				a.sendCommandProgress(p)
+			}
+		}
+	})
+}
+
+// sendCommandProgress sends the build progress of the running command.
+func (a *Agent) sendCommandProgress(p protocol.CommandProgressPayload) {
+	a.mu.RLock()
+	p.CommandID, p.Command = a.commandReq.ID, a.commandReq.Command
+	a.mu.RUnlock()
+
+	if err := a.ws.SendMessage(protocol.TypeCommandProgress, p); err != nil {
+		a.log.Debug().Err(err).Msg("failed to send command progress")
+	}
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNixLog(t *testing.T) {
+	l := newNixLog()
+	start := time.Unix(1000, 0)
+
+	steps := []struct {
+		line    string
+		output  []string
+		changed bool
+	}{
+		{"building the system configuration...", []string{"building the system configuration..."}, false},
+		{`@nix {"action":"start","id":1,"level":0,"type":104,"text":"","parent":0}`, nil, false},
+		{`@nix {"action":"start","id":2,"level":3,"type":105,"text":"building '/nix/store/abc-foo.drv'","parent":1}`,
+			[]string{"building '/nix/store/abc-foo.drv'..."}, false},
+		{`@nix {"action":"result","id":1,"type":105,"fields":[12,47,1,0]}`, nil, true},
+		{`@nix {"action":"result","id":2,"type":101,"fields":["make: Entering directory"]}`, nil, false},
+		{`@nix {"action":"msg","level":5,"msg":"evaluating file"}`, nil, false},
+		{`@nix {"action":"msg","level":0,"msg":"error: builder failed\nlast 10 log lines:"}`,
+			[]string{"error: builder failed", "last 10 log lines:"}, false},
+		{`@nix {not json`, []string{`@nix {not json`}, false},
+	}
+	for i, s := range steps {
+		output, changed := l.parse(s.line)
+		if !reflect.DeepEqual(output, s.output) || changed != s.changed {
+			t.Errorf("step %d: parse = %q, %v; want %q, %v", i, output, changed, s.output, s.changed)
+		}
+	}
+
+	p, ok := l.progress(start)
+	if !ok || p.Phase != "build" || p.Current != 12 || p.Total != 47 || p.Description != "building 12/47 derivations" {
+		t.Fatalf("progress = %+v, %v", p, ok)
+	}
+	if _, ok := l.progress(start.Add(100 * time.Millisecond)); ok {
+		t.Error("progress not throttled")
+	}
+
+	// Downloads announced by the parent, then transferred
+	l.parse(`@nix {"action":"start","id":3,"level":4,"type":102,"text":""}`)
+	l.parse(`@nix {"action":"result","id":3,"type":106,"fields":[101,1100000000]}`)
+	l.parse(`@nix {"action":"start","id":4,"level":4,"type":101,"text":"downloading 'https://cache.nixos.org/nar/x.nar.xz'"}`)
+	l.parse(`@nix {"action":"result","id":4,"type":105,"fields":[230000000,600000000,0,0]}`)
+	l.parse(`@nix {"action":"result","id":1,"type":105,"fields":[24,47,1,0]}`)
+
+	p, ok = l.progress(start.Add(10 * time.Second))
+	want := "building 24/47 derivations, downloading 219.35 MiB / 1.02 GiB"
+	if !ok || p.Phase != "build" || p.Description != want {
+		t.Fatalf("progress = %+v, %v; want description %q", p, ok, want)
+	}
+	if p.ETASeconds != 10 { // 24 derivations in 10s, 23 to go
+		t.Errorf("eta = %d, want 10", p.ETASeconds)
+	}
+
+	// Builds done: the download is what is left
+	l.parse(`@nix {"action":"stop","id":1}`)
+	p, ok = l.progress(start.Add(20 * time.Second))
+	if !ok || p.Phase != "download" || p.Current != 230000000 || p.Total != 1100000000 {
+		t.Fatalf("progress = %+v, %v", p, ok)
+	}
+}
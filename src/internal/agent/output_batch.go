@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"sync"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// Output batching: a rebuild can print tens of thousands of lines, and one
+// message per line floods the dashboard and every browser watching it.
+const (
+	outputBatchInterval = 100 * time.Millisecond // longest a line waits
+	outputBatchMaxLines = 500
+	outputBatchMaxBytes = 64 * 1024
+
+	// maxOutputLine caps a single line so a batch stays far below the
+	// dashboard's message size limit.
+	maxOutputLine = 16 * 1024
+)
+
+// outputBatcher collects output lines of a command and sends them as one
+// output_batch when the batch is full or outputBatchInterval has passed.
+// Sending blocks add, so a slow dashboard connection slows the command's
+// output instead of buffering it without bound.
+type outputBatcher struct {
+	send func(protocol.OutputBatchPayload) // set by New
+
+	mu    sync.Mutex
+	batch protocol.OutputBatchPayload
+	size  int
+	timer *time.Timer
+}
+
+// add queues a line read at the given time.
+func (b *outputBatcher) add(commandID, command, line, stream string, at time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// A batch belongs to one command
+	if len(b.batch.Lines) > 0 && (b.batch.CommandID != commandID || b.batch.Command != command) {
+		b.flushLocked()
+	}
+	b.batch.CommandID, b.batch.Command = commandID, command
+	b.batch.Lines = append(b.batch.Lines, protocol.OutputLine{Line: line, Stream: stream, Time: at.UnixMilli()})
+	b.size += len(line)
+
+	switch {
+	case len(b.batch.Lines) >= outputBatchMaxLines || b.size >= outputBatchMaxBytes:
+		b.flushLocked()
+	case b.timer == nil:
+		b.timer = time.AfterFunc(outputBatchInterval, b.flush)
+	}
+}
+
+// flush sends the queued lines, if any. Call it before messages that must
+// not overtake the output, like the command's status.
+func (b *outputBatcher) flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushLocked()
+}
+
+func (b *outputBatcher) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.batch.Lines) == 0 {
+		return
+	}
+	batch := b.batch
+	b.batch = protocol.OutputBatchPayload{}
+	b.size = 0
+	b.send(batch)
+}
+
+// truncateOutputLine shortens line to maxOutputLine bytes.
+func truncateOutputLine(line string) string {
+	if len(line) <= maxOutputLine {
+		return line
+	}
+	return line[:maxOutputLine] + " … [truncated]"
+}
@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+func TestOutputBatcher(t *testing.T) {
+	var mu sync.Mutex
+	var sent []protocol.OutputBatchPayload
+	b := outputBatcher{send: func(p protocol.OutputBatchPayload) {
+		mu.Lock()
+		sent = append(sent, p)
+		mu.Unlock()
+	}}
+	batches := func() []protocol.OutputBatchPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]protocol.OutputBatchPayload(nil), sent...)
+	}
+
+	at := time.UnixMilli(1700000000000)
+	for i := 0; i < outputBatchMaxLines+1; i++ {
+		b.add("c1", "switch", "line", "stdout", at)
+	}
+	if got := batches(); len(got) != 1 || len(got[0].Lines) != outputBatchMaxLines {
+		t.Fatalf("full batch not sent: %d batches", len(got))
+	}
+	if l := batches()[0].Lines[0]; l.Time != at.UnixMilli() || l.Stream != "stdout" {
+		t.Errorf("line = %+v", l)
+	}
+
+	// Another command starts a new batch
+	b.add("c2", "test", "other", "stderr", at)
+	got := batches()
+	if len(got) != 2 || got[1].CommandID != "c1" || len(got[1].Lines) != 1 {
+		t.Fatalf("batch of c1 not sent before c2: %+v", got)
+	}
+
+	// The rest goes out after outputBatchInterval
+	deadline := time.Now().Add(5 * time.Second)
+	for len(batches()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	got = batches()
+	if len(got) != 3 || got[2].CommandID != "c2" || got[2].Lines[0].Line != "other" {
+		t.Fatalf("pending batch not flushed: %+v", got)
+	}
+
+	b.flush() // nothing pending
+	if len(batches()) != 3 {
+		t.Error("empty batch sent")
+	}
+
+	long := truncateOutputLine(strings.Repeat("x", maxOutputLine+1))
+	if !strings.HasSuffix(long, "[truncated]") || len(long) > maxOutputLine+20 {
+		t.Errorf("long line not truncated: %d bytes", len(long))
+	}
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	bootedSystemPath  = "/run/booted-system"
+	currentSystemPath = "/run/current-system"
+)
+
+// bootComponents are the parts of a NixOS system that only take effect on
+// reboot. A switch activates everything else in place.
+var bootComponents = []string{"kernel", "initrd", "kernel-modules"}
+
+// checkRebootRequired reports whether the running kernel is stale, i.e. the
+// last switch changed the kernel, initrd or kernel modules.
+// Always false on macOS (no /run/booted-system).
+func (a *Agent) checkRebootRequired() (bool, string) {
+	if runtime.GOOS == "darwin" {
+		return false, ""
+	}
+	return compareBootComponents(bootedSystemPath, currentSystemPath)
+}
+
+// compareBootComponents resolves each boot component in both system
+// profiles and describes those that differ.
+// Components missing from either side (containers, non-NixOS) are ignored.
+func compareBootComponents(booted, current string) (bool, string) {
+	var changed []string
+	for _, name := range bootComponents {
+		old, err := filepath.EvalSymlinks(filepath.Join(booted, name))
+		if err != nil {
+			continue
+		}
+		cur, err := filepath.EvalSymlinks(filepath.Join(current, name))
+		if err != nil {
+			continue
+		}
+		if old == cur {
+			continue
+		}
+
+		// Include versions for the kernel, that's what users look for
+		oldName, curName := storePathName(old), storePathName(cur)
+		if name == "kernel" && oldName != "" && curName != "" && oldName != curName {
+			changed = append(changed, fmt.Sprintf("kernel (%s → %s)", oldName, curName))
+		} else {
+			changed = append(changed, name)
+		}
+	}
+
+	if len(changed) == 0 {
+		return false, ""
+	}
+	return true, strings.Join(changed, ", ") + " changed"
+}
+
+// storePathName returns the package name of a store path without its hash,
+// e.g. "/nix/store/<hash>-linux-6.6.32/bzImage" → "linux-6.6.32".
+func storePathName(path string) string {
+	_, rest, ok := strings.Cut(path, "/nix/store/")
+	if !ok {
+		return ""
+	}
+	entry, _, _ := strings.Cut(rest, "/")
+	// Nix hashes are 32 base32 characters followed by '-'
+	if len(entry) <= 33 || entry[32] != '-' {
+		return ""
+	}
+	return entry[33:]
+}
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareBootComponents(t *testing.T) {
+	root := t.TempDir()
+	store := filepath.Join(root, "nix", "store")
+
+	// mkStore creates <store>/<hash>-<name>/<file> and returns its path
+	mkStore := func(hash, name, file string) string {
+		dir := filepath.Join(store, hash+"-"+name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		p := filepath.Join(dir, file)
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	// mkSystem creates a system profile dir with component symlinks
+	mkSystem := func(name string, links map[string]string) string {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for comp, target := range links {
+			if err := os.Symlink(target, filepath.Join(dir, comp)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+
+	const h1 = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	const h2 = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	oldKernel := mkStore(h1, "linux-6.6.30", "bzImage")
+	newKernel := mkStore(h2, "linux-6.6.32", "bzImage")
+	initrd := mkStore(h1, "initrd-linux-6.6.30", "initrd")
+
+	booted := mkSystem("booted", map[string]string{"kernel": oldKernel, "initrd": initrd})
+	same := mkSystem("same", map[string]string{"kernel": oldKernel, "initrd": initrd})
+	changed := mkSystem("changed", map[string]string{"kernel": newKernel, "initrd": initrd})
+
+	if required, reason := compareBootComponents(booted, same); required {
+		t.Errorf("identical systems: required=true, reason %q", reason)
+	}
+
+	required, reason := compareBootComponents(booted, changed)
+	if !required || reason != "kernel (linux-6.6.30 → linux-6.6.32) changed" {
+		t.Errorf("kernel change: got %v, %q", required, reason)
+	}
+
+	// Missing profile (e.g. container without /run/booted-system)
+	if required, _ := compareBootComponents(filepath.Join(root, "missing"), changed); required {
+		t.Error("missing booted system should not require reboot")
+	}
+}
+
+func TestStorePathName(t *testing.T) {
+	tests := map[string]string{
+		"/nix/store/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-linux-6.6.32/bzImage": "linux-6.6.32",
+		"/nix/store/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa-initrd-linux":         "initrd-linux",
+		"/boot/vmlinuz":              "",
+		"/nix/store/short-name/file": "",
+	}
+	for path, want := range tests {
+		if got := storePathName(path); got != want {
+			t.Errorf("storePathName(%q) = %q; want %q", path, got, want)
+		}
+	}
+}
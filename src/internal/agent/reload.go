@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/markus-barta/nixfleet/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// currentConfig returns a copy of the config, safe against concurrent reloads.
+// Fields that Reload may change must be read through this.
+func (a *Agent) currentConfig() config.Config {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return *a.cfg
+}
+
+// Reload applies a freshly loaded config (SIGHUP).
+//
+// Live: url, token, interval, log_level, theme_color, location, device_type,
+// gc_older_than, test_timeout, exec_commands, service_units. Everything else
+// (hostname, repository, control socket, ...) needs a restart and is only
+// reported.
+func (a *Agent) Reload(next *config.Config) {
+	a.mu.Lock()
+	prev := *a.cfg
+
+	endpointChanged := !slices.Equal(next.DashboardURLs(), prev.DashboardURLs()) || next.Token != prev.Token
+	registrationChanged := next.ThemeColor != prev.ThemeColor ||
+		next.Location != prev.Location ||
+		next.DeviceType != prev.DeviceType ||
+		next.HeartbeatInterval != prev.HeartbeatInterval ||
+		!reflect.DeepEqual(next.ExecCommands, prev.ExecCommands) || // advertised in the capabilities
+		!slices.Equal(next.ServiceUnits, prev.ServiceUnits)
+
+	a.cfg.DashboardURL = next.DashboardURL
+	a.cfg.FallbackURLs = next.FallbackURLs
+	a.cfg.Token = next.Token
+	a.cfg.HeartbeatInterval = next.HeartbeatInterval
+	a.cfg.LogLevel = next.LogLevel
+	a.cfg.ThemeColor = next.ThemeColor
+	a.cfg.Location = next.Location
+	a.cfg.DeviceType = next.DeviceType
+	a.cfg.GCDeleteOlderThan = next.GCDeleteOlderThan
+	a.cfg.TestTimeout = next.TestTimeout
+	a.cfg.ExecCommands = next.ExecCommands
+	a.cfg.ServiceUnits = next.ServiceUnits
+	a.mu.Unlock()
+
+	var ignored []string
+	for _, f := range []struct {
+		name      string
+		old, next string
+	}{
+		{"hostname", prev.Hostname, next.Hostname},
+		{"repo_url", prev.RepoURL, next.RepoURL},
+		{"repo_dir", prev.RepoDir, next.RepoDir},
+		{"branch", prev.Branch, next.Branch},
+		{"ssh_key", prev.SSHKey, next.SSHKey},
+		{"nixpkgs_version", prev.NixpkgsVersion, next.NixpkgsVersion},
+		{"control_socket", prev.ControlSocket, next.ControlSocket},
+		{"darwin_mode", prev.DarwinMode, next.DarwinMode},
+		{"targets", config.FormatTargets(prev.Targets), config.FormatTargets(next.Targets)},
+	} {
+		if f.old != f.next {
+			ignored = append(ignored, f.name)
+		}
+	}
+	if len(ignored) > 0 {
+		a.log.Warn().Strs("fields", ignored).Msg("config changes require an agent restart")
+	}
+
+	SetLogLevel(next.LogLevel)
+
+	if next.HeartbeatInterval != prev.HeartbeatInterval {
+		// Drop a pending, not yet applied value so the latest one wins
+		select {
+		case <-a.intervalCh:
+		default:
+		}
+		a.intervalCh <- next.HeartbeatInterval
+	}
+
+	a.log.Info().
+		Strs("urls", next.DashboardURLs()).
+		Dur("interval", next.HeartbeatInterval).
+		Str("location", next.Location).
+		Str("device_type", next.DeviceType).
+		Msg("config reloaded")
+
+	if a.ws == nil {
+		return
+	}
+	switch {
+	case endpointChanged:
+		// Registration happens on the new connection
+		a.ws.SetEndpoints(next.DashboardURLs(), next.Token)
+		if err := a.ws.Reconnect(); err != nil {
+			a.log.Debug().Err(err).Msg("error closing websocket for reconnect")
+		}
+	case registrationChanged && a.ws.IsConnected():
+		a.sendRegistration()
+	}
+}
+
+// SetLogLevel sets the global log level ("debug", "info", "warn", "error").
+// Unknown values fall back to info.
+func SetLogLevel(level string) {
+	switch level {
+	case "debug":
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	case "warn":
+		zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	case "error":
+		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	default:
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	}
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// SERVICE OPS - restart/stop/start an allowlisted unit (NIXFLEET_SERVICE_UNITS)
+// ═══════════════════════════════════════════════════════════════════════════
+
+const (
+	// serviceSettleTimeout bounds how long a unit may stay in a transitional
+	// state (activating, deactivating) before its state is checked.
+	serviceSettleTimeout = 30 * time.Second
+	// serviceRecheckDelay catches units that start and then crash right away.
+	serviceRecheckDelay = 2 * time.Second
+
+	// serviceExitWrongState is the exit code when the unit did not end up in
+	// the wanted state (like `systemctl is-active`).
+	serviceExitWrongState = 3
+)
+
+// serviceCommands are the service ops and the state each leaves its unit in.
+var serviceCommands = map[string]string{
+	"service-restart": "active",
+	"service-start":   "active",
+	"service-stop":    "inactive",
+}
+
+// isServiceCommand reports whether command controls a unit (CommandArgs.Unit).
+func isServiceCommand(command string) bool {
+	_, ok := serviceCommands[command]
+	return ok
+}
+
+// handleServiceOp restarts, stops or starts a unit from the host's
+// allowlist, then checks the unit's ActiveState: the command fails with
+// exit 3 if the unit did not end up active (restart, start) or stopped.
+func (a *Agent) handleServiceOp(command string, args protocol.CommandArgs) {
+	unit := args.Unit
+	if !slices.Contains(a.currentConfig().ServiceUnits, unit) {
+		a.sendStatus("error", command, 1, fmt.Sprintf("%q is not in this host's service units", unit))
+		return
+	}
+	action := strings.TrimPrefix(command, "service-")
+	want := serviceCommands[command]
+
+	argv := serviceControlArgv(action, unit)
+	a.log.Info().Str("unit", unit).Str("action", action).Strs("argv", argv).Msg("controlling service")
+	a.sendOutput("$ "+protocol.FormatCommandLine(argv), "stdout")
+
+	cmd := exec.CommandContext(a.ctx, argv[0], argv[1:]...)
+	if exitCode := a.runWithStreaming(cmd); exitCode != 0 {
+		a.sendStatus("error", command, exitCode, fmt.Sprintf("%s %s failed (exit %d)", action, unit, exitCode))
+		return
+	}
+
+	state := a.settledUnitState(unit)
+	if state == "active" && want == "active" {
+		// A unit that crashes on start can look active for a moment
+		time.Sleep(serviceRecheckDelay)
+		state = a.settledUnitState(unit)
+	}
+	a.sendOutput(fmt.Sprintf("● %s: %s", unit, state), "stdout")
+	// A unit that exits non-zero on SIGTERM is stopped, but "failed"
+	if state != want && !(want == "inactive" && state == "failed") {
+		a.sendStatus("error", command, serviceExitWrongState, fmt.Sprintf("%s is %s after %s (want %s)", unit, state, action, want))
+		return
+	}
+	a.sendStatus("ok", command, 0, fmt.Sprintf("%s is %s", unit, state))
+}
+
+// settledUnitState polls the unit's ActiveState until it leaves the
+// transitional states or serviceSettleTimeout has passed.
+func (a *Agent) settledUnitState(unit string) string {
+	deadline := time.Now().Add(serviceSettleTimeout)
+	for {
+		state := a.unitActiveState(unit)
+		switch state {
+		case "activating", "deactivating", "reloading", "refreshing":
+			if time.Now().Before(deadline) {
+				time.Sleep(500 * time.Millisecond)
+				continue
+			}
+		}
+		return state
+	}
+}
+
+// unitActiveState returns the unit's ActiveState ("active", "inactive",
+// "failed", ...), or "unknown" if it cannot be read. On macOS a running
+// launchd job counts as active, anything else as inactive.
+func (a *Agent) unitActiveState(unit string) string {
+	ctx, cancel := context.WithTimeout(a.ctx, servicesTimeout)
+	defer cancel()
+
+	if runtime.GOOS == "darwin" {
+		out, err := exec.CommandContext(ctx, "launchctl", "print", launchdTarget(unit)).Output()
+		if err != nil {
+			return "inactive" // not loaded
+		}
+		return launchdActiveState(string(out))
+	}
+	out, err := exec.CommandContext(ctx, "systemctl", "show", "--property=ActiveState", "--value", unit).Output()
+	if err != nil {
+		return "unknown"
+	}
+	if state := strings.TrimSpace(string(out)); state != "" {
+		return state
+	}
+	return "unknown"
+}
+
+// serviceControlArgv returns the command that restarts, stops or starts unit.
+func serviceControlArgv(action, unit string) []string {
+	if runtime.GOOS == "darwin" {
+		target := launchdTarget(unit)
+		switch action {
+		case "restart":
+			return []string{"launchctl", "kickstart", "-k", target}
+		case "stop":
+			return []string{"launchctl", "kill", "SIGTERM", target}
+		default:
+			return []string{"launchctl", "kickstart", target}
+		}
+	}
+	return asRoot("systemctl", action, unit)
+}
+
+// launchdTarget is the launchctl service target for label: the system
+// domain when running as root, the user's GUI domain otherwise.
+func launchdTarget(label string) string {
+	if uid := os.Geteuid(); uid != 0 {
+		return fmt.Sprintf("gui/%d/%s", uid, label)
+	}
+	return "system/" + label
+}
+
+// launchdActiveState maps `launchctl print` output to an ActiveState.
+func launchdActiveState(out string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), " = "); ok && key == "state" {
+			if value == "running" {
+				return "active"
+			}
+			return "inactive"
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,175 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+const (
+	// servicesInterval is how often systemctl is queried for failed units.
+	// Heartbeats in between reuse the cached result.
+	servicesInterval = 30 * time.Second
+
+	// servicesTimeout bounds a single systemctl invocation.
+	servicesTimeout = 5 * time.Second
+)
+
+// readFailedUnits returns the failed systemd units, cached for servicesInterval.
+// Returns nil on hosts without systemd (macOS) or when systemctl is unavailable,
+// so the dashboard can tell "unknown" apart from "nothing failing".
+func (a *Agent) readFailedUnits() *protocol.ServicesStatus {
+	if runtime.GOOS == "darwin" {
+		return nil
+	}
+
+	a.servicesMu.Lock()
+	defer a.servicesMu.Unlock()
+
+	if a.services != nil && time.Since(a.servicesCheckedAt) < servicesInterval {
+		return a.services
+	}
+
+	status, err := a.queryFailedUnits()
+	if err != nil {
+		a.log.Debug().Err(err).Msg("failed to query systemd units")
+		// Keep reporting the last known state rather than flapping to "unknown"
+		return a.services
+	}
+
+	if a.services != nil && !sameFailedUnits(a.services.Failed, status.Failed) {
+		a.log.Info().Int("failed", len(status.Failed)).Msg("failed systemd units changed")
+	}
+	a.services = status
+	a.servicesCheckedAt = time.Now()
+	return status
+}
+
+// queryFailedUnits runs `systemctl --failed --output=json` and enriches each
+// unit with its Result and the time it entered the failed state.
+func (a *Agent) queryFailedUnits() (*protocol.ServicesStatus, error) {
+	ctx, cancel := context.WithTimeout(a.ctx, servicesTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "systemctl", "--failed", "--no-pager", "--output=json").Output()
+	if err != nil {
+		return nil, err
+	}
+	units, err := parseFailedUnits(out)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(units) > 0 {
+		args := []string{"show", "--no-pager", "--timestamp=unix", "-p", "Id", "-p", "Result", "-p", "StateChangeTimestamp"}
+		for _, u := range units {
+			args = append(args, u.Name)
+		}
+		// Details are best effort: older systemd lacks --timestamp=unix
+		if show, err := exec.CommandContext(ctx, "systemctl", args...).Output(); err == nil {
+			details := parseUnitShow(show)
+			for i := range units {
+				if d, ok := details[units[i].Name]; ok {
+					units[i].Result = d.Result
+					units[i].Since = d.Since
+				}
+			}
+		} else {
+			a.log.Debug().Err(err).Msg("systemctl show failed, reporting unit names only")
+		}
+	}
+
+	return &protocol.ServicesStatus{
+		Failed:    units,
+		CheckedAt: time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// parseFailedUnits parses `systemctl --failed --output=json`.
+// The result is sorted by name and never nil, so "no failures" serialises as [].
+func parseFailedUnits(data []byte) ([]protocol.FailedUnit, error) {
+	var raw []struct {
+		Unit string `json:"unit"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	units := make([]protocol.FailedUnit, 0, len(raw))
+	for _, r := range raw {
+		if r.Unit == "" {
+			continue
+		}
+		units = append(units, protocol.FailedUnit{Name: r.Unit})
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].Name < units[j].Name })
+	return units, nil
+}
+
+// parseUnitShow parses `systemctl show -p Id -p Result -p StateChangeTimestamp`
+// output for several units (blank-line separated blocks) keyed by unit Id.
+func parseUnitShow(data []byte) map[string]protocol.FailedUnit {
+	result := make(map[string]protocol.FailedUnit)
+	var cur protocol.FailedUnit
+
+	flush := func() {
+		if cur.Name != "" {
+			result[cur.Name] = cur
+		}
+		cur = protocol.FailedUnit{}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			flush()
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Id":
+			cur.Name = value
+		case "Result":
+			cur.Result = value
+		case "StateChangeTimestamp":
+			cur.Since = parseUnixTimestamp(value)
+		}
+	}
+	flush()
+	return result
+}
+
+// parseUnixTimestamp converts systemd's "@1700000000" to RFC3339.
+// Empty or unparseable values yield "".
+func parseUnixTimestamp(value string) string {
+	secs, err := strconv.ParseInt(strings.TrimPrefix(value, "@"), 10, 64)
+	if err != nil || secs <= 0 {
+		return ""
+	}
+	return time.Unix(secs, 0).UTC().Format(time.RFC3339)
+}
+
+// sameFailedUnits reports whether two sorted lists name the same units.
+func sameFailedUnits(a, b []protocol.FailedUnit) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package agent
+
+import "testing"
+
+func TestParseFailedUnits(t *testing.T) {
+	data := []byte(`[
+		{"unit":"nginx.service","load":"loaded","active":"failed","sub":"failed","description":"Nginx"},
+		{"unit":"backup.timer","load":"loaded","active":"failed","sub":"failed","description":"Backup"}
+	]`)
+
+	units, err := parseFailedUnits(data)
+	if err != nil {
+		t.Fatalf("parseFailedUnits: %v", err)
+	}
+	if len(units) != 2 || units[0].Name != "backup.timer" || units[1].Name != "nginx.service" {
+		t.Errorf("parseFailedUnits = %+v; want backup.timer, nginx.service", units)
+	}
+
+	empty, err := parseFailedUnits([]byte(`[]`))
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("parseFailedUnits([]) = %v, %v; want empty non-nil slice", empty, err)
+	}
+
+	if _, err := parseFailedUnits([]byte("UNIT LOAD ACTIVE")); err == nil {
+		t.Error("parseFailedUnits(plain text) should fail")
+	}
+}
+
+func TestParseUnitShow(t *testing.T) {
+	data := []byte("Result=exit-code\nId=nginx.service\nStateChangeTimestamp=@1700000000\n\n" +
+		"Id=backup.timer\nResult=timeout\nStateChangeTimestamp=\n")
+
+	got := parseUnitShow(data)
+	if len(got) != 2 {
+		t.Fatalf("parseUnitShow returned %d units; want 2", len(got))
+	}
+	if u := got["nginx.service"]; u.Result != "exit-code" || u.Since != "2023-11-14T22:13:20Z" {
+		t.Errorf("nginx.service = %+v", u)
+	}
+	if u := got["backup.timer"]; u.Result != "timeout" || u.Since != "" {
+		t.Errorf("backup.timer = %+v", u)
+	}
+}
+
+func TestLaunchdActiveState(t *testing.T) {
+	running := "gui/501/com.example.app = {\n\tactive count = 1\n\tpath = /Library/LaunchAgents/com.example.app.plist\n\tstate = running\n\tpid = 812\n}\n"
+	if got := launchdActiveState(running); got != "active" {
+		t.Errorf("running job = %q; want active", got)
+	}
+	waiting := "system/com.example.app = {\n\tstate = not running\n\tlast exit code = 1\n}\n"
+	if got := launchdActiveState(waiting); got != "inactive" {
+		t.Errorf("stopped job = %q; want inactive", got)
+	}
+	if got := launchdActiveState(""); got != "unknown" {
+		t.Errorf("no output = %q; want unknown", got)
+	}
+}
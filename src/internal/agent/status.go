@@ -6,10 +6,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/markus-barta/nixfleet/internal/config"
 	"github.com/markus-barta/nixfleet/internal/protocol"
 )
 
@@ -22,6 +23,10 @@ type StatusChecker struct {
 	systemStatus protocol.StatusCheck
 	testsStatus  protocol.StatusCheck // P3900: Tests compartment
 
+	// System status per deploy target (by name); systemStatus is their aggregate
+	targetMu     sync.Mutex
+	targetStatus map[string]protocol.StatusCheck
+
 	// Last check times
 	lastLockCheck   time.Time
 	lastSystemCheck time.Time
@@ -39,6 +44,7 @@ type StatusChecker struct {
 func NewStatusChecker(a *Agent) *StatusChecker {
 	return &StatusChecker{
 		a:              a,
+		targetStatus:   make(map[string]protocol.StatusCheck),
 		lockInterval:   5 * time.Minute,
 		systemInterval: 5 * time.Minute,
 		staleThreshold: 5 * time.Minute, // Status considered stale after 5 minutes
@@ -78,6 +84,8 @@ func (s *StatusChecker) GetStatus(ctx context.Context) *protocol.UpdateStatus {
 		Lock:   s.lockStatus,
 		System: s.systemStatus, // Returns cached/unknown until explicit refresh
 		Tests:  s.testsStatus,  // P3900: Tests compartment status
+
+		Targets: s.GetTargetStatuses(),
 	}
 }
 
@@ -96,10 +104,17 @@ func (s *StatusChecker) RefreshLock(ctx context.Context) {
 	s.lastLockCheck = time.Now()
 }
 
-// P2800: RefreshSystem forces an immediate refresh of just the system status.
-func (s *StatusChecker) RefreshSystem(ctx context.Context) {
-	s.a.log.Debug().Msg("force-refreshing system status")
-	s.systemStatus = s.checkSystemStatus(ctx)
+// P2800: RefreshSystem forces an immediate refresh of just the system status,
+// for the given deploy targets.
+func (s *StatusChecker) RefreshSystem(ctx context.Context, targets []config.Target) {
+	s.a.log.Debug().Int("targets", len(targets)).Msg("force-refreshing system status")
+	for _, t := range targets {
+		check := s.checkSystemStatus(ctx, t)
+		s.targetMu.Lock()
+		s.targetStatus[t.Name] = check
+		s.targetMu.Unlock()
+	}
+	s.updateSystemStatus()
 	s.lastSystemCheck = time.Now()
 }
 
@@ -116,37 +131,91 @@ func (s *StatusChecker) GetSystemStatus() protocol.StatusCheck {
 // SetSystemOk sets the system status to "ok" without running the expensive check.
 // Called after a successful switch (exit 0) since we know the system is current.
 func (s *StatusChecker) SetSystemOk(message string) {
-	s.systemStatus = protocol.StatusCheck{
-		Status:    "ok",
-		Message:   message,
-		CheckedAt: time.Now().UTC().Format(time.RFC3339),
-	}
-	s.lastSystemCheck = time.Now()
-	s.lastStatusUpdate = time.Time{} // P1900: Reset stale tracking on terminal state
+	s.SetTargetSystem("", "ok", message)
 }
 
 // SetSystemOutdated sets the system status to "outdated" without running the expensive check.
 // Called after a successful pull (exit 0) since the system now differs from the flake.
 func (s *StatusChecker) SetSystemOutdated(message string) {
-	s.systemStatus = protocol.StatusCheck{
-		Status:    "outdated",
-		Message:   message,
-		CheckedAt: time.Now().UTC().Format(time.RFC3339),
-	}
-	s.lastSystemCheck = time.Now()
-	s.lastStatusUpdate = time.Time{} // P1900: Reset stale tracking on terminal state
+	s.SetTargetSystem("", "outdated", message)
 }
 
 // P3800: SetSystemError sets the system status to "error" without running the expensive check.
 // Called after a failed switch to indicate the system is in an error state.
 func (s *StatusChecker) SetSystemError(message string) {
-	s.systemStatus = protocol.StatusCheck{
-		Status:    "error",
+	s.SetTargetSystem("", "error", message)
+}
+
+// SetTargetSystem sets the System status of one deploy target without running
+// the expensive check, or of all targets if target is empty. The host-wide
+// System status becomes the aggregate of the targets.
+func (s *StatusChecker) SetTargetSystem(target, status, message string) {
+	check := protocol.StatusCheck{
+		Status:    status,
 		Message:   message,
 		CheckedAt: time.Now().UTC().Format(time.RFC3339),
 	}
+
+	cfg := s.a.currentConfig()
+	s.targetMu.Lock()
+	if target == "" {
+		for _, t := range cfg.DeployTargets() {
+			s.targetStatus[t.Name] = check
+		}
+	} else {
+		s.targetStatus[target] = check
+	}
+	s.targetMu.Unlock()
+
+	if target == "" {
+		s.systemStatus = check
+	} else {
+		s.updateSystemStatus()
+	}
 	s.lastSystemCheck = time.Now()
-	s.lastStatusUpdate = time.Time{} // P1900: Reset stale tracking on terminal state
+	if status == "working" {
+		s.lastStatusUpdate = time.Now()
+	} else {
+		s.lastStatusUpdate = time.Time{} // P1900: Reset stale tracking on terminal state
+	}
+}
+
+// GetTargetStatuses returns the cached System status of each deploy target,
+// in deploy order. Targets never checked have an empty status.
+func (s *StatusChecker) GetTargetStatuses() []protocol.TargetStatus {
+	cfg := s.a.currentConfig()
+	targets := cfg.DeployTargets()
+
+	s.targetMu.Lock()
+	defer s.targetMu.Unlock()
+	out := make([]protocol.TargetStatus, len(targets))
+	for i, t := range targets {
+		out[i] = protocol.TargetStatus{Name: t.Name, Type: t.Type, User: t.User, System: s.targetStatus[t.Name]}
+	}
+	return out
+}
+
+// updateSystemStatus recomputes the host-wide System status from the targets
+// that have a status. With several targets the messages name the target.
+func (s *StatusChecker) updateSystemStatus() {
+	var checks []protocol.StatusCheck
+	statuses := s.GetTargetStatuses()
+	for _, ts := range statuses {
+		if ts.System.Status == "" {
+			continue
+		}
+		if len(statuses) > 1 {
+			ts.System.Message = ts.Name + ": " + ts.System.Message
+		}
+		checks = append(checks, ts.System)
+	}
+	switch len(checks) {
+	case 0:
+	case 1:
+		s.systemStatus = checks[0]
+	default:
+		s.systemStatus = combineStatusChecks(checks...)
+	}
 }
 
 // P3900: Tests compartment status methods
@@ -179,6 +248,15 @@ func (s *StatusChecker) SetTestsError(message string) {
 	}
 }
 
+// SetTestsUnknown sets the tests status to "unknown" (e.g. no test scripts).
+func (s *StatusChecker) SetTestsUnknown(message string) {
+	s.testsStatus = protocol.StatusCheck{
+		Status:    "unknown",
+		Message:   message,
+		CheckedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
 // SetTestsWorking sets the tests status to "working" while tests are running.
 func (s *StatusChecker) SetTestsWorking() {
 	s.testsStatus = protocol.StatusCheck{
@@ -188,15 +266,10 @@ func (s *StatusChecker) SetTestsWorking() {
 	}
 }
 
-// P1100: SetSystemWorking sets the system status to "working" during switch operations.
-func (s *StatusChecker) SetSystemWorking() {
-	s.systemStatus = protocol.StatusCheck{
-		Status:    "working",
-		Message:   "Switch in progress...",
-		CheckedAt: time.Now().UTC().Format(time.RFC3339),
-	}
-	s.lastSystemCheck = time.Now()
-	s.lastStatusUpdate = time.Now()
+// P1100: SetSystemWorking sets the system status to "working" during switch operations
+// (of one deploy target, or all if target is empty).
+func (s *StatusChecker) SetSystemWorking(target string) {
+	s.SetTargetSystem(target, "working", "Switch in progress...")
 }
 
 // P1100: SetLockWorking sets the lock status to "working" during refresh-lock operations.
@@ -290,10 +363,9 @@ func (s *StatusChecker) checkLockStatus(ctx context.Context) protocol.StatusChec
 	}
 }
 
-// checkSystemStatus checks if the running system matches what the current flake would build.
-func (s *StatusChecker) checkSystemStatus(ctx context.Context) protocol.StatusCheck {
+// checkSystemStatus checks if a deploy target's active generation matches what the current flake would build.
+func (s *StatusChecker) checkSystemStatus(ctx context.Context, t config.Target) protocol.StatusCheck {
 	repoDir := s.a.cfg.RepoDir
-	hostname := s.a.cfg.Hostname
 
 	if repoDir == "" {
 		return protocol.StatusCheck{
@@ -306,14 +378,11 @@ func (s *StatusChecker) checkSystemStatus(ctx context.Context) protocol.StatusCh
 	ctx, cancel := context.WithTimeout(ctx, 60*time.Second) // Longer timeout for nix operations
 	defer cancel()
 
-	if runtime.GOOS == "darwin" {
-		return s.checkMacOSSystemStatus(ctx, repoDir, hostname)
-	}
-	return s.checkNixOSSystemStatus(ctx, repoDir, hostname)
+	return s.checkTargetStatus(ctx, repoDir, t)
 }
 
 // checkNixOSSystemStatus checks NixOS system status by comparing derivations.
-func (s *StatusChecker) checkNixOSSystemStatus(ctx context.Context, repoDir, hostname string) protocol.StatusCheck {
+func (s *StatusChecker) checkNixOSSystemStatus(ctx context.Context, repoDir, attr string) protocol.StatusCheck {
 	// Get current system derivation (resolve symlink to store path)
 	currentPath, err := filepath.EvalSymlinks("/run/current-system")
 	if err != nil {
@@ -325,7 +394,7 @@ func (s *StatusChecker) checkNixOSSystemStatus(ctx context.Context, repoDir, hos
 	}
 
 	// Get what the flake would build (dry-run)
-	flakeRef := repoDir + "#nixosConfigurations." + hostname + ".config.system.build.toplevel"
+	flakeRef := repoDir + "#nixosConfigurations." + attr + ".config.system.build.toplevel"
 	cmd := exec.CommandContext(ctx, "nix", "build", "--experimental-features", "nix-command flakes", "--dry-run", "--json", flakeRef)
 	cmd.Dir = repoDir
 	output, err := cmd.CombinedOutput()
@@ -367,76 +436,6 @@ func (s *StatusChecker) checkNixOSSystemStatus(ctx context.Context, repoDir, hos
 	}
 }
 
-// checkMacOSSystemStatus checks macOS/Home Manager system status.
-func (s *StatusChecker) checkMacOSSystemStatus(ctx context.Context, repoDir, hostname string) protocol.StatusCheck {
-	// For macOS, check Home Manager generation
-	// Get current generation path
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return protocol.StatusCheck{
-			Status:    "error",
-			Message:   "Cannot get home directory: " + err.Error(),
-			CheckedAt: time.Now().UTC().Format(time.RFC3339),
-		}
-	}
-
-	currentGen := filepath.Join(homeDir, ".local/state/nix/profiles/home-manager")
-
-	// Resolve symlink chain to get final store path
-	// home-manager -> home-manager-37-link -> /nix/store/xxx-home-manager-generation
-	currentPath, err := filepath.EvalSymlinks(currentGen)
-	if err != nil {
-		return protocol.StatusCheck{
-			Status:    "unknown",
-			Message:   "No Home Manager profile found",
-			CheckedAt: time.Now().UTC().Format(time.RFC3339),
-		}
-	}
-
-	// Get what the flake would build (dry-run)
-	flakeRef := repoDir + "#homeConfigurations." + hostname + ".activationPackage"
-	cmd := exec.CommandContext(ctx, "nix", "build", "--experimental-features", "nix-command flakes", "--dry-run", "--json", flakeRef)
-	cmd.Dir = repoDir
-	output, err := cmd.CombinedOutput() // Capture stderr too
-
-	if err != nil {
-		// Nix evaluation might fail for various reasons
-		msg := "Cannot evaluate Home Manager config"
-		if len(output) > 0 {
-			// Extract first line of error for brevity
-			errLines := strings.Split(string(output), "\n")
-			if len(errLines) > 0 {
-				msg = fmt.Sprintf("Evaluation failed: %s", strings.TrimSpace(errLines[0]))
-			}
-		} else {
-			msg = fmt.Sprintf("Evaluation failed: %v", err)
-		}
-
-		return protocol.StatusCheck{
-			Status:    "unknown",
-			Message:   msg,
-			CheckedAt: time.Now().UTC().Format(time.RFC3339),
-		}
-	}
-
-	// Check if current store path matches what would be built
-	// The output contains paths like /nix/store/xxx-home-manager-generation
-	outStr := string(output)
-	if strings.Contains(outStr, currentPath) {
-		return protocol.StatusCheck{
-			Status:    "ok",
-			Message:   "Home Manager is current",
-			CheckedAt: time.Now().UTC().Format(time.RFC3339),
-		}
-	}
-
-	return protocol.StatusCheck{
-		Status:    "outdated",
-		Message:   "Home Manager needs switch",
-		CheckedAt: time.Now().UTC().Format(time.RFC3339),
-	}
-}
-
 // formatDaysAgo formats the number of days since last update.
 func formatDaysAgo(days int) string {
 	switch {
@@ -493,6 +492,13 @@ func (s *StatusChecker) detectAndResolveStaleStatus(now time.Time) {
 			Message:   "Status stale (agent may have restarted during command)",
 			CheckedAt: now.UTC().Format(time.RFC3339),
 		}
+		s.targetMu.Lock()
+		for name, check := range s.targetStatus {
+			if check.Status == "working" {
+				s.targetStatus[name] = s.systemStatus
+			}
+		}
+		s.targetMu.Unlock()
 		s.lastSystemCheck = now
 		s.lastStatusUpdate = now
 	}
@@ -0,0 +1,293 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/config"
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// DEPLOY TARGETS - system and per-user home-manager, switched and tracked
+// separately (NIXFLEET_TARGETS)
+// ═══════════════════════════════════════════════════════════════════════════
+
+// targetCommands are the commands that accept a target; without one they
+// act on all targets.
+var targetCommands = map[string]bool{
+	"switch":         true,
+	"pull-switch":    true,
+	"rollback":       true,
+	"refresh-system": true,
+}
+
+// homeManagerRollbackScript activates the previous Home Manager generation.
+const homeManagerRollbackScript = `prev=$(ls -1 ~/.local/state/nix/profiles/home-manager-*-link 2>/dev/null | tail -2 | head -1) && ` +
+	`if [ -n "$prev" ] && [ -L "$prev" ]; then ` +
+	`echo "Rolling back to: $prev" && "$prev/activate"; ` +
+	`else echo "No previous generation found" && exit 1; fi`
+
+// homeManagerGenerationScript activates Home Manager generation %[1]d.
+const homeManagerGenerationScript = `gen=~/.local/state/nix/profiles/home-manager-%[1]d-link && ` +
+	`if [ -L "$gen" ]; then echo "Switching to: $gen" && "$gen/activate"; ` +
+	`else echo "Generation %[1]d not found" && exit 1; fi`
+
+// checkCommandArgs rejects arguments the command does not take.
+func checkCommandArgs(command string, args protocol.CommandArgs, targets []config.Target) error {
+	if args.Target != "" && !targetCommands[command] {
+		return fmt.Errorf("%s does not take a target", command)
+	}
+	if args.Generation != 0 {
+		if command != "rollback" || args.Generation < 0 {
+			return fmt.Errorf("%s does not take generation %d", command, args.Generation)
+		}
+		// Generation numbers are per profile
+		if len(targets) != 1 {
+			return fmt.Errorf("rollback to generation %d needs a target", args.Generation)
+		}
+	}
+	if args.Ref != "" {
+		if command != "pull" && command != "pull-switch" {
+			return fmt.Errorf("%s does not take a git ref", command)
+		}
+		if !protocol.ValidGitRef(args.Ref) {
+			return fmt.Errorf("invalid git ref %q", args.Ref)
+		}
+	}
+	if command == "exec" && args.Exec == "" {
+		return errors.New("exec needs a command name")
+	}
+	if command != "exec" && (args.Exec != "" || len(args.Params) > 0) {
+		return fmt.Errorf("%s does not take an exec command", command)
+	}
+	if isServiceCommand(command) && !protocol.ValidUnitName(args.Unit) {
+		return fmt.Errorf("%s needs a unit name, got %q", command, args.Unit)
+	}
+	if !isServiceCommand(command) && args.Unit != "" {
+		return fmt.Errorf("%s does not take a unit", command)
+	}
+	return nil
+}
+
+// resolveTargets returns the target named name, or all targets if name is
+// empty.
+func (a *Agent) resolveTargets(name string) ([]config.Target, error) {
+	cfg := a.currentConfig()
+	if name == "" {
+		return cfg.DeployTargets(), nil
+	}
+	t, ok := cfg.FindTarget(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown target %q", name)
+	}
+	return []config.Target{t}, nil
+}
+
+// switchCommand switches targets in order, stopping at the first failure.
+func switchCommand(ctx context.Context, repoDir string, targets []config.Target, extraArgs ...string) *exec.Cmd {
+	steps := make([][]string, len(targets))
+	for i, t := range targets {
+		steps[i] = targetSwitchArgs(repoDir, t, extraArgs...)
+	}
+	cmd := commandChain(ctx, steps)
+	cmd.Dir = repoDir
+	return cmd
+}
+
+// targetSwitchArgs builds the switch of one target.
+func targetSwitchArgs(repoDir string, t config.Target, extraArgs ...string) []string {
+	flakeRef := repoDir + "#" + t.Attr
+
+	var args []string
+	switch t.Type {
+	case config.TargetNixOS:
+		// nixos-rebuild passes the log format on to nix (see runNixBuild)
+		args = append([]string{"sudo", "nixos-rebuild", "switch", "--flake", flakeRef}, nixLogArgs...)
+	case config.TargetNixDarwin:
+		args = asRoot(darwinRebuildPath(), "switch", "--flake", flakeRef)
+	default:
+		args = asUser(t.User, "home-manager", "switch", "--flake", flakeRef)
+	}
+	return append(args, extraArgs...)
+}
+
+// rollbackCommand activates the previous generation of each target, or
+// the given generation if it is not zero.
+func rollbackCommand(ctx context.Context, repoDir string, targets []config.Target, generation int) *exec.Cmd {
+	steps := make([][]string, 0, len(targets))
+	for _, t := range targets {
+		switch {
+		case t.Type == config.TargetNixOS && generation != 0:
+			steps = append(steps,
+				[]string{"sudo", "nix-env", "-p", "/nix/var/nix/profiles/system", "--switch-generation", strconv.Itoa(generation)},
+				[]string{"sudo", "/nix/var/nix/profiles/system/bin/switch-to-configuration", "switch"})
+		case t.Type == config.TargetNixOS:
+			steps = append(steps, []string{"sudo", "nixos-rebuild", "--rollback", "switch"})
+		case t.Type == config.TargetNixDarwin && generation != 0:
+			steps = append(steps, asRoot(darwinRebuildPath(), "switch", "--switch-generation", strconv.Itoa(generation)))
+		case t.Type == config.TargetNixDarwin:
+			// Switches the system profile to its previous generation and activates it
+			steps = append(steps, asRoot(darwinRebuildPath(), "switch", "--rollback"))
+		case generation != 0:
+			steps = append(steps, asUser(t.User, "sh", "-c", fmt.Sprintf(homeManagerGenerationScript, generation)))
+		default:
+			steps = append(steps, asUser(t.User, "sh", "-c", homeManagerRollbackScript))
+		}
+	}
+	cmd := commandChain(ctx, steps)
+	cmd.Dir = repoDir
+	return cmd
+}
+
+// asUser runs args as another user via a sudo login shell, so HOME and the
+// user's PATH (where home-manager lives) are set. The agent's own user, or
+// an empty one, runs args directly.
+func asUser(name string, args ...string) []string {
+	if name == "" {
+		return args
+	}
+	if current, err := user.Current(); err == nil && current.Username == name {
+		return args
+	}
+	return append([]string{"sudo", "-u", name, "-i", "--"}, args...)
+}
+
+// commandChain runs a single step directly, or several with sh -c,
+// stopping at the first failure.
+func commandChain(ctx context.Context, steps [][]string) *exec.Cmd {
+	if len(steps) == 1 {
+		return exec.CommandContext(ctx, steps[0][0], steps[0][1:]...)
+	}
+	parts := make([]string, len(steps))
+	for i, s := range steps {
+		parts[i] = shellJoin(s)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", strings.Join(parts, " && "))
+}
+
+// shellJoin quotes args for sh.
+func shellJoin(args []string) string {
+	return protocol.FormatCommandLine(args)
+}
+
+// checkTargetStatus compares a target's active generation with what the
+// flake would build.
+func (s *StatusChecker) checkTargetStatus(ctx context.Context, repoDir string, t config.Target) protocol.StatusCheck {
+	switch t.Type {
+	case config.TargetNixOS:
+		return s.checkNixOSSystemStatus(ctx, repoDir, t.Attr)
+	case config.TargetNixDarwin:
+		return s.checkNixDarwinStatus(ctx, repoDir, t.Attr)
+	default:
+		return s.checkHomeManagerStatus(ctx, repoDir, t.Attr, t.User)
+	}
+}
+
+// checkHomeManagerStatus compares a user's Home Manager profile with what
+// homeConfigurations.<attr>.activationPackage would build. An empty
+// username means the agent's own user.
+func (s *StatusChecker) checkHomeManagerStatus(ctx context.Context, repoDir, attr, username string) protocol.StatusCheck {
+	homeDir, err := os.UserHomeDir()
+	if username != "" {
+		var u *user.User
+		if u, err = user.Lookup(username); err == nil {
+			homeDir = u.HomeDir
+		}
+	}
+	if err != nil {
+		return protocol.StatusCheck{
+			Status:    "error",
+			Message:   "Cannot get home directory: " + err.Error(),
+			CheckedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+
+	currentGen := filepath.Join(homeDir, ".local/state/nix/profiles/home-manager")
+
+	// Resolve symlink chain to get final store path
+	// home-manager -> home-manager-37-link -> /nix/store/xxx-home-manager-generation
+	currentPath, err := filepath.EvalSymlinks(currentGen)
+	if err != nil {
+		return protocol.StatusCheck{
+			Status:    "unknown",
+			Message:   "No Home Manager profile found",
+			CheckedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+
+	flakeRef := repoDir + "#homeConfigurations." + attr + ".activationPackage"
+	return compareFlakeOutput(ctx, repoDir, flakeRef, currentPath, "Home Manager")
+}
+
+// compareFlakeOutput dry-run builds flakeRef and reports whether its output
+// is currentPath. what names the configuration in messages.
+func compareFlakeOutput(ctx context.Context, repoDir, flakeRef, currentPath, what string) protocol.StatusCheck {
+	cmd := exec.CommandContext(ctx, "nix", "build", "--experimental-features", "nix-command flakes", "--dry-run", "--json", flakeRef)
+	cmd.Dir = repoDir
+	output, err := cmd.CombinedOutput() // Capture stderr too
+
+	if err != nil {
+		// Nix evaluation might fail for various reasons
+		msg := fmt.Sprintf("Evaluation failed: %v", err)
+		if len(output) > 0 {
+			// Extract first line of error for brevity
+			errLines := strings.Split(string(output), "\n")
+			msg = fmt.Sprintf("Evaluation failed: %s", strings.TrimSpace(errLines[0]))
+		}
+
+		return protocol.StatusCheck{
+			Status:    "unknown",
+			Message:   msg,
+			CheckedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+
+	// The output contains the store path the flake would build
+	if strings.Contains(string(output), currentPath) {
+		return protocol.StatusCheck{
+			Status:    "ok",
+			Message:   what + " is current",
+			CheckedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+	}
+
+	return protocol.StatusCheck{
+		Status:    "outdated",
+		Message:   what + " needs switch",
+		CheckedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// combineStatusChecks merges checks into one: the worst status wins
+// (working > error > outdated > unknown > ok), with the messages of all
+// non-ok checks.
+func combineStatusChecks(checks ...protocol.StatusCheck) protocol.StatusCheck {
+	rank := map[string]int{"ok": 0, "unknown": 1, "outdated": 2, "error": 3, "working": 4}
+
+	combined := protocol.StatusCheck{Status: "ok", CheckedAt: time.Now().UTC().Format(time.RFC3339)}
+	var messages, okMessages []string
+	for _, c := range checks {
+		if rank[c.Status] > rank[combined.Status] {
+			combined.Status = c.Status
+		}
+		if c.Status == "ok" {
+			okMessages = append(okMessages, c.Message)
+		} else {
+			messages = append(messages, c.Message)
+		}
+	}
+	if len(messages) == 0 {
+		messages = okMessages
+	}
+	combined.Message = strings.Join(messages, "; ")
+	return combined
+}
@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/markus-barta/nixfleet/internal/config"
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+func TestSwitchCommand(t *testing.T) {
+	rebuild := strings.Join(asRoot(darwinRebuildPath(), "switch", "--flake", "/repo#mac"), " ")
+	home := config.Target{Name: "home", Type: config.TargetHomeManager, Attr: "mac"}
+
+	tests := []struct {
+		name    string
+		targets []config.Target
+		want    string // joined argv
+	}{
+		{"home-manager", []config.Target{home}, "home-manager switch --flake /repo#mac"},
+		{"nix-darwin", []config.Target{{Name: "system", Type: config.TargetNixDarwin, Attr: "mac"}}, rebuild},
+		{"both", []config.Target{{Name: "system", Type: config.TargetNixDarwin, Attr: "mac"}, home},
+			"sh -c " + rebuild + " && home-manager switch --flake /repo#mac"},
+		{"nixos and another user", []config.Target{
+			{Name: "system", Type: config.TargetNixOS, Attr: "ws1"},
+			{Name: "alice", Type: config.TargetHomeManager, Attr: "alice@ws1", User: "nixfleet-test-alice"},
+		}, "sh -c sudo nixos-rebuild switch --flake /repo#ws1 --log-format internal-json && sudo -u nixfleet-test-alice -i -- home-manager switch --flake /repo#alice@ws1"},
+	}
+	for _, tt := range tests {
+		cmd := switchCommand(context.Background(), "/repo", tt.targets)
+		if got := strings.Join(cmd.Args, " "); got != tt.want {
+			t.Errorf("%s: args = %q, want %q", tt.name, got, tt.want)
+		}
+		if cmd.Dir != "/repo" {
+			t.Errorf("%s: dir = %q", tt.name, cmd.Dir)
+		}
+	}
+
+	cmd := switchCommand(context.Background(), "/repo", []config.Target{home}, "--option", "narinfo-cache-negative-ttl", "0")
+	if !strings.HasSuffix(strings.Join(cmd.Args, " "), "--option narinfo-cache-negative-ttl 0") {
+		t.Errorf("extra args not passed: %v", cmd.Args)
+	}
+
+	nixos := []config.Target{{Name: "system", Type: config.TargetNixOS, Attr: "ws1"}}
+	cmd = rollbackCommand(context.Background(), "/repo", nixos, 0)
+	if got := strings.Join(cmd.Args, " "); got != "sudo nixos-rebuild --rollback switch" {
+		t.Errorf("rollback args = %q", got)
+	}
+	cmd = rollbackCommand(context.Background(), "/repo", nixos, 42)
+	want := "sh -c sudo nix-env -p /nix/var/nix/profiles/system --switch-generation 42 && sudo /nix/var/nix/profiles/system/bin/switch-to-configuration switch"
+	if got := strings.Join(cmd.Args, " "); got != want {
+		t.Errorf("rollback to generation args = %q", got)
+	}
+}
+
+func TestCheckCommandArgs(t *testing.T) {
+	one := []config.Target{{Name: "system", Type: config.TargetNixOS}}
+	two := append(one, config.Target{Name: "alice", Type: config.TargetHomeManager, User: "alice"})
+
+	for _, tt := range []struct {
+		command string
+		args    protocol.CommandArgs
+		targets []config.Target
+		ok      bool
+	}{
+		{"switch", protocol.CommandArgs{Target: "alice"}, two[1:], true},
+		{"pull", protocol.CommandArgs{Target: "alice"}, two[1:], false},
+		{"rollback", protocol.CommandArgs{Generation: 7}, one, true},
+		{"rollback", protocol.CommandArgs{Generation: 7}, two, false}, // which profile?
+		{"switch", protocol.CommandArgs{Generation: 7}, one, false},
+		{"pull-switch", protocol.CommandArgs{Ref: "v1.2.0"}, two, true},
+		{"pull", protocol.CommandArgs{Ref: "--upload-pack=evil"}, two, false},
+		{"pull", protocol.CommandArgs{Ref: "abc1234"}, two, true}, // tag, branch or short SHA
+		{"pull", protocol.CommandArgs{Ref: "abc1234567890def1234567890abcdef12345678"}, two, true},
+		{"switch", protocol.CommandArgs{Ref: "main"}, two, false},
+		{"exec", protocol.CommandArgs{Exec: "journal", Params: map[string]string{"unit": "sshd"}}, two, true},
+		{"exec", protocol.CommandArgs{}, two, false},
+		{"test", protocol.CommandArgs{Exec: "journal"}, two, false},
+		{"service-restart", protocol.CommandArgs{Unit: "app.service"}, two, true},
+		{"service-stop", protocol.CommandArgs{}, two, false},
+		{"service-start", protocol.CommandArgs{Unit: "--all"}, two, false},
+		{"switch", protocol.CommandArgs{Unit: "app.service"}, two, false},
+	} {
+		if err := checkCommandArgs(tt.command, tt.args, tt.targets); (err == nil) != tt.ok {
+			t.Errorf("checkCommandArgs(%s, %+v): err = %v", tt.command, tt.args, err)
+		}
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	got := shellJoin([]string{"darwin-rebuild", "--flake", "/Users/me/My Repo#mac", "it's", ""})
+	want := `darwin-rebuild --flake '/Users/me/My Repo#mac' 'it'\''s' ''`
+	if got != want {
+		t.Errorf("shellJoin = %s, want %s", got, want)
+	}
+}
+
+func TestCombineStatusChecks(t *testing.T) {
+	ok := func(msg string) protocol.StatusCheck { return protocol.StatusCheck{Status: "ok", Message: msg} }
+
+	c := combineStatusChecks(ok("nix-darwin system is current"), ok("Home Manager is current"))
+	if c.Status != "ok" || c.Message != "nix-darwin system is current; Home Manager is current" {
+		t.Errorf("both ok: %+v", c)
+	}
+
+	c = combineStatusChecks(
+		protocol.StatusCheck{Status: "unknown", Message: "No nix-darwin system found"},
+		protocol.StatusCheck{Status: "outdated", Message: "Home Manager needs switch"},
+	)
+	if c.Status != "outdated" || c.Message != "No nix-darwin system found; Home Manager needs switch" {
+		t.Errorf("mixed: %+v", c)
+	}
+
+	c = combineStatusChecks(ok("nix-darwin system is current"), protocol.StatusCheck{Status: "outdated", Message: "Home Manager needs switch"})
+	if c.Status != "outdated" || c.Message != "Home Manager needs switch" {
+		t.Errorf("one outdated: %+v", c)
+	}
+}
+
+func TestTargetSystemStatus(t *testing.T) {
+	a := &Agent{cfg: &config.Config{Hostname: "ws1", Targets: []config.Target{
+		{Name: "system", Type: config.TargetNixOS},
+		{Name: "alice", Type: config.TargetHomeManager, User: "alice"},
+	}}}
+	s := NewStatusChecker(a)
+
+	s.SetSystemOk("Switch successful (exit 0)")
+	s.SetTargetSystem("alice", "working", "Switch in progress...")
+	if got := s.GetSystemStatus(); got.Status != "working" || got.Message != "alice: Switch in progress..." {
+		t.Errorf("working: %+v", got)
+	}
+
+	s.SetTargetSystem("alice", "error", "Switch failed (exit 1)")
+	got := s.GetStatus(context.Background())
+	if got.System.Status != "error" || got.System.Message != "alice: Switch failed (exit 1)" {
+		t.Errorf("system = %+v", got.System)
+	}
+	if len(got.Targets) != 2 || got.Targets[0].System.Status != "ok" || got.Targets[1].Name != "alice" || got.Targets[1].User != "alice" {
+		t.Errorf("targets = %+v", got.Targets)
+	}
+}
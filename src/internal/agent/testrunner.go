@@ -0,0 +1,400 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+const (
+	// maxTestOutputBytes is how much output (the tail) is kept per script in the report.
+	// The full output is still streamed live.
+	maxTestOutputBytes = 16 * 1024
+
+	// testKillGrace bounds how long a timed-out script's leftover children
+	// may keep its output pipes open.
+	testKillGrace = 5 * time.Second
+
+	// testExitSkip is the automake convention for "test skipped".
+	testExitSkip = 77
+)
+
+// errTestsStopped aborts the remaining scripts after a STOP from the dashboard.
+var errTestsStopped = errors.New("stopped")
+
+// handleTests runs every executable hosts/<host>/tests/*.sh on its own, with a
+// per-script timeout, streams test_progress and sends a JUnit-style report.
+func (a *Agent) handleTests() {
+	command := "test"
+	cfg := a.currentConfig()
+	suite := filepath.Join("hosts", cfg.Hostname, "tests")
+
+	scripts, err := findTestScripts(filepath.Join(cfg.RepoDir, suite))
+	if err != nil {
+		a.sendOperationProgress("tests", "error", 0, 8)
+		a.statusChecker.SetTestsError("Cannot read test directory")
+		a.sendStatus("error", command, 1, err.Error())
+		return
+	}
+	if len(scripts) == 0 {
+		a.sendOutput(fmt.Sprintf("ℹ️ No executable test scripts in %s/", suite), "stdout")
+		a.sendOperationProgress("tests", "complete", 0, 0)
+		a.statusChecker.SetTestsUnknown("No test scripts in " + suite)
+		a.sendStatus("ok", command, 0, "no tests")
+		return
+	}
+
+	a.sendOutput(fmt.Sprintf("🧪 Running %d test script(s) from %s/ (timeout %s each)", len(scripts), suite, cfg.TestTimeout), "stdout")
+
+	report := protocol.TestReportPayload{
+		Suite:     suite,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Tests:     len(scripts),
+		Cases:     make([]protocol.TestCase, 0, len(scripts)),
+	}
+	results := make([]string, len(scripts))
+	for i := range results {
+		results[i] = "pending"
+	}
+	passed, failed := 0, 0
+	start := time.Now()
+	stopped := false
+
+	for i, script := range scripts {
+		name := filepath.Base(script)
+		a.sendTestProgress(protocol.TestProgressPayload{
+			Current: i + 1, Total: len(scripts), Passed: passed + report.Skipped, Failed: failed, Running: true, Name: name,
+		})
+		a.sendTestsPhase(i, results, "in_progress")
+		a.sendOutput("", "stdout")
+		a.sendOutput("▶ "+name, "stdout")
+
+		tc, err := a.runTestScript(script, cfg.RepoDir, cfg.TestTimeout)
+		if errors.Is(err, errTestsStopped) {
+			stopped = true
+			tc.Status = "error"
+			tc.Message = "stopped"
+		}
+		report.Cases = append(report.Cases, tc)
+
+		switch tc.Status {
+		case "pass":
+			passed++
+			results[i] = "pass"
+			a.sendOutput(fmt.Sprintf("✅ %s (%.1fs)", name, tc.Duration), "stdout")
+		case "skipped":
+			report.Skipped++
+			results[i] = "pass"
+			a.sendOutput(fmt.Sprintf("⏭️ %s skipped", name), "stdout")
+		default:
+			failed++
+			results[i] = "fail"
+			if tc.Status == "error" {
+				report.Errors++
+			} else {
+				report.Failures++
+			}
+			a.sendOutput(fmt.Sprintf("❌ %s: %s (%.1fs)", name, tc.Message, tc.Duration), "stderr")
+		}
+
+		if stopped {
+			a.sendOutput("⏹️ Test run stopped, remaining scripts not run", "stderr")
+			break
+		}
+	}
+	report.Duration = time.Since(start).Seconds()
+
+	summary := fmt.Sprintf("%d/%d", passed+report.Skipped, len(scripts))
+	a.sendTestProgress(protocol.TestProgressPayload{
+		Current: len(report.Cases), Total: len(scripts), Passed: passed + report.Skipped, Failed: failed, Result: summary,
+	})
+	if err := a.ws.SendMessage(protocol.TypeTestReport, report); err != nil {
+		a.log.Error().Err(err).Msg("failed to send test report")
+	}
+
+	// Push the new Tests compartment state right away
+	defer a.sendHeartbeat()
+
+	a.sendOutput("", "stdout")
+	if failed == 0 && !stopped {
+		a.sendTestsPhase(len(scripts), results, "complete")
+		a.sendOutput(fmt.Sprintf("✅ All tests passed (%s)", summary), "stdout")
+		a.statusChecker.SetTestsOk(fmt.Sprintf("%s tests passed", summary))
+		a.sendStatus("ok", command, 0, summary+" passed")
+		return
+	}
+
+	a.sendTestsPhase(len(report.Cases), results, "error")
+	var names []string
+	for _, tc := range report.Cases {
+		if tc.Status == "fail" || tc.Status == "error" {
+			names = append(names, tc.Name)
+		}
+	}
+	msg := fmt.Sprintf("%d of %d tests failed: %s", failed, len(scripts), strings.Join(names, ", "))
+	a.sendOutput("❌ "+msg, "stderr")
+	a.statusChecker.SetTestsError(msg)
+	a.sendStatus("error", command, 1, msg)
+}
+
+// runTestScript runs one script and turns its exit code and TAP output into a test case.
+// Returns errTestsStopped if the script was killed from outside (STOP).
+func (a *Agent) runTestScript(script, dir string, timeout time.Duration) (protocol.TestCase, error) {
+	tc := protocol.TestCase{Name: filepath.Base(script)}
+
+	ctx, cancel := context.WithTimeout(a.ctx, timeout)
+	defer cancel()
+
+	cmd := buildTestScriptCommand(ctx, script)
+	cmd.Dir = dir
+	// Own process group so a timeout (and STOP) takes the script's children too
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) }
+	cmd.WaitDelay = testKillGrace
+
+	var out testOutput
+	started := time.Now()
+	tc.ExitCode = a.runWithLineHook(cmd, out.add)
+	tc.Duration = time.Since(started).Seconds()
+	tc.Output = out.String()
+	tc.TAP = out.tap.results
+
+	switch {
+	case cmd.ProcessState == nil:
+		tc.Status = "error"
+		tc.Message = "could not start script"
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		tc.Status = "error"
+		tc.Message = fmt.Sprintf("timed out after %s", timeout)
+	case killedBySignal(cmd.ProcessState):
+		tc.Status = "error"
+		tc.Message = "killed"
+		return tc, errTestsStopped
+	default:
+		tc.Status, tc.Message = testVerdict(tc.ExitCode, &out.tap)
+	}
+	return tc, nil
+}
+
+// buildTestScriptCommand runs a test script directly. On macOS the Nix
+// profile is sourced first so tests find Nix-installed tools (P5999).
+func buildTestScriptCommand(ctx context.Context, script string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		// IMPORTANT: `/bin/sh` on macOS can exit immediately when `.` fails to open the file,
+		// even when wrapped in `|| true`. So guard the source with a file check.
+		return exec.CommandContext(ctx, "sh", "-c",
+			`if [ -f "$HOME/.nix-profile/etc/profile.d/nix.sh" ]; then . "$HOME/.nix-profile/etc/profile.d/nix.sh"; fi; exec "$0"`,
+			script)
+	}
+	return exec.CommandContext(ctx, script)
+}
+
+func killedBySignal(ps *os.ProcessState) bool {
+	ws, ok := ps.Sys().(syscall.WaitStatus)
+	return ok && ws.Signaled()
+}
+
+// testVerdict decides pass/fail/skipped from the exit code and any TAP output.
+func testVerdict(exitCode int, tap *tapParser) (status, message string) {
+	if exitCode == testExitSkip || tap.skipAll {
+		return "skipped", ""
+	}
+	if tap.bailOut != "" {
+		return "fail", "bail out: " + tap.bailOut
+	}
+	if exitCode != 0 {
+		if f := tap.firstFailure(); f != "" {
+			return "fail", fmt.Sprintf("exit code %d (not ok %s)", exitCode, f)
+		}
+		return "fail", fmt.Sprintf("exit code %d", exitCode)
+	}
+	if n := tap.failures(); n > 0 {
+		return "fail", fmt.Sprintf("%d of %d TAP assertions failed (first: %s)", n, len(tap.results), tap.firstFailure())
+	}
+	if tap.plan > 0 && len(tap.results) != tap.plan {
+		return "fail", fmt.Sprintf("planned %d TAP assertions, ran %d", tap.plan, len(tap.results))
+	}
+	return "pass", ""
+}
+
+// findTestScripts returns the executable *.sh files in dir, sorted by name.
+// A missing directory means "no tests", not an error.
+func findTestScripts(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var scripts []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sh") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.Mode()&0o111 == 0 {
+			continue
+		}
+		scripts = append(scripts, filepath.Join(dir, e.Name()))
+	}
+	sort.Strings(scripts)
+	return scripts, nil
+}
+
+// sendTestProgress sends a test_progress message (counts and current script).
+func (a *Agent) sendTestProgress(p protocol.TestProgressPayload) {
+	if err := a.ws.SendMessage(protocol.TypeTestProgress, p); err != nil {
+		a.log.Debug().Err(err).Msg("failed to send test progress")
+	}
+}
+
+// sendTestsPhase sends the tests phase of operation_progress with one
+// result per script, so the status dots show which script failed.
+func (a *Agent) sendTestsPhase(current int, results []string, status string) {
+	payload := protocol.OperationProgressPayload{
+		CommandID: a.currentCommandID(),
+		Progress: protocol.OperationProgress{
+			Tests: &protocol.TestsProgress{
+				Current: current,
+				Total:   len(results),
+				Results: append([]string(nil), results...),
+				Status:  status,
+			},
+		},
+	}
+	if err := a.ws.SendMessage(protocol.TypeOperationProgress, payload); err != nil {
+		a.log.Debug().Err(err).Msg("failed to send operation progress")
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// OUTPUT CAPTURE + TAP
+// ═══════════════════════════════════════════════════════════════════════════
+
+// testOutput collects a script's output (stdout and stderr interleaved, as
+// streamed) and feeds it to the TAP parser. Keeps only the tail.
+type testOutput struct {
+	mu      sync.Mutex
+	lines   []string
+	size    int
+	dropped bool
+	tap     tapParser
+}
+
+func (o *testOutput) add(line string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.tap.feed(line)
+	o.lines = append(o.lines, line)
+	o.size += len(line) + 1
+	for o.size > maxTestOutputBytes && len(o.lines) > 1 {
+		o.size -= len(o.lines[0]) + 1
+		o.lines = o.lines[1:]
+		o.dropped = true
+	}
+}
+
+func (o *testOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := strings.Join(o.lines, "\n")
+	if o.dropped {
+		out = "[... earlier output truncated ...]\n" + out
+	}
+	return out
+}
+
+var (
+	// "ok 1 - description # SKIP reason" / "not ok 2 description # TODO"
+	tapResultLine = regexp.MustCompile(`^(not )?ok\b(?:\s+(\d+))?(?:\s*-)?\s*(.*)$`)
+	tapDirective  = regexp.MustCompile(`(?i)\s*#\s*(skip|todo)\b\S*\s*(.*)$`)
+	tapPlanLine   = regexp.MustCompile(`^1\.\.(\d+)(?:\s*#\s*(?i:skip)\b.*)?$`)
+)
+
+// tapParser understands the subset of TAP that matters for a verdict:
+// plan, test lines with SKIP/TODO directives and "Bail out!".
+// Indented lines (subtests) and anything else are ignored.
+type tapParser struct {
+	results []protocol.TAPResult
+	plan    int
+	skipAll bool
+	bailOut string
+}
+
+func (p *tapParser) feed(line string) {
+	if strings.HasPrefix(line, "Bail out!") {
+		p.bailOut = strings.TrimSpace(strings.TrimPrefix(line, "Bail out!"))
+		if p.bailOut == "" {
+			p.bailOut = "no reason given"
+		}
+		return
+	}
+	if m := tapPlanLine.FindStringSubmatch(line); m != nil {
+		p.plan, _ = strconv.Atoi(m[1])
+		p.skipAll = p.plan == 0
+		return
+	}
+	m := tapResultLine.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+
+	r := protocol.TAPResult{Status: "pass", Description: strings.TrimSpace(m[3])}
+	if m[1] != "" {
+		r.Status = "fail"
+	}
+	if d := tapDirective.FindStringSubmatchIndex(r.Description); d != nil {
+		kind := strings.ToLower(r.Description[d[2]:d[3]])
+		r.Directive = strings.TrimSpace(r.Description[d[4]:d[5]])
+		r.Description = strings.TrimSpace(r.Description[:d[0]])
+		// TODO failures are expected and don't fail the script
+		if kind == "skip" {
+			r.Status = "skip"
+		} else {
+			r.Status = "todo"
+		}
+	}
+	if m[2] != "" {
+		r.Number, _ = strconv.Atoi(m[2])
+	} else {
+		r.Number = len(p.results) + 1
+	}
+	p.results = append(p.results, r)
+}
+
+func (p *tapParser) failures() int {
+	n := 0
+	for _, r := range p.results {
+		if r.Status == "fail" {
+			n++
+		}
+	}
+	return n
+}
+
+// firstFailure describes the first failed assertion, e.g. `3 - nginx answers`.
+func (p *tapParser) firstFailure() string {
+	for _, r := range p.results {
+		if r.Status == "fail" {
+			if r.Description == "" {
+				return strconv.Itoa(r.Number)
+			}
+			return fmt.Sprintf("%d - %s", r.Number, r.Description)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/config"
+	"github.com/rs/zerolog"
+)
+
+func TestTAPParser(t *testing.T) {
+	var p tapParser
+	for _, line := range strings.Split(`TAP version 13
+1..5
+ok 1 - nginx is running
+not ok 2 - port 443 answers
+ok 3 # SKIP no ipv6 here
+not ok 4 flaky thing # TODO fix upstream
+ok - unnumbered
+    ok 1 - indented subtest is ignored
+# diagnostics are ignored`, "\n") {
+		p.feed(line)
+	}
+
+	if p.plan != 5 || len(p.results) != 5 {
+		t.Fatalf("plan=%d results=%d, want 5/5", p.plan, len(p.results))
+	}
+	want := []struct {
+		number int
+		status string
+		desc   string
+	}{
+		{1, "pass", "nginx is running"},
+		{2, "fail", "port 443 answers"},
+		{3, "skip", ""},
+		{4, "todo", "flaky thing"},
+		{5, "pass", "unnumbered"},
+	}
+	for i, w := range want {
+		r := p.results[i]
+		if r.Number != w.number || r.Status != w.status || r.Description != w.desc {
+			t.Errorf("result %d = %+v, want %+v", i, r, w)
+		}
+	}
+	if p.results[2].Directive != "no ipv6 here" {
+		t.Errorf("directive = %q", p.results[2].Directive)
+	}
+	if p.failures() != 1 || p.firstFailure() != "2 - port 443 answers" {
+		t.Errorf("failures=%d first=%q", p.failures(), p.firstFailure())
+	}
+}
+
+func TestTestVerdict(t *testing.T) {
+	tap := func(lines ...string) *tapParser {
+		var p tapParser
+		for _, l := range lines {
+			p.feed(l)
+		}
+		return &p
+	}
+
+	tests := []struct {
+		name     string
+		exitCode int
+		tap      *tapParser
+		want     string
+	}{
+		{"plain pass", 0, tap(), "pass"},
+		{"plain fail", 1, tap(), "fail"},
+		{"automake skip", 77, tap(), "skipped"},
+		{"tap skip all", 0, tap("1..0 # SKIP not on this host"), "skipped"},
+		{"tap failure with exit 0", 0, tap("1..2", "ok 1", "not ok 2"), "fail"},
+		{"tap todo failure", 0, tap("1..1", "not ok 1 # TODO later"), "pass"},
+		{"tap plan mismatch", 0, tap("1..3", "ok 1", "ok 2"), "fail"},
+		{"bail out", 0, tap("1..2", "ok 1", "Bail out! database down"), "fail"},
+	}
+	for _, tt := range tests {
+		if got, _ := testVerdict(tt.exitCode, tt.tap); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunTestScript(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, body string, mode os.FileMode) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("#!/bin/sh\n"+body), mode); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	pass := write("01-pass.sh", "echo 1..1\necho ok 1 - fine\n", 0o755)
+	fail := write("02-fail.sh", "echo 'not ok 1 - broken'\nexit 3\n", 0o755)
+	slow := write("03-slow.sh", "sleep 30\n", 0o755)
+	write("04-not-executable.sh", "exit 1\n", 0o644)
+	write("README.md", "", 0o755)
+
+	scripts, err := findTestScripts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scripts) != 3 || scripts[0] != pass || scripts[2] != slow {
+		t.Fatalf("findTestScripts = %v", scripts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := &config.Config{Hostname: "testhost"}
+	a := &Agent{cfg: cfg, log: zerolog.Nop(), ctx: ctx, cancel: cancel}
+	a.ws = NewWebSocketClient(cfg, a.log, a) // never connected: output is dropped
+
+	tc, err := a.runTestScript(pass, dir, 10*time.Second)
+	if err != nil || tc.Status != "pass" || len(tc.TAP) != 1 {
+		t.Errorf("pass script: %+v, %v", tc, err)
+	}
+
+	tc, _ = a.runTestScript(fail, dir, 10*time.Second)
+	if tc.Status != "fail" || tc.ExitCode != 3 || !strings.Contains(tc.Message, "broken") {
+		t.Errorf("fail script: %+v", tc)
+	}
+	if !strings.Contains(tc.Output, "not ok 1 - broken") {
+		t.Errorf("output not captured: %q", tc.Output)
+	}
+
+	start := time.Now()
+	tc, err = a.runTestScript(slow, dir, 200*time.Millisecond)
+	if err != nil || tc.Status != "error" || !strings.Contains(tc.Message, "timed out") {
+		t.Errorf("slow script: %+v, %v", tc, err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("timeout took %s", time.Since(start))
+	}
+}
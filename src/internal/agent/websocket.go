@@ -3,6 +3,8 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"sync"
 	"time"
@@ -29,6 +31,12 @@ type WebSocketClient struct {
 	mu       sync.Mutex
 	messages chan *protocol.Message
 
+	// Dashboard endpoints in failover order, and the one in use (guarded by
+	// mu, replaced on config reload)
+	urls   []string
+	active int
+	token  string
+
 	// Reconnection
 	connected bool
 	backoff   time.Duration
@@ -48,6 +56,8 @@ const (
 func NewWebSocketClient(cfg *config.Config, log zerolog.Logger, handler ConnectionHandler) *WebSocketClient {
 	return &WebSocketClient{
 		cfg:      cfg,
+		urls:     cfg.DashboardURLs(),
+		token:    cfg.Token,
 		log:      log.With().Str("component", "websocket").Logger(),
 		handler:  handler,
 		messages: make(chan *protocol.Message, 100),
@@ -83,32 +93,42 @@ func (c *WebSocketClient) Run(ctx context.Context) {
 	}
 }
 
-// connect establishes the WebSocket connection.
+// connect establishes the WebSocket connection to the first dashboard
+// endpoint that answers, in configured order.
 func (c *WebSocketClient) connect(ctx context.Context) error {
-	c.log.Debug().Str("url", c.cfg.DashboardURL).Msg("connecting")
-
-	// Create request with auth header
-	header := http.Header{}
-	header.Set("Authorization", "Bearer "+c.cfg.Token)
-
-	// Connect with context
-	dialer := websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
-	}
+	c.mu.Lock()
+	urls, token := c.urls, c.token
+	c.mu.Unlock()
 
-	conn, resp, err := dialer.DialContext(ctx, c.cfg.DashboardURL, header)
-	if err != nil {
-		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
-			c.log.Error().Msg("authentication failed: 401 Unauthorized")
+	var conn *websocket.Conn
+	var active int
+	var errs []error
+	for i, url := range urls {
+		var err error
+		if conn, err = c.dial(ctx, url, token); err == nil {
+			active = i
+			break
 		}
-		return err
+		if len(urls) > 1 {
+			c.log.Warn().Err(err).Str("url", url).Msg("dashboard unreachable, trying next")
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", url, err))
+	}
+	if conn == nil {
+		return errors.Join(errs...)
 	}
 
 	c.mu.Lock()
 	c.conn = conn
+	c.active = active
 	c.connected = true
 	c.mu.Unlock()
 
+	if active > 0 {
+		c.log.Warn().Str("url", urls[active]).Msg("connected to fallback dashboard")
+		go c.failbackLoop(ctx, conn, active)
+	}
+
 	// Configure connection
 	if err := conn.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
 		c.log.Debug().Err(err).Msg("failed to set read deadline")
@@ -131,6 +151,72 @@ func (c *WebSocketClient) connect(ctx context.Context) error {
 	return nil
 }
 
+// dial opens a connection to one dashboard endpoint. Proxies come from
+// HTTPS_PROXY/HTTP_PROXY/NO_PROXY.
+func (c *WebSocketClient) dial(ctx context.Context, url, token string) (*websocket.Conn, error) {
+	c.log.Debug().Str("url", url).Msg("connecting")
+
+	// Create request with auth header
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+
+	// Build logs compress well: negotiate permessage-deflate
+	dialer := websocket.Dialer{
+		Proxy:             http.ProxyFromEnvironment,
+		HandshakeTimeout:  10 * time.Second,
+		EnableCompression: true,
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, url, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			c.log.Error().Str("url", url).Msg("authentication failed: 401 Unauthorized")
+		}
+		return nil, err
+	}
+	return conn, nil
+}
+
+// failbackLoop runs while connected to a fallback endpoint: it probes the
+// endpoints configured before it, spaced like reconnects, and reconnects
+// once one of them answers so the agent returns to the preferred dashboard.
+func (c *WebSocketClient) failbackLoop(ctx context.Context, conn *websocket.Conn, active int) {
+	backoff := initialBackoff
+	for {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxBackoff)
+
+		c.mu.Lock()
+		current, urls, token := c.conn, c.urls, c.token
+		c.mu.Unlock()
+		if current != conn {
+			return // disconnected or replaced
+		}
+
+		for _, url := range urls[:min(active, len(urls))] {
+			probe, err := c.dial(ctx, url, token)
+			if err != nil {
+				continue
+			}
+			_ = probe.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, "probe"), time.Now().Add(writeWait))
+			_ = probe.Close()
+
+			c.log.Info().Str("url", url).Msg("preferred dashboard reachable again, failing back")
+			if err := c.Reconnect(); err != nil {
+				c.log.Debug().Err(err).Msg("error closing websocket for failback")
+			}
+			return
+		}
+	}
+}
+
 // readLoop reads messages from the WebSocket.
 func (c *WebSocketClient) readLoop(ctx context.Context) {
 	defer func() {
@@ -261,8 +347,39 @@ func (c *WebSocketClient) Messages() <-chan *protocol.Message {
 	return c.messages
 }
 
+// SetEndpoints changes the dashboard URLs (in failover order) and token
+// used for the next connection.
+func (c *WebSocketClient) SetEndpoints(urls []string, token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.urls = urls
+	c.active = 0
+	c.token = token
+}
+
+// ActiveURL returns the dashboard endpoint of the current connection, or
+// the one tried first while disconnected.
+func (c *WebSocketClient) ActiveURL() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.connected || c.active >= len(c.urls) {
+		return c.urls[0]
+	}
+	return c.urls[c.active]
+}
+
+// Reconnect closes the current connection; Run then connects again,
+// picking up a changed endpoint.
+func (c *WebSocketClient) Reconnect() error {
+	return c.closeWithReason("reconnect")
+}
+
 // Close closes the connection gracefully.
 func (c *WebSocketClient) Close() error {
+	return c.closeWithReason("shutdown")
+}
+
+func (c *WebSocketClient) closeWithReason(reason string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -274,7 +391,7 @@ func (c *WebSocketClient) Close() error {
 	deadline := time.Now().Add(closeGracePeriod)
 	err := c.conn.WriteControl(
 		websocket.CloseMessage,
-		websocket.FormatCloseMessage(websocket.CloseNormalClosure, "shutdown"),
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason),
 		deadline,
 	)
 	if err != nil {
@@ -1,8 +1,10 @@
-// Package config handles agent configuration from environment variables.
+// Package config handles agent configuration from environment variables
+// and an optional config file.
 package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,13 +12,16 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
 )
 
 // Config holds all agent configuration.
 type Config struct {
 	// Connection
-	DashboardURL string // WebSocket URL (ws:// or wss://)
-	Token        string // Agent authentication token
+	DashboardURL string   // WebSocket URL (ws:// or wss://)
+	FallbackURLs []string // further dashboards, tried in order when DashboardURL is unreachable
+	Token        string   // Agent authentication token
 
 	// Repository
 	RepoURL string // Git repository URL (for isolated mode)
@@ -36,6 +41,30 @@ type Config struct {
 	ThemeColor     string // Host theme color (hex like #7aa2f7)
 	Location       string // Location: home, work, cloud
 	DeviceType     string // Device type: server, desktop, laptop, gaming
+
+	// Garbage collection
+	GCDeleteOlderThan string // --delete-older-than period for gc (e.g. "14d"), empty = dead paths only
+
+	// Tests
+	TestTimeout time.Duration // per-script timeout for hosts/<host>/tests/*.sh
+
+	// macOS: what switch/rollback/System status manage (see DarwinMode*)
+	DarwinMode string
+
+	// Deploy targets (system, per-user home-manager), empty = platform default (see DeployTargets)
+	Targets []Target
+
+	// Commands the exec op may run, by name (see LoadExecCommands), empty = exec disabled
+	ExecCommands map[string]protocol.ExecCommand
+
+	// Units the service-restart/-stop/-start ops may control, empty = service ops disabled
+	ServiceUnits []string
+
+	// Local control socket for the CLI (status, last-output, run), empty = disabled
+	ControlSocket string
+
+	// Config file the values were merged from (empty = environment only)
+	ConfigFile string
 }
 
 // DefaultConfig returns a config with default values.
@@ -44,7 +73,10 @@ func DefaultConfig() *Config {
 		Branch:            "main",
 		HeartbeatInterval: 5 * time.Second, // Match Nix module default (PRD FR-1.2)
 		LogLevel:          "info",
+		TestTimeout:       5 * time.Minute,
+		DarwinMode:        DarwinModeHomeManager,
 		Hostname:          getStableHostname(),
+		ControlSocket:     DefaultControlSocket(),
 	}
 }
 
@@ -69,29 +101,42 @@ func getStableHostname() string {
 	return hostname
 }
 
-// LoadFromEnv loads configuration from environment variables.
+// LoadFromEnv loads configuration from environment variables, merged with
+// the config file named by NIXFLEET_CONFIG if set.
 func LoadFromEnv() (*Config, error) {
+	return Load(os.Getenv("NIXFLEET_CONFIG"))
+}
+
+// Load loads configuration from environment variables and, if path is not
+// empty, the config file at path. Values from the file take precedence.
+func Load(path string) (*Config, error) {
 	cfg := DefaultConfig()
 
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		fc, err := ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := fc.apply(cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		cfg.ConfigFile = path
+	}
+
 	// Required
-	cfg.DashboardURL = os.Getenv("NIXFLEET_URL")
 	if cfg.DashboardURL == "" {
-		return nil, errors.New("NIXFLEET_URL is required")
+		return nil, errors.New("NIXFLEET_URL (or url in the config file) is required")
 	}
-
-	cfg.Token = os.Getenv("NIXFLEET_TOKEN")
 	if cfg.Token == "" {
-		return nil, errors.New("NIXFLEET_TOKEN is required")
+		return nil, errors.New("NIXFLEET_TOKEN (or token/token_file in the config file) is required")
 	}
 
 	// Repository configuration
 	// Priority: REPO_DIR > NIXCFG (legacy) > default isolated path (if REPO_URL set)
-	cfg.RepoURL = os.Getenv("NIXFLEET_REPO_URL")
-	cfg.RepoDir = os.Getenv("NIXFLEET_REPO_DIR")
-	if cfg.RepoDir == "" {
-		// Check legacy variable
-		cfg.RepoDir = os.Getenv("NIXFLEET_NIXCFG")
-	}
 	if cfg.RepoDir == "" {
 		if cfg.RepoURL != "" {
 			// Isolated mode: use platform-specific default path
@@ -101,41 +146,142 @@ func LoadFromEnv() (*Config, error) {
 		}
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv reads the NIXFLEET_* environment variables into c.
+func (c *Config) applyEnv() error {
+	if err := c.setDashboardURLs(os.Getenv("NIXFLEET_URL")); err != nil {
+		return fmt.Errorf("NIXFLEET_URL: %w", err)
+	}
+	c.Token = os.Getenv("NIXFLEET_TOKEN")
+
+	c.RepoURL = os.Getenv("NIXFLEET_REPO_URL")
+	c.RepoDir = os.Getenv("NIXFLEET_REPO_DIR")
+	if c.RepoDir == "" {
+		// Check legacy variable
+		c.RepoDir = os.Getenv("NIXFLEET_NIXCFG")
+	}
+
 	// Optional
 	if branch := os.Getenv("NIXFLEET_BRANCH"); branch != "" {
-		cfg.Branch = branch
+		c.Branch = branch
 	}
 
-	cfg.SSHKey = os.Getenv("NIXFLEET_SSH_KEY")
+	c.SSHKey = os.Getenv("NIXFLEET_SSH_KEY")
 
 	if interval := os.Getenv("NIXFLEET_INTERVAL"); interval != "" {
 		seconds, err := strconv.Atoi(interval)
 		if err != nil {
-			return nil, errors.New("NIXFLEET_INTERVAL must be a number (seconds)")
+			return errors.New("NIXFLEET_INTERVAL must be a number (seconds)")
 		}
-		cfg.HeartbeatInterval = time.Duration(seconds) * time.Second
+		c.HeartbeatInterval = time.Duration(seconds) * time.Second
 	}
 
 	if level := os.Getenv("NIXFLEET_LOG_LEVEL"); level != "" {
-		cfg.LogLevel = level
+		c.LogLevel = level
 	}
 
 	// Override hostname if specified
 	if hostname := os.Getenv("NIXFLEET_HOSTNAME"); hostname != "" {
-		cfg.Hostname = hostname
+		c.Hostname = hostname
 	}
 
 	// Override nixpkgs version if specified (for macOS/Home Manager)
-	cfg.NixpkgsVersion = os.Getenv("NIXFLEET_NIXPKGS_VERSION")
+	c.NixpkgsVersion = os.Getenv("NIXFLEET_NIXPKGS_VERSION")
 
 	// Theme color for dashboard
-	cfg.ThemeColor = os.Getenv("NIXFLEET_THEME_COLOR")
+	c.ThemeColor = os.Getenv("NIXFLEET_THEME_COLOR")
 
 	// Location and device type (with defaults)
-	cfg.Location = getEnvOrDefault("NIXFLEET_LOCATION", "home")
-	cfg.DeviceType = getEnvOrDefault("NIXFLEET_DEVICE_TYPE", "desktop")
+	c.Location = getEnvOrDefault("NIXFLEET_LOCATION", "home")
+	c.DeviceType = getEnvOrDefault("NIXFLEET_DEVICE_TYPE", "desktop")
 
-	return cfg, nil
+	// Garbage collection retention (optional)
+	c.GCDeleteOlderThan = os.Getenv("NIXFLEET_GC_OLDER_THAN")
+
+	if timeout := os.Getenv("NIXFLEET_TEST_TIMEOUT"); timeout != "" {
+		seconds, err := strconv.Atoi(timeout)
+		if err != nil {
+			return errors.New("NIXFLEET_TEST_TIMEOUT must be a number (seconds)")
+		}
+		c.TestTimeout = time.Duration(seconds) * time.Second
+	}
+
+	// Control socket ("off" disables it)
+	c.ControlSocket = ControlSocketFromEnv()
+
+	c.DarwinMode = getEnvOrDefault("NIXFLEET_DARWIN_MODE", DarwinModeHomeManager)
+
+	if targets := os.Getenv("NIXFLEET_TARGETS"); targets != "" {
+		parsed, err := ParseTargets(targets)
+		if err != nil {
+			return fmt.Errorf("NIXFLEET_TARGETS: %w", err)
+		}
+		c.Targets = parsed
+	}
+
+	if file := os.Getenv("NIXFLEET_EXEC_COMMANDS"); file != "" {
+		commands, err := LoadExecCommands(file)
+		if err != nil {
+			return fmt.Errorf("NIXFLEET_EXEC_COMMANDS: %w", err)
+		}
+		c.ExecCommands = commands
+	}
+
+	units, err := ParseServiceUnits(os.Getenv("NIXFLEET_SERVICE_UNITS"))
+	if err != nil {
+		return fmt.Errorf("NIXFLEET_SERVICE_UNITS: %w", err)
+	}
+	c.ServiceUnits = units
+
+	return nil
+}
+
+// ParseServiceUnits parses a comma-separated list of unit names (systemd
+// units on Linux, launchd labels on macOS), e.g. "nginx.service,app.service".
+func ParseServiceUnits(list string) ([]string, error) {
+	var units []string
+	for _, unit := range strings.Split(list, ",") {
+		unit = strings.TrimSpace(unit)
+		if unit == "" {
+			continue
+		}
+		if !protocol.ValidUnitName(unit) {
+			return nil, fmt.Errorf("invalid unit name %q", unit)
+		}
+		units = append(units, unit)
+	}
+	return units, nil
+}
+
+// setDashboardURLs sets DashboardURL and FallbackURLs from a comma-separated
+// list, primary first.
+func (c *Config) setDashboardURLs(list string) error {
+	c.DashboardURL, c.FallbackURLs = "", nil
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+	for i, u := range strings.Split(list, ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			return errors.New("empty entry in URL list")
+		}
+		if i == 0 {
+			c.DashboardURL = u
+		} else {
+			c.FallbackURLs = append(c.FallbackURLs, u)
+		}
+	}
+	return nil
+}
+
+// DashboardURLs returns all dashboard endpoints in the order they are tried.
+func (c *Config) DashboardURLs() []string {
+	return append([]string{c.DashboardURL}, c.FallbackURLs...)
 }
 
 func getEnvOrDefault(key, defaultVal string) string {
@@ -157,6 +303,53 @@ func getDefaultRepoDir() string {
 	return "/var/lib/nixfleet-agent/repo"
 }
 
+// DefaultControlSocket returns the platform-specific control socket path.
+// These paths match what the Nix modules configure.
+func DefaultControlSocket() string {
+	if runtime.GOOS == "darwin" {
+		// macOS: next to the isolated repo (matches home-manager module)
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, ".local", "state", "nixfleet-agent", "agent.sock")
+	}
+	// NixOS: RuntimeDirectory of the systemd unit
+	return "/run/nixfleet-agent/agent.sock"
+}
+
+// ControlSocketFromEnv resolves NIXFLEET_CONTROL_SOCKET.
+// Shared by the agent and the CLI subcommands, which don't need a full config.
+func ControlSocketFromEnv() string {
+	return parseControlSocket(os.Getenv("NIXFLEET_CONTROL_SOCKET"))
+}
+
+func parseControlSocket(v string) string {
+	switch v {
+	case "":
+		return DefaultControlSocket()
+	case "off", "none":
+		return ""
+	default:
+		return v
+	}
+}
+
+// Darwin host modes: which configurations the agent switches on macOS.
+const (
+	DarwinModeHomeManager = "home-manager" // homeConfigurations.<host> (standalone)
+	DarwinModeNixDarwin   = "nix-darwin"   // darwinConfigurations.<host>
+	DarwinModeBoth        = "both"         // nix-darwin first, then home-manager
+)
+
+// UsesNixDarwin reports whether the host has a nix-darwin system to manage.
+func (c *Config) UsesNixDarwin() bool {
+	return c.DarwinMode == DarwinModeNixDarwin || c.DarwinMode == DarwinModeBoth
+}
+
+// UsesHomeManager reports whether the host has a standalone home-manager
+// configuration to manage.
+func (c *Config) UsesHomeManager() bool {
+	return c.DarwinMode == DarwinModeHomeManager || c.DarwinMode == DarwinModeBoth
+}
+
 // Validate checks that the configuration is valid.
 func (c *Config) Validate() error {
 	if c.DashboardURL == "" {
@@ -168,6 +361,18 @@ func (c *Config) Validate() error {
 	if c.HeartbeatInterval < time.Second {
 		return errors.New("heartbeat interval must be at least 1 second")
 	}
+	if c.TestTimeout < time.Second {
+		return errors.New("test timeout must be at least 1 second")
+	}
+	switch c.DarwinMode {
+	case DarwinModeHomeManager, DarwinModeNixDarwin, DarwinModeBoth:
+	default:
+		return fmt.Errorf("darwin mode must be %s, %s or %s (got %q)",
+			DarwinModeHomeManager, DarwinModeNixDarwin, DarwinModeBoth, c.DarwinMode)
+	}
+	if err := validateTargets(c.Targets); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// validExecName matches the names of allowlisted exec commands.
+var validExecName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// LoadExecCommands reads the exec allowlist (NIXFLEET_EXEC_COMMANDS, or
+// `exec_commands` in the config file): a JSON object of commands by name.
+//
+//	{
+//	  "journal": {"command": ["journalctl", "-u", "{unit}", "-n", "200"], "params": {"unit": "[a-z0-9@._-]+"}},
+//	  "verify-store": {"command": ["nix-store", "--verify"], "timeout": 1800}
+//	}
+func LoadExecCommands(path string) (map[string]protocol.ExecCommand, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var commands map[string]protocol.ExecCommand
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&commands); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !validExecName.MatchString(name) {
+			return nil, fmt.Errorf("%s: invalid command name %q", path, name)
+		}
+		if err := commands[name].Check(); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, name, err)
+		}
+	}
+	return commands, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadExecCommands(t *testing.T) {
+	commands, err := LoadExecCommands(writeFile(t, "exec.json", `{
+		"journal": {"command": ["journalctl", "-u", "{unit}", "-n", "{lines}"], "params": {"unit": "[a-z0-9@._-]+", "lines": "[0-9]{1,4}"}},
+		"verify-store": {"command": ["nix-store", "--verify"], "timeout": 1800}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 2 || commands["verify-store"].TimeoutDuration().Minutes() != 30 {
+		t.Fatalf("commands = %+v", commands)
+	}
+
+	journal := commands["journal"]
+	argv, err := journal.Expand(map[string]string{"unit": "nginx.service", "lines": "200"})
+	if err != nil || !slices.Equal(argv, []string{"journalctl", "-u", "nginx.service", "-n", "200"}) {
+		t.Errorf("Expand = %v, %v", argv, err)
+	}
+	for _, params := range []map[string]string{
+		{"unit": "nginx.service"},                            // lines missing
+		{"unit": "nginx.service", "lines": "200; reboot"},    // pattern is anchored
+		{"unit": "-nginx", "lines": "200"},                   // never an option
+		{"unit": "nginx", "lines": "200", "since": "1h ago"}, // not declared
+	} {
+		if argv, err := journal.Expand(params); err == nil {
+			t.Errorf("Expand(%v) = %v, want error", params, argv)
+		}
+	}
+
+	for content, want := range map[string]string{
+		`{"Bad Name": {"command": ["true"]}}`:                      "invalid command name",
+		`{"x": {"command": []}}`:                                   "command is empty",
+		`{"x": {"command": ["cat", "{file}"]}}`:                    "{file} has no pattern",
+		`{"x": {"command": ["{prog}"], "params": {"prog": ".*"}}}`: "program name",
+		`{"x": {"command": ["true"], "timeout": 7200}}`:            "timeout",
+		`{"x": {"command": ["true"], "params": {"p": "("}}}`:       "param p",
+		`{"x": {"command": ["true"], "sudo": true}}`:               "unknown field",
+	} {
+		if _, err := LoadExecCommands(writeFile(t, "exec.json", content)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadExecCommands(%s) = %v, want error containing %q", content, err, want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileConfig is the optional agent config file (NIXFLEET_CONFIG or --config).
+// Every key is optional; keys that are set override the environment, so
+// editing the file and sending SIGHUP takes effect even when the service
+// also sets the matching NIXFLEET_* variable.
+//
+// The file is JSON if it ends in .json, otherwise TOML. Only flat
+// `key = value` TOML is supported (strings, integers, booleans), which
+// covers every key below.
+type FileConfig struct {
+	URL            *string `json:"url"` // comma-separated: primary dashboard first, then fallbacks
+	Token          *string `json:"token"`
+	TokenFile      *string `json:"token_file"` // read token from this file (keeps it out of the environment)
+	RepoURL        *string `json:"repo_url"`
+	RepoDir        *string `json:"repo_dir"`
+	Branch         *string `json:"branch"`
+	SSHKey         *string `json:"ssh_key"`
+	Interval       *int    `json:"interval"` // heartbeat interval in seconds
+	LogLevel       *string `json:"log_level"`
+	Hostname       *string `json:"hostname"`
+	NixpkgsVersion *string `json:"nixpkgs_version"`
+	ThemeColor     *string `json:"theme_color"`
+	Location       *string `json:"location"`
+	DeviceType     *string `json:"device_type"`
+	GCOlderThan    *string `json:"gc_older_than"`
+	TestTimeout    *int    `json:"test_timeout"`   // per test script, in seconds
+	DarwinMode     *string `json:"darwin_mode"`    // "home-manager", "nix-darwin" or "both"
+	Targets        *string `json:"targets"`        // deploy targets, see ParseTargets
+	ControlSocket  *string `json:"control_socket"` // "off" disables it
+	ExecCommands   *string `json:"exec_commands"`  // JSON allowlist file for the exec op, see LoadExecCommands
+	ServiceUnits   *string `json:"service_units"`  // comma-separated units the service ops may control
+}
+
+// ReadFile parses a config file. Unknown keys are an error so typos
+// surface in `nixfleet-agent --check` instead of being silently ignored.
+func ReadFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// TOML is converted to JSON so both formats share the strict decoder
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		values, err := parseFlatTOML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if data, err = json.Marshal(values); err != nil {
+			return nil, err
+		}
+	}
+
+	var fc FileConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &fc, nil
+}
+
+// apply merges the file's values into cfg.
+func (fc *FileConfig) apply(cfg *Config) error {
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+
+	if fc.URL != nil {
+		if err := cfg.setDashboardURLs(*fc.URL); err != nil {
+			return fmt.Errorf("url: %w", err)
+		}
+	}
+	setString(&cfg.Token, fc.Token)
+	if fc.TokenFile != nil {
+		data, err := os.ReadFile(*fc.TokenFile)
+		if err != nil {
+			return fmt.Errorf("token_file: %w", err)
+		}
+		cfg.Token = strings.TrimSpace(string(data))
+	}
+	setString(&cfg.RepoURL, fc.RepoURL)
+	setString(&cfg.RepoDir, fc.RepoDir)
+	setString(&cfg.Branch, fc.Branch)
+	setString(&cfg.SSHKey, fc.SSHKey)
+	if fc.Interval != nil {
+		cfg.HeartbeatInterval = time.Duration(*fc.Interval) * time.Second
+	}
+	setString(&cfg.LogLevel, fc.LogLevel)
+	setString(&cfg.Hostname, fc.Hostname)
+	setString(&cfg.NixpkgsVersion, fc.NixpkgsVersion)
+	setString(&cfg.ThemeColor, fc.ThemeColor)
+	setString(&cfg.Location, fc.Location)
+	setString(&cfg.DeviceType, fc.DeviceType)
+	setString(&cfg.GCDeleteOlderThan, fc.GCOlderThan)
+	if fc.TestTimeout != nil {
+		cfg.TestTimeout = time.Duration(*fc.TestTimeout) * time.Second
+	}
+	setString(&cfg.DarwinMode, fc.DarwinMode)
+	if fc.Targets != nil {
+		targets, err := ParseTargets(*fc.Targets)
+		if err != nil {
+			return fmt.Errorf("targets: %w", err)
+		}
+		cfg.Targets = targets
+	}
+	if fc.ControlSocket != nil {
+		cfg.ControlSocket = parseControlSocket(*fc.ControlSocket)
+	}
+	if fc.ExecCommands != nil {
+		commands, err := LoadExecCommands(*fc.ExecCommands)
+		if err != nil {
+			return fmt.Errorf("exec_commands: %w", err)
+		}
+		cfg.ExecCommands = commands
+	}
+	if fc.ServiceUnits != nil {
+		units, err := ParseServiceUnits(*fc.ServiceUnits)
+		if err != nil {
+			return fmt.Errorf("service_units: %w", err)
+		}
+		cfg.ServiceUnits = units
+	}
+	return nil
+}
+
+// parseFlatTOML parses `key = value` lines with # comments.
+// Tables and arrays are rejected rather than misread.
+func parseFlatTOML(data []byte) (map[string]any, error) {
+	values := make(map[string]any)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported, use top-level keys", lineNo)
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: empty key", lineNo)
+		}
+		if _, dup := values[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNo, key)
+		}
+
+		value, err := parseTOMLValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", lineNo, key, err)
+		}
+		values[key] = value
+	}
+	return values, scanner.Err()
+}
+
+// parseTOMLValue parses a basic string, literal string, integer or boolean,
+// followed by an optional comment.
+func parseTOMLValue(raw string) (any, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		// Find the closing quote, skipping escaped ones
+		end := -1
+		for i := 1; i < len(raw); i++ {
+			if raw[i] == '\\' {
+				i++
+				continue
+			}
+			if raw[i] == '"' {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		if err := checkTrailing(raw[end+1:]); err != nil {
+			return nil, err
+		}
+		// TOML basic strings use the same escapes as Go for everything we accept
+		return strconv.Unquote(raw[:end+1])
+	case strings.HasPrefix(raw, "'"):
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		if err := checkTrailing(raw[end+2:]); err != nil {
+			return nil, err
+		}
+		return raw[1 : end+1], nil
+	}
+
+	value, _, _ := strings.Cut(raw, "#")
+	value = strings.TrimSpace(value)
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "":
+		return nil, fmt.Errorf("missing value")
+	}
+	if strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{") {
+		return nil, fmt.Errorf("arrays and inline tables are not supported")
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(value, "_", ""), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported value %q (strings must be quoted)", value)
+	}
+	return n, nil
+}
+
+func checkTrailing(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected %q after value", rest)
+	}
+	return nil
+}
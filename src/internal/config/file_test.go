@@ -0,0 +1,154 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseFlatTOML(t *testing.T) {
+	values, err := parseFlatTOML([]byte(`
+# comment
+url = "wss://fleet.example.com/ws"   # trailing comment
+ssh_key = 'C:\keys\id # not a comment'
+interval = 1_0
+enabled = true
+quoted = "a \"b\" #c"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"url":      "wss://fleet.example.com/ws",
+		"ssh_key":  `C:\keys\id # not a comment`,
+		"interval": int64(10),
+		"enabled":  true,
+		"quoted":   `a "b" #c`,
+	}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, values[k], v)
+		}
+	}
+
+	for _, bad := range []string{
+		"[agent]",
+		"location = home",
+		`url = "unterminated`,
+		"hosts = [1, 2]",
+		"a = 1\na = 2",
+		`url = "x" junk`,
+	} {
+		if _, err := parseFlatTOML([]byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestLoadMergesFileOverEnv(t *testing.T) {
+	t.Setenv("NIXFLEET_URL", "wss://env.example.com/ws")
+	t.Setenv("NIXFLEET_TOKEN", "env-token")
+	t.Setenv("NIXFLEET_REPO_DIR", "/srv/nixcfg")
+	t.Setenv("NIXFLEET_LOCATION", "home")
+	t.Setenv("NIXFLEET_INTERVAL", "5")
+
+	tokenFile := writeFile(t, "token", "file-token\n")
+	path := writeFile(t, "agent.toml", `
+location = "work"
+interval = 30
+token_file = "`+tokenFile+`"
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Location != "work" || cfg.HeartbeatInterval != 30*time.Second {
+		t.Errorf("file values not applied: location=%s interval=%s", cfg.Location, cfg.HeartbeatInterval)
+	}
+	if cfg.Token != "file-token" {
+		t.Errorf("token = %q, want token from token_file", cfg.Token)
+	}
+	if cfg.DashboardURL != "wss://env.example.com/ws" || cfg.RepoDir != "/srv/nixcfg" {
+		t.Error("env values not kept for keys missing from the file")
+	}
+	if cfg.ConfigFile != path {
+		t.Errorf("ConfigFile = %q", cfg.ConfigFile)
+	}
+}
+
+func TestLoadDashboardURLList(t *testing.T) {
+	t.Setenv("NIXFLEET_URL", "wss://a.example.com/ws, wss://b.example.com/ws")
+	t.Setenv("NIXFLEET_TOKEN", "env-token")
+	t.Setenv("NIXFLEET_REPO_DIR", "/srv/nixcfg")
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	urls := cfg.DashboardURLs()
+	if len(urls) != 2 || urls[0] != "wss://a.example.com/ws" || urls[1] != "wss://b.example.com/ws" {
+		t.Errorf("DashboardURLs() = %q", urls)
+	}
+
+	path := writeFile(t, "agent.toml", `url = "wss://c.example.com/ws"`)
+	if cfg, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if urls := cfg.DashboardURLs(); len(urls) != 1 || urls[0] != "wss://c.example.com/ws" {
+		t.Errorf("file url did not replace the env list: %q", urls)
+	}
+
+	t.Setenv("NIXFLEET_URL", "wss://a.example.com/ws,,wss://b.example.com/ws")
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "NIXFLEET_URL") {
+		t.Errorf("expected empty entry error, got %v", err)
+	}
+}
+
+func TestLoadJSONRejectsUnknownKeys(t *testing.T) {
+	t.Setenv("NIXFLEET_URL", "wss://env.example.com/ws")
+	t.Setenv("NIXFLEET_TOKEN", "env-token")
+	t.Setenv("NIXFLEET_REPO_DIR", "/srv/nixcfg")
+
+	path := writeFile(t, "agent.json", `{"device_type": "laptop", "intervall": 10}`)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "intervall") {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+
+	path = writeFile(t, "agent.json", `{"device_type": "laptop", "interval": 0}`)
+	if _, err := Load(path); err == nil {
+		t.Error("expected validation error for interval 0")
+	}
+}
+
+func TestDarwinMode(t *testing.T) {
+	t.Setenv("NIXFLEET_URL", "wss://env.example.com/ws")
+	t.Setenv("NIXFLEET_TOKEN", "env-token")
+	t.Setenv("NIXFLEET_REPO_DIR", "/srv/nixcfg")
+
+	cfg, err := Load("")
+	if err != nil || cfg.DarwinMode != DarwinModeHomeManager || !cfg.UsesHomeManager() || cfg.UsesNixDarwin() {
+		t.Fatalf("default mode: %+v, %v", cfg, err)
+	}
+
+	path := writeFile(t, "agent.toml", `darwin_mode = "both"`)
+	if cfg, err = Load(path); err != nil || !cfg.UsesHomeManager() || !cfg.UsesNixDarwin() {
+		t.Errorf("both: %v, %v", cfg.DarwinMode, err)
+	}
+
+	t.Setenv("NIXFLEET_DARWIN_MODE", "nixdarwin")
+	if _, err := Load(""); err == nil {
+		t.Error("expected error for unknown darwin mode")
+	}
+}
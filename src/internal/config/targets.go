@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Deploy target types: which flake output a target builds and how it is
+// switched.
+const (
+	TargetNixOS       = "nixos"        // nixosConfigurations.<attr>, nixos-rebuild
+	TargetNixDarwin   = "nix-darwin"   // darwinConfigurations.<attr>, darwin-rebuild
+	TargetHomeManager = "home-manager" // homeConfigurations.<attr>, home-manager
+)
+
+// Target is one layer the agent deploys and tracks separately, e.g. the
+// NixOS system and the standalone home-manager configuration of each user.
+type Target struct {
+	Name string // unique per host, used by ops ("switch --target alice")
+	Type string // TargetNixOS, TargetNixDarwin or TargetHomeManager
+	Attr string // configuration name in the flake (default: hostname)
+	User string // home-manager only: activate as this user (default: the agent's user)
+}
+
+// String formats t in the NIXFLEET_TARGETS syntax.
+func (t Target) String() string {
+	s := t.Name + "=" + t.Type + ":" + t.Attr
+	if t.User != "" {
+		s += ":" + t.User
+	}
+	return s
+}
+
+// IsSystem reports whether t is a system-level target (NixOS or nix-darwin).
+func (t Target) IsSystem() bool {
+	return t.Type == TargetNixOS || t.Type == TargetNixDarwin
+}
+
+// ParseTargets parses NIXFLEET_TARGETS (or `targets` in the config file):
+// entries separated by commas, each `[name=]type[:attr[:user]]`.
+//
+//	nixos, alice=home-manager:alice@ws1:alice, bob=home-manager:bob@ws1:bob
+//
+// The name defaults to "system" for system targets and to the user (or
+// "home") for home-manager; an empty attr means the hostname.
+func ParseTargets(s string) ([]Target, error) {
+	var targets []Target
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		var t Target
+		spec := entry
+		if name, rest, ok := strings.Cut(entry, "="); ok {
+			t.Name, spec = strings.TrimSpace(name), rest
+		}
+		parts := strings.Split(spec, ":")
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("target %q: expected [name=]type[:attr[:user]]", entry)
+		}
+		t.Type = strings.TrimSpace(parts[0])
+		if len(parts) > 1 {
+			t.Attr = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			t.User = strings.TrimSpace(parts[2])
+		}
+
+		if t.Name == "" {
+			switch {
+			case t.IsSystem():
+				t.Name = "system"
+			case t.User != "":
+				t.Name = t.User
+			default:
+				t.Name = "home"
+			}
+		}
+		targets = append(targets, t)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no targets in %q", s)
+	}
+	return targets, validateTargets(targets)
+}
+
+// FormatTargets is the inverse of ParseTargets.
+func FormatTargets(targets []Target) string {
+	parts := make([]string, len(targets))
+	for i, t := range targets {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+func validateTargets(targets []Target) error {
+	seen := make(map[string]bool)
+	for _, t := range targets {
+		switch t.Type {
+		case TargetNixOS, TargetNixDarwin, TargetHomeManager:
+		default:
+			return fmt.Errorf("target %s: type must be %s, %s or %s (got %q)",
+				t.Name, TargetNixOS, TargetNixDarwin, TargetHomeManager, t.Type)
+		}
+		if t.User != "" && t.Type != TargetHomeManager {
+			return fmt.Errorf("target %s: user is only supported for %s targets", t.Name, TargetHomeManager)
+		}
+		if strings.ContainsAny(t.Name, " ,=:") {
+			return fmt.Errorf("target name %q must not contain spaces, commas, '=' or ':'", t.Name)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("duplicate target name %q", t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return nil
+}
+
+// DeployTargets returns the configured targets, or the platform default:
+// the NixOS system on Linux, and on macOS whatever DarwinMode selects.
+// Empty attrs are resolved to the hostname. System targets come first,
+// since a home-manager switch on macOS restarts the agent.
+func (c *Config) DeployTargets() []Target {
+	return c.deployTargets(runtime.GOOS)
+}
+
+func (c *Config) deployTargets(goos string) []Target {
+	targets := c.Targets
+	if len(targets) == 0 {
+		switch {
+		case goos != "darwin":
+			targets = []Target{{Name: "system", Type: TargetNixOS}}
+		case c.DarwinMode == DarwinModeNixDarwin:
+			targets = []Target{{Name: "system", Type: TargetNixDarwin}}
+		case c.DarwinMode == DarwinModeBoth:
+			targets = []Target{{Name: "system", Type: TargetNixDarwin}, {Name: "home", Type: TargetHomeManager}}
+		default:
+			targets = []Target{{Name: "home", Type: TargetHomeManager}}
+		}
+	}
+
+	out := make([]Target, 0, len(targets))
+	for _, system := range []bool{true, false} {
+		for _, t := range targets {
+			if t.IsSystem() != system {
+				continue
+			}
+			if t.Attr == "" {
+				t.Attr = c.Hostname
+			}
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// FindTarget returns the deploy target with the given name.
+func (c *Config) FindTarget(name string) (Target, bool) {
+	for _, t := range c.DeployTargets() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Target{}, false
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	got, err := ParseTargets("nixos, alice=home-manager:alice@ws1:alice, home-manager::bob ,home-manager:shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Target{
+		{Name: "system", Type: TargetNixOS},
+		{Name: "alice", Type: TargetHomeManager, Attr: "alice@ws1", User: "alice"},
+		{Name: "bob", Type: TargetHomeManager, User: "bob"},
+		{Name: "home", Type: TargetHomeManager, Attr: "shared"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTargets =\n%+v\nwant\n%+v", got, want)
+	}
+	if again, err := ParseTargets(FormatTargets(got)); err != nil || !reflect.DeepEqual(again, want) {
+		t.Errorf("round trip: %+v, %v", again, err)
+	}
+
+	for _, bad := range []string{
+		"",
+		"nixos-rebuild",
+		"nixos:ws1:alice", // user on a system target
+		"home-manager:a:alice, home-manager:b:alice", // duplicate name
+		"x=nixos:a:b:c",
+	} {
+		if _, err := ParseTargets(bad); err == nil {
+			t.Errorf("ParseTargets(%q): expected error", bad)
+		}
+	}
+}
+
+func TestDeployTargets(t *testing.T) {
+	cfg := &Config{Hostname: "ws1", DarwinMode: DarwinModeHomeManager}
+	if got := cfg.deployTargets("linux"); !reflect.DeepEqual(got, []Target{{Name: "system", Type: TargetNixOS, Attr: "ws1"}}) {
+		t.Errorf("linux default = %+v", got)
+	}
+	if got := cfg.deployTargets("darwin"); !reflect.DeepEqual(got, []Target{{Name: "home", Type: TargetHomeManager, Attr: "ws1"}}) {
+		t.Errorf("darwin default = %+v", got)
+	}
+	cfg.DarwinMode = DarwinModeBoth
+	if got := cfg.deployTargets("darwin"); len(got) != 2 || got[0].Type != TargetNixDarwin || got[1].Type != TargetHomeManager {
+		t.Errorf("darwin both = %+v", got)
+	}
+
+	// System targets are switched first, whatever the configured order
+	cfg.Targets = []Target{{Name: "alice", Type: TargetHomeManager, Attr: "alice@ws1", User: "alice"}, {Name: "system", Type: TargetNixOS}}
+	got := cfg.deployTargets("linux")
+	if len(got) != 2 || got[0].Name != "system" || got[0].Attr != "ws1" || got[1].Attr != "alice@ws1" {
+		t.Errorf("configured = %+v", got)
+	}
+	if _, ok := cfg.FindTarget("bob"); ok {
+		t.Error("FindTarget found an unknown target")
+	}
+}
@@ -0,0 +1,405 @@
+package dashboard
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/markus-barta/nixfleet/internal/store"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// API TOKENS - bearer tokens for scripts and automation clients
+// ═══════════════════════════════════════════════════════════════════════════
+
+// Scopes of API tokens and user roles, each including the ones before it:
+// read-only GETs, then dispatching ops and other state changes, then
+// rebooting, fleet, user and token management.
+const (
+	ScopeRead     = "read"
+	ScopeDispatch = "dispatch"
+	ScopeAdmin    = "admin"
+)
+
+var tokenScopes = []string{ScopeRead, ScopeDispatch, ScopeAdmin}
+
+// Token kinds: a personal token stands in for a person, a service token for
+// a CI job or other automation. Only informational for now.
+var tokenKinds = []string{"personal", "service"}
+
+// apiTokenPrefix marks a secret as a NixFleet API token, so it is
+// recognisable in a leaked config or a secret scanner.
+const apiTokenPrefix = "nft_"
+
+var tokenNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+// APIToken is a stored token. The secret itself is only returned once, at
+// creation; the database keeps its SHA-256.
+type APIToken struct {
+	ID           int64      `json:"id"`
+	Name         string     `json:"name"`
+	Kind         string     `json:"kind"`
+	Scope        string     `json:"scope"`
+	HostSelector string     `json:"host_selector,omitempty"` // restricts the hosts it can act on, see resolveHostSelector
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"` // nil = never
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Allows reports whether the token's scope covers scope.
+func (t *APIToken) Allows(scope string) bool {
+	return scopeCovers(t.Scope, scope)
+}
+
+// scopeCovers reports whether have includes need; unknown scopes cover nothing.
+func scopeCovers(have, need string) bool {
+	i := slices.Index(tokenScopes, have)
+	return i >= 0 && i >= slices.Index(tokenScopes, need)
+}
+
+// Actor is how the token appears in event_log.
+func (t *APIToken) Actor() string {
+	return "token:" + t.Name
+}
+
+// NewAPIToken describes a token to create.
+type NewAPIToken struct {
+	Name         string `json:"name"`
+	Kind         string `json:"kind,omitempty"` // default "service"
+	Scope        string `json:"scope"`
+	HostSelector string `json:"host_selector,omitempty"`
+	ExpiresDays  int    `json:"expires_days,omitempty"` // 0 = never
+}
+
+func (n *NewAPIToken) validate() error {
+	if !tokenNamePattern.MatchString(n.Name) {
+		return fmt.Errorf("invalid token name %q (letters, digits, . _ -, up to 64)", n.Name)
+	}
+	if n.Kind == "" {
+		n.Kind = "service"
+	}
+	if !slices.Contains(tokenKinds, n.Kind) {
+		return fmt.Errorf("invalid kind %q (want %s)", n.Kind, strings.Join(tokenKinds, ", "))
+	}
+	if !slices.Contains(tokenScopes, n.Scope) {
+		return fmt.Errorf("invalid scope %q (want %s)", n.Scope, strings.Join(tokenScopes, ", "))
+	}
+	if n.ExpiresDays < 0 {
+		return fmt.Errorf("expires_days must not be negative")
+	}
+	if n.HostSelector != "" {
+		return validateHostSelector(n.HostSelector)
+	}
+	return nil
+}
+
+func hashAPIToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken stores a new token and returns it with its secret.
+func CreateAPIToken(db *sql.DB, n NewAPIToken) (*APIToken, string, error) {
+	if err := n.validate(); err != nil {
+		return nil, "", err
+	}
+	random, err := generateSecureToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	secret := apiTokenPrefix + strings.TrimRight(random, "=")
+
+	t := &APIToken{
+		Name:         n.Name,
+		Kind:         n.Kind,
+		Scope:        n.Scope,
+		HostSelector: n.HostSelector,
+		CreatedAt:    time.Now(),
+	}
+	var expires sql.NullTime
+	if n.ExpiresDays > 0 {
+		e := t.CreatedAt.AddDate(0, 0, n.ExpiresDays)
+		t.ExpiresAt = &e
+		expires = sql.NullTime{Time: e, Valid: true}
+	}
+
+	res, err := db.Exec(`
+		INSERT INTO api_tokens (name, token_hash, kind, scope, host_selector, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, t.Name, hashAPIToken(secret), t.Kind, t.Scope, t.HostSelector, t.CreatedAt, expires)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, "", fmt.Errorf("a token named %q already exists", n.Name)
+		}
+		return nil, "", err
+	}
+	t.ID, _ = res.LastInsertId()
+	return t, secret, nil
+}
+
+// ListAPITokens returns all tokens, expired ones included, by name.
+func ListAPITokens(db *sql.DB) ([]APIToken, error) {
+	rows, err := db.Query(`
+		SELECT id, name, kind, scope, host_selector, created_at, expires_at, last_used_at
+		FROM api_tokens ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	tokens := []APIToken{}
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokeAPIToken deletes a token by name.
+func RevokeAPIToken(db *sql.DB, name string) error {
+	res, err := db.Exec(`DELETE FROM api_tokens WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no token named %q", name)
+	}
+	return nil
+}
+
+func scanAPIToken(row interface{ Scan(...any) error }) (*APIToken, error) {
+	var t APIToken
+	var expires, lastUsed sql.NullTime
+	if err := row.Scan(&t.ID, &t.Name, &t.Kind, &t.Scope, &t.HostSelector, &t.CreatedAt, &expires, &lastUsed); err != nil {
+		return nil, err
+	}
+	if expires.Valid {
+		t.ExpiresAt = &expires.Time
+	}
+	if lastUsed.Valid {
+		t.LastUsedAt = &lastUsed.Time
+	}
+	return &t, nil
+}
+
+var errTokenExpired = errors.New("token expired")
+
+// lookupAPIToken finds the token for a secret and records its use. The
+// last-used time is written at most once a minute.
+func lookupAPIToken(db *sql.DB, secret string) (*APIToken, error) {
+	t, err := scanAPIToken(db.QueryRow(`
+		SELECT id, name, kind, scope, host_selector, created_at, expires_at, last_used_at
+		FROM api_tokens WHERE token_hash = ?
+	`, hashAPIToken(secret)))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if t.ExpiresAt != nil && now.After(*t.ExpiresAt) {
+		return nil, errTokenExpired
+	}
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) > time.Minute {
+		_, _ = db.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, now, t.ID)
+	}
+	return t, nil
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token), ok
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// MIDDLEWARE
+// ═══════════════════════════════════════════════════════════════════════════
+
+// requireAPIAuth accepts an API token or a session. A token request skips
+// CSRF (no cookie is involved). Either way the read scope is needed for
+// GET/HEAD and dispatch for anything else; routes that need more add
+// requireScope.
+func (s *Server) requireAPIAuth(next http.Handler) http.Handler {
+	scoped := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := ScopeDispatch
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			scope = ScopeRead
+		}
+		if s.checkScope(w, r, scope) {
+			next.ServeHTTP(w, r)
+		}
+	})
+	sessionAuth := s.requireAuth(scoped)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret, ok := bearerToken(r)
+		if !ok {
+			sessionAuth.ServeHTTP(w, r)
+			return
+		}
+
+		token, err := lookupAPIToken(s.db, secret)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, errTokenExpired) {
+				s.log.Error().Err(err).Msg("failed to look up API token")
+			}
+			s.jsonError(w, "Invalid or expired API token", http.StatusUnauthorized)
+			return
+		}
+		scoped.ServeHTTP(w, r.WithContext(withAPIToken(r.Context(), token)))
+	})
+}
+
+// requireScope restricts a route to tokens with scope and users whose role
+// grants it.
+func (s *Server) requireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.checkScope(w, r, scope) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// checkScope reports whether the request's token or user has scope, and
+// answers 403 if not.
+func (s *Server) checkScope(w http.ResponseWriter, r *http.Request, scope string) bool {
+	if token := apiTokenFromContext(r.Context()); token != nil {
+		if token.Allows(scope) {
+			return true
+		}
+		s.jsonError(w, fmt.Sprintf("API token %s lacks the %s scope", token.Name, scope), http.StatusForbidden)
+		return false
+	}
+	if session := sessionFromContext(r.Context()); session != nil && session.User != nil {
+		if scopeCovers(session.User.Scope(), scope) {
+			return true
+		}
+		s.jsonError(w, fmt.Sprintf("user %s (%s) may not do this", session.User.Username, session.User.Role), http.StatusForbidden)
+		return false
+	}
+	s.jsonError(w, "Unauthorized", http.StatusUnauthorized)
+	return false
+}
+
+// requireTokenHost keeps a token with a host selector to the hosts it
+// selects, on routes under /api/hosts/{hostID}.
+func (s *Server) requireTokenHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := apiTokenFromContext(r.Context()); token != nil {
+			hostID := chi.URLParam(r, "hostID")
+			if !s.tokenAllowsHost(token, hostID) {
+				s.jsonError(w, fmt.Sprintf("API token %s may not act on host %s", token.Name, hostID), http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tokenAllowsHost reports whether the token's host selector, if any,
+// currently selects the host (by ID or hostname), online or not.
+func (s *Server) tokenAllowsHost(token *APIToken, hostID string) bool {
+	if token == nil || token.HostSelector == "" {
+		return true
+	}
+	allowed, err := s.selectHosts(token.HostSelector, false)
+	if err != nil {
+		return false
+	}
+	var id string
+	if err := s.db.QueryRow(`SELECT id FROM hosts WHERE id = ? OR hostname = ?`, hostID, hostID).Scan(&id); err != nil {
+		return false
+	}
+	return slices.Contains(allowed, id)
+}
+
+// requestActor is the event_log actor for a request: "token:<name>" or
+// "user:<username>".
+func requestActor(r *http.Request) string {
+	if token := apiTokenFromContext(r.Context()); token != nil {
+		return token.Actor()
+	}
+	if session := sessionFromContext(r.Context()); session != nil && session.User != nil {
+		return "user:" + session.User.Username
+	}
+	return "user"
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// HANDLERS
+// ═══════════════════════════════════════════════════════════════════════════
+
+// handleGetAPITokens lists the API tokens (never their secrets).
+// GET /api/tokens
+func (s *Server) handleGetAPITokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := ListAPITokens(s.db)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to list API tokens")
+		s.jsonError(w, "Failed to list API tokens", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"tokens": tokens})
+}
+
+// LogAPITokenCreated records a new token in the audit log. The CLI logs as
+// actor "cli", requests as requestActor.
+func LogAPITokenCreated(events *store.StateStore, actor string, token *APIToken) {
+	events.LogEvent("audit", "info", actor, "", "token:create",
+		fmt.Sprintf("Created API token %s (%s)", token.Name, token.Scope),
+		map[string]any{"kind": token.Kind, "host_selector": token.HostSelector, "expires_at": token.ExpiresAt})
+}
+
+// LogAPITokenRevoked records a revoked token in the audit log.
+func LogAPITokenRevoked(events *store.StateStore, actor, name string) {
+	events.LogEvent("audit", "warn", actor, "", "token:revoke", "Revoked API token "+name, nil)
+}
+
+// handleCreateAPIToken creates a token and returns its secret, once.
+// POST /api/tokens
+func (s *Server) handleCreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	var req NewAPIToken
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	token, secret, err := CreateAPIToken(s.db, req)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	LogAPITokenCreated(s.stateStore, requestActor(r), token)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{"token": token, "secret": secret})
+}
+
+// handleRevokeAPIToken deletes a token.
+// DELETE /api/tokens/{name}
+func (s *Server) handleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := RevokeAPIToken(s.db, name); err != nil {
+		s.jsonError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	LogAPITokenRevoked(s.stateStore, requestActor(r), name)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "revoked"})
+}
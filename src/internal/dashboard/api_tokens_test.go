@@ -0,0 +1,45 @@
+package dashboard
+
+import "testing"
+
+func TestAPITokenScopes(t *testing.T) {
+	tests := []struct {
+		scope, need string
+		want        bool
+	}{
+		{ScopeRead, ScopeRead, true},
+		{ScopeRead, ScopeDispatch, false},
+		{ScopeDispatch, ScopeRead, true},
+		{ScopeDispatch, ScopeAdmin, false},
+		{ScopeAdmin, ScopeDispatch, true},
+		{"bogus", ScopeRead, false},
+	}
+	for _, tt := range tests {
+		token := &APIToken{Scope: tt.scope}
+		if got := token.Allows(tt.need); got != tt.want {
+			t.Errorf("%s allows %s = %v, want %v", tt.scope, tt.need, got, tt.want)
+		}
+	}
+}
+
+func TestNewAPITokenValidate(t *testing.T) {
+	ok := NewAPIToken{Name: "ci.deploy-1", Scope: ScopeDispatch, HostSelector: "hosts:web1, web2"}
+	if err := ok.validate(); err != nil || ok.Kind != "service" {
+		t.Errorf("valid token: err=%v kind=%q", err, ok.Kind)
+	}
+
+	for _, bad := range []NewAPIToken{
+		{Name: "", Scope: ScopeRead},
+		{Name: "has space", Scope: ScopeRead},
+		{Name: "ci", Scope: "write"},
+		{Name: "ci", Scope: ScopeRead, Kind: "robot"},
+		{Name: "ci", Scope: ScopeRead, ExpiresDays: -1},
+		{Name: "ci", Scope: ScopeRead, HostSelector: "hosts:"},
+		{Name: "ci", Scope: ScopeRead, HostSelector: "facts:ram>4"},
+		{Name: "ci", Scope: ScopeRead, HostSelector: "web1"},
+	} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
@@ -20,6 +20,7 @@ type Session struct {
 	CSRFToken string
 	CreatedAt time.Time
 	ExpiresAt time.Time
+	User      *User // who logged in, the built-in admin for sessions older than user accounts
 }
 
 // RateLimiter tracks login attempts.
@@ -96,16 +97,16 @@ func (a *AuthService) CheckPassword(password string) bool {
 	return err == nil
 }
 
-// CheckTOTP verifies the TOTP code.
-func (a *AuthService) CheckTOTP(code string) bool {
-	if !a.cfg.HasTOTP() {
+// CheckTOTP verifies the user's TOTP code.
+func (a *AuthService) CheckTOTP(user *User, code string) bool {
+	if !user.HasTOTP() {
 		return true // TOTP not required
 	}
-	return totp.Validate(code, a.cfg.TOTPSecret)
+	return totp.Validate(code, user.TOTPSecret)
 }
 
-// CreateSession creates a new session and stores it in the database.
-func (a *AuthService) CreateSession() (*Session, error) {
+// CreateSession creates a new session for the user and stores it in the database.
+func (a *AuthService) CreateSession(user *User) (*Session, error) {
 	sessionID, err := generateSecureToken(32)
 	if err != nil {
 		return nil, err
@@ -120,11 +121,16 @@ func (a *AuthService) CreateSession() (*Session, error) {
 		CSRFToken: csrfToken,
 		CreatedAt: time.Now(),
 		ExpiresAt: time.Now().Add(a.cfg.SessionDuration),
+		User:      user,
 	}
 
+	var userID sql.NullInt64
+	if user.ID != 0 {
+		userID = sql.NullInt64{Int64: user.ID, Valid: true}
+	}
 	_, err = a.db.Exec(
-		`INSERT INTO sessions (id, csrf_token, created_at, expires_at) VALUES (?, ?, ?, ?)`,
-		session.ID, session.CSRFToken, session.CreatedAt, session.ExpiresAt,
+		`INSERT INTO sessions (id, csrf_token, created_at, expires_at, user_id) VALUES (?, ?, ?, ?, ?)`,
+		session.ID, session.CSRFToken, session.CreatedAt, session.ExpiresAt, userID,
 	)
 	if err != nil {
 		return nil, err
@@ -136,10 +142,11 @@ func (a *AuthService) CreateSession() (*Session, error) {
 // GetSession retrieves a session from the database.
 func (a *AuthService) GetSession(sessionID string) (*Session, error) {
 	session := &Session{}
+	var userID sql.NullInt64
 	err := a.db.QueryRow(
-		`SELECT id, csrf_token, created_at, expires_at FROM sessions WHERE id = ?`,
+		`SELECT id, csrf_token, created_at, expires_at, user_id FROM sessions WHERE id = ?`,
 		sessionID,
-	).Scan(&session.ID, &session.CSRFToken, &session.CreatedAt, &session.ExpiresAt)
+	).Scan(&session.ID, &session.CSRFToken, &session.CreatedAt, &session.ExpiresAt, &userID)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +157,16 @@ func (a *AuthService) GetSession(sessionID string) (*Session, error) {
 		return nil, sql.ErrNoRows
 	}
 
+	// Load the user on every request, so role changes apply at once
+	if userID.Valid {
+		session.User, err = getUserByID(a.db, userID.Int64)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		session.User = a.builtinAdmin()
+	}
+
 	return session, nil
 }
 
@@ -0,0 +1,68 @@
+package dashboard
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+	"github.com/markus-barta/nixfleet/internal/templates"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// AGENT CAPABILITIES - protocol version and capabilities sent at registration
+// ═══════════════════════════════════════════════════════════════════════════
+
+// capabilitiesJSON returns what to store for a registering agent: NULL for
+// agents without capabilities, so an older agent replacing a newer one on a
+// host falls back to the legacy set.
+func capabilitiesJSON(payload protocol.RegisterPayload) *string {
+	if payload.Capabilities == nil {
+		return nil
+	}
+	data, err := json.Marshal(payload.Capabilities)
+	if err != nil {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
+
+// parseCapabilities returns the stored capabilities of an agent, or the
+// legacy set if it registered without them.
+func parseCapabilities(raw sql.NullString) protocol.Capabilities {
+	if raw.Valid && raw.String != "" {
+		var caps protocol.Capabilities
+		if err := json.Unmarshal([]byte(raw.String), &caps); err == nil {
+			return caps
+		}
+	}
+	return protocol.LegacyCapabilities()
+}
+
+// loadAgentCapabilities returns the protocol version and capabilities of a
+// host's agent (by ID or hostname).
+func loadAgentCapabilities(db *sql.DB, hostID string) (int, protocol.Capabilities) {
+	var version sql.NullInt64
+	var raw sql.NullString
+	_ = db.QueryRow(`SELECT protocol_version, capabilities_json FROM hosts WHERE id = ? OR hostname = ?`, hostID, hostID).Scan(&version, &raw)
+	return int(version.Int64), parseCapabilities(raw)
+}
+
+// applyAgentCapabilities fills the capability fields ops validate against.
+func applyAgentCapabilities(db *sql.DB, host *templates.Host) {
+	version, caps := loadAgentCapabilities(db, host.ID)
+	host.ProtocolVersion = version
+	host.AgentCommands = caps.Commands
+	host.AgentFeatures = caps.Features
+}
+
+// supportedOps drops the ops the agent does not execute.
+func supportedOps(opIDs []string, supports func(command string) bool) []string {
+	available := opIDs[:0]
+	for _, op := range opIDs {
+		if supports(op) {
+			available = append(available, op)
+		}
+	}
+	return available
+}
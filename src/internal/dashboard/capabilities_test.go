@@ -0,0 +1,55 @@
+package dashboard
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/markus-barta/nixfleet/internal/ops"
+	"github.com/markus-barta/nixfleet/internal/templates"
+	"github.com/rs/zerolog"
+)
+
+func TestAgentCapabilities(t *testing.T) {
+	// Agents that register without capabilities get the legacy set
+	legacy := parseCapabilities(sql.NullString{})
+	if !legacy.HasCommand("switch") || legacy.HasCommand("gc") {
+		t.Errorf("legacy commands = %v", legacy.Commands)
+	}
+	if got := supportedOps([]string{"pull", "switch", "test", "gc", "reboot"}, legacy.HasCommand); len(got) != 4 {
+		t.Errorf("supportedOps = %v", got)
+	}
+
+	host := ops.NewHostAdapter(&templates.Host{
+		Hostname:      "hsb1",
+		AgentVersion:  "2.1.0",
+		AgentCommands: legacy.Commands,
+		AgentFeatures: legacy.Features,
+	})
+	registry := ops.DefaultRegistry()
+
+	if verr := ops.ValidateAgentSupport(registry.Get("gc"), host, ops.Args{}); verr == nil || verr.Code != "agent_too_old" {
+		t.Errorf("gc on legacy agent: %v", verr)
+	}
+	if verr := ops.ValidateAgentSupport(registry.Get("rollback"), host, ops.Args{Generation: 3}); verr == nil {
+		t.Error("rollback to a generation on legacy agent: not blocked")
+	}
+	if verr := ops.ValidateAgentSupport(registry.Get("switch"), host, ops.Args{}); verr != nil {
+		t.Errorf("switch on legacy agent: %v", verr)
+	}
+
+	// A plain pull of a pinned host carries the pin, which a legacy agent would ignore
+	pinned := ops.NewHostAdapter(&templates.Host{
+		ID:            "hsb1",
+		Hostname:      "hsb1",
+		AgentVersion:  "2.1.0",
+		AgentCommands: legacy.Commands,
+		AgentFeatures: legacy.Features,
+		GitRef:        "v1.2.0",
+	})
+	lm := ops.NewLifecycleManager(zerolog.Nop(), registry, nil, nil, nil)
+	if _, err := lm.ExecuteOpWithArgs("pull", pinned, false, ops.Args{}); err == nil {
+		t.Error("pull of a pinned host on legacy agent: not blocked")
+	} else if verr, ok := err.(*ops.ValidationError); !ok || verr.Code != "agent_too_old" {
+		t.Errorf("pull of a pinned host on legacy agent: %v", err)
+	}
+}
@@ -3,6 +3,8 @@ package dashboard
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -21,6 +23,21 @@ type Config struct {
 	TOTPSecret     string // optional, for 2FA
 	AgentToken     string // token that agents must provide
 
+	// OpenID Connect single sign-on, off unless OIDCIssuer is set
+	OIDCIssuer        string
+	OIDCClientID      string
+	OIDCClientSecret  string            // optional, public clients rely on PKCE alone
+	OIDCRedirectURL   string            // default: BaseURL + /login/oidc/callback, never taken from request headers
+	OIDCScopes        []string          // default: openid profile email
+	OIDCUsernameClaim string            // default: preferred_username
+	OIDCGroupsClaim   string            // default: groups
+	OIDCRoles         map[string]string // group -> role, "*" matches every user
+
+	// WebAuthn (passkeys): the origin browsers must report and the relying
+	// party ID credentials are scoped to; never taken from request headers
+	WebAuthnOrigin string // default: BaseURL
+	WebAuthnRPID   string // default: the origin's host name
+
 	// Session
 	SessionDuration time.Duration
 
@@ -57,6 +74,13 @@ type Config struct {
 	StaleMultiplier      int           // Number of missed heartbeats before stale (default: 120)
 	StaleMinimum         time.Duration // Floor to prevent aggressive cleanup (default: 5m)
 	StaleCleanupInterval time.Duration // How often to run cleanup job (default: 1m)
+
+	// Nix store disk pressure (pre-switch check)
+	StoreMinFreeGB     int    // Minimum free GiB on /nix/store before switch (default: 5, 0 = off)
+	StoreLowDiskAction string // "warn" or "block" (default: "warn")
+
+	// Flake input freshness rules (NIXFLEET_LOCK_POLICY, JSON file), nil = off
+	LockPolicy *LockPolicy
 }
 
 // LoadConfig loads configuration from environment variables.
@@ -70,6 +94,13 @@ func LoadConfig() (*Config, error) {
 		SessionSecret:     os.Getenv("NIXFLEET_SESSION_SECRET"),
 		TOTPSecret:        os.Getenv("NIXFLEET_TOTP_SECRET"), // optional
 		AgentToken:        os.Getenv("NIXFLEET_AGENT_TOKEN"),
+		OIDCIssuer:        strings.TrimSuffix(os.Getenv("NIXFLEET_OIDC_ISSUER"), "/"),
+		OIDCClientID:      os.Getenv("NIXFLEET_OIDC_CLIENT_ID"),
+		OIDCClientSecret:  os.Getenv("NIXFLEET_OIDC_CLIENT_SECRET"),
+		OIDCScopes:        strings.Fields(getEnv("NIXFLEET_OIDC_SCOPES", "openid profile email")),
+		OIDCUsernameClaim: getEnv("NIXFLEET_OIDC_USERNAME_CLAIM", "preferred_username"),
+		OIDCGroupsClaim:   getEnv("NIXFLEET_OIDC_GROUPS_CLAIM", "groups"),
+		WebAuthnRPID:      os.Getenv("NIXFLEET_WEBAUTHN_RP_ID"),
 		SessionDuration:   parseDuration("NIXFLEET_SESSION_DURATION", 24*time.Hour),
 		RateLimitRequests: parseInt("NIXFLEET_RATE_LIMIT", 5),
 		RateLimitWindow:   parseDuration("NIXFLEET_RATE_WINDOW", 1*time.Minute),
@@ -96,6 +127,30 @@ func LoadConfig() (*Config, error) {
 		StaleMultiplier:      parseInt("NIXFLEET_STALE_MULTIPLIER", 120),
 		StaleMinimum:         parseDuration("NIXFLEET_STALE_MINIMUM", 5*time.Minute),
 		StaleCleanupInterval: parseDuration("NIXFLEET_STALE_CLEANUP_INTERVAL", 1*time.Minute),
+
+		// Nix store disk pressure
+		StoreMinFreeGB:     parseInt("NIXFLEET_STORE_MIN_FREE_GB", 5),
+		StoreLowDiskAction: getEnv("NIXFLEET_STORE_LOW_DISK_ACTION", "warn"),
+	}
+
+	roles, err := parseOIDCRoles(os.Getenv("NIXFLEET_OIDC_ROLES"))
+	if err != nil {
+		return nil, fmt.Errorf("NIXFLEET_OIDC_ROLES: %w", err)
+	}
+	cfg.OIDCRoles = roles
+	cfg.OIDCRedirectURL = getEnv("NIXFLEET_OIDC_REDIRECT_URL", strings.TrimSuffix(cfg.BaseURL, "/")+"/login/oidc/callback")
+
+	cfg.WebAuthnOrigin = strings.TrimSuffix(getEnv("NIXFLEET_WEBAUTHN_ORIGIN", cfg.BaseURL), "/")
+	if u, err := url.Parse(cfg.WebAuthnOrigin); err == nil && cfg.WebAuthnRPID == "" {
+		cfg.WebAuthnRPID = u.Hostname()
+	}
+
+	if file := os.Getenv("NIXFLEET_LOCK_POLICY"); file != "" {
+		policy, err := LoadLockPolicy(file)
+		if err != nil {
+			return nil, fmt.Errorf("NIXFLEET_LOCK_POLICY: %w", err)
+		}
+		cfg.LockPolicy = policy
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -117,6 +172,15 @@ func (c *Config) validate() error {
 	if c.AgentToken == "" {
 		errs = append(errs, "NIXFLEET_AGENT_TOKEN is required")
 	}
+	if c.OIDCIssuer != "" && c.OIDCClientID == "" {
+		errs = append(errs, "NIXFLEET_OIDC_CLIENT_ID is required when NIXFLEET_OIDC_ISSUER is set")
+	}
+	if u, err := url.Parse(c.OIDCRedirectURL); c.HasOIDC() && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+		errs = append(errs, fmt.Sprintf("NIXFLEET_OIDC_REDIRECT_URL (or NIXFLEET_BASE_URL) must be an absolute URL like https://fleet.example.com/login/oidc/callback, got %q", c.OIDCRedirectURL))
+	}
+	if err := validateWebAuthnOrigin(c.WebAuthnOrigin, c.WebAuthnRPID); err != nil {
+		errs = append(errs, err.Error())
+	}
 
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, "; "))
@@ -149,9 +213,40 @@ func (c *Config) Warnings() []string {
 		warnings = append(warnings, "NIXFLEET_COLOR_COMMIT_MODE must be 'push' or 'pr'; using 'push'")
 	}
 
+	if c.StoreLowDiskAction != "warn" && c.StoreLowDiskAction != "block" {
+		warnings = append(warnings, "NIXFLEET_STORE_LOW_DISK_ACTION must be 'warn' or 'block'; using 'warn'")
+	}
+
+	if c.HasOIDC() && len(c.OIDCRoles) == 0 {
+		warnings = append(warnings, "NIXFLEET_OIDC_ROLES not set; single sign-on will refuse every user")
+	}
+
+	if c.WebAuthnRPID == "localhost" {
+		warnings = append(warnings, "NIXFLEET_BASE_URL not set to the dashboard's public URL; passkeys only work at "+c.WebAuthnOrigin)
+	}
+
 	return warnings
 }
 
+// validateWebAuthnOrigin checks that origin is a bare http(s) origin and that
+// rpID is its host name or a parent domain of it, as browsers require.
+func validateWebAuthnOrigin(origin, rpID string) error {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.User != nil {
+		return fmt.Errorf("NIXFLEET_WEBAUTHN_ORIGIN (or NIXFLEET_BASE_URL) must be an origin like https://fleet.example.com, got %q", origin)
+	}
+	if host := u.Hostname(); rpID != host && !strings.HasSuffix(host, "."+rpID) {
+		return fmt.Errorf("NIXFLEET_WEBAUTHN_RP_ID %q must be %s or a parent domain of it", rpID, host)
+	}
+	return nil
+}
+
+// HasOIDC returns true if OpenID Connect single sign-on is configured.
+func (c *Config) HasOIDC() bool {
+	return c.OIDCIssuer != "" && c.OIDCClientID != ""
+}
+
 // HasTOTP returns true if TOTP is configured.
 func (c *Config) HasTOTP() bool {
 	return c.TOTPSecret != ""
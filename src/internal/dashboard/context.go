@@ -4,7 +4,11 @@ import "context"
 
 type contextKey string
 
-const sessionContextKey contextKey = "session"
+const (
+	sessionContextKey  contextKey = "session"
+	apiTokenContextKey contextKey = "api_token"
+	stepUpContextKey   contextKey = "step_up"
+)
 
 // withSession adds a session to the context.
 func withSession(ctx context.Context, session *Session) context.Context {
@@ -17,3 +21,24 @@ func sessionFromContext(ctx context.Context) *Session {
 	return session
 }
 
+// withAPIToken adds the API token a request authenticated with to the context.
+func withAPIToken(ctx context.Context, token *APIToken) context.Context {
+	return context.WithValue(ctx, apiTokenContextKey, token)
+}
+
+// apiTokenFromContext retrieves the API token, nil for session requests.
+func apiTokenFromContext(ctx context.Context) *APIToken {
+	token, _ := ctx.Value(apiTokenContextKey).(*APIToken)
+	return token
+}
+
+// withStepUp records how the request confirmed a TOTP-gated op.
+func withStepUp(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, stepUpContextKey, method)
+}
+
+// stepUpFromContext returns stepUpTOTP, stepUpWebAuthn or "" if none was needed.
+func stepUpFromContext(ctx context.Context) string {
+	method, _ := ctx.Value(stepUpContextKey).(string)
+	return method
+}
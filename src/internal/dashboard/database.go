@@ -123,6 +123,7 @@ func createTables(db *sql.DB) error {
 		exit_code   INTEGER,
 		error       TEXT,
 		output_file TEXT,
+		args_json   TEXT,
 		FOREIGN KEY (host_id) REFERENCES hosts(id)
 	);
 	CREATE INDEX IF NOT EXISTS idx_commands_host ON commands(host_id, created_at DESC);
@@ -157,6 +158,61 @@ func createTables(db *sql.DB) error {
 	CREATE INDEX IF NOT EXISTS idx_event_log_host ON event_log(host_id, timestamp DESC);
 	CREATE INDEX IF NOT EXISTS idx_event_log_category ON event_log(category, timestamp DESC);
 
+	-- Per-test outcome of every test run, for history and flaky detection
+	CREATE TABLE IF NOT EXISTS test_results (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		host_id     TEXT NOT NULL,
+		generation  TEXT,
+		run_at      DATETIME NOT NULL,
+		test_name   TEXT NOT NULL,
+		status      TEXT NOT NULL,
+		duration    REAL,
+		message     TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_test_results_host_test ON test_results(host_id, test_name, run_at DESC);
+
+	-- Hardware and OS inventory of each host, as last reported by its agent
+	CREATE TABLE IF NOT EXISTS host_facts (
+		host_id    TEXT PRIMARY KEY,
+		facts_json TEXT NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Dashboard user accounts next to the built-in admin (NIXFLEET_PASSWORD_HASH)
+	CREATE TABLE IF NOT EXISTS users (
+		id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		username      TEXT NOT NULL UNIQUE,
+		password_hash TEXT NOT NULL,
+		totp_secret   TEXT NOT NULL DEFAULT '',
+		role          TEXT NOT NULL,
+		created_at    DATETIME NOT NULL
+	);
+
+	-- WebAuthn passkeys; user_id 0 is the built-in admin
+	CREATE TABLE IF NOT EXISTS webauthn_credentials (
+		id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id       INTEGER NOT NULL,
+		credential_id TEXT NOT NULL UNIQUE,
+		public_key    BLOB NOT NULL,
+		sign_count    INTEGER NOT NULL DEFAULT 0,
+		name          TEXT NOT NULL,
+		created_at    DATETIME NOT NULL,
+		last_used_at  DATETIME
+	);
+
+	-- Bearer tokens for automation clients; only the SHA-256 of the secret is kept
+	CREATE TABLE IF NOT EXISTS api_tokens (
+		id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		name          TEXT NOT NULL UNIQUE,
+		token_hash    TEXT NOT NULL UNIQUE,
+		kind          TEXT NOT NULL,
+		scope         TEXT NOT NULL,
+		host_selector TEXT NOT NULL DEFAULT '',
+		created_at    DATETIME NOT NULL,
+		expires_at    DATETIME,
+		last_used_at  DATETIME
+	);
+
 	-- State version table (CORE-004)
 	CREATE TABLE IF NOT EXISTS state_version (
 		id      INTEGER PRIMARY KEY CHECK (id = 1),
@@ -216,6 +272,106 @@ func createTables(db *sql.DB) error {
 		_, _ = db.Exec(m)
 	}
 
+	// Nix store disk usage reported in heartbeat
+	diskMigrations := []string{
+		`ALTER TABLE hosts ADD COLUMN disk_json TEXT`,
+	}
+	for _, m := range diskMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// Failed systemd units reported in heartbeat
+	servicesMigrations := []string{
+		`ALTER TABLE hosts ADD COLUMN services_json TEXT`,
+	}
+	for _, m := range servicesMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// Reboot-required detection (booted vs current kernel/initrd)
+	rebootMigrations := []string{
+		`ALTER TABLE hosts ADD COLUMN reboot_required INTEGER DEFAULT 0`,
+		`ALTER TABLE hosts ADD COLUMN reboot_reason TEXT`,
+	}
+	for _, m := range rebootMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// Per-script test results (latest run, JUnit-style)
+	testReportMigrations := []string{
+		`ALTER TABLE hosts ADD COLUMN test_report_json TEXT`,
+	}
+	for _, m := range testReportMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// macOS host mode (home-manager, nix-darwin, both)
+	darwinMigrations := []string{
+		`ALTER TABLE hosts ADD COLUMN darwin_mode TEXT`,
+	}
+	for _, m := range darwinMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// Per-target System status (NIXFLEET_TARGETS: system + home-manager users)
+	targetsMigrations := []string{
+		`ALTER TABLE hosts ADD COLUMN targets_status_json TEXT`,
+	}
+	for _, m := range targetsMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// Typed command arguments (target, generation) sent with the command ID
+	commandArgsMigrations := []string{
+		`ALTER TABLE commands ADD COLUMN args_json TEXT`,
+	}
+	for _, m := range commandArgsMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// Git ref pins: pull/pull-switch deploy this ref instead of the branch tip
+	gitRefMigrations := []string{
+		`ALTER TABLE hosts ADD COLUMN git_ref TEXT`,
+		`ALTER TABLE hosts ADD COLUMN git_ref_commit TEXT`,
+	}
+	for _, m := range gitRefMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// Capability negotiation: what each host's agent supports
+	capabilitiesMigrations := []string{
+		`ALTER TABLE hosts ADD COLUMN protocol_version INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE hosts ADD COLUMN capabilities_json TEXT`,
+	}
+	for _, m := range capabilitiesMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// User accounts: sessions belong to a user (NULL = the built-in admin)
+	sessionUserMigrations := []string{
+		`ALTER TABLE sessions ADD COLUMN user_id INTEGER`,
+	}
+	for _, m := range sessionUserMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// Accounts created by OpenID Connect sign-on, keyed by the provider's subject
+	oidcMigrations := []string{
+		`ALTER TABLE users ADD COLUMN oidc_subject TEXT`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_oidc_subject ON users(oidc_subject)`,
+	}
+	for _, m := range oidcMigrations {
+		_, _ = db.Exec(m)
+	}
+
+	// Flake input inventory (direct inputs of each host's flake.lock)
+	flakeInputsMigrations := []string{
+		`ALTER TABLE hosts ADD COLUMN flake_inputs_json TEXT`,
+	}
+	for _, m := range flakeInputsMigrations {
+		_, _ = db.Exec(m)
+	}
+
 	return nil
 }
 
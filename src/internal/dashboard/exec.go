@@ -0,0 +1,68 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/ops"
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// EXEC OP - allowlisted ad-hoc commands, audited
+// ═══════════════════════════════════════════════════════════════════════════
+
+// execCommandLine expands an exec request against the allowlist the host's
+// agent advertised, so the audit log records the command line that runs.
+// The agent expands it again from its own allowlist; agents without exec
+// support pass through to the op engine's agent_too_old check.
+func execCommandLine(caps protocol.Capabilities, args ops.Args) (string, *ops.ValidationError) {
+	if !caps.HasCommand("exec") {
+		return "", nil
+	}
+	def, ok := caps.Exec[args.Exec]
+	if !ok {
+		return "", &ops.ValidationError{
+			Code:    "exec_not_allowed",
+			Message: fmt.Sprintf("%q is not in the host's exec allowlist", args.Exec),
+		}
+	}
+	argv, err := def.Expand(args.Params)
+	if err != nil {
+		return "", &ops.ValidationError{Code: "invalid_args", Message: fmt.Sprintf("%s: %v", args.Exec, err)}
+	}
+	return protocol.FormatCommandLine(argv), nil
+}
+
+// auditLogExec records an exec request in audit_log: who asked (session or
+// API token, and IP), the command and its expanded command line, and whether it was sent.
+// result is "dispatched" or why it was not.
+func (s *Server) auditLogExec(r *http.Request, hostID string, args ops.Args, commandLine, commandID, result string) {
+	sessionID := ""
+	if session := sessionFromContext(r.Context()); session != nil {
+		sessionID = session.ID
+	}
+	success := 0
+	if result == "dispatched" {
+		success = 1
+	}
+	details, _ := json.Marshal(map[string]any{
+		"exec":         args.Exec,
+		"params":       args.Params,
+		"command_line": commandLine,
+		"command_id":   commandID,
+		"result":       result,
+		"actor":        requestActor(r),
+		"step_up":      stepUpFromContext(r.Context()),
+		"ip":           r.RemoteAddr,
+	})
+	_, err := s.db.Exec(
+		`INSERT INTO audit_log (action, host_id, user_session, timestamp, success, details) VALUES (?, ?, ?, ?, ?, ?)`,
+		"exec", hostID, sessionID, time.Now().Unix(), success, string(details),
+	)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to write audit log")
+	}
+}
@@ -0,0 +1,261 @@
+package dashboard
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// FLAKE INPUT INVENTORY + DRIFT MATRIX
+// ═══════════════════════════════════════════════════════════════════════════
+
+// Drift of a host's input against the target lock.
+const (
+	flakeDriftOK      = "ok"      // same revision as the target
+	flakeDriftDiffers = "differs" // locked to another revision
+	flakeDriftExtra   = "extra"   // input not in the target lock
+	flakeDriftUnknown = "unknown" // no target lock to compare with
+)
+
+// flakeHost is a host's stored flake input inventory.
+type flakeHost struct {
+	lockPolicyHost
+	ID       string
+	LockHash string
+	Inputs   []protocol.FlakeInput
+}
+
+// FlakeMatrix is the fleet-wide flake input report of GET /api/flake-inputs.
+type FlakeMatrix struct {
+	Target     *FlakeTarget               `json:"target"`     // nil if no target lock is known
+	Inputs     []string                   `json:"inputs"`     // all input names, sorted
+	Hosts      []FlakeHostInputs          `json:"hosts"`      // hosts that reported their inputs
+	Unreported []string                   `json:"unreported"` // hostnames without inventory (older agents)
+	Revisions  map[string][]FlakeRevision `json:"revisions"`  // input name → revisions in use, most hosts first
+}
+
+// FlakeTarget is the lock the fleet should run.
+type FlakeTarget struct {
+	LockHash string                `json:"lock_hash"`
+	Source   string                `json:"source"` // hostname whose inventory describes the target lock
+	Inputs   []protocol.FlakeInput `json:"inputs"`
+}
+
+// FlakeHostInputs is one row of the matrix.
+type FlakeHostInputs struct {
+	HostID   string                     `json:"host_id"`
+	Hostname string                     `json:"hostname"`
+	LockHash string                     `json:"lock_hash"`
+	Current  bool                       `json:"current"` // runs the target lock
+	Inputs   map[string]FlakeInputState `json:"inputs"`
+	Missing  []string                   `json:"missing,omitempty"` // target inputs the host's lock lacks
+}
+
+// FlakeInputState is one cell of the matrix.
+type FlakeInputState struct {
+	protocol.FlakeInput
+	AgeDays   int    `json:"age_days"` // days since the locked revision, 0 if unknown
+	Drift     string `json:"drift"`
+	Violation string `json:"violation,omitempty"` // why the input breaks the lock policy
+}
+
+// FlakeRevision is one revision of an input and the hosts locked to it.
+type FlakeRevision struct {
+	Rev          string   `json:"rev"` // revision, or NAR hash for inputs without one
+	LastModified int64    `json:"last_modified"`
+	AgeDays      int      `json:"age_days"`
+	Target       bool     `json:"target"`
+	Hosts        []string `json:"hosts"`
+}
+
+// flakeInputKey identifies an input's locked revision; inputs without a
+// revision (tarballs, paths) are compared by content.
+func flakeInputKey(in protocol.FlakeInput) string {
+	if in.Rev != "" {
+		return in.Rev
+	}
+	return in.NarHash
+}
+
+// flakeInputAge returns the whole days since the input's revision.
+func flakeInputAge(in protocol.FlakeInput, now time.Time) int {
+	if in.LastModified <= 0 {
+		return 0
+	}
+	return int(now.Sub(time.Unix(in.LastModified, 0)).Hours() / 24)
+}
+
+// buildFlakeMatrix compares each host's inputs with target (nil if unknown)
+// and checks them against policy (nil if none).
+func buildFlakeMatrix(hosts []flakeHost, target *FlakeTarget, policy *LockPolicy, now time.Time) FlakeMatrix {
+	m := FlakeMatrix{
+		Target:     target,
+		Inputs:     []string{},
+		Hosts:      []FlakeHostInputs{},
+		Unreported: []string{},
+		Revisions:  make(map[string][]FlakeRevision),
+	}
+
+	targetKeys := make(map[string]string)
+	if target != nil {
+		for _, in := range target.Inputs {
+			targetKeys[in.Name] = flakeInputKey(in)
+		}
+	}
+
+	names := make(map[string]bool)
+	revisions := make(map[string]map[string]*FlakeRevision) // input → key → revision
+	for _, h := range hosts {
+		if h.Inputs == nil {
+			m.Unreported = append(m.Unreported, h.Hostname)
+			continue
+		}
+		row := FlakeHostInputs{
+			HostID:   h.ID,
+			Hostname: h.Hostname,
+			LockHash: h.LockHash,
+			Current:  target != nil && h.LockHash != "" && h.LockHash == target.LockHash,
+			Inputs:   make(map[string]FlakeInputState, len(h.Inputs)),
+		}
+		for _, in := range h.Inputs {
+			names[in.Name] = true
+			key := flakeInputKey(in)
+
+			state := FlakeInputState{FlakeInput: in, AgeDays: flakeInputAge(in, now), Drift: flakeDriftUnknown}
+			if target != nil {
+				switch want, ok := targetKeys[in.Name]; {
+				case !ok:
+					state.Drift = flakeDriftExtra
+				case want == key:
+					state.Drift = flakeDriftOK
+				default:
+					state.Drift = flakeDriftDiffers
+				}
+			}
+			if policy != nil {
+				state.Violation = policy.check(h.lockPolicyHost, in, now)
+			}
+			row.Inputs[in.Name] = state
+
+			if revisions[in.Name] == nil {
+				revisions[in.Name] = make(map[string]*FlakeRevision)
+			}
+			rev := revisions[in.Name][key]
+			if rev == nil {
+				rev = &FlakeRevision{
+					Rev:          key,
+					LastModified: in.LastModified,
+					AgeDays:      state.AgeDays,
+					Target:       target != nil && targetKeys[in.Name] == key,
+				}
+				revisions[in.Name][key] = rev
+			}
+			rev.Hosts = append(rev.Hosts, h.Hostname)
+		}
+		if target != nil {
+			for _, in := range target.Inputs {
+				if _, ok := row.Inputs[in.Name]; !ok {
+					row.Missing = append(row.Missing, in.Name)
+				}
+			}
+		}
+		m.Hosts = append(m.Hosts, row)
+	}
+
+	for name := range names {
+		m.Inputs = append(m.Inputs, name)
+	}
+	sort.Strings(m.Inputs)
+
+	for name, byKey := range revisions {
+		list := make([]FlakeRevision, 0, len(byKey))
+		for _, rev := range byKey {
+			list = append(list, *rev)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if len(list[i].Hosts) != len(list[j].Hosts) {
+				return len(list[i].Hosts) > len(list[j].Hosts)
+			}
+			return list[i].LastModified > list[j].LastModified
+		})
+		m.Revisions[name] = list
+	}
+	return m
+}
+
+// loadFlakeHosts returns every host with its stored inventory, by hostname.
+func loadFlakeHosts(db *sql.DB) ([]flakeHost, error) {
+	rows, err := db.Query(`
+		SELECT id, hostname, COALESCE(location, ''), COALESCE(device_type, ''), COALESCE(host_type, ''),
+			COALESCE(lock_hash, ''), flake_inputs_json
+		FROM hosts ORDER BY hostname
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var hosts []flakeHost
+	for rows.Next() {
+		var h flakeHost
+		var inputsJSON sql.NullString
+		if err := rows.Scan(&h.ID, &h.Hostname, &h.Location, &h.DeviceType, &h.HostType, &h.LockHash, &inputsJSON); err != nil {
+			return nil, err
+		}
+		if inputsJSON.Valid {
+			if err := json.Unmarshal([]byte(inputsJSON.String), &h.Inputs); err != nil {
+				h.Inputs = nil
+			}
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, rows.Err()
+}
+
+// flakeTarget picks the target lock: the given host's lock if targetHost is
+// set, otherwise the latest lock from version.json, described by the
+// inventory of a host that runs it. Nil if neither is known.
+func flakeTarget(hosts []flakeHost, latestLockHash, targetHost string) *FlakeTarget {
+	for _, h := range hosts {
+		if h.Inputs == nil {
+			continue
+		}
+		if (targetHost != "" && (h.ID == targetHost || h.Hostname == targetHost)) ||
+			(targetHost == "" && latestLockHash != "" && h.LockHash == latestLockHash) {
+			return &FlakeTarget{LockHash: h.LockHash, Source: h.Hostname, Inputs: h.Inputs}
+		}
+	}
+	return nil
+}
+
+// handleGetFlakeInputs returns the fleet-wide flake input matrix.
+// GET /api/flake-inputs[?target=<host>]
+//
+// Without ?target the target lock is the latest lock hash of version.json.
+func (s *Server) handleGetFlakeInputs(w http.ResponseWriter, r *http.Request) {
+	hosts, err := loadFlakeHosts(s.db)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to load flake inputs")
+		s.jsonError(w, "Failed to load flake inputs", http.StatusInternalServerError)
+		return
+	}
+
+	targetHost := r.URL.Query().Get("target")
+	var latest string
+	if targetHost == "" && s.versionFetcher != nil {
+		latest = s.versionFetcher.GetLatestLockHash()
+	}
+	target := flakeTarget(hosts, latest, targetHost)
+	if targetHost != "" && target == nil {
+		s.jsonError(w, "Target host not found or has not reported its flake inputs", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(buildFlakeMatrix(hosts, target, s.cfg.LockPolicy, time.Now()))
+}
@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+func TestBuildFlakeMatrix(t *testing.T) {
+	now := time.Unix(1718000000, 0)
+	day := int64(24 * 60 * 60)
+	nixpkgs := func(rev string, age int64) protocol.FlakeInput {
+		return protocol.FlakeInput{Name: "nixpkgs", Type: "github", Rev: rev, LastModified: now.Unix() - age*day}
+	}
+	hm := protocol.FlakeInput{Name: "home-manager", Type: "github", Rev: "hm1", LastModified: now.Unix() - 3*day}
+
+	hosts := []flakeHost{
+		{ID: "a", lockPolicyHost: lockPolicyHost{Hostname: "alpha"}, LockHash: "new", Inputs: []protocol.FlakeInput{hm, nixpkgs("np2", 2)}},
+		{ID: "b", lockPolicyHost: lockPolicyHost{Hostname: "beta"}, LockHash: "old", Inputs: []protocol.FlakeInput{nixpkgs("np1", 40),
+			{Name: "agenix", Type: "github", Rev: "ag1"}}},
+		{ID: "c", lockPolicyHost: lockPolicyHost{Hostname: "gamma"}, LockHash: "new", Inputs: []protocol.FlakeInput{hm, nixpkgs("np2", 2)}},
+		{ID: "d", lockPolicyHost: lockPolicyHost{Hostname: "delta"}},
+	}
+
+	target := flakeTarget(hosts, "new", "")
+	if target == nil || target.Source != "alpha" {
+		t.Fatalf("target = %+v", target)
+	}
+	m := buildFlakeMatrix(hosts, target, nil, now)
+
+	if len(m.Inputs) != 3 || m.Inputs[0] != "agenix" || m.Inputs[2] != "nixpkgs" {
+		t.Errorf("inputs = %v", m.Inputs)
+	}
+	if len(m.Unreported) != 1 || m.Unreported[0] != "delta" {
+		t.Errorf("unreported = %v", m.Unreported)
+	}
+	if len(m.Hosts) != 3 || !m.Hosts[0].Current || m.Hosts[1].Current {
+		t.Fatalf("hosts = %+v", m.Hosts)
+	}
+
+	beta := m.Hosts[1]
+	if s := beta.Inputs["nixpkgs"]; s.Drift != flakeDriftDiffers || s.AgeDays != 40 {
+		t.Errorf("beta nixpkgs = %+v", s)
+	}
+	if s := beta.Inputs["agenix"]; s.Drift != flakeDriftExtra || s.AgeDays != 0 {
+		t.Errorf("beta agenix = %+v", s)
+	}
+	if len(beta.Missing) != 1 || beta.Missing[0] != "home-manager" {
+		t.Errorf("beta missing = %v", beta.Missing)
+	}
+	if s := m.Hosts[0].Inputs["nixpkgs"]; s.Drift != flakeDriftOK || s.AgeDays != 2 {
+		t.Errorf("alpha nixpkgs = %+v", s)
+	}
+
+	revs := m.Revisions["nixpkgs"]
+	if len(revs) != 2 || revs[0].Rev != "np2" || !revs[0].Target || len(revs[0].Hosts) != 2 ||
+		revs[1].Rev != "np1" || revs[1].Target || revs[1].Hosts[0] != "beta" {
+		t.Errorf("nixpkgs revisions = %+v", revs)
+	}
+
+	// Without a target lock nothing can drift
+	m = buildFlakeMatrix(hosts, flakeTarget(hosts, "", ""), nil, now)
+	if m.Target != nil || m.Hosts[0].Inputs["nixpkgs"].Drift != flakeDriftUnknown || m.Hosts[0].Current {
+		t.Errorf("matrix without target = %+v", m.Hosts[0])
+	}
+
+	// An explicit target host
+	if target := flakeTarget(hosts, "new", "beta"); target == nil || target.LockHash != "old" {
+		t.Errorf("target host = %+v", target)
+	}
+}
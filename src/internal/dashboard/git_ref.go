@@ -0,0 +1,168 @@
+package dashboard
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/markus-barta/nixfleet/internal/ops"
+	"github.com/markus-barta/nixfleet/internal/protocol"
+	"github.com/markus-barta/nixfleet/internal/templates"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// GIT REF PINS - hosts deployed from a commit, tag or branch instead of the
+// branch tip in version.json
+// ═══════════════════════════════════════════════════════════════════════════
+
+// GitPin is the git ref a host is pinned to. Commit is what the ref checked
+// out on the host's last successful pull of it (refs other than commit SHAs
+// can only be resolved by the agent).
+type GitPin struct {
+	Ref    string
+	Commit string
+}
+
+// loadGitPin returns the pin of a host (by ID or hostname); the zero GitPin
+// if it follows the branch tip.
+func loadGitPin(db *sql.DB, hostID string) GitPin {
+	var ref, commit sql.NullString
+	_ = db.QueryRow(`SELECT git_ref, git_ref_commit FROM hosts WHERE id = ? OR hostname = ?`, hostID, hostID).Scan(&ref, &commit)
+	return GitPin{Ref: ref.String, Commit: commit.String}
+}
+
+// setGitPin pins a host to ref, or unpins it if ref is empty. A new ref
+// forgets the commit the old one resolved to.
+func setGitPin(db *sql.DB, hostID, ref string) error {
+	_, err := db.Exec(`UPDATE hosts SET git_ref = NULLIF(?, ''),
+		git_ref_commit = CASE WHEN git_ref IS ? THEN git_ref_commit END
+		WHERE id = ? OR hostname = ?`, ref, ref, hostID, hostID)
+	return err
+}
+
+// recordGitPinCommit stores the commit a pull of ref checked out, if the host
+// is still pinned to ref.
+func recordGitPinCommit(db *sql.DB, hostID, ref, commit string) error {
+	_, err := db.Exec(`UPDATE hosts SET git_ref_commit = ? WHERE (id = ? OR hostname = ?) AND git_ref = ?`,
+		commit, hostID, hostID, ref)
+	return err
+}
+
+// checkGitRef validates the ref a pull/pull-switch names, if any.
+func checkGitRef(opID string, args ops.Args) error {
+	if args.Ref == "" {
+		return nil
+	}
+	if verr := ops.ValidateArgs(opID, args); verr != nil {
+		return verr
+	}
+	if !protocol.ValidGitRef(args.Ref) {
+		return &ops.ValidationError{Code: "invalid_args", Message: "Invalid git ref: " + args.Ref}
+	}
+	return nil
+}
+
+// withGitRef shows host as pinned to ref, so validation compares the Git
+// compartment against the ref a pull/pull-switch names. Only the in-memory
+// host changes; pinGitRef stores the pin once the op is dispatched.
+func withGitRef(host *templates.Host, ref string) {
+	if ref == "" || ref == host.GitRef {
+		return
+	}
+	host.GitRef = ref
+	if host.UpdateStatus != nil {
+		status, message, checkedAt := GitPin{Ref: ref}.status(host.Generation)
+		host.UpdateStatus.Git = templates.StatusCheck{Status: status, Message: message, CheckedAt: checkedAt}
+	}
+}
+
+// pinGitRef makes the ref a dispatched pull/pull-switch named the host's pin.
+// Called only after dispatch, so a refused op never moves the pin.
+func (s *Server) pinGitRef(hostID, ref string) {
+	if ref == "" {
+		return
+	}
+	if err := setGitPin(s.db, hostID, ref); err != nil {
+		s.log.Error().Err(err).Str("host", hostID).Msg("failed to store git ref pin")
+	}
+}
+
+// handleSetGitRef pins a host to a git ref, or back to the branch tip.
+// POST /api/hosts/{hostID}/git-ref {"ref": "v1.2.0"} ({"ref": ""} unpins)
+func (s *Server) handleSetGitRef(w http.ResponseWriter, r *http.Request) {
+	hostID := chi.URLParam(r, "hostID")
+
+	var req struct {
+		Ref string `json:"ref"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Ref != "" && !protocol.ValidGitRef(req.Ref) {
+		s.jsonError(w, "invalid git ref", http.StatusBadRequest)
+		return
+	}
+
+	var id string
+	if err := s.db.QueryRow(`SELECT id FROM hosts WHERE id = ?`, hostID).Scan(&id); err != nil {
+		s.jsonError(w, "host not found", http.StatusNotFound)
+		return
+	}
+	if err := setGitPin(s.db, hostID, req.Ref); err != nil {
+		s.log.Error().Err(err).Str("host", hostID).Msg("failed to store git ref pin")
+		s.jsonError(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	s.log.Info().Str("host", hostID).Str("ref", req.Ref).Msg("git ref pin changed")
+
+	// Git compartment now compares against the new pin
+	s.hub.BroadcastHostStatus(hostID)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok", "git_ref": req.Ref})
+}
+
+// status compares the host's generation with the pinned commit.
+func (p GitPin) status(agentGeneration string) (status, message, checkedAt string) {
+	checkedAt = time.Now().UTC().Format(time.RFC3339)
+
+	want := p.Commit
+	if want == "" && isCommitHash(p.Ref) {
+		want = p.Ref
+	}
+	if want == "" {
+		return "outdated", "Pinned to " + p.Ref + ", not pulled yet", checkedAt
+	}
+	if agentGeneration == "" {
+		return "unknown", "Agent has not reported generation", checkedAt
+	}
+
+	if shortHash(agentGeneration) == shortHash(want) {
+		return "ok", "Pinned to " + p.Ref + " (" + shortHash(want) + ")", checkedAt
+	}
+	return "outdated", "Not on pinned " + p.Ref + " (" + shortHash(agentGeneration) + " → " + shortHash(want) + ")", checkedAt
+}
+
+// isCommitHash reports whether ref looks like a full commit SHA.
+func isCommitHash(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+	for _, c := range ref {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// shortHash returns the 7-char form the agent reports as generation.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -2,10 +2,12 @@ package dashboard
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/markus-barta/nixfleet/internal/colors"
 	"github.com/markus-barta/nixfleet/internal/ops"
+	"github.com/markus-barta/nixfleet/internal/protocol"
 	syncproto "github.com/markus-barta/nixfleet/internal/sync"
 	"github.com/markus-barta/nixfleet/internal/templates"
 )
@@ -22,9 +25,10 @@ import (
 func (s *Server) upgrader() *websocket.Upgrader {
 	if s.wsUpgrader == nil {
 		s.wsUpgrader = &websocket.Upgrader{
-			ReadBufferSize:  1024,
-			WriteBufferSize: 1024,
-			CheckOrigin:     s.checkOrigin,
+			ReadBufferSize:    1024,
+			WriteBufferSize:   1024,
+			CheckOrigin:       s.checkOrigin,
+			EnableCompression: true, // permessage-deflate, if the peer offers it
 		}
 	}
 	return s.wsUpgrader
@@ -113,19 +117,13 @@ func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
 	errorMsg := r.URL.Query().Get("error")
 
 	w.Header().Set("Content-Type", "text/html")
-	_ = templates.Login(errorMsg, Version).Render(context.Background(), w)
+	_ = templates.Login(errorMsg, Version, s.oidc != nil).Render(context.Background(), w)
 }
 
 // handleLogin processes login form submission.
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
-	// Check rate limit - normalize IP by stripping port
-	ip := r.RemoteAddr
-	if colonIdx := strings.LastIndex(ip, ":"); colonIdx != -1 {
-		// Check if this is IPv6 in brackets
-		if bracketIdx := strings.LastIndex(ip, "]"); bracketIdx == -1 || colonIdx > bracketIdx {
-			ip = ip[:colonIdx]
-		}
-	}
+	// Check rate limit
+	ip := clientIP(r)
 	if s.auth.IsRateLimited(ip) {
 		http.Redirect(w, r, "/login?error=Too+many+attempts.+Please+wait.", http.StatusFound)
 		return
@@ -137,25 +135,27 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	username := r.FormValue("username")
 	password := r.FormValue("password")
 	totpCode := r.FormValue("totp")
 
-	// Check password
-	if !s.auth.CheckPassword(password) {
-		s.log.Warn().Str("ip", ip).Msg("failed login attempt: wrong password")
-		http.Redirect(w, r, "/login?error=Invalid+password", http.StatusFound)
+	// Check password (no username = the built-in admin)
+	user, err := s.auth.Authenticate(username, password)
+	if err != nil {
+		s.log.Warn().Err(err).Str("ip", ip).Str("user", username).Msg("failed login attempt: wrong password")
+		http.Redirect(w, r, "/login?error=Invalid+username+or+password", http.StatusFound)
 		return
 	}
 
-	// Check TOTP if configured
-	if s.cfg.HasTOTP() && !s.auth.CheckTOTP(totpCode) {
-		s.log.Warn().Str("ip", ip).Msg("failed login attempt: wrong TOTP")
+	// Check TOTP if the user has it
+	if !s.auth.CheckTOTP(user, totpCode) {
+		s.log.Warn().Str("ip", ip).Str("user", user.Username).Msg("failed login attempt: wrong TOTP")
 		http.Redirect(w, r, "/login?error=Invalid+TOTP+code", http.StatusFound)
 		return
 	}
 
 	// Create session
-	session, err := s.auth.CreateSession()
+	session, err := s.auth.CreateSession(user)
 	if err != nil {
 		s.log.Error().Err(err).Msg("failed to create session")
 		http.Redirect(w, r, "/login?error=Server+error", http.StatusFound)
@@ -170,6 +170,19 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
+// clientIP is the request's remote address without the port, the key for
+// login rate limiting.
+func clientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if colonIdx := strings.LastIndex(ip, ":"); colonIdx != -1 {
+		// Check if this is IPv6 in brackets
+		if bracketIdx := strings.LastIndex(ip, "]"); bracketIdx == -1 || colonIdx > bracketIdx {
+			ip = ip[:colonIdx]
+		}
+	}
+	return ip
+}
+
 // handleLogout logs the user out.
 func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 	session := sessionFromContext(r.Context())
@@ -254,6 +267,8 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		HeartbeatInterval: 5,       // Matches host configs (5s heartbeat)
 		FleetTarget:       fleetTarget,
 		PendingPR:         pendingPR,
+		Username:          session.User.Username,
+		Role:              session.User.Role,
 	}
 
 	w.Header().Set("Content-Type", "text/html")
@@ -266,7 +281,8 @@ func (s *Server) getHosts() ([]templates.Host, error) {
 		SELECT id, hostname, host_type, agent_version, os_version, 
 		       nixpkgs_version, generation, last_seen, status, pending_command, 
 		       theme_color, metrics_json, location, device_type, test_progress,
-		       repo_url, repo_dir, lock_status_json, system_status_json
+		       repo_url, repo_dir, lock_status_json, system_status_json, disk_json,
+		       services_json, reboot_required, reboot_reason, targets_status_json
 		FROM hosts ORDER BY hostname
 	`)
 	if err != nil {
@@ -284,13 +300,16 @@ func (s *Server) getHosts() ([]templates.Host, error) {
 			PendingCommand, ThemeColor, MetricsJSON             *string
 			Location, DeviceType, TestProgressJSON              *string
 			RepoURL, RepoDir                                    *string
-			LockStatusJSON, SystemStatusJSON                    *string
+			LockStatusJSON, SystemStatusJSON, DiskJSON          *string
+			ServicesJSON, RebootReason, TargetsJSON             *string
+			RebootRequired                                      *bool
 		}
 		if err := rows.Scan(&h.ID, &h.Hostname, &h.HostType, &h.AgentVersion,
 			&h.OSVersion, &h.NixpkgsVersion, &h.Generation, &h.LastSeen,
 			&h.Status, &h.PendingCommand, &h.ThemeColor, &h.MetricsJSON,
 			&h.Location, &h.DeviceType, &h.TestProgressJSON,
-			&h.RepoURL, &h.RepoDir, &h.LockStatusJSON, &h.SystemStatusJSON); err != nil {
+			&h.RepoURL, &h.RepoDir, &h.LockStatusJSON, &h.SystemStatusJSON, &h.DiskJSON,
+			&h.ServicesJSON, &h.RebootRequired, &h.RebootReason, &h.TargetsJSON); err != nil {
 			s.log.Debug().Err(err).Msg("failed to scan host row")
 			continue
 		}
@@ -326,6 +345,14 @@ func (s *Server) getHosts() ([]templates.Host, error) {
 				host.Metrics = &metrics
 			}
 		}
+		host.Disk = parseDiskJSON(h.DiskJSON)
+		host.Services = parseServicesJSON(h.ServicesJSON)
+		if h.RebootRequired != nil && *h.RebootRequired {
+			host.RebootRequired = true
+			if h.RebootReason != nil {
+				host.RebootReason = *h.RebootReason
+			}
+		}
 		if h.Location != nil {
 			host.Location = *h.Location
 		} else {
@@ -365,7 +392,11 @@ func (s *Server) getHosts() ([]templates.Host, error) {
 		}
 
 		// Populate Update Status (P5000)
-		host.UpdateStatus = s.getUpdateStatus(host.Generation, host.RepoURL, host.RepoDir, lockStatus, systemStatus)
+		pin := loadGitPin(s.db, host.ID)
+		host.GitRef = pin.Ref
+		applyAgentCapabilities(s.db, &host)
+		host.UpdateStatus = s.getUpdateStatus(host.Generation, pin, host.RepoURL, host.RepoDir, lockStatus, systemStatus)
+		host.UpdateStatus.Targets = parseTargetsJSON(h.TargetsJSON)
 
 		// Check if agent version is outdated (compare with dashboard version)
 		host.ExpectedAgentVersion = Version
@@ -399,14 +430,19 @@ func (s *Server) calculateAvailableOps(host *templates.Host) []string {
 	// Standard ops available for all online, idle hosts
 	available = append(available, "pull", "switch", "test")
 
+	// Nix store garbage collection
+	available = append(available, "gc")
+
 	// Reboot requires TOTP (always show if online + idle)
 	available = append(available, "reboot")
 
-	return available
+	// Only what the agent can run
+	return supportedOps(available, ops.NewHostAdapter(host).SupportsCommand)
 }
 
-// getUpdateStatus returns the update status for a host based on its generation.
-func (s *Server) getUpdateStatus(generation, repoURL, repoDir string, lockStatus, systemStatus *templates.StatusCheck) *templates.UpdateStatus {
+// getUpdateStatus returns the update status for a host based on its generation
+// and git ref pin.
+func (s *Server) getUpdateStatus(generation string, pin GitPin, repoURL, repoDir string, lockStatus, systemStatus *templates.StatusCheck) *templates.UpdateStatus {
 	status := &templates.UpdateStatus{
 		RepoURL: repoURL,
 		RepoDir: repoDir,
@@ -414,7 +450,7 @@ func (s *Server) getUpdateStatus(generation, repoURL, repoDir string, lockStatus
 
 	// Git status (from GitHub Pages)
 	if s.versionFetcher != nil {
-		gitStatus, gitMsg, gitChecked := s.versionFetcher.GetGitStatus(generation)
+		gitStatus, gitMsg, gitChecked := s.versionFetcher.GetGitStatus(generation, pin)
 		status.Git = templates.StatusCheck{
 			Status:    gitStatus,
 			Message:   gitMsg,
@@ -494,17 +530,35 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		hub:        s.hub,
 		server:     s,
 	}
+	if clientType == "browser" {
+		client.output = make(chan []byte, outputQueueSize)
+		go client.outputPump()
+	}
 
 	s.hub.register <- client
 	go client.writePump()
 	go client.readPump()
 }
 
-// handleGetHosts returns the list of hosts.
+// handleGetHosts returns the list of hosts. An API token with a host
+// selector only sees the hosts it selects.
 func (s *Server) handleGetHosts(w http.ResponseWriter, r *http.Request) {
+	token := apiTokenFromContext(r.Context())
+	restricted := token != nil && token.HostSelector != ""
+	var allowed []string
+	if restricted {
+		var err error
+		if allowed, err = s.selectHosts(token.HostSelector, false); err != nil {
+			s.log.Error().Err(err).Str("token", token.Name).Msg("failed to resolve token host selector")
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	rows, err := s.db.Query(`
 		SELECT id, hostname, host_type, agent_version, os_version, 
-		       nixpkgs_version, generation, last_seen, status, pending_command, comment
+		       nixpkgs_version, generation, last_seen, status, pending_command, comment,
+		       darwin_mode
 		FROM hosts ORDER BY hostname
 	`)
 	if err != nil {
@@ -521,11 +575,14 @@ func (s *Server) handleGetHosts(w http.ResponseWriter, r *http.Request) {
 			AgentVersion, OSVersion, NixpkgsVersion, Generation                    *string
 			LastSeen                                                               *string
 			Status                                                                 string
-			PendingCommand, Comment                                                *string
+			PendingCommand, Comment, DarwinMode                                    *string
 		}
 		if err := rows.Scan(&h.ID, &h.Hostname, &h.HostType, &h.AgentVersion,
 			&h.OSVersion, &h.NixpkgsVersion, &h.Generation, &h.LastSeen,
-			&h.Status, &h.PendingCommand, &h.Comment); err != nil {
+			&h.Status, &h.PendingCommand, &h.Comment, &h.DarwinMode); err != nil {
+			continue
+		}
+		if restricted && !slices.Contains(allowed, h.ID) {
 			continue
 		}
 		hosts = append(hosts, map[string]any{
@@ -540,6 +597,7 @@ func (s *Server) handleGetHosts(w http.ResponseWriter, r *http.Request) {
 			"status":          h.Status,
 			"pending_command": h.PendingCommand,
 			"comment":         h.Comment,
+			"darwin_mode":     h.DarwinMode,
 		})
 	}
 
@@ -554,15 +612,22 @@ func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
 	hostID := chi.URLParam(r, "hostID")
 
 	var req struct {
-		Command string   `json:"command"`
-		Args    []string `json:"args,omitempty"`
-		Force   bool     `json:"force,omitempty"`
+		Command    string   `json:"command"`
+		Args       []string `json:"args,omitempty"`
+		Force      bool     `json:"force,omitempty"`
+		Target     string   `json:"target,omitempty"`     // deploy target, empty = all
+		Generation int      `json:"generation,omitempty"` // rollback to this generation
+		Ref        string   `json:"ref,omitempty"`        // pull/pull-switch: git ref to deploy and pin
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
 
+	if !s.allowOp(w, r, req.Command) {
+		return
+	}
+
 	// Get host info for validation
 	host, err := s.getHostByID(hostID)
 	if err != nil {
@@ -570,9 +635,15 @@ func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// v3: Delegate to Lifecycle Manager
-	hostAdapter := ops.NewHostAdapter(host)
-	cmd, err := s.lifecycleManager.ExecuteOp(req.Command, hostAdapter, req.Force)
+	// Validate against the ref being pinned, store it only once dispatched
+	args := ops.Args{Target: req.Target, Generation: req.Generation, Ref: req.Ref}
+	var cmd *ops.ActiveCommand
+	if err = checkGitRef(req.Command, args); err == nil {
+		withGitRef(host, args.Ref)
+
+		// v3: Delegate to Lifecycle Manager
+		cmd, err = s.lifecycleManager.ExecuteOpWithArgs(req.Command, ops.NewHostAdapter(host), req.Force, args)
+	}
 	if err != nil {
 		// Check if it's a validation error (blocked)
 		if verr, ok := err.(*ops.ValidationError); ok {
@@ -594,6 +665,8 @@ func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.pinGitRef(host.ID, args.Ref)
+
 	// Return response in legacy format for backward compatibility
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]any{
@@ -613,19 +686,23 @@ func (s *Server) getHostByID(hostID string) (*templates.Host, error) {
 		PendingCommand, ThemeColor                          *string
 		Location, DeviceType                                *string
 		LockStatusJSON, SystemStatusJSON                    *string
-		RepoURL, RepoDir                                    *string
+		RepoURL, RepoDir, DiskJSON, ServicesJSON            *string
+		RebootReason, TargetsJSON                           *string
+		RebootRequired                                      *bool
 	}
 
 	err := s.db.QueryRow(`
 		SELECT id, hostname, host_type, agent_version, os_version,
 		       nixpkgs_version, generation, last_seen, status, pending_command,
 		       theme_color, location, device_type, lock_status_json, system_status_json,
-		       repo_url, repo_dir
+		       repo_url, repo_dir, disk_json, services_json, reboot_required, reboot_reason,
+		       targets_status_json
 		FROM hosts WHERE id = ?
 	`, hostID).Scan(&h.ID, &h.Hostname, &h.HostType, &h.AgentVersion,
 		&h.OSVersion, &h.NixpkgsVersion, &h.Generation, &h.LastSeen,
 		&h.Status, &h.PendingCommand, &h.ThemeColor, &h.Location, &h.DeviceType,
-		&h.LockStatusJSON, &h.SystemStatusJSON, &h.RepoURL, &h.RepoDir)
+		&h.LockStatusJSON, &h.SystemStatusJSON, &h.RepoURL, &h.RepoDir, &h.DiskJSON, &h.ServicesJSON,
+		&h.RebootRequired, &h.RebootReason, &h.TargetsJSON)
 	if err != nil {
 		return nil, err
 	}
@@ -661,6 +738,14 @@ func (s *Server) getHostByID(hostID string) (*templates.Host, error) {
 	if h.RepoDir != nil {
 		host.RepoDir = *h.RepoDir
 	}
+	host.Disk = parseDiskJSON(h.DiskJSON)
+	host.Services = parseServicesJSON(h.ServicesJSON)
+	if h.RebootRequired != nil && *h.RebootRequired {
+		host.RebootRequired = true
+		if h.RebootReason != nil {
+			host.RebootReason = *h.RebootReason
+		}
+	}
 
 	// Parse lock and system status
 	var lockStatus, systemStatus *templates.StatusCheck
@@ -677,7 +762,11 @@ func (s *Server) getHostByID(hostID string) (*templates.Host, error) {
 		}
 	}
 
-	host.UpdateStatus = s.getUpdateStatus(host.Generation, host.RepoURL, host.RepoDir, lockStatus, systemStatus)
+	pin := loadGitPin(s.db, host.ID)
+	host.GitRef = pin.Ref
+	applyAgentCapabilities(s.db, host)
+	host.UpdateStatus = s.getUpdateStatus(host.Generation, pin, host.RepoURL, host.RepoDir, lockStatus, systemStatus)
+	host.UpdateStatus.Targets = parseTargetsJSON(h.TargetsJSON)
 	host.ExpectedAgentVersion = Version
 	if host.AgentVersion != "" && host.AgentVersion != Version {
 		host.AgentOutdated = true
@@ -799,6 +888,8 @@ func (s *Server) handleDeleteHost(w http.ResponseWriter, r *http.Request) {
 
 	// Delete command logs first (foreign key)
 	_, _ = s.db.Exec(`DELETE FROM command_logs WHERE host_id = ?`, hostID)
+	_, _ = s.db.Exec(`DELETE FROM test_results WHERE host_id = ?`, hostID)
+	_, _ = s.db.Exec(`DELETE FROM host_facts WHERE host_id = ?`, hostID)
 
 	// Delete the host
 	result, err := s.db.Exec(`DELETE FROM hosts WHERE id = ?`, hostID)
@@ -845,6 +936,7 @@ func (s *Server) handleRefreshHost(w http.ResponseWriter, r *http.Request) {
 		SystemStatusJSON *string
 		TestsStatusJSON  *string
 		TestsGeneration  *string
+		TargetsJSON      sql.NullString
 		RepoURL          *string
 		RepoDir          *string
 		Status           string
@@ -854,12 +946,12 @@ func (s *Server) handleRefreshHost(w http.ResponseWriter, r *http.Request) {
 	err := s.db.QueryRow(`
 		SELECT hostname, generation, agent_version, lock_status_json,
 		       system_status_json, tests_status_json, tests_generation,
-		       repo_url, repo_dir, status, pending_command
+		       targets_status_json, repo_url, repo_dir, status, pending_command
 		FROM hosts WHERE id = ?
 	`, hostID).Scan(
 		&h.Hostname, &h.Generation, &h.AgentVersion,
 		&h.LockStatusJSON, &h.SystemStatusJSON, &h.TestsStatusJSON, &h.TestsGeneration,
-		&h.RepoURL, &h.RepoDir, &h.Status, &h.PendingCommand,
+		&h.TargetsJSON, &h.RepoURL, &h.RepoDir, &h.Status, &h.PendingCommand,
 	)
 
 	// P1000-FIX: Clear stale pending_command if agent is online but no active command in lifecycle
@@ -890,7 +982,7 @@ func (s *Server) handleRefreshHost(w http.ResponseWriter, r *http.Request) {
 		generation = *h.Generation
 	}
 	if s.versionFetcher != nil {
-		gitStatus, gitMsg, gitChecked = s.versionFetcher.GetGitStatus(generation)
+		gitStatus, gitMsg, gitChecked = s.versionFetcher.GetGitStatus(generation, loadGitPin(s.db, hostID))
 	} else {
 		gitStatus, gitMsg, gitChecked = "error", "Version tracking not configured (remote desired state unavailable)", ""
 	}
@@ -941,6 +1033,7 @@ func (s *Server) handleRefreshHost(w http.ResponseWriter, r *http.Request) {
 			"lock":     lockStatus,
 			"system":   systemStatus,
 			"tests":    testsStatus,
+			"targets":  parseTargetStatuses(h.TargetsJSON),
 			"repo_url": repoURL,
 			"repo_dir": repoDir,
 		},
@@ -972,7 +1065,7 @@ func (s *Server) handleRefreshGit(w http.ResponseWriter, r *http.Request) {
 	if generation != nil {
 		gen = *generation
 	}
-	status, message, checkedAt := s.versionFetcher.GetGitStatus(gen)
+	status, message, checkedAt := s.versionFetcher.GetGitStatus(gen, loadGitPin(s.db, hostID))
 
 	s.log.Info().
 		Str("host_id", hostID).
@@ -1403,6 +1496,12 @@ func (s *Server) handleKillCommand(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Agents without kill support would ignore the signal
+	if _, caps := loadAgentCapabilities(s.db, hostID); !caps.HasFeature(protocol.FeatureKill) {
+		http.Error(w, "Agent too old to kill commands - update the agent", http.StatusConflict)
+		return
+	}
+
 	// v3: Use lifecycle manager for kill
 	if err := s.lifecycleManager.KillCommand(hostID, req.Signal, 0); err != nil {
 		http.Error(w, err.Error(), http.StatusConflict)
@@ -1503,7 +1602,8 @@ func (s *Server) handleReboot(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		TOTP string `json:"totp"`
+		TOTP     string             `json:"totp"`
+		WebAuthn *webauthnAssertion `json:"webauthn,omitempty"` // passkey instead of TOTP
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.Header().Set("Content-Type", "application/json")
@@ -1512,17 +1612,17 @@ func (s *Server) handleReboot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TOTP is mandatory for reboot
-	if !s.cfg.HasTOTP() {
-		s.log.Warn().Msg("reboot rejected: TOTP not configured")
+	// TOTP or a passkey is mandatory for reboot
+	if !s.hasStepUp(session.User) {
+		s.log.Warn().Msg("reboot rejected: neither TOTP nor a passkey configured")
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusForbidden)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "TOTP must be configured to use reboot feature"})
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "TOTP or a passkey must be configured to use reboot feature"})
 		return
 	}
 
-	// Validate TOTP
-	if req.TOTP == "" {
+	// Validate TOTP or passkey
+	if req.TOTP == "" && req.WebAuthn == nil {
 		s.log.Warn().Str("host", hostID).Msg("reboot rejected: missing TOTP")
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnauthorized)
@@ -1530,17 +1630,19 @@ func (s *Server) handleReboot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !s.auth.CheckTOTP(req.TOTP) {
+	stepUp := s.checkStepUp(session.User, req.TOTP, req.WebAuthn)
+	if stepUp == "" {
+		reason, msg := stepUpRefusal(req.WebAuthn)
 		s.log.Warn().
 			Str("host", hostID).
 			Str("session", session.ID).
 			Str("ip", ip).
-			Msg("reboot rejected: invalid TOTP")
-		s.auditLogReboot(hostID, session.ID, ip, false, "invalid_totp")
+			Msg("reboot rejected: " + reason)
+		s.auditLogReboot(hostID, session.ID, ip, false, reason, "")
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Invalid TOTP code"})
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
 		return
 	}
 
@@ -1551,7 +1653,7 @@ func (s *Server) handleReboot(w http.ResponseWriter, r *http.Request) {
 			Str("session", session.ID).
 			Str("ip", ip).
 			Msg("reboot rejected: rate limit exceeded")
-		s.auditLogReboot(hostID, session.ID, ip, false, "rate_limit")
+		s.auditLogReboot(hostID, session.ID, ip, false, "rate_limit", stepUp)
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusTooManyRequests)
@@ -1614,7 +1716,7 @@ func (s *Server) handleReboot(w http.ResponseWriter, r *http.Request) {
 		Str("session", session.ID).
 		Str("ip", ip).
 		Msg("reboot command sent")
-	s.auditLogReboot(hostID, session.ID, ip, true, "success")
+	s.auditLogReboot(hostID, session.ID, ip, true, "success", stepUp)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -1656,13 +1758,14 @@ func (s *Server) recordRebootAttempt(hostID string, success bool) error {
 	return err
 }
 
-// auditLogReboot logs reboot attempt to audit log.
-func (s *Server) auditLogReboot(hostID, sessionID, ip string, success bool, reason string) {
+// auditLogReboot logs reboot attempt to audit log, with the step-up method
+// (totp or webauthn) that confirmed it.
+func (s *Server) auditLogReboot(hostID, sessionID, ip string, success bool, reason, stepUp string) {
 	successInt := 0
 	if success {
 		successInt = 1
 	}
-	details := fmt.Sprintf(`{"reason": "%s", "ip": "%s"}`, reason, ip)
+	details := fmt.Sprintf(`{"reason": "%s", "ip": "%s", "step_up": "%s"}`, reason, ip, stepUp)
 	_, err := s.db.Exec(
 		`INSERT INTO audit_log (action, host_id, user_session, timestamp, success, details) VALUES (?, ?, ?, ?, ?, ?)`,
 		"reboot", hostID, sessionID, time.Now().Unix(), successInt, details,
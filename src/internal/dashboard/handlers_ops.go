@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/markus-barta/nixfleet/internal/ops"
@@ -20,10 +21,18 @@ import (
 // POST /api/dispatch
 func (s *Server) handleDispatchOp(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Op    string   `json:"op"`              // Op ID: "pull", "switch", "test", etc.
-		Hosts []string `json:"hosts"`           // Host IDs to execute on
-		Force bool     `json:"force,omitempty"` // Skip pre-validation
-		TOTP  string   `json:"totp,omitempty"`  // For ops requiring TOTP (reboot)
+		Op         string             `json:"op"`                   // Op ID: "pull", "switch", "test", etc.
+		Hosts      []string           `json:"hosts"`                // Host IDs to execute on
+		Selector   string             `json:"selector,omitempty"`   // Alternative to hosts: "reboot-required"
+		Force      bool               `json:"force,omitempty"`      // Skip pre-validation
+		TOTP       string             `json:"totp,omitempty"`       // For ops requiring TOTP (reboot, exec)
+		WebAuthn   *webauthnAssertion `json:"webauthn,omitempty"`   // Passkey instead of TOTP
+		Target     string             `json:"target,omitempty"`     // Deploy target for switch/rollback, empty = all
+		Generation int                `json:"generation,omitempty"` // rollback: this generation instead of the previous one
+		Ref        string             `json:"ref,omitempty"`        // pull/pull-switch: git ref to deploy and pin
+		Exec       string             `json:"exec,omitempty"`       // exec: command name in the agents' allowlist
+		Params     map[string]string  `json:"params,omitempty"`     // exec: values for the command's placeholders
+		Unit       string             `json:"unit,omitempty"`       // service-*: unit in the agents' allowlist
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -36,6 +45,15 @@ func (s *Server) handleDispatchOp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(req.Hosts) == 0 && req.Selector != "" {
+		hosts, err := s.resolveHostSelector(req.Selector)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Hosts = hosts
+	}
+
 	if len(req.Hosts) == 0 {
 		s.jsonError(w, "hosts is required", http.StatusBadRequest)
 		return
@@ -48,16 +66,33 @@ func (s *Server) handleDispatchOp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check TOTP for ops that require it
+	args := ops.Args{Target: req.Target, Generation: req.Generation, Ref: req.Ref, Exec: req.Exec, Params: req.Params, Unit: req.Unit}
+	audit := req.Op == "exec" // every exec request is recorded, refused or not
+	token := apiTokenFromContext(r.Context())
+
+	if !s.allowOp(w, r, req.Op) {
+		return
+	}
+
+	// Check TOTP or a passkey for ops that require it
 	if op.RequiresTotp {
-		if !s.cfg.HasTOTP() {
-			s.jsonError(w, "TOTP must be configured for this operation", http.StatusForbidden)
+		user := s.stepUpUser(r)
+		if !s.hasStepUp(user) {
+			s.jsonError(w, "TOTP or a passkey must be configured for this operation", http.StatusForbidden)
 			return
 		}
-		if req.TOTP == "" || !s.auth.CheckTOTP(req.TOTP) {
-			s.jsonError(w, "Invalid TOTP code", http.StatusUnauthorized)
+		stepUp := s.checkStepUp(user, req.TOTP, req.WebAuthn)
+		if stepUp == "" {
+			reason, msg := stepUpRefusal(req.WebAuthn)
+			if audit {
+				for _, hostID := range req.Hosts {
+					s.auditLogExec(r, hostID, args, "", "", reason)
+				}
+			}
+			s.jsonError(w, msg, http.StatusUnauthorized)
 			return
 		}
+		r = r.WithContext(withStepUp(r.Context(), stepUp))
 	}
 
 	// Execute on each host
@@ -65,6 +100,27 @@ func (s *Server) handleDispatchOp(w http.ResponseWriter, r *http.Request) {
 	var successCount, errorCount int
 
 	for _, hostID := range req.Hosts {
+		if !s.tokenAllowsHost(token, hostID) {
+			results = append(results, map[string]any{
+				"host_id": hostID,
+				"status":  "blocked",
+				"code":    "host_not_allowed",
+				"message": fmt.Sprintf("API token %s may not act on this host", token.Name),
+			})
+			errorCount++
+			continue
+		}
+
+		if err := checkGitRef(req.Op, args); err != nil {
+			results = append(results, map[string]any{
+				"host_id": hostID,
+				"status":  "error",
+				"error":   err.Error(),
+			})
+			errorCount++
+			continue
+		}
+
 		host, err := s.getHostByID(hostID)
 		if err != nil {
 			results = append(results, map[string]any{
@@ -76,11 +132,59 @@ func (s *Server) handleDispatchOp(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
+		// Resolve the command line from the host's allowlist for the audit log
+		var commandLine string
+		if audit {
+			_, caps := loadAgentCapabilities(s.db, host.ID)
+			line, verr := execCommandLine(caps, args)
+			if verr != nil {
+				s.auditLogExec(r, host.ID, args, "", "", verr.Code)
+				results = append(results, map[string]any{
+					"host_id": hostID,
+					"status":  "blocked",
+					"code":    verr.Code,
+					"message": verr.Message,
+				})
+				errorCount++
+				continue
+			}
+			commandLine = line
+		}
+		if ops.ServiceOps[req.Op] {
+			_, caps := loadAgentCapabilities(s.db, host.ID)
+			if verr := serviceUnitAllowed(caps, req.Op, args.Unit); verr != nil {
+				results = append(results, map[string]any{
+					"host_id": hostID,
+					"status":  "blocked",
+					"code":    verr.Code,
+					"message": verr.Message,
+				})
+				errorCount++
+				continue
+			}
+		}
+
+		// Validate against the ref being pinned, store it only once dispatched
+		withGitRef(host, args.Ref)
+
 		// Create host adapter for Op Engine
 		hostAdapter := ops.NewHostAdapter(host)
 
 		// Execute the op via lifecycle manager
-		cmd, err := s.lifecycleManager.ExecuteOp(req.Op, hostAdapter, req.Force)
+		cmd, err := s.lifecycleManager.ExecuteOpWithArgs(req.Op, hostAdapter, req.Force, args)
+		if audit {
+			result := "dispatched"
+			if verr, ok := err.(*ops.ValidationError); ok {
+				result = verr.Code
+			} else if err != nil {
+				result = "error"
+			}
+			commandID := ""
+			if cmd != nil {
+				commandID = cmd.ID
+			}
+			s.auditLogExec(r, host.ID, args, commandLine, commandID, result)
+		}
 		if err != nil {
 			// Check if it's a validation error (blocked)
 			if verr, ok := err.(*ops.ValidationError); ok {
@@ -100,11 +204,19 @@ func (s *Server) handleDispatchOp(w http.ResponseWriter, r *http.Request) {
 			errorCount++
 			continue
 		}
+		s.pinGitRef(host.ID, args.Ref)
+
+		details := map[string]any{"command_id": cmd.ID}
+		if stepUp := stepUpFromContext(r.Context()); stepUp != "" {
+			details["step_up"] = stepUp
+		}
+		s.stateStore.LogEvent("audit", "info", requestActor(r), host.ID, "op:"+req.Op, "Dispatched "+req.Op, details)
 
 		results = append(results, map[string]any{
 			"host_id":    hostID,
 			"status":     string(cmd.Status),
 			"command_id": cmd.ID,
+			"args":       cmd.Args,
 		})
 		if cmd.Status == ops.StatusExecuting || cmd.Status == ops.StatusPending {
 			successCount++
@@ -142,9 +254,12 @@ func (s *Server) handleDispatchOp(w http.ResponseWriter, r *http.Request) {
 // POST /api/dispatch/pipeline
 func (s *Server) handleDispatchPipeline(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Pipeline string   `json:"pipeline"`       // Pipeline ID: "do-all", "merge-deploy"
-		Hosts    []string `json:"hosts"`          // Host IDs to execute on
-		TOTP     string   `json:"totp,omitempty"` // For pipelines with TOTP ops
+		Pipeline string             `json:"pipeline"`           // Pipeline ID: "do-all", "merge-deploy"
+		Hosts    []string           `json:"hosts"`              // Host IDs to execute on
+		Selector string             `json:"selector,omitempty"` // Alternative to hosts: "reboot-required"
+		TOTP     string             `json:"totp,omitempty"`     // For pipelines with TOTP ops
+		WebAuthn *webauthnAssertion `json:"webauthn,omitempty"` // Passkey instead of TOTP
+		Unit     string             `json:"unit,omitempty"`     // For pipelines with service ops
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -157,6 +272,15 @@ func (s *Server) handleDispatchPipeline(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if len(req.Hosts) == 0 && req.Selector != "" {
+		hosts, err := s.resolveHostSelector(req.Selector)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Hosts = hosts
+	}
+
 	if len(req.Hosts) == 0 {
 		s.jsonError(w, "hosts is required", http.StatusBadRequest)
 		return
@@ -169,37 +293,85 @@ func (s *Server) handleDispatchPipeline(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check if any op in the pipeline requires TOTP
+	for _, opID := range pipeline.Ops {
+		if !s.allowOp(w, r, opID) {
+			return
+		}
+	}
+
+	// Check if any op in the pipeline requires TOTP (or a passkey)
+	stepUp := ""
 	for _, opID := range pipeline.Ops {
 		op := s.opRegistry.Get(opID)
 		if op != nil && op.RequiresTotp {
-			if !s.cfg.HasTOTP() {
-				s.jsonError(w, "TOTP must be configured for this pipeline", http.StatusForbidden)
+			user := s.stepUpUser(r)
+			if !s.hasStepUp(user) {
+				s.jsonError(w, "TOTP or a passkey must be configured for this pipeline", http.StatusForbidden)
 				return
 			}
-			if req.TOTP == "" || !s.auth.CheckTOTP(req.TOTP) {
-				s.jsonError(w, "Invalid TOTP code", http.StatusUnauthorized)
+			if stepUp = s.checkStepUp(user, req.TOTP, req.WebAuthn); stepUp == "" {
+				_, msg := stepUpRefusal(req.WebAuthn)
+				s.jsonError(w, msg, http.StatusUnauthorized)
 				return
 			}
 			break
 		}
 	}
 
+	// Service ops need a unit every host allows; checked up front so a
+	// refusal does not surface only after the deploy stage
+	args := ops.Args{Unit: req.Unit}
+	serviceOp := ""
+	for _, opID := range pipeline.Ops {
+		if ops.ServiceOps[opID] {
+			serviceOp = opID
+			break
+		}
+	}
+	if serviceOp == "" && req.Unit != "" {
+		s.jsonError(w, fmt.Sprintf("pipeline %s does not take a unit", req.Pipeline), http.StatusBadRequest)
+		return
+	}
+	if serviceOp != "" {
+		if verr := ops.ValidateArgs(serviceOp, args); verr != nil {
+			s.jsonError(w, verr.Message, http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Build host list
+	token := apiTokenFromContext(r.Context())
 	hosts := make([]ops.Host, 0, len(req.Hosts))
 	for _, hostID := range req.Hosts {
+		if !s.tokenAllowsHost(token, hostID) {
+			s.jsonError(w, fmt.Sprintf("API token %s may not act on host %s", token.Name, hostID), http.StatusForbidden)
+			return
+		}
 		host, err := s.getHostByID(hostID)
 		if err != nil {
 			s.jsonError(w, fmt.Sprintf("host not found: %s", hostID), http.StatusNotFound)
 			return
 		}
+		if serviceOp != "" {
+			_, caps := loadAgentCapabilities(s.db, host.ID)
+			if verr := serviceUnitAllowed(caps, serviceOp, args.Unit); verr != nil {
+				s.jsonError(w, host.Hostname+": "+verr.Message, http.StatusBadRequest)
+				return
+			}
+		}
 		hosts = append(hosts, ops.NewHostAdapter(host))
 	}
 
+	if stepUp != "" {
+		s.stateStore.LogEvent("audit", "info", requestActor(r), "", "pipeline:"+req.Pipeline,
+			"Started "+req.Pipeline, map[string]any{"hosts": req.Hosts, "step_up": stepUp})
+	}
+
 	// Execute pipeline (async - returns immediately, completion comes via WebSocket)
 	// Use background context since HTTP request context is canceled after response
+	ctx := ops.WithActor(context.Background(), requestActor(r))
 	go func() {
-		record, err := s.pipelineExecutor.Execute(context.Background(), req.Pipeline, hosts)
+		record, err := s.pipelineExecutor.Execute(ctx, req.Pipeline, hosts, args)
 		if err != nil {
 			s.log.Error().Err(err).Str("pipeline", req.Pipeline).Msg("pipeline execution failed")
 		} else {
@@ -218,6 +390,103 @@ func (s *Server) handleDispatchPipeline(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// resolveHostSelector expands a named host group into host IDs, so callers
+// can target e.g. "every host that needs a reboot" without mirroring state.
+// "facts:..." selects by hardware and OS facts, see parseFactsSelector, and
+// "hosts:a,b" the listed hosts. Only online hosts are selected.
+func (s *Server) resolveHostSelector(selector string) ([]string, error) {
+	hostIDs, err := s.selectHosts(selector, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(hostIDs) == 0 {
+		return nil, fmt.Errorf("no online hosts match selector: %s", selector)
+	}
+	return hostIDs, nil
+}
+
+// selectHosts returns the IDs of the hosts a selector matches, only the
+// online ones if onlineOnly.
+func (s *Server) selectHosts(selector string, onlineOnly bool) ([]string, error) {
+	if conds, ok := strings.CutPrefix(selector, factsSelectorPrefix); ok {
+		return s.selectHostsByFacts(conds, onlineOnly)
+	}
+
+	var query string
+	var args []any
+	if list, ok := strings.CutPrefix(selector, hostsSelectorPrefix); ok {
+		names := parseHostList(list)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("empty hosts selector")
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(names)), ",")
+		query = `SELECT id FROM hosts WHERE (id IN (` + placeholders + `) OR hostname IN (` + placeholders + `))
+			AND (status = 'online' OR NOT ?) ORDER BY hostname`
+		for range 2 {
+			for _, name := range names {
+				args = append(args, name)
+			}
+		}
+		args = append(args, onlineOnly)
+	} else {
+		switch selector {
+		case "reboot-required":
+			query = `SELECT id FROM hosts WHERE reboot_required = 1 AND (status = 'online' OR NOT ?) ORDER BY hostname`
+			args = append(args, onlineOnly)
+		default:
+			return nil, fmt.Errorf("unknown selector: %s", selector)
+		}
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var hostIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		hostIDs = append(hostIDs, id)
+	}
+	return hostIDs, rows.Err()
+}
+
+// hostsSelectorPrefix starts a host selector that lists hosts by name.
+const hostsSelectorPrefix = "hosts:"
+
+func parseHostList(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// validateHostSelector checks a selector's syntax without resolving it, for
+// selectors stored to be resolved later (API token restrictions).
+func validateHostSelector(selector string) error {
+	if conds, ok := strings.CutPrefix(selector, factsSelectorPrefix); ok {
+		_, err := parseFactsSelector(conds)
+		return err
+	}
+	if list, ok := strings.CutPrefix(selector, hostsSelectorPrefix); ok {
+		if len(parseHostList(list)) == 0 {
+			return fmt.Errorf("empty hosts selector")
+		}
+		return nil
+	}
+	if selector != "reboot-required" {
+		return fmt.Errorf("unknown selector: %s", selector)
+	}
+	return nil
+}
+
 // handleGetOps returns the list of available ops.
 // GET /api/ops
 func (s *Server) handleGetOps(w http.ResponseWriter, r *http.Request) {
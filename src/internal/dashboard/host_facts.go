@@ -0,0 +1,276 @@
+package dashboard
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// HOST FACTS - hardware and OS inventory, and selectors over it
+// ═══════════════════════════════════════════════════════════════════════════
+
+// HostFactsResponse is the body of GET /api/hosts/{hostID}/facts.
+type HostFactsResponse struct {
+	HostID    string              `json:"host_id"`
+	Facts     *protocol.HostFacts `json:"facts"`
+	UpdatedAt string              `json:"updated_at"` // when the agent last sent changed facts
+}
+
+// storeHostFacts saves the facts an agent sent at registration or with a
+// heartbeat (only when they changed).
+func storeHostFacts(db *sql.DB, hostID string, facts *protocol.HostFacts) error {
+	data, err := json.Marshal(facts)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		INSERT INTO host_facts (host_id, facts_json, updated_at) VALUES (?, ?, datetime('now'))
+		ON CONFLICT(host_id) DO UPDATE SET facts_json = excluded.facts_json, updated_at = excluded.updated_at
+	`, hostID, string(data))
+	return err
+}
+
+// loadHostFacts returns a host's facts, nil if its agent never sent any.
+func loadHostFacts(db *sql.DB, hostID string) (*HostFactsResponse, error) {
+	var resp HostFactsResponse
+	var raw string
+	err := db.QueryRow(`
+		SELECT f.host_id, f.facts_json, f.updated_at FROM host_facts f
+		JOIN hosts h ON h.id = f.host_id
+		WHERE h.id = ? OR h.hostname = ?
+	`, hostID, hostID).Scan(&resp.HostID, &raw, &resp.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(raw), &resp.Facts); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// handleGetHostFacts returns a host's hardware and OS inventory.
+// GET /api/hosts/{hostID}/facts
+func (s *Server) handleGetHostFacts(w http.ResponseWriter, r *http.Request) {
+	hostID := chi.URLParam(r, "hostID")
+
+	resp, err := loadHostFacts(s.db, hostID)
+	if err != nil {
+		s.log.Error().Err(err).Str("host", hostID).Msg("failed to load host facts")
+		s.jsonError(w, "Failed to load host facts", http.StatusInternalServerError)
+		return
+	}
+	if resp == nil {
+		s.jsonError(w, "No facts for this host", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// FACT SELECTORS
+// ═══════════════════════════════════════════════════════════════════════════
+
+// factsSelectorPrefix starts a host selector over facts, e.g.
+// "facts:system=aarch64-linux,memory_gb>=16". All conditions must match.
+const factsSelectorPrefix = "facts:"
+
+// factCondition is one condition of a facts selector. Text facts compare
+// with "=" or "!=" against a glob (case-insensitive), numeric facts with
+// =, !=, <, <=, > or >=.
+type factCondition struct {
+	key, op, value string
+}
+
+// textFacts and numericFacts are the facts a selector can test.
+var textFacts = map[string]func(*protocol.HostFacts) []string{
+	"system":         func(f *protocol.HostFacts) []string { return []string{f.System} },
+	"arch":           func(f *protocol.HostFacts) []string { return []string{f.Arch} },
+	"kernel":         func(f *protocol.HostFacts) []string { return []string{f.Kernel} },
+	"cpu_model":      func(f *protocol.HostFacts) []string { return []string{f.CPUModel} },
+	"virtualization": func(f *protocol.HostFacts) []string { return []string{f.Virtualization} },
+	"vendor":         func(f *protocol.HostFacts) []string { return []string{f.Vendor} },
+	"model":          func(f *protocol.HostFacts) []string { return []string{f.Model} },
+	"serial_number":  func(f *protocol.HostFacts) []string { return []string{f.SerialNumber} },
+	"fs_type":        diskFSTypes,
+	"interface":      func(f *protocol.HostFacts) []string { return interfaceFactValues(f, false) },
+	"address":        func(f *protocol.HostFacts) []string { return interfaceFactValues(f, true) },
+}
+
+var numericFacts = map[string]func(*protocol.HostFacts) float64{
+	"cpu_cores": func(f *protocol.HostFacts) float64 { return float64(f.CPUCores) },
+	"memory_gb": func(f *protocol.HostFacts) float64 { return float64(f.MemoryBytes) / (1 << 30) },
+	"disk_gb": func(f *protocol.HostFacts) float64 {
+		var total int64
+		for _, d := range f.Disks {
+			total += d.TotalBytes
+		}
+		return float64(total) / (1 << 30)
+	},
+}
+
+func diskFSTypes(f *protocol.HostFacts) []string {
+	values := make([]string, 0, len(f.Disks))
+	for _, d := range f.Disks {
+		values = append(values, d.FSType)
+	}
+	return values
+}
+
+// interfaceFactValues returns the interface names, or their addresses
+// without the prefix length.
+func interfaceFactValues(f *protocol.HostFacts, addresses bool) []string {
+	var values []string
+	for _, iface := range f.Interfaces {
+		if !addresses {
+			values = append(values, iface.Name)
+			continue
+		}
+		for _, addr := range iface.Addresses {
+			ip, _, _ := strings.Cut(addr, "/")
+			values = append(values, ip)
+		}
+	}
+	return values
+}
+
+// parseFactsSelector parses the conditions after "facts:".
+func parseFactsSelector(selector string) ([]factCondition, error) {
+	var conds []factCondition
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		// The operator is the first of <>!= in the condition, "<=" over "<"
+		i := strings.IndexAny(part, "<>!=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid fact condition %q (want key=value)", part)
+		}
+		op := part[i : i+1]
+		if strings.HasPrefix(part[i+1:], "=") {
+			op = part[i : i+2]
+		}
+		switch op {
+		case "=", "!=", "<", "<=", ">", ">=":
+		default:
+			return nil, fmt.Errorf("invalid operator %q in fact condition %q", op, part)
+		}
+		cond := factCondition{key: strings.TrimSpace(part[:i]), op: op, value: strings.TrimSpace(part[i+len(op):])}
+		if _, ok := numericFacts[cond.key]; ok {
+			if _, err := strconv.ParseFloat(cond.value, 64); err != nil {
+				return nil, fmt.Errorf("fact %s needs a number, got %q", cond.key, cond.value)
+			}
+		} else if _, ok := textFacts[cond.key]; ok {
+			if cond.op != "=" && cond.op != "!=" {
+				return nil, fmt.Errorf("fact %s only supports = and !=", cond.key)
+			}
+			if _, err := path.Match(strings.ToLower(cond.value), ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q for fact %s", cond.value, cond.key)
+			}
+		} else {
+			return nil, fmt.Errorf("unknown fact %q", cond.key)
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return nil, fmt.Errorf("empty facts selector")
+	}
+	return conds, nil
+}
+
+// matches reports whether the facts satisfy the condition. A list fact
+// (fs_type, interface, address) matches "=" if any value does, and "!="
+// if none does.
+func (c factCondition) matches(f *protocol.HostFacts) bool {
+	if get, ok := numericFacts[c.key]; ok {
+		want, _ := strconv.ParseFloat(c.value, 64)
+		got := get(f)
+		switch c.op {
+		case "=":
+			return got == want
+		case "!=":
+			return got != want
+		case "<":
+			return got < want
+		case "<=":
+			return got <= want
+		case ">":
+			return got > want
+		case ">=":
+			return got >= want
+		}
+		return false
+	}
+
+	pattern := strings.ToLower(c.value)
+	matched := false
+	for _, v := range textFacts[c.key](f) {
+		if ok, _ := path.Match(pattern, strings.ToLower(v)); ok {
+			matched = true
+			break
+		}
+	}
+	if c.op == "!=" {
+		return !matched
+	}
+	return matched
+}
+
+// selectHostsByFacts returns the hosts (only online ones if onlineOnly)
+// whose facts match all conditions. Hosts without facts (older agents)
+// never match.
+func (s *Server) selectHostsByFacts(selector string, onlineOnly bool) ([]string, error) {
+	conds, err := parseFactsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT h.id, f.facts_json FROM hosts h
+		JOIN host_facts f ON f.host_id = h.id
+		WHERE h.status = 'online' OR NOT ?
+		ORDER BY h.hostname
+	`, onlineOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var hostIDs []string
+	for rows.Next() {
+		var id, raw string
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, err
+		}
+		var facts protocol.HostFacts
+		if err := json.Unmarshal([]byte(raw), &facts); err != nil {
+			s.log.Warn().Err(err).Str("host", id).Msg("unreadable host facts")
+			continue
+		}
+		match := true
+		for _, c := range conds {
+			if !c.matches(&facts) {
+				match = false
+				break
+			}
+		}
+		if match {
+			hostIDs = append(hostIDs, id)
+		}
+	}
+	return hostIDs, rows.Err()
+}
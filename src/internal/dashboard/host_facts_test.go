@@ -0,0 +1,60 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+func TestFactsSelector(t *testing.T) {
+	facts := &protocol.HostFacts{
+		CPUModel:       "AMD Ryzen 9 5950X 16-Core Processor",
+		CPUCores:       32,
+		MemoryBytes:    64 << 30,
+		Arch:           "x86_64",
+		System:         "x86_64-linux",
+		Virtualization: "",
+		Disks:          []protocol.DiskFact{{Mount: "/", FSType: "zfs", TotalBytes: 1 << 40}},
+		Interfaces: []protocol.InterfaceFact{
+			{Name: "enp5s0", Addresses: []string{"192.168.1.10/24", "fe80::1/64"}},
+		},
+	}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"system=x86_64-linux", true},
+		{"system=aarch64-*", false},
+		{"cpu_model=*ryzen*,memory_gb>=32", true},
+		{"memory_gb>64", false},
+		{"cpu_cores=32", true},
+		{"disk_gb>=1000", true},
+		{"fs_type=zfs", true},
+		{"fs_type!=btrfs", true},
+		{"address=192.168.1.*", true},
+		{"address=10.*", false},
+		{"interface=enp*,virtualization=", true},
+		{"virtualization!=", false},
+	}
+	for _, tt := range tests {
+		conds, err := parseFactsSelector(tt.selector)
+		if err != nil {
+			t.Errorf("%s: %v", tt.selector, err)
+			continue
+		}
+		got := true
+		for _, c := range conds {
+			got = got && c.matches(facts)
+		}
+		if got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.selector, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "system", "ram>=8", "memory_gb>=lots", "system>=x86", "cpu_cores==4", "=x86"} {
+		if _, err := parseFactsSelector(bad); err == nil {
+			t.Errorf("%q: want error", bad)
+		}
+	}
+}
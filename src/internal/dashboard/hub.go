@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"runtime/debug"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -35,6 +36,12 @@ const (
 	// Broadcast queue size - large enough to buffer bursts
 	broadcastQueueSize = 1024
 
+	// Backpressure for command output: each browser gets a queue of this
+	// many messages in front of its send buffer, and is disconnected when
+	// the buffer stays full for outputSendWait.
+	outputQueueSize = 1024
+	outputSendWait  = 2 * time.Second
+
 	// Panic recovery delay before restarting
 	panicRecoveryDelay = 100 * time.Millisecond
 )
@@ -48,8 +55,8 @@ type flakeUpdateGetter interface {
 // lifecycleManagerInterface is the subset of ops.LifecycleManager used by Hub.
 // Defined as interface to avoid import cycle.
 type lifecycleManagerInterface interface {
-	HandleCommandComplete(hostID, opID string, exitCode int, message string) (interface{}, error)
-	HandleCommandRejected(hostID, reason, currentCommand string, currentPID int)
+	HandleCommandComplete(hostID, commandID, opID string, exitCode int, message string) (interface{}, error)
+	HandleCommandRejected(hostID, commandID, reason, currentCommand string, currentPID int)
 	HandleHeartbeat(hostID string, freshness interface{})
 	HandleAgentReconnect(hostID string, freshness interface{})
 	// P1100: Check if host has an active command in lifecycle manager
@@ -71,6 +78,13 @@ type Client struct {
 	// Safe close handling - prevents send-on-closed-channel panics
 	closeOnce sync.Once
 	closed    atomic.Bool
+
+	// Messages dropped because the send buffer was full
+	dropped atomic.Int64
+
+	// Browsers only: command output waiting for room in send, so a browser
+	// that stops reading holds up its own output and nobody else's
+	output chan []byte
 }
 
 // Send implements sync.ClientSender (CORE-004).
@@ -83,8 +97,14 @@ func (c *Client) Send(data []byte) error {
 }
 
 // SafeSend sends data to the client without panicking on closed channel.
-// Returns true if sent successfully, false if channel closed or buffer full.
-func (c *Client) SafeSend(data []byte) (sent bool) {
+// Returns true if sent successfully, false if channel closed or buffer full
+// (see overflow).
+func (c *Client) SafeSend(data []byte) bool {
+	return c.sendWithin(data, 0)
+}
+
+// sendWithin is SafeSend, waiting up to wait for room in a full buffer.
+func (c *Client) sendWithin(data []byte, wait time.Duration) (sent bool) {
 	// Recover from send-on-closed-channel panic
 	// This is necessary because there's a race between checking c.closed
 	// and actually sending - Close() could run between those two operations.
@@ -101,17 +121,82 @@ func (c *Client) SafeSend(data []byte) (sent bool) {
 	case c.send <- data:
 		return true
 	default:
-		// Buffer full, drop message
+	}
+	if wait <= 0 {
+		return c.overflow()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case c.send <- data:
+		return true
+	case <-timer.C:
+		return c.overflow()
+	}
+}
+
+// queueOutput queues command output for the client's outputPump. While
+// the queue is full the output is dropped: the pump is stuck on a browser
+// that stopped reading, and disconnects it once outputSendWait runs out.
+func (c *Client) queueOutput(data []byte) (queued bool) {
+	if c.output == nil {
+		return c.SafeSend(data)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			queued = false
+		}
+	}()
+
+	if c.closed.Load() {
+		return false
+	}
+	select {
+	case c.output <- data:
+		return true
+	default:
+		c.dropped.Add(1)
 		return false
 	}
 }
 
+// outputPump moves queued command output into the send buffer, waiting up
+// to outputSendWait for room. It returns once the client is closed.
+func (c *Client) outputPump() {
+	for data := range c.output {
+		c.sendWithin(data, outputSendWait)
+	}
+}
+
+// overflow handles a message that did not fit into the full send buffer:
+// the client does not keep up. It is dropped with a warning, and a browser
+// is disconnected so it reconnects and resyncs its state instead of
+// silently missing updates.
+func (c *Client) overflow() bool {
+	dropped := c.dropped.Add(1)
+	if c.hub != nil {
+		c.hub.log.Warn().
+			Str("type", c.clientType).
+			Str("id", c.clientID).
+			Int64("dropped", dropped).
+			Msg("send buffer full, dropping message")
+	}
+	if c.clientType == "browser" {
+		c.Close()
+	}
+	return false
+}
+
 // Close safely closes the send channel exactly once.
 // Uses sync.Once to prevent double-close panics.
 func (c *Client) Close() {
 	c.closeOnce.Do(func() {
 		c.closed.Store(true)
 		close(c.send)
+		if c.output != nil {
+			close(c.output)
+		}
 	})
 }
 
@@ -123,6 +208,7 @@ type Hub struct {
 	versionFetcher *VersionFetcher         // For Git status in heartbeat broadcasts
 	flakeUpdates   flakeUpdateGetter       // For PR status on browser connect (P5300)
 	stateManager   *syncproto.StateManager // CORE-004: browser state sync (optional)
+	events         ops.EventLogger         // CORE-003: event_log for host-level transitions (optional)
 
 	// Registered clients
 	clients map[*Client]bool
@@ -141,7 +227,7 @@ type Hub struct {
 	agentMessages chan *agentMessage
 
 	// Async broadcast queue - decouples state changes from notifications
-	broadcasts chan []byte
+	broadcasts chan broadcast
 
 	// Log storage for command output
 	logStore *LogStore
@@ -161,6 +247,10 @@ type Hub struct {
 	completionSubs   map[string][]chan CommandCompletion
 	completionSubsMu sync.Mutex
 
+	// Log streams by stream ID (see log_streams.go)
+	logStreams   map[string]*logStreamSession
+	logStreamsMu sync.Mutex
+
 	// P1110: Track last status update time for stale state detection
 	// Maps hostID -> map[status_field]lastUpdateTime
 	lastStatusUpdates map[string]map[string]time.Time
@@ -195,8 +285,9 @@ func NewHub(log zerolog.Logger, db *sql.DB, cfg *Config, vf *VersionFetcher) *Hu
 		register:          make(chan *Client),
 		unregister:        make(chan *Client),
 		agentMessages:     make(chan *agentMessage, 256),
-		broadcasts:        make(chan []byte, broadcastQueueSize),
+		broadcasts:        make(chan broadcast, broadcastQueueSize),
 		completionSubs:    make(map[string][]chan CommandCompletion),
+		logStreams:        make(map[string]*logStreamSession),
 		agentFreshness:    make(map[string]ops.AgentFreshness), // P2810
 		hostIDByHostname:  make(map[string]string),
 		lastStatusUpdates: make(map[string]map[string]time.Time), // P1110
@@ -251,6 +342,12 @@ func (h *Hub) SetStateManager(sm *syncproto.StateManager) {
 	h.stateManager = sm
 }
 
+// SetEventLogger wires the event log used for heartbeat-derived events
+// (e.g. systemd units starting or stopping to fail).
+func (h *Hub) SetEventLogger(events ops.EventLogger) {
+	h.events = events
+}
+
 // GetAgentFreshness returns the last known freshness data for an agent (P2810).
 func (h *Hub) GetAgentFreshness(hostID string) *ops.AgentFreshness {
 	h.mu.RLock()
@@ -365,6 +462,9 @@ func (h *Hub) handleUnregister(client *Client) {
 		// Close channel safely (uses sync.Once)
 		client.Close()
 	}
+	if wasKnown && (client.clientType == "browser" || hostID != "") {
+		h.endLogStreams(client)
+	}
 
 	if hostID != "" {
 		key := h.hostKey(hostID)
@@ -413,6 +513,23 @@ func (h *Hub) handleUnregister(client *Client) {
 		Msg("client unregistered")
 }
 
+// closeBrowserSessions disconnects the browsers connected on the given
+// sessions; their readPump then unregisters them.
+func (h *Hub) closeBrowserSessions(sessionIDs []string) {
+	h.mu.RLock()
+	var clients []*Client
+	for client := range h.browsers {
+		if slices.Contains(sessionIDs, client.clientID) {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		client.Close()
+	}
+}
+
 // broadcastLoop runs in a separate goroutine and handles all browser broadcasts.
 // This decouples broadcasts from the main hub loop, preventing blocking.
 func (h *Hub) broadcastLoop(ctx context.Context) {
@@ -433,14 +550,20 @@ func (h *Hub) broadcastLoop(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case data := <-h.broadcasts:
-			h.doBroadcast(data)
+		case b := <-h.broadcasts:
+			h.doBroadcast(b)
 		}
 	}
 }
 
-// doBroadcast sends data to all connected browsers.
-func (h *Hub) doBroadcast(data []byte) {
+// broadcast is a message queued for all browsers.
+type broadcast struct {
+	data   []byte
+	output bool // command output: goes through each browser's output queue
+}
+
+// doBroadcast sends a message to all connected browsers.
+func (h *Hub) doBroadcast(b broadcast) {
 	h.mu.RLock()
 	browsers := make([]*Client, 0, len(h.browsers))
 	for client := range h.browsers {
@@ -449,7 +572,11 @@ func (h *Hub) doBroadcast(data []byte) {
 	h.mu.RUnlock()
 
 	for _, client := range browsers {
-		client.SafeSend(data) // Never panics
+		if b.output {
+			client.queueOutput(b.data) // Never blocks
+		} else {
+			client.SafeSend(b.data) // Never panics
+		}
 	}
 }
 
@@ -595,13 +722,30 @@ func (h *Hub) queueBroadcast(msg map[string]any) {
 	}
 
 	select {
-	case h.broadcasts <- data:
+	case h.broadcasts <- broadcast{data: data}:
 		// Queued successfully
 	default:
 		h.log.Warn().Msg("broadcast queue full, dropping message")
 	}
 }
 
+// queueOutput queues command output for browsers. Backpressure is applied
+// per browser (see Client.outputPump), so this never waits: the hub loop
+// keeps handling agent messages while a browser is slow.
+func (h *Hub) queueOutput(msg map[string]any) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		h.log.Error().Err(err).Msg("failed to marshal output message")
+		return
+	}
+
+	select {
+	case h.broadcasts <- broadcast{data: data, output: true}:
+	default:
+		h.log.Warn().Msg("broadcast queue full, dropping command output")
+	}
+}
+
 // BroadcastToBrowsers queues a message for broadcast to all connected browsers.
 // This is the public API - internally uses the async queue.
 func (h *Hub) BroadcastToBrowsers(msg map[string]any) {
@@ -620,6 +764,7 @@ func (h *Hub) BroadcastHostStatus(hostID string) {
 		SystemStatusJSON *string
 		TestsStatusJSON  *string
 		TestsGeneration  *string
+		TargetsJSON      sql.NullString
 		RepoURL          *string
 		RepoDir          *string
 		Status           string
@@ -628,13 +773,13 @@ func (h *Hub) BroadcastHostStatus(hostID string) {
 	err := h.db.QueryRow(`
 		SELECT hostname, generation, agent_version, lock_status_json,
 		       system_status_json, tests_status_json, tests_generation,
-		       repo_url, repo_dir, status
+		       targets_status_json, repo_url, repo_dir, status
 		FROM hosts WHERE id = ? OR hostname = ?
 	`, hostID, hostID).Scan(
 		&host.Hostname, &host.Generation, &host.AgentVersion,
 		&host.LockStatusJSON, &host.SystemStatusJSON,
 		&host.TestsStatusJSON, &host.TestsGeneration,
-		&host.RepoURL, &host.RepoDir, &host.Status,
+		&host.TargetsJSON, &host.RepoURL, &host.RepoDir, &host.Status,
 	)
 	if err != nil {
 		h.log.Debug().Err(err).Str("host", hostID).Msg("BroadcastHostStatus: host not found")
@@ -660,7 +805,7 @@ func (h *Hub) BroadcastHostStatus(hostID string) {
 		generation = *host.Generation
 	}
 	if h.versionFetcher != nil {
-		status, msg, checked := h.versionFetcher.GetGitStatus(generation)
+		status, msg, checked := h.versionFetcher.GetGitStatus(generation, loadGitPin(h.db, hostID))
 		gitStatus = map[string]any{"status": status, "message": msg, "checked_at": checked}
 		// P7100: Debug logging
 		h.log.Debug().
@@ -695,6 +840,9 @@ func (h *Hub) BroadcastHostStatus(hostID string) {
 		"repo_url": repoURL,
 		"repo_dir": repoDir,
 	}
+	if targets := parseTargetStatuses(host.TargetsJSON); len(targets) > 0 {
+		updateStatus["targets"] = targets
+	}
 
 	// CORE-006: Tests are generation-scoped (old-generation pass => 🟡 on new deployment)
 	testsGen := ""
@@ -783,20 +931,28 @@ func (h *Hub) handleAgentMessage(msg *agentMessage) {
 
 		// Log to file
 		if h.logStore != nil {
-			_ = h.logStore.AppendLine(msg.client.clientID, payload.Command, payload.Line, payload.IsError)
+			_ = h.logStore.AppendLine(msg.client.clientID, payload.CommandID, payload.Command, payload.Line, payload.IsError)
 		}
 
 		// Forward to browsers
-		h.BroadcastToBrowsers(map[string]any{
+		h.queueOutput(map[string]any{
 			"type": "command_output",
 			"payload": map[string]any{
-				"host_id":  msg.client.clientID,
-				"line":     payload.Line,
-				"command":  payload.Command,
-				"is_error": payload.IsError,
+				"host_id":    msg.client.clientID,
+				"line":       payload.Line,
+				"command":    payload.Command,
+				"command_id": payload.CommandID,
+				"is_error":   payload.IsError,
 			},
 		})
 
+	case protocol.TypeOutputBatch:
+		var payload protocol.OutputBatchPayload
+		if err := msg.message.ParsePayload(&payload); err != nil {
+			return
+		}
+		h.handleOutputBatch(msg.client.clientID, payload)
+
 	case protocol.TypeStatus:
 		var payload protocol.StatusPayload
 		if err := msg.message.ParsePayload(&payload); err != nil {
@@ -820,7 +976,7 @@ func (h *Hub) handleAgentMessage(msg *agentMessage) {
 		// Reconcile lifecycle state so we don't get stuck "busy/pulling"
 		if h.lifecycleManager != nil {
 			key := h.hostKey(msg.client.clientID)
-			h.lifecycleManager.HandleCommandRejected(key, payload.Reason, payload.CurrentCommand, payload.CurrentPID)
+			h.lifecycleManager.HandleCommandRejected(key, payload.CommandID, payload.Reason, payload.CurrentCommand, payload.CurrentPID)
 		}
 
 		// NOTE: We intentionally don't send legacy toast here.
@@ -848,18 +1004,15 @@ func (h *Hub) handleAgentMessage(msg *agentMessage) {
 			return
 		}
 
-		// Forward to browsers
-		h.BroadcastToBrowsers(map[string]any{
-			"type": "test_progress",
-			"payload": map[string]any{
-				"host_id": msg.client.clientID,
-				"current": payload.Current,
-				"total":   payload.Total,
-				"passed":  payload.Passed,
-				"running": payload.Running,
-				"result":  payload.Result,
-			},
-		})
+		h.handleTestProgress(msg.client.clientID, payload)
+
+	case protocol.TypeTestReport:
+		var payload protocol.TestReportPayload
+		if err := msg.message.ParsePayload(&payload); err != nil {
+			h.log.Error().Err(err).Msg("failed to parse test_report payload")
+			return
+		}
+		h.handleTestReport(msg.client.clientID, payload)
 
 	case protocol.TypeOperationProgress:
 		// P2800: Operation progress for status dots
@@ -877,12 +1030,76 @@ func (h *Hub) handleAgentMessage(msg *agentMessage) {
 		// Forward to browsers for status dot animation
 		h.BroadcastToBrowsers(map[string]any{
 			"type": "operation_progress",
+			"payload": map[string]any{
+				"host_id":    msg.client.clientID,
+				"command_id": payload.CommandID,
+				"progress":   payload.Progress,
+			},
+		})
+
+	case protocol.TypeCommandProgress:
+		// Build/download progress parsed from nix's log, for the progress bar
+		var payload protocol.CommandProgressPayload
+		if err := msg.message.ParsePayload(&payload); err != nil {
+			h.log.Error().Err(err).Msg("failed to parse command_progress payload")
+			return
+		}
+
+		h.BroadcastToBrowsers(map[string]any{
+			"type": "command_progress",
 			"payload": map[string]any{
 				"host_id":  msg.client.clientID,
-				"progress": payload.Progress,
+				"progress": payload,
 			},
 		})
+
+	case protocol.TypeLogStream:
+		var payload protocol.LogStreamPayload
+		if err := msg.message.ParsePayload(&payload); err != nil {
+			h.log.Error().Err(err).Msg("failed to parse log_stream payload")
+			return
+		}
+		h.handleLogStream(msg.client, payload)
+
+	case protocol.TypeLogStreamEnd:
+		var payload protocol.LogStreamEndPayload
+		if err := msg.message.ParsePayload(&payload); err != nil {
+			h.log.Error().Err(err).Msg("failed to parse log_stream_end payload")
+			return
+		}
+		h.handleLogStreamEnd(msg.client, payload)
+	}
+}
+
+// handleOutputBatch logs a batch of output lines and forwards it to browsers
+// as one message.
+func (h *Hub) handleOutputBatch(hostID string, batch protocol.OutputBatchPayload) {
+	lines := make([]map[string]any, len(batch.Lines))
+	for i, l := range batch.Lines {
+		isError := l.Stream == "stderr"
+		at := time.Now()
+		if l.Time > 0 {
+			at = time.UnixMilli(l.Time)
+		}
+		if h.logStore != nil {
+			_ = h.logStore.AppendLineAt(hostID, batch.CommandID, batch.Command, l.Line, isError, at)
+		}
+		lines[i] = map[string]any{
+			"line":     l.Line,
+			"is_error": isError,
+			"ts":       l.Time,
+		}
 	}
+
+	h.queueOutput(map[string]any{
+		"type": "command_output_batch",
+		"payload": map[string]any{
+			"host_id":    hostID,
+			"command":    batch.Command,
+			"command_id": batch.CommandID,
+			"lines":      lines,
+		},
+	})
 }
 
 // handleAgentRegister processes agent registration.
@@ -914,10 +1131,16 @@ func (h *Hub) handleAgentRegister(msg *agentMessage) {
 
 	// Update host in database
 	h.updateHost(payload)
+	if payload.Facts != nil {
+		if err := storeHostFacts(h.db, payload.Hostname, payload.Facts); err != nil {
+			h.log.Error().Err(err).Str("hostname", payload.Hostname).Msg("failed to store host facts")
+		}
+	}
 
 	// Send registered response (uses SafeSend)
 	resp, _ := protocol.NewMessage(protocol.TypeRegistered, protocol.RegisteredPayload{
-		HostID: payload.Hostname,
+		HostID:   payload.Hostname,
+		Features: []string{protocol.FeatureOutputBatch},
 	})
 	respData, _ := json.Marshal(resp)
 	msg.client.SafeSend(respData)
@@ -926,6 +1149,7 @@ func (h *Hub) handleAgentRegister(msg *agentMessage) {
 		Str("hostname", payload.Hostname).
 		Str("agent_version", payload.AgentVersion).
 		Str("source_commit", payload.SourceCommit).
+		Str("dashboard_url", payload.DashboardURL).
 		Msg("agent registered")
 
 	// P2810: Store agent freshness for pre-switch snapshot capture
@@ -969,8 +1193,8 @@ func (h *Hub) updateHost(payload protocol.RegisterPayload) {
 	// Upsert host record
 	// On re-registration (after switch/restart), clear pending_command and set online
 	_, err := h.db.Exec(`
-		INSERT INTO hosts (id, hostname, host_type, agent_version, os_version, nixpkgs_version, generation, theme_color, location, device_type, repo_url, repo_dir, last_seen, status, pending_command)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), 'online', NULL)
+		INSERT INTO hosts (id, hostname, host_type, agent_version, os_version, nixpkgs_version, generation, theme_color, location, device_type, repo_url, repo_dir, darwin_mode, protocol_version, capabilities_json, last_seen, status, pending_command)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), 'online', NULL)
 		ON CONFLICT(hostname) DO UPDATE SET
 			host_type = excluded.host_type,
 			agent_version = excluded.agent_version,
@@ -982,12 +1206,15 @@ func (h *Hub) updateHost(payload protocol.RegisterPayload) {
 			device_type = excluded.device_type,
 			repo_url = excluded.repo_url,
 			repo_dir = excluded.repo_dir,
+			darwin_mode = excluded.darwin_mode,
+			protocol_version = excluded.protocol_version,
+			capabilities_json = excluded.capabilities_json,
 			last_seen = datetime('now'),
 			status = 'online',
 			pending_command = NULL
 	`, payload.Hostname, payload.Hostname, payload.HostType, payload.AgentVersion,
 		payload.OSVersion, payload.NixpkgsVersion, payload.Generation, themeColor, location, deviceType,
-		payload.RepoURL, payload.RepoDir)
+		payload.RepoURL, payload.RepoDir, payload.DarwinMode, payload.ProtocolVersion, capabilitiesJSON(payload))
 
 	if err != nil {
 		h.log.Error().Err(err).Str("hostname", payload.Hostname).Msg("failed to upsert host")
@@ -998,7 +1225,9 @@ func (h *Hub) updateHost(payload protocol.RegisterPayload) {
 		Str("hostname", payload.Hostname).
 		Str("host_type", payload.HostType).
 		Str("os_version", payload.OSVersion).
+		Str("darwin_mode", payload.DarwinMode).
 		Str("theme_color", themeColor).
+		Int("protocol_version", payload.ProtocolVersion).
 		Msg("updated host record")
 
 	// Legacy host_heartbeat broadcast removed (CORE-004 delta is the source of truth).
@@ -1021,6 +1250,7 @@ func (h *Hub) updateHost(payload protocol.RegisterPayload) {
 				"device_type":     deviceType,
 				"repo_url":        payload.RepoURL,
 				"repo_dir":        payload.RepoDir,
+				"darwin_mode":     payload.DarwinMode,
 				"status":          "online",
 				"pending_command": nil,
 				"last_seen":       time.Now().UTC().Format(time.RFC3339),
@@ -1054,6 +1284,11 @@ func (h *Hub) handleHeartbeat(hostID string, payload protocol.HeartbeatPayload)
 		lockStatus = payload.UpdateStatus.Lock
 	}
 
+	// Per-input lock policy replaces the agent's fixed age thresholds
+	if h.cfg != nil && h.cfg.LockPolicy != nil {
+		lockStatus = h.applyLockPolicy(hostID, payload.FlakeInputs, lockStatus)
+	}
+
 	// P3800: Compute System status with inference
 	// Rule: If Lock is outdated → System MUST be outdated (can't be current with old deps)
 	var systemStatus protocol.StatusCheck
@@ -1087,7 +1322,7 @@ func (h *Hub) handleHeartbeat(hostID string, payload protocol.HeartbeatPayload)
 	// P1110: Persist tests compartment status (generation-scoped)
 	var testsGenerationPtr *string
 	if payload.UpdateStatus != nil && payload.UpdateStatus.Tests.Status != "" {
-		if data, err := json.Marshal(payload.UpdateStatus.Tests); err == nil {
+		if data, err := json.Marshal(h.flakyTestsStatus(hostID, payload.UpdateStatus.Tests)); err == nil {
 			s := string(data)
 			testsStatusJSON = &s
 		}
@@ -1096,12 +1331,51 @@ func (h *Hub) handleHeartbeat(hostID string, payload protocol.HeartbeatPayload)
 		}
 	}
 
+	// Per-target System status, merged with what is stored
+	var targets []protocol.TargetStatus
+	var targetsStatusJSON *string
+	if payload.UpdateStatus != nil && len(payload.UpdateStatus.Targets) > 0 {
+		targets = mergeTargetStatuses(h.loadTargetStatuses(hostID), payload.UpdateStatus.Targets)
+		if data, err := json.Marshal(targets); err == nil {
+			s := string(data)
+			targetsStatusJSON = &s
+		}
+	}
+
 	// P3700: Store lock_hash in database
 	var lockHashPtr *string
 	if payload.LockHash != "" {
 		lockHashPtr = &payload.LockHash
 	}
 
+	// Flake inputs: only sent when the lock changed, keep the stored ones otherwise
+	var flakeInputsJSON *string
+	if payload.FlakeInputs != nil {
+		if data, err := json.Marshal(payload.FlakeInputs); err == nil {
+			s := string(data)
+			flakeInputsJSON = &s
+		}
+	}
+
+	// Nix store disk usage
+	var diskJSON *string
+	if payload.Disk != nil {
+		if data, err := json.Marshal(payload.Disk); err == nil {
+			s := string(data)
+			diskJSON = &s
+		}
+	}
+
+	// Failed systemd units: log transitions against the previously persisted set
+	var servicesJSON *string
+	if payload.Services != nil {
+		if data, err := json.Marshal(payload.Services); err == nil {
+			s := string(data)
+			servicesJSON = &s
+		}
+		h.recordServiceTransitions(hostID, payload.Services)
+	}
+
 	// P1100: DO NOT update pending_command from heartbeat!
 	// LifecycleManager is the SINGLE SOURCE OF TRUTH for pending_command.
 	// Heartbeat reports what the agent *thinks* it's running, but the dashboard
@@ -1130,14 +1404,28 @@ func (h *Hub) handleHeartbeat(hostID string, payload protocol.HeartbeatPayload)
 			system_status_json = COALESCE(?, system_status_json),
 			tests_status_json = COALESCE(?, tests_status_json),
 			tests_generation = COALESCE(?, tests_generation),
-			lock_hash = ?
+			targets_status_json = COALESCE(?, targets_status_json),
+			lock_hash = ?,
+			flake_inputs_json = COALESCE(?, flake_inputs_json),
+			disk_json = ?,
+			services_json = ?,
+			reboot_required = ?,
+			reboot_reason = ?
 		WHERE hostname = ?
-	`, payload.Generation, payload.NixpkgsVersion, metricsJSON, lockStatusJSON, systemStatusJSON, testsStatusJSON, testsGenerationPtr, lockHashPtr, hostID)
+	`, payload.Generation, payload.NixpkgsVersion, metricsJSON, lockStatusJSON, systemStatusJSON, testsStatusJSON, testsGenerationPtr, targetsStatusJSON, lockHashPtr, flakeInputsJSON, diskJSON, servicesJSON,
+		payload.RebootRequired, payload.RebootReason, hostID)
 
 	if err != nil {
 		h.log.Error().Err(err).Str("host", hostID).Msg("failed to update heartbeat")
 	}
 
+	// Facts: only sent when they changed
+	if payload.Facts != nil {
+		if err := storeHostFacts(h.db, hostID, payload.Facts); err != nil {
+			h.log.Error().Err(err).Str("host", hostID).Msg("failed to store host facts")
+		}
+	}
+
 	h.log.Debug().
 		Str("host", hostID).
 		Str("generation", payload.Generation).
@@ -1158,7 +1446,7 @@ func (h *Hub) handleHeartbeat(hostID string, payload protocol.HeartbeatPayload)
 	// Build update status for broadcast
 	var gitStatus map[string]any
 	if h.versionFetcher != nil {
-		status, msg, checked := h.versionFetcher.GetGitStatus(payload.Generation)
+		status, msg, checked := h.versionFetcher.GetGitStatus(payload.Generation, loadGitPin(h.db, hostID))
 		gitStatus = map[string]any{"status": status, "message": msg, "checked_at": checked}
 	} else {
 		gitStatus = map[string]any{
@@ -1183,6 +1471,9 @@ func (h *Hub) handleHeartbeat(hostID string, payload protocol.HeartbeatPayload)
 	if payload.UpdateStatus != nil && payload.UpdateStatus.Tests.Status != "" {
 		updateStatus["tests"] = payload.UpdateStatus.Tests
 	}
+	if len(targets) > 0 {
+		updateStatus["targets"] = targets
+	}
 
 	// CORE-006: Remote-gate System/Tests
 	// - System/Tests MUST NOT be green unless Git+Lock are green.
@@ -1301,11 +1592,15 @@ func (h *Hub) handleHeartbeat(hostID string, payload protocol.HeartbeatPayload)
 			Type: syncproto.ChangeHostUpdated,
 			ID:   key,
 			Fields: map[string]any{
-				"status":        "online",
-				"last_seen":     time.Now().UTC().Format(time.RFC3339),
-				"generation":    payload.Generation,
-				"metrics":       payload.Metrics,
-				"update_status": updateStatus,
+				"status":          "online",
+				"last_seen":       time.Now().UTC().Format(time.RFC3339),
+				"generation":      payload.Generation,
+				"metrics":         payload.Metrics,
+				"disk":            payload.Disk,
+				"services":        payload.Services,
+				"reboot_required": payload.RebootRequired,
+				"reboot_reason":   payload.RebootReason,
+				"update_status":   updateStatus,
 			},
 		})
 	}
@@ -1315,6 +1610,7 @@ func (h *Hub) handleStatus(hostID string, payload protocol.StatusPayload) {
 	h.log.Info().
 		Str("host", hostID).
 		Str("command", payload.Command).
+		Str("command_id", payload.CommandID).
 		Str("status", payload.Status).
 		Int("exit_code", payload.ExitCode).
 		Msg("command status")
@@ -1329,7 +1625,7 @@ func (h *Hub) handleStatus(hostID string, payload protocol.StatusPayload) {
 
 	// Complete the log file
 	if h.logStore != nil {
-		_ = h.logStore.CompleteCommand(hostID, payload.Command, payload.ExitCode)
+		_ = h.logStore.CompleteCommand(hostID, payload.CommandID, payload.Command, payload.ExitCode)
 	}
 
 	// Notify completion subscribers (P5300 - deploy tracking)
@@ -1344,6 +1640,14 @@ func (h *Hub) handleStatus(hostID string, payload protocol.StatusPayload) {
 		}
 	}
 
+	// Remember what a pinned tag/branch checked out: the Git compartment
+	// compares against it (the dashboard cannot resolve refs itself)
+	if payload.Ref != "" && payload.Generation != "" && payload.ExitCode == 0 {
+		if err := recordGitPinCommit(h.db, hostID, payload.Ref, payload.Generation); err != nil {
+			h.log.Error().Err(err).Str("host", hostID).Msg("failed to record git ref commit")
+		}
+	}
+
 	// P1110: Persist System/Tests compartment status from command outcome (dashboard-side inference)
 	// This is cheap and survives agent restarts.
 	now := time.Now().UTC()
@@ -1359,6 +1663,11 @@ func (h *Hub) handleStatus(hostID string, payload protocol.StatusPayload) {
 			}
 			sys = protocol.StatusCheck{Status: "error", Message: msg, CheckedAt: now.Format(time.RFC3339)}
 		}
+		// A command on one target only changes that target; System shows the worst
+		if targets := h.loadTargetStatuses(hostID); len(targets) > 0 {
+			sys = setTargetSystem(targets, payload.Target, sys)
+			h.saveTargetStatuses(hostID, targets)
+		}
 		if data, err := json.Marshal(sys); err == nil {
 			s := string(data)
 			_, _ = h.db.Exec(`UPDATE hosts SET system_status_json = ? WHERE hostname = ?`, s, hostID)
@@ -1383,6 +1692,7 @@ func (h *Hub) handleStatus(hostID string, payload protocol.StatusPayload) {
 			}
 			tests = protocol.StatusCheck{Status: "error", Message: msg, CheckedAt: now.Format(time.RFC3339)}
 		}
+		tests = h.flakyTestsStatus(hostID, tests)
 		if data, err := json.Marshal(tests); err == nil {
 			s := string(data)
 			gen := payload.Generation
@@ -1408,7 +1718,7 @@ func (h *Hub) handleStatus(hostID string, payload protocol.StatusPayload) {
 		h.versionFetcher.ForceRefresh()
 		// P7100: Debug logging for git status comparison
 		if latest := h.versionFetcher.GetLatest(); latest != nil {
-			status, msg, _ := h.versionFetcher.GetGitStatus(payload.Generation)
+			status, msg, _ := h.versionFetcher.GetGitStatus(payload.Generation, loadGitPin(h.db, hostID))
 			h.log.Info().
 				Str("host", hostID).
 				Str("agent_generation", payload.Generation).
@@ -1422,7 +1732,7 @@ func (h *Hub) handleStatus(hostID string, payload protocol.StatusPayload) {
 	// v3: Notify lifecycle manager of command completion
 	if h.lifecycleManager != nil {
 		key := h.hostKey(hostID)
-		_, err := h.lifecycleManager.HandleCommandComplete(key, payload.Command, payload.ExitCode, payload.Message)
+		_, err := h.lifecycleManager.HandleCommandComplete(key, payload.CommandID, payload.Command, payload.ExitCode, payload.Message)
 		if err != nil {
 			h.log.Debug().Err(err).Str("host", hostID).Str("command", payload.Command).
 				Msg("lifecycle manager did not track this command")
@@ -1474,7 +1784,7 @@ func (h *Hub) getHostForPostValidation(hostID string) *templates.Host {
 	// Get git status from version fetcher
 	var gitStatus templates.StatusCheck
 	if h.versionFetcher != nil {
-		status, msg, checked := h.versionFetcher.GetGitStatus(host.Generation)
+		status, msg, checked := h.versionFetcher.GetGitStatus(host.Generation, loadGitPin(h.db, hostID))
 		gitStatus = templates.StatusCheck{Status: status, Message: msg, CheckedAt: checked}
 	}
 
@@ -1547,7 +1857,13 @@ func (h *Hub) detectStaleStatus(hostID string, updateStatus map[string]any) {
 
 				// Log to command output if available
 				if h.logStore != nil {
-					_ = h.logStore.LogStaleState(hostID, "switch", ss.Status, "unknown", int(elapsed.Seconds()))
+					commandID := ""
+					if h.lifecycleManager != nil {
+						if cmd := h.lifecycleManager.GetActiveCommand(h.hostKey(hostID)); cmd != nil {
+							commandID = cmd.ID
+						}
+					}
+					_ = h.logStore.LogStaleState(hostID, commandID, "switch", ss.Status, "unknown", int(elapsed.Seconds()))
 				}
 
 				// Resolve stale status to "unknown"
@@ -1571,15 +1887,20 @@ func (h *Hub) detectStaleStatus(hostID string, updateStatus map[string]any) {
 
 // SendCommand sends a command to a specific agent by host ID.
 func (h *Hub) SendCommand(hostID, command string) bool {
+	return h.SendCommandPayload(hostID, protocol.CommandPayload{Command: command})
+}
+
+// SendCommandPayload sends a command with its ID and arguments. The agent
+// echoes the ID in the command's output and status messages.
+func (h *Hub) SendCommandPayload(hostID string, payload protocol.CommandPayload) bool {
 	agent := h.GetAgent(hostID)
 	if agent == nil {
 		h.log.Warn().Str("host", hostID).Msg("cannot send command: agent not connected")
 		return false
 	}
 
-	msg, err := protocol.NewMessage(protocol.TypeCommand, protocol.CommandPayload{
-		Command: command,
-	})
+	command := payload.Command
+	msg, err := protocol.NewMessage(protocol.TypeCommand, payload)
 	if err != nil {
 		h.log.Error().Err(err).Msg("failed to create command message")
 		return false
@@ -1593,7 +1914,8 @@ func (h *Hub) SendCommand(hostID, command string) bool {
 
 	select {
 	case agent.send <- data:
-		h.log.Debug().Str("host", hostID).Str("command", command).Msg("command sent")
+		h.log.Debug().Str("host", hostID).Str("command", command).Str("command_id", payload.ID).
+			Interface("args", payload.Args).Msg("command sent")
 		return true
 	default:
 		h.log.Warn().Str("host", hostID).Msg("agent send buffer full")
@@ -1830,5 +2152,23 @@ func (c *Client) handleBrowserMessage(data []byte) {
 	case "unsubscribe":
 		// Browser unsubscribing
 		c.hub.log.Debug().Str("browser", c.clientID).Msg("browser unsubscribed")
+	case "log_stream_start":
+		var p struct {
+			HostID string `json:"host_id"`
+			Unit   string `json:"unit"`
+			Lines  int    `json:"lines"`
+		}
+		if err := json.Unmarshal(msg.Payload, &p); err != nil {
+			return
+		}
+		c.hub.startLogStream(c, p.HostID, p.Unit, p.Lines)
+	case "log_stream_stop":
+		var p struct {
+			StreamID string `json:"stream_id"`
+		}
+		if err := json.Unmarshal(msg.Payload, &p); err != nil {
+			return
+		}
+		c.hub.stopLogStream(c, p.StreamID)
 	}
 }
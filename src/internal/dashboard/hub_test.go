@@ -0,0 +1,67 @@
+package dashboard
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+	"github.com/rs/zerolog"
+)
+
+func TestHubHandlesHeartbeatsWhileBrowserStalls(t *testing.T) {
+	db, err := InitDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`INSERT INTO hosts (id, hostname, host_type, status) VALUES ('web1', 'web1', 'nixos', 'offline')`); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHub(zerolog.Nop(), db, &Config{}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go h.broadcastLoop(ctx)
+	go func() { _ = h.runLoop(ctx) }()
+
+	// A browser that never reads: its send buffer is already full
+	stalled := &Client{clientType: "browser", clientID: "s1", send: make(chan []byte, 1), hub: h}
+	stalled.send <- []byte("{}")
+	stalled.output = make(chan []byte, outputQueueSize)
+	go stalled.outputPump()
+	h.register <- stalled
+
+	agent := &Client{clientType: "agent", clientID: "web1", send: make(chan []byte, 1), hub: h}
+	for i := 0; i < 3*outputQueueSize; i++ {
+		msg, _ := protocol.NewMessage(protocol.TypeOutput, protocol.OutputPayload{Line: "building", Command: "switch"})
+		h.agentMessages <- &agentMessage{client: agent, message: msg}
+	}
+	msg, _ := protocol.NewMessage(protocol.TypeHeartbeat, protocol.HeartbeatPayload{Generation: "abc"})
+	h.agentMessages <- &agentMessage{client: agent, message: msg}
+
+	// Well before the stalled browser's outputSendWait runs out
+	deadline := time.Now().Add(outputSendWait / 2)
+	for {
+		var status string
+		if err := db.QueryRow(`SELECT status FROM hosts WHERE hostname = 'web1'`).Scan(&status); err != nil {
+			t.Fatal(err)
+		}
+		if status == "online" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("heartbeat not handled while a browser stopped reading")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	deadline = time.Now().Add(2 * outputSendWait)
+	for !stalled.closed.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("browser with a full output queue was not disconnected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
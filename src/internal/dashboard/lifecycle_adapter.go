@@ -27,8 +27,11 @@ func (h *hostProviderAdapter) GetHostByID(hostID string) (ops.Host, error) {
 		return nil, err
 	}
 	// Fill git status (dashboard-side) for ops validation / post-checks
+	pin := loadGitPin(h.db, hostID)
+	host.GitRef = pin.Ref
+	applyAgentCapabilities(h.db, host)
 	if h.vf != nil {
-		status, msg, checked := h.vf.GetGitStatus(host.Generation)
+		status, msg, checked := h.vf.GetGitStatus(host.Generation, pin)
 		if host.UpdateStatus == nil {
 			host.UpdateStatus = &templates.UpdateStatus{}
 		}
@@ -47,19 +50,19 @@ func getHostByIDFromDB(db *sql.DB, hostID string) (*templates.Host, error) {
 		PendingCommand, ThemeColor                          *string
 		Location, DeviceType                                *string
 		LockStatusJSON, SystemStatusJSON                    *string
-		RepoURL, RepoDir                                    *string
+		RepoURL, RepoDir, DiskJSON                          *string
 	}
 
 	err := db.QueryRow(`
 		SELECT id, hostname, host_type, agent_version, os_version,
 		       nixpkgs_version, generation, last_seen, status, pending_command,
 		       theme_color, location, device_type, lock_status_json, system_status_json,
-		       repo_url, repo_dir
+		       repo_url, repo_dir, disk_json
 		FROM hosts WHERE id = ?
 	`, hostID).Scan(&h.ID, &h.Hostname, &h.HostType, &h.AgentVersion,
 		&h.OSVersion, &h.NixpkgsVersion, &h.Generation, &h.LastSeen,
 		&h.Status, &h.PendingCommand, &h.ThemeColor, &h.Location, &h.DeviceType,
-		&h.LockStatusJSON, &h.SystemStatusJSON, &h.RepoURL, &h.RepoDir)
+		&h.LockStatusJSON, &h.SystemStatusJSON, &h.RepoURL, &h.RepoDir, &h.DiskJSON)
 
 	if err != nil {
 		return nil, err
@@ -100,6 +103,7 @@ func getHostByIDFromDB(db *sql.DB, hostID string) (*templates.Host, error) {
 	if h.RepoDir != nil {
 		host.RepoDir = *h.RepoDir
 	}
+	host.Disk = parseDiskJSON(h.DiskJSON)
 
 	// Parse update status from JSON
 	host.UpdateStatus = parseUpdateStatusJSON(h.SystemStatusJSON, h.LockStatusJSON)
@@ -132,6 +136,29 @@ func parseUpdateStatusJSON(systemJSON *string, lockJSON *string) *templates.Upda
 	return us
 }
 
+// parseDiskJSON parses the persisted /nix/store disk usage (nil if absent).
+func parseDiskJSON(diskJSON *string) *templates.DiskUsage {
+	if diskJSON == nil || *diskJSON == "" {
+		return nil
+	}
+	var d templates.DiskUsage
+	if err := json.Unmarshal([]byte(*diskJSON), &d); err != nil {
+		return nil
+	}
+	return &d
+}
+
+func parseTargetsJSON(targetsJSON *string) []templates.TargetStatus {
+	if targetsJSON == nil || *targetsJSON == "" {
+		return nil
+	}
+	var t []templates.TargetStatus
+	if err := json.Unmarshal([]byte(*targetsJSON), &t); err != nil {
+		return nil
+	}
+	return t
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // BROADCAST SENDER ADAPTER
 // ═══════════════════════════════════════════════════════════════════════════
@@ -207,12 +234,12 @@ type lifecycleManagerWrapper struct {
 }
 
 // HandleCommandComplete implements lifecycleManagerInterface.
-func (w *lifecycleManagerWrapper) HandleCommandComplete(hostID, opID string, exitCode int, message string) (interface{}, error) {
-	return w.lm.HandleCommandComplete(hostID, opID, exitCode, message)
+func (w *lifecycleManagerWrapper) HandleCommandComplete(hostID, commandID, opID string, exitCode int, message string) (interface{}, error) {
+	return w.lm.HandleCommandComplete(hostID, commandID, opID, exitCode, message)
 }
 
-func (w *lifecycleManagerWrapper) HandleCommandRejected(hostID, reason, currentCommand string, currentPID int) {
-	w.lm.HandleCommandRejected(hostID, reason, currentCommand, currentPID)
+func (w *lifecycleManagerWrapper) HandleCommandRejected(hostID, commandID, reason, currentCommand string, currentPID int) {
+	w.lm.HandleCommandRejected(hostID, commandID, reason, currentCommand, currentPID)
 }
 
 // HandleHeartbeat implements lifecycleManagerInterface.
@@ -0,0 +1,237 @@
+package dashboard
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// LOCK FRESHNESS POLICY (NIXFLEET_LOCK_POLICY)
+// ═══════════════════════════════════════════════════════════════════════════
+
+// LockPolicy holds freshness rules for flake inputs, per input and per host
+// group. For each input and host, rules apply from least to most specific:
+// the default, the defaults of matching groups, the input's rule, then the
+// input's rule in matching groups (groups in file order). A later rule only
+// overrides the fields it sets.
+type LockPolicy struct {
+	Default InputPolicy            `json:"default"`
+	Inputs  map[string]InputPolicy `json:"inputs"` // by input name, e.g. "nixpkgs"
+	Groups  []LockPolicyGroup      `json:"groups"`
+}
+
+// LockPolicyGroup applies rules to the hosts matching all criteria it sets.
+type LockPolicyGroup struct {
+	Name       string                 `json:"name"`
+	Hosts      []string               `json:"hosts"` // hostname globs, e.g. "hsb*"
+	Location   string                 `json:"location"`
+	DeviceType string                 `json:"device_type"`
+	HostType   string                 `json:"host_type"`
+	Default    InputPolicy            `json:"default"`
+	Inputs     map[string]InputPolicy `json:"inputs"`
+}
+
+// InputPolicy is a rule for one input. Unset fields do not constrain.
+type InputPolicy struct {
+	MaxAgeDays     *int    `json:"max_age_days"`     // locked revision at most this old
+	Branch         *string `json:"branch"`           // input must follow this branch or tag
+	AllowPinnedRev *bool   `json:"allow_pinned_rev"` // default true; pinned revs are exempt from max_age_days
+}
+
+// lockPolicyHost is what groups match on.
+type lockPolicyHost struct {
+	Hostname   string
+	Location   string
+	DeviceType string
+	HostType   string
+}
+
+// LoadLockPolicy reads a JSON lock policy file.
+func LoadLockPolicy(file string) (*LockPolicy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var p LockPolicy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return &p, nil
+}
+
+func (p *LockPolicy) validate() error {
+	check := func(where string, rule InputPolicy) error {
+		if rule.MaxAgeDays != nil && *rule.MaxAgeDays < 0 {
+			return fmt.Errorf("%s: max_age_days must not be negative", where)
+		}
+		return nil
+	}
+	if err := check("default", p.Default); err != nil {
+		return err
+	}
+	for name, rule := range p.Inputs {
+		if err := check("inputs."+name, rule); err != nil {
+			return err
+		}
+	}
+	for i, g := range p.Groups {
+		where := fmt.Sprintf("groups[%d]", i)
+		if g.Name != "" {
+			where = "group " + g.Name
+		}
+		for _, pattern := range g.Hosts {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s: host pattern %q: %w", where, pattern, err)
+			}
+		}
+		if err := check(where+" default", g.Default); err != nil {
+			return err
+		}
+		for name, rule := range g.Inputs {
+			if err := check(where+" inputs."+name, rule); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether the group applies to host.
+func (g *LockPolicyGroup) matches(host lockPolicyHost) bool {
+	if g.Location != "" && g.Location != host.Location {
+		return false
+	}
+	if g.DeviceType != "" && g.DeviceType != host.DeviceType {
+		return false
+	}
+	if g.HostType != "" && g.HostType != host.HostType {
+		return false
+	}
+	if len(g.Hosts) == 0 {
+		return true
+	}
+	for _, pattern := range g.Hosts {
+		if ok, _ := path.Match(pattern, host.Hostname); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// merge returns r with the fields set in over replaced.
+func (r InputPolicy) merge(over InputPolicy) InputPolicy {
+	if over.MaxAgeDays != nil {
+		r.MaxAgeDays = over.MaxAgeDays
+	}
+	if over.Branch != nil {
+		r.Branch = over.Branch
+	}
+	if over.AllowPinnedRev != nil {
+		r.AllowPinnedRev = over.AllowPinnedRev
+	}
+	return r
+}
+
+// rule returns the effective rule for an input on host.
+func (p *LockPolicy) rule(host lockPolicyHost, input string) InputPolicy {
+	var groups []*LockPolicyGroup
+	for i := range p.Groups {
+		if p.Groups[i].matches(host) {
+			groups = append(groups, &p.Groups[i])
+		}
+	}
+	r := p.Default
+	for _, g := range groups {
+		r = r.merge(g.Default)
+	}
+	r = r.merge(p.Inputs[input])
+	for _, g := range groups {
+		r = r.merge(g.Inputs[input])
+	}
+	return r
+}
+
+// check returns why in violates the policy on host, or "" if it does not.
+func (p *LockPolicy) check(host lockPolicyHost, in protocol.FlakeInput, now time.Time) string {
+	r := p.rule(host, in.Name)
+	if in.Pinned && r.AllowPinnedRev != nil && !*r.AllowPinnedRev {
+		return fmt.Sprintf("%s is pinned to %s, pinned revs are not allowed", in.Name, shortHash(in.Rev))
+	}
+	if r.Branch != nil && *r.Branch != in.Ref {
+		ref := in.Ref
+		if ref == "" {
+			ref = "the default branch"
+		}
+		return fmt.Sprintf("%s follows %s, policy requires %s", in.Name, ref, *r.Branch)
+	}
+	if r.MaxAgeDays != nil && !in.Pinned && in.LastModified > 0 {
+		if age := flakeInputAge(in, now); age > *r.MaxAgeDays {
+			return fmt.Sprintf("%s is %d days old (max %d)", in.Name, age, *r.MaxAgeDays)
+		}
+	}
+	return ""
+}
+
+// status turns a host's inputs into a Lock compartment status. ok is false
+// if the host has not reported its inputs.
+func (p *LockPolicy) status(host lockPolicyHost, inputs []protocol.FlakeInput, now time.Time) (check protocol.StatusCheck, ok bool) {
+	if inputs == nil {
+		return check, false
+	}
+	check.CheckedAt = now.UTC().Format(time.RFC3339)
+
+	var reasons []string
+	for _, in := range inputs { // sorted by name
+		if reason := p.check(host, in, now); reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	if len(reasons) > 0 {
+		check.Status = "outdated"
+		check.Message = "Lock policy: " + strings.Join(reasons, "; ")
+		return check, true
+	}
+	check.Status = "ok"
+	check.Message = fmt.Sprintf("All %d inputs within lock policy", len(inputs))
+	return check, true
+}
+
+// applyLockPolicy evaluates the lock policy for a host on heartbeat. inputs
+// are the heartbeat's flake inputs, nil to use the stored ones. A violation
+// overrides current; otherwise current stays if it compares the lock hash
+// with version.json, since a lock within policy can still be behind.
+func (h *Hub) applyLockPolicy(hostname string, inputs []protocol.FlakeInput, current protocol.StatusCheck) protocol.StatusCheck {
+	host := lockPolicyHost{Hostname: hostname}
+	var inputsJSON sql.NullString
+	err := h.db.QueryRow(`
+		SELECT COALESCE(location, ''), COALESCE(device_type, ''), COALESCE(host_type, ''), flake_inputs_json
+		FROM hosts WHERE hostname = ?
+	`, hostname).Scan(&host.Location, &host.DeviceType, &host.HostType, &inputsJSON)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		h.log.Debug().Err(err).Str("host", hostname).Msg("failed to load host for lock policy")
+		return current
+	}
+	if inputs == nil && inputsJSON.Valid {
+		_ = json.Unmarshal([]byte(inputsJSON.String), &inputs)
+	}
+
+	check, ok := h.cfg.LockPolicy.status(host, inputs, time.Now())
+	if !ok || (check.Status == "ok" && h.versionFetcher != nil) {
+		return current
+	}
+	return check
+}
@@ -0,0 +1,91 @@
+package dashboard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+func TestLockPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lock-policy.json")
+	err := os.WriteFile(file, []byte(`{
+		"default": {"max_age_days": 90},
+		"inputs": {
+			"nixpkgs": {"max_age_days": 14, "branch": "nixos-unstable"},
+			"vendored": {"allow_pinned_rev": true}
+		},
+		"groups": [
+			{"name": "servers", "hosts": ["hsb*"], "inputs": {"nixpkgs": {"max_age_days": 3}}},
+			{"name": "strict", "location": "cloud", "default": {"allow_pinned_rev": false}}
+		]
+	}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadLockPolicy(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1718000000, 0)
+	day := int64(24 * 60 * 60)
+	inputs := []protocol.FlakeInput{
+		{Name: "home-manager", LastModified: now.Unix() - 100*day},
+		{Name: "nixpkgs", Ref: "nixos-unstable", LastModified: now.Unix() - 5*day},
+		{Name: "vendored", Rev: "0123456789abcdef", Pinned: true, LastModified: now.Unix() - 400*day},
+	}
+
+	desktop := lockPolicyHost{Hostname: "imac0", Location: "home"}
+	check, ok := policy.status(desktop, inputs, now)
+	if !ok || check.Status != "outdated" || check.Message != "Lock policy: home-manager is 100 days old (max 90)" {
+		t.Errorf("desktop: %+v", check)
+	}
+
+	server := lockPolicyHost{Hostname: "hsb1", Location: "home"}
+	check, _ = policy.status(server, inputs, now)
+	if !strings.Contains(check.Message, "nixpkgs is 5 days old (max 3)") {
+		t.Errorf("server group rule not applied: %+v", check)
+	}
+
+	// The group default overrides the global default, not the input's rule
+	cloud := lockPolicyHost{Hostname: "csb0", Location: "cloud"}
+	check, _ = policy.status(cloud, inputs, now)
+	if strings.Contains(check.Message, "vendored") {
+		t.Errorf("input rule should win over group default: %+v", check)
+	}
+	inputs[2].Name = "other"
+	check, _ = policy.status(cloud, inputs, now)
+	if !strings.Contains(check.Message, "other is pinned to 0123456, pinned revs are not allowed") {
+		t.Errorf("pinned rev not rejected: %+v", check)
+	}
+
+	inputs[1].Ref = ""
+	if reason := policy.check(desktop, inputs[1], now); reason != "nixpkgs follows the default branch, policy requires nixos-unstable" {
+		t.Errorf("branch reason = %q", reason)
+	}
+
+	check, ok = policy.status(desktop, inputs[:0], now)
+	if !ok || check.Status != "ok" {
+		t.Errorf("empty inventory: %+v, %v", check, ok)
+	}
+	if _, ok := policy.status(desktop, nil, now); ok {
+		t.Error("status without inventory")
+	}
+
+	for _, bad := range []string{
+		`{"default": {"max_age": 7}}`,
+		`{"default": {"max_age_days": -1}}`,
+		`{"groups": [{"hosts": ["["]}]}`,
+	} {
+		if err := os.WriteFile(file, []byte(bad), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadLockPolicy(file); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// LOG STREAMS - live journal tail of one unit, for one browser
+// ═══════════════════════════════════════════════════════════════════════════
+
+// maxLogStreamsPerBrowser bounds the agent sessions one browser can hold.
+const maxLogStreamsPerBrowser = 4
+
+// logStreamSession is a log stream a browser started on an agent. Its lines
+// go to that browser only, and it ends when either side disconnects.
+type logStreamSession struct {
+	id       string
+	hostname string // agent key in h.agents
+	hostID   string // hosts.id, as the browser knows the host
+	unit     string
+	browser  *Client
+}
+
+// startLogStream asks a host's agent to follow unit's log for browser. The
+// browser gets log_stream_started, or log_stream_end with the reason.
+func (h *Hub) startLogStream(browser *Client, hostID, unit string, lines int) {
+	fail := func(msg string) {
+		h.sendToBrowser(browser, "log_stream_end", map[string]any{"host_id": hostID, "unit": unit, "error": msg})
+	}
+
+	var hostname string
+	if err := h.db.QueryRow(`SELECT hostname FROM hosts WHERE id = ? OR hostname = ?`, hostID, hostID).Scan(&hostname); err != nil {
+		fail("unknown host")
+		return
+	}
+	agent := h.GetAgent(hostname)
+	if agent == nil {
+		fail("host is offline")
+		return
+	}
+	if _, caps := loadAgentCapabilities(h.db, hostname); !caps.HasFeature(protocol.FeatureLogStream) {
+		fail("agent does not support log streaming, update it")
+		return
+	}
+	if !protocol.ValidUnitName(unit) {
+		fail(fmt.Sprintf("invalid unit name %q", unit))
+		return
+	}
+	id, err := generateSecureToken(9)
+	if err != nil {
+		fail("internal error")
+		return
+	}
+	s := &logStreamSession{id: "ls-" + id, hostname: hostname, hostID: h.hostKey(hostname), unit: unit, browser: browser}
+
+	h.logStreamsMu.Lock()
+	n := 0
+	for _, other := range h.logStreams {
+		if other.browser == browser {
+			n++
+		}
+	}
+	if n >= maxLogStreamsPerBrowser {
+		h.logStreamsMu.Unlock()
+		fail(fmt.Sprintf("too many log streams (max %d)", maxLogStreamsPerBrowser))
+		return
+	}
+	h.logStreams[s.id] = s
+	h.logStreamsMu.Unlock()
+
+	if !h.sendToAgent(agent, protocol.TypeLogStreamStart, protocol.LogStreamStartPayload{StreamID: s.id, Unit: unit, Lines: lines}) {
+		h.removeLogStream(s.id)
+		fail("agent send buffer full")
+		return
+	}
+	h.log.Info().Str("host", hostname).Str("unit", unit).Str("stream_id", s.id).Str("browser", browser.clientID).Msg("log stream started")
+	h.sendToBrowser(browser, "log_stream_started", map[string]any{"stream_id": s.id, "host_id": s.hostID, "unit": unit})
+}
+
+// stopLogStream stops a browser's own stream. The browser gets
+// log_stream_end when the agent confirms.
+func (h *Hub) stopLogStream(browser *Client, streamID string) {
+	h.logStreamsMu.Lock()
+	s := h.logStreams[streamID]
+	h.logStreamsMu.Unlock()
+	if s == nil || s.browser != browser {
+		return
+	}
+	if agent := h.GetAgent(s.hostname); agent != nil {
+		h.sendToAgent(agent, protocol.TypeLogStreamStop, protocol.LogStreamStopPayload{StreamID: streamID})
+	}
+}
+
+// handleLogStream forwards an agent's log lines to the browser that started
+// the stream. Lines for a stream nobody holds stop it on the agent.
+func (h *Hub) handleLogStream(agent *Client, p protocol.LogStreamPayload) {
+	h.logStreamsMu.Lock()
+	s := h.logStreams[p.StreamID]
+	h.logStreamsMu.Unlock()
+	if s == nil || s.hostname != agent.clientID {
+		h.sendToAgent(agent, protocol.TypeLogStreamStop, protocol.LogStreamStopPayload{StreamID: p.StreamID})
+		return
+	}
+	h.sendToBrowser(s.browser, "log_stream", map[string]any{"stream_id": s.id, "host_id": s.hostID, "lines": p.Lines})
+}
+
+// handleLogStreamEnd tells the browser its stream ended on the agent.
+func (h *Hub) handleLogStreamEnd(agent *Client, p protocol.LogStreamEndPayload) {
+	h.logStreamsMu.Lock()
+	s := h.logStreams[p.StreamID]
+	if s != nil && s.hostname == agent.clientID {
+		delete(h.logStreams, p.StreamID)
+	} else {
+		s = nil
+	}
+	h.logStreamsMu.Unlock()
+	if s == nil {
+		return
+	}
+	h.log.Info().Str("host", s.hostname).Str("stream_id", s.id).Str("error", p.Error).Msg("log stream ended")
+	h.sendToBrowser(s.browser, "log_stream_end", map[string]any{"stream_id": s.id, "host_id": s.hostID, "unit": s.unit, "error": p.Error})
+}
+
+// endLogStreams tears down the streams of a disconnected client: a browser's
+// streams are stopped on their agents, an agent's streams end for their
+// browsers.
+func (h *Hub) endLogStreams(client *Client) {
+	var ended []*logStreamSession
+	h.logStreamsMu.Lock()
+	for id, s := range h.logStreams {
+		if s.browser == client || (client.clientType == "agent" && s.hostname == client.clientID) {
+			delete(h.logStreams, id)
+			ended = append(ended, s)
+		}
+	}
+	h.logStreamsMu.Unlock()
+
+	for _, s := range ended {
+		if s.browser == client {
+			if agent := h.GetAgent(s.hostname); agent != nil {
+				h.sendToAgent(agent, protocol.TypeLogStreamStop, protocol.LogStreamStopPayload{StreamID: s.id})
+			}
+			continue
+		}
+		h.sendToBrowser(s.browser, "log_stream_end", map[string]any{"stream_id": s.id, "host_id": s.hostID, "unit": s.unit, "error": "agent disconnected"})
+	}
+}
+
+func (h *Hub) removeLogStream(streamID string) {
+	h.logStreamsMu.Lock()
+	delete(h.logStreams, streamID)
+	h.logStreamsMu.Unlock()
+}
+
+// sendToAgent sends a dashboard → agent message without blocking.
+func (h *Hub) sendToAgent(agent *Client, msgType string, payload any) bool {
+	msg, err := protocol.NewMessage(msgType, payload)
+	if err != nil {
+		h.log.Error().Err(err).Str("type", msgType).Msg("failed to create agent message")
+		return false
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		h.log.Error().Err(err).Str("type", msgType).Msg("failed to marshal agent message")
+		return false
+	}
+	return agent.SafeSend(data)
+}
+
+// sendToBrowser sends a message to one browser without blocking.
+func (h *Hub) sendToBrowser(browser *Client, msgType string, payload map[string]any) {
+	data, err := json.Marshal(map[string]any{"type": msgType, "payload": payload})
+	if err != nil {
+		h.log.Error().Err(err).Str("type", msgType).Msg("failed to marshal browser message")
+		return
+	}
+	if !browser.SafeSend(data) {
+		h.log.Debug().Str("browser", browser.clientID).Str("type", msgType).Msg("browser send failed")
+	}
+}
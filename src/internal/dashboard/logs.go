@@ -12,7 +12,16 @@ import (
 type LogStore struct {
 	basePath string
 	mu       sync.RWMutex
-	files    map[string]*os.File // hostID:command -> file
+	files    map[string]*os.File // logKey -> file
+}
+
+// logKey identifies a command's log file: its ID, or hostID:command for
+// agents that do not echo command IDs.
+func logKey(hostID, commandID, command string) string {
+	if commandID != "" {
+		return commandID
+	}
+	return hostID + ":" + command
 }
 
 // NewLogStore creates a new log store with the given base path
@@ -27,7 +36,7 @@ func NewLogStore(basePath string) (*LogStore, error) {
 }
 
 // StartCommand opens a new log file for a command execution
-func (ls *LogStore) StartCommand(hostID, command string) error {
+func (ls *LogStore) StartCommand(hostID, commandID, command string) error {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
 
@@ -48,15 +57,19 @@ func (ls *LogStore) StartCommand(hostID, command string) error {
 	}
 
 	// Write header
-	header := fmt.Sprintf("# Command: %s\n# Host: %s\n# Started: %s\n\n",
+	header := fmt.Sprintf("# Command: %s\n# Host: %s\n# Started: %s\n",
 		command, hostID, time.Now().Format(time.RFC3339))
+	if commandID != "" {
+		header += "# Command ID: " + commandID + "\n"
+	}
+	header += "\n"
 	if _, err := f.WriteString(header); err != nil {
 		_ = f.Close()
 		return fmt.Errorf("failed to write log header: %w", err)
 	}
 
 	// Store file handle
-	key := hostID + ":" + command
+	key := logKey(hostID, commandID, command)
 	if existing, ok := ls.files[key]; ok {
 		_ = existing.Close()
 	}
@@ -66,15 +79,20 @@ func (ls *LogStore) StartCommand(hostID, command string) error {
 }
 
 // AppendLine writes a line to the active log file for a host's command
-func (ls *LogStore) AppendLine(hostID, command, line string, isError bool) error {
+func (ls *LogStore) AppendLine(hostID, commandID, command, line string, isError bool) error {
+	return ls.AppendLineAt(hostID, commandID, command, line, isError, time.Now())
+}
+
+// AppendLineAt is AppendLine for a line the agent read at the given time.
+func (ls *LogStore) AppendLineAt(hostID, commandID, command, line string, isError bool, at time.Time) error {
 	ls.mu.RLock()
-	key := hostID + ":" + command
+	key := logKey(hostID, commandID, command)
 	f, ok := ls.files[key]
 	ls.mu.RUnlock()
 
 	if !ok {
 		// No active log file, start one
-		if err := ls.StartCommand(hostID, command); err != nil {
+		if err := ls.StartCommand(hostID, commandID, command); err != nil {
 			return err
 		}
 		ls.mu.RLock()
@@ -83,7 +101,7 @@ func (ls *LogStore) AppendLine(hostID, command, line string, isError bool) error
 	}
 
 	// Format line with timestamp
-	timestamp := time.Now().Format("15:04:05")
+	timestamp := at.Format("15:04:05")
 	prefix := ""
 	if isError {
 		prefix = "[ERR] "
@@ -97,11 +115,11 @@ func (ls *LogStore) AppendLine(hostID, command, line string, isError bool) error
 }
 
 // CompleteCommand closes the log file for a completed command
-func (ls *LogStore) CompleteCommand(hostID, command string, exitCode int) error {
+func (ls *LogStore) CompleteCommand(hostID, commandID, command string, exitCode int) error {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
 
-	key := hostID + ":" + command
+	key := logKey(hostID, commandID, command)
 	f, ok := ls.files[key]
 	if !ok {
 		return nil // No log file was opened
@@ -119,20 +137,21 @@ func (ls *LogStore) CompleteCommand(hostID, command string, exitCode int) error
 	return nil
 }
 
-// LogStaleState writes a stale state detection event to the log
-// P1110: Used to record when stale state is detected and resolved
-func (ls *LogStore) LogStaleState(hostID, command, oldStatus, newStatus string, elapsedSeconds int) error {
+// LogStaleState writes a stale state detection event to the command's open log.
+// P1110: Used to record when stale state is detected and resolved.
+// commandID is the active command's ID, if any; agents that do not echo IDs
+// write under hostID:command instead. Without an open log nothing is written,
+// since no completion would ever close a file opened here.
+func (ls *LogStore) LogStaleState(hostID, commandID, command, oldStatus, newStatus string, elapsedSeconds int) error {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
 
-	key := hostID + ":" + command
-	f, ok := ls.files[key]
+	f, ok := ls.files[logKey(hostID, commandID, command)]
 	if !ok {
-		// No active log file, create one for the stale state event
-		if err := ls.StartCommand(hostID, command); err != nil {
-			return err
-		}
-		f = ls.files[key]
+		f, ok = ls.files[logKey(hostID, "", command)]
+	}
+	if !ok {
+		return nil
 	}
 
 	// Write stale state warning
@@ -200,29 +219,6 @@ func (ls *LogStore) GetLatestLogContent(hostID string, maxLines int) (string, er
 	return string(content), nil
 }
 
-// GetCurrentCommandOutput returns the output of the currently executing command.
-// P3300: Used to restore output when page is refreshed mid-command.
-func (ls *LogStore) GetCurrentCommandOutput(hostID, command string) (string, error) {
-	ls.mu.RLock()
-	key := hostID + ":" + command
-	f, ok := ls.files[key]
-	ls.mu.RUnlock()
-
-	if !ok {
-		// No active command, try to get latest log
-		return ls.GetLatestLogContent(hostID, 0)
-	}
-
-	// Read the current file
-	logPath := f.Name()
-	content, err := os.ReadFile(logPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read current log: %w", err)
-	}
-
-	return string(content), nil
-}
-
 // Helper functions for line manipulation
 func splitLines(s string) []string {
 	if s == "" {
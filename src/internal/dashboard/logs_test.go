@@ -0,0 +1,46 @@
+package dashboard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogStaleStateUsesCommandLog(t *testing.T) {
+	ls, err := NewLogStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ls.Close() }()
+
+	// Nothing open: no stray file that no completion would close
+	if err := ls.LogStaleState("web1", "cmd-1", "switch", "working", "unknown", 400); err != nil {
+		t.Fatal(err)
+	}
+	if logs, _ := ls.ListLogs("web1"); len(logs) != 0 {
+		t.Fatalf("stale state without an open log created %v", logs)
+	}
+
+	if err := ls.StartCommand("web1", "cmd-1", "switch"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ls.LogStaleState("web1", "cmd-1", "switch", "working", "unknown", 400); err != nil {
+		t.Fatal(err)
+	}
+	if len(ls.files) != 1 {
+		t.Errorf("%d open log files, want 1", len(ls.files))
+	}
+	if err := ls.CompleteCommand("web1", "cmd-1", "switch", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	logs, _ := ls.ListLogs("web1")
+	if len(logs) != 1 {
+		t.Fatalf("logs = %v; want one", logs)
+	}
+	content, _ := os.ReadFile(filepath.Join(ls.GetLogPath("web1"), logs[0]))
+	if !strings.Contains(string(content), "P1110: Stale state detected") || !strings.Contains(string(content), "# Command ID: cmd-1") {
+		t.Errorf("stale state not in the command's log:\n%s", content)
+	}
+}
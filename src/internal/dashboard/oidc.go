@@ -0,0 +1,618 @@
+package dashboard
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	_ "crypto/sha512" // SHA-384 and SHA-512 for RS384/RS512/ES384/ES512
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// OPENID CONNECT - single sign-on via the authorization code flow with PKCE
+// ═══════════════════════════════════════════════════════════════════════════
+
+const (
+	oidcStateCookie  = "nixfleet_oidc"
+	oidcLoginTimeout = 10 * time.Minute
+	oidcMaxPending   = 1000
+	oidcClockSkew    = time.Minute
+)
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// parseOIDCRoles parses NIXFLEET_OIDC_ROLES, e.g.
+// "fleet-admins=admin,fleet-ops=operator,*=viewer".
+func parseOIDCRoles(v string) (map[string]string, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	roles := make(map[string]string)
+	for _, entry := range strings.Split(v, ",") {
+		group, role, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" {
+			return nil, fmt.Errorf("invalid entry %q (want group=role)", entry)
+		}
+		if err := validateRole(role); err != nil {
+			return nil, err
+		}
+		roles[group] = role
+	}
+	return roles, nil
+}
+
+// mapOIDCRole returns the highest role any of the groups maps to, or ""
+// if none does. The group "*" matches every user.
+func mapOIDCRole(roles map[string]string, groups []string) string {
+	best := roles["*"]
+	for _, g := range groups {
+		if role := roles[g]; roleRanks[role] > roleRanks[best] {
+			best = role
+		}
+	}
+	return best
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcLogin is a sign-on in progress, from the redirect to the provider
+// until the browser comes back to the callback.
+type oidcLogin struct {
+	nonce       string
+	verifier    string // PKCE code verifier
+	redirectURL string
+	expires     time.Time
+}
+
+// oidcProvider talks to the identity provider. Discovery is fetched once;
+// the signing keys are refetched when a token names an unknown key.
+type oidcProvider struct {
+	cfg    *Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]crypto.PublicKey
+	keysAt    time.Time
+	pending   map[string]*oidcLogin // by state
+}
+
+// newOIDCProvider returns nil if single sign-on is not configured.
+func newOIDCProvider(cfg *Config) *oidcProvider {
+	if !cfg.HasOIDC() {
+		return nil
+	}
+	return &oidcProvider{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		pending: make(map[string]*oidcLogin),
+	}
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	d := p.discovery
+	p.mu.Unlock()
+	if d != nil {
+		return d, nil
+	}
+
+	d = &oidcDiscovery{}
+	if err := p.getJSON(ctx, p.cfg.OIDCIssuer+"/.well-known/openid-configuration", d); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != p.cfg.OIDCIssuer {
+		return nil, fmt.Errorf("discovery: issuer %q does not match %q", d.Issuer, p.cfg.OIDCIssuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("discovery: missing authorization, token or jwks endpoint")
+	}
+
+	p.mu.Lock()
+	p.discovery = d
+	p.mu.Unlock()
+	return d, nil
+}
+
+// begin records a new sign-on and returns its state.
+func (p *oidcProvider) begin(redirectURL string) (string, *oidcLogin, error) {
+	state, err := randomURLString(24)
+	if err != nil {
+		return "", nil, err
+	}
+	login := &oidcLogin{redirectURL: redirectURL, expires: time.Now().Add(oidcLoginTimeout)}
+	if login.nonce, err = randomURLString(24); err != nil {
+		return "", nil, err
+	}
+	if login.verifier, err = randomURLString(32); err != nil {
+		return "", nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for s, l := range p.pending {
+		if now.After(l.expires) {
+			delete(p.pending, s)
+		}
+	}
+	if len(p.pending) >= oidcMaxPending {
+		return "", nil, errors.New("too many sign-ons in progress")
+	}
+	p.pending[state] = login
+	return state, login, nil
+}
+
+// finish removes and returns the sign-on for state, nil if unknown or expired.
+func (p *oidcProvider) finish(state string) *oidcLogin {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	login := p.pending[state]
+	delete(p.pending, state)
+	if login == nil || time.Now().After(login.expires) {
+		return nil
+	}
+	return login
+}
+
+// authURL is where the browser is sent to sign in.
+func (p *oidcProvider) authURL(d *oidcDiscovery, state string, login *oidcLogin) (string, error) {
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(login.verifier))
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.OIDCClientID)
+	q.Set("redirect_uri", login.redirectURL)
+	q.Set("scope", strings.Join(p.cfg.OIDCScopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", login.nonce)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// authenticate redeems the authorization code and returns the verified
+// ID token claims.
+func (p *oidcProvider) authenticate(ctx context.Context, code string, login *oidcLogin) (map[string]any, error) {
+	if code == "" {
+		return nil, errors.New("no authorization code")
+	}
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {login.redirectURL},
+		"client_id":     {p.cfg.OIDCClientID},
+		"code_verifier": {login.verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.OIDCClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.OIDCClientID), url.QueryEscape(p.cfg.OIDCClientSecret))
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var tok struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tok); err != nil {
+		return nil, fmt.Errorf("token response (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK || tok.Error != "" {
+		return nil, fmt.Errorf("token request: %s %s %s", resp.Status, tok.Error, tok.ErrorDescription)
+	}
+	if tok.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	return p.verifyIDToken(ctx, d, tok.IDToken, login.nonce)
+}
+
+// verifyIDToken checks the token's signature against the provider's keys
+// and its issuer, audience, expiry and nonce.
+func (p *oidcProvider) verifyIDToken(ctx context.Context, d *oidcDiscovery, raw, nonce string) (map[string]any, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ID token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("ID token header: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("ID token signature: %w", err)
+	}
+	key, err := p.key(ctx, d, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyJWS(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("ID token claims: %w", err)
+	}
+	if iss, _ := claims["iss"].(string); iss != d.Issuer {
+		return nil, fmt.Errorf("ID token issuer %q, want %q", iss, d.Issuer)
+	}
+	if !claimContains(claims["aud"], p.cfg.OIDCClientID) {
+		return nil, fmt.Errorf("ID token is not for client %q", p.cfg.OIDCClientID)
+	}
+	exp, _ := claims["exp"].(float64)
+	if time.Now().Add(-oidcClockSkew).After(time.Unix(int64(exp), 0)) {
+		return nil, errors.New("ID token expired")
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errors.New("ID token nonce mismatch")
+	}
+	return claims, nil
+}
+
+// key returns the signing key kid, refetching the key set at most once a
+// minute when the key is unknown (the provider rotated its keys).
+func (p *oidcProvider) key(ctx context.Context, d *oidcDiscovery, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	fresh := time.Since(p.keysAt) < time.Minute
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if fresh {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, d.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+
+	p.mu.Lock()
+	p.keys, p.keysAt = keys, time.Now()
+	p.mu.Unlock()
+	if key, ok = keys[kid]; !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// jwk is an RSA or EC public key from the provider's key set.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+var jwsHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// verifyJWS checks an RS* or ES* signature over signed.
+func verifyJWS(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	hash, ok := jwsHashes[alg]
+	if !ok {
+		return fmt.Errorf("unsupported ID token algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if alg[0] == 'R' && rsa.VerifyPKCS1v15(pub, hash, digest, sig) == nil {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if alg[0] == 'E' && len(sig) == 2*size {
+			r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+			if ecdsa.Verify(pub, digest, r, s) {
+				return nil
+			}
+		}
+	}
+	return errors.New("invalid ID token signature")
+}
+
+func decodeJWTPart(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// claimStrings reads a claim that is a string or a list of strings.
+func claimStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func claimContains(v any, want string) bool {
+	for _, s := range claimStrings(v) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
+
+// randomURLString returns n random bytes as unpadded base64url, which is
+// also a valid PKCE code verifier.
+func randomURLString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// upsertOIDCUser finds the account for an OIDC subject, creating it on
+// first sign-on, and applies the role from the group mapping. It returns
+// the role the account had before, "" if it is new.
+func upsertOIDCUser(db *sql.DB, subject, username, role string) (*User, string, error) {
+	user, err := scanUser(db.QueryRow(`SELECT `+userColumns+` FROM users WHERE oidc_subject = ?`, subject))
+	if err == nil {
+		prev := user.Role
+		if prev != role {
+			if _, err := db.Exec(`UPDATE users SET role = ? WHERE id = ?`, role, user.ID); err != nil {
+				return nil, "", err
+			}
+			user.Role = role
+		}
+		return user, prev, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, "", err
+	}
+
+	if !usernamePattern.MatchString(username) || username == builtinAdminName {
+		return nil, "", fmt.Errorf("username %q cannot be used for a dashboard account", username)
+	}
+	user = &User{Username: username, Role: role, OIDCSubject: subject, CreatedAt: time.Now()}
+	res, err := db.Exec(`
+		INSERT INTO users (username, password_hash, role, created_at, oidc_subject) VALUES (?, '', ?, ?, ?)
+	`, user.Username, user.Role, user.CreatedAt, user.OIDCSubject)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, "", fmt.Errorf("username %s is already taken by another account", username)
+		}
+		return nil, "", err
+	}
+	user.ID, _ = res.LastInsertId()
+	return user, "", nil
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// HANDLERS
+// ═══════════════════════════════════════════════════════════════════════════
+
+// oidcLoginFailed sends the browser back to the login form with msg.
+func (s *Server) oidcLoginFailed(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	s.log.Warn().Err(err).Str("ip", r.RemoteAddr).Msg("single sign-on failed")
+	http.Redirect(w, r, "/login?error="+url.QueryEscape(msg), http.StatusFound)
+}
+
+// handleOIDCLogin starts a sign-on at the identity provider.
+// GET /login/oidc
+func (s *Server) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil {
+		http.NotFound(w, r)
+		return
+	}
+	d, err := s.oidc.discover(r.Context())
+	if err != nil {
+		s.oidcLoginFailed(w, r, "Single sign-on is unavailable", err)
+		return
+	}
+	state, login, err := s.oidc.begin(s.cfg.OIDCRedirectURL)
+	if err != nil {
+		s.oidcLoginFailed(w, r, "Single sign-on is unavailable", err)
+		return
+	}
+	target, err := s.oidc.authURL(d, state, login)
+	if err != nil {
+		s.oidcLoginFailed(w, r, "Single sign-on is unavailable", err)
+		return
+	}
+
+	// Ties the callback to this browser; Lax so it survives the redirect back
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/login/oidc",
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(oidcLoginTimeout.Seconds()),
+	})
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// handleOIDCCallback finishes a sign-on: it redeems the code, maps the
+// user's groups to a role and starts a session.
+// GET /login/oidc/callback
+func (s *Server) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if s.oidc == nil {
+		http.NotFound(w, r)
+		return
+	}
+	q := r.URL.Query()
+	cookie, cookieErr := r.Cookie(oidcStateCookie)
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/login/oidc", HttpOnly: true, MaxAge: -1})
+
+	if e := q.Get("error"); e != "" {
+		s.oidcLoginFailed(w, r, "Single sign-on failed: "+e, errors.New(q.Get("error_description")))
+		return
+	}
+	state := q.Get("state")
+	if cookieErr != nil || state == "" || cookie.Value != state {
+		s.oidcLoginFailed(w, r, "Single sign-on expired, please try again", errors.New("state mismatch"))
+		return
+	}
+	login := s.oidc.finish(state)
+	if login == nil {
+		s.oidcLoginFailed(w, r, "Single sign-on expired, please try again", errors.New("unknown state"))
+		return
+	}
+
+	claims, err := s.oidc.authenticate(r.Context(), q.Get("code"), login)
+	if err != nil {
+		s.oidcLoginFailed(w, r, "Single sign-on failed", err)
+		return
+	}
+	subject, _ := claims["sub"].(string)
+	username, _ := claims[s.cfg.OIDCUsernameClaim].(string)
+	username = strings.ToLower(strings.TrimSpace(username))
+	if subject == "" || username == "" {
+		s.oidcLoginFailed(w, r, "Single sign-on failed", fmt.Errorf("ID token lacks sub or %s", s.cfg.OIDCUsernameClaim))
+		return
+	}
+	role := mapOIDCRole(s.cfg.OIDCRoles, claimStrings(claims[s.cfg.OIDCGroupsClaim]))
+	if role == "" {
+		s.oidcLoginFailed(w, r, "Your account has no access to this dashboard", fmt.Errorf("%s is in no mapped group", username))
+		return
+	}
+
+	user, prevRole, err := upsertOIDCUser(s.db, subject, username, role)
+	if err != nil {
+		s.oidcLoginFailed(w, r, "Single sign-on failed: "+err.Error(), err)
+		return
+	}
+	actor := "user:" + user.Username
+	if prevRole == "" {
+		s.stateStore.LogEvent("audit", "info", actor, "", "user:create",
+			fmt.Sprintf("Created user %s (%s) on single sign-on", user.Username, role), nil)
+	} else if prevRole != role {
+		s.stateStore.LogEvent("audit", "info", actor, "", "user:update",
+			fmt.Sprintf("Role of %s changed from %s to %s by group mapping", user.Username, prevRole, role), nil)
+	}
+
+	session, err := s.auth.CreateSession(user)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to create session")
+		http.Redirect(w, r, "/login?error=Server+error", http.StatusFound)
+		return
+	}
+	s.auth.SetSessionCookie(w, r, session)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
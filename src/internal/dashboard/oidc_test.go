@@ -0,0 +1,64 @@
+package dashboard
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestOIDCRoleMapping(t *testing.T) {
+	roles, err := parseOIDCRoles(" fleet-admins=admin, fleet-ops=operator,*=viewer ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		groups []string
+		want   string
+	}{
+		{[]string{"fleet-ops", "fleet-admins"}, RoleAdmin},
+		{[]string{"staff", "fleet-ops"}, RoleOperator},
+		{nil, RoleViewer},
+	}
+	for _, tt := range tests {
+		if got := mapOIDCRole(roles, tt.groups); got != tt.want {
+			t.Errorf("groups %v = %q, want %q", tt.groups, got, tt.want)
+		}
+	}
+	if got := mapOIDCRole(map[string]string{"ops": RoleOperator}, []string{"staff"}); got != "" {
+		t.Errorf("unmapped group got role %q", got)
+	}
+
+	for _, bad := range []string{"admins", "=admin", "admins=root"} {
+		if _, err := parseOIDCRoles(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestVerifyJWS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := "header.payload"
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	if err := verifyJWS("ES256", &key.PublicKey, signed, sig); err != nil {
+		t.Errorf("valid signature: %v", err)
+	}
+	if verifyJWS("ES256", &key.PublicKey, "header.tampered", sig) == nil {
+		t.Error("tampered payload accepted")
+	}
+	if verifyJWS("RS256", &key.PublicKey, signed, sig) == nil || verifyJWS("none", &key.PublicKey, signed, nil) == nil {
+		t.Error("algorithm not matching the key accepted")
+	}
+}
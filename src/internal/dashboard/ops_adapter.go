@@ -1,6 +1,9 @@
 package dashboard
 
-import "github.com/markus-barta/nixfleet/internal/ops"
+import (
+	"github.com/markus-barta/nixfleet/internal/ops"
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
 
 // hubCommandSender adapts Hub to the ops.CommandSender interface.
 type hubCommandSender struct {
@@ -12,6 +15,22 @@ func (h *hubCommandSender) SendCommand(hostID, command string) bool {
 	return h.hub.SendCommand(hostID, command)
 }
 
+// SendOpCommand implements ops.CommandSender.
+func (h *hubCommandSender) SendOpCommand(hostID string, cmd *ops.Command) bool {
+	return h.hub.SendCommandPayload(hostID, protocol.CommandPayload{
+		ID:      cmd.ID,
+		Command: cmd.OpID,
+		Args: protocol.CommandArgs{
+			Target:     cmd.Args.Target,
+			Generation: cmd.Args.Generation,
+			Ref:        cmd.Args.Ref,
+			Exec:       cmd.Args.Exec,
+			Params:     cmd.Args.Params,
+			Unit:       cmd.Args.Unit,
+		},
+	})
+}
+
 // GetOnlineHosts implements ops.CommandSender.
 func (h *hubCommandSender) GetOnlineHosts() []string {
 	return h.hub.GetOnlineHosts()
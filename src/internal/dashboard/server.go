@@ -22,6 +22,8 @@ type Server struct {
 	db             *sql.DB
 	log            zerolog.Logger
 	auth           *AuthService
+	oidc           *oidcProvider // nil unless single sign-on is configured
+	webauthn       *webauthnChallenges
 	hub            *Hub
 	logStore       *LogStore
 	versionFetcher *VersionFetcher
@@ -94,6 +96,10 @@ func New(cfg *Config, db *sql.DB, log zerolog.Logger) *Server {
 
 	// Create op and pipeline registries with default ops
 	opRegistry := ops.DefaultRegistry()
+	opRegistry.SetStoreSpace(ops.StoreSpacePolicy{ // Pre-switch /nix/store free space check
+		MinFreeBytes: int64(cfg.StoreMinFreeGB) << 30,
+		Block:        cfg.StoreLowDiskAction == "block",
+	})
 	pipelineRegistry := ops.DefaultPipelineRegistry()
 
 	// Create command sender adapter
@@ -108,7 +114,7 @@ func New(cfg *Config, db *sql.DB, log zerolog.Logger) *Server {
 	hub.SetLifecycleManager(&lifecycleManagerWrapper{lm: lifecycleManager})
 
 	// Create pipeline executor (uses lifecycle manager for op execution)
-	pipelineExecutor := ops.NewPipelineExecutor(log, nil, pipelineRegistry, stateStore, stateStore) // TODO: Update to use lifecycle manager
+	pipelineExecutor := ops.NewPipelineExecutor(log, lifecycleManager, pipelineRegistry, stateStore, stateStore)
 
 	// Create state provider for sync protocol
 	stateProvider := NewDashboardStateProvider(db, versionFetcher)
@@ -117,6 +123,7 @@ func New(cfg *Config, db *sql.DB, log zerolog.Logger) *Server {
 	stateManager := sync.NewStateManager(log, stateStore, stateProvider)
 	// Wire CORE-004 state sync into hub (browser clients receive init/full_state)
 	hub.SetStateManager(stateManager)
+	hub.SetEventLogger(stateStore)
 
 	// CORE-004: Mirror persisted events into realtime deltas so the UI can render the system log without legacy WS/REST polling.
 	stateStore.SetEventHook(func(e store.Event) {
@@ -159,6 +166,8 @@ func New(cfg *Config, db *sql.DB, log zerolog.Logger) *Server {
 		db:               db,
 		log:              log.With().Str("component", "dashboard").Logger(),
 		auth:             NewAuthService(cfg, db),
+		oidc:             newOIDCProvider(cfg),
+		webauthn:         newWebAuthnChallenges(),
 		hub:              hub,
 		logStore:         logStore,
 		versionFetcher:   versionFetcher,
@@ -239,6 +248,10 @@ func (s *Server) setupRouter() {
 	r.Get("/health", s.handleHealth)
 	r.Get("/login", s.handleLoginPage)
 	r.Post("/login", s.handleLogin)
+	r.Get("/login/oidc", s.handleOIDCLogin)
+	r.Get("/login/oidc/callback", s.handleOIDCCallback)
+	r.Post("/login/webauthn/begin", s.handleWebAuthnLoginBegin)
+	r.Post("/login/webauthn/finish", s.handleWebAuthnLoginFinish)
 
 	// WebSocket (handles both agents and browsers)
 	r.Get("/ws", s.handleWebSocket)
@@ -252,44 +265,89 @@ func (s *Server) setupRouter() {
 		// Logout requires CSRF
 		r.With(s.requireCSRF).Post("/logout", s.handleLogout)
 
-		// API routes
-		r.Route("/api", func(r chi.Router) {
+		// The signed-in user's passkeys, for any role (not for API tokens)
+		r.Route("/webauthn", func(r chi.Router) {
 			r.Use(s.requireCSRF)
+			r.Get("/credentials", s.handleGetWebAuthnCredentials)
+			r.Delete("/credentials/{id}", s.handleDeleteWebAuthnCredential)
+			r.Post("/register/begin", s.handleWebAuthnRegisterBegin)
+			r.Post("/register/finish", s.handleWebAuthnRegisterFinish)
+			r.Post("/step-up/begin", s.handleWebAuthnStepUpBegin)
+		})
+	})
+
+	// API routes: a session (with CSRF) or an API token (with a scope)
+	r.Route("/api", func(r chi.Router) {
+		r.Use(s.requireAPIAuth)
+		r.Use(s.requireCSRF)
+
+		r.Get("/hosts", s.handleGetHosts)
+		r.With(s.requireScope(ScopeAdmin)).Post("/hosts", s.handleAddHost)
 
-			r.Get("/hosts", s.handleGetHosts)
-			r.Post("/hosts", s.handleAddHost)
-			r.Post("/hosts/{hostID}/command", s.handleCommand)
-			r.Post("/hosts/{hostID}/refresh", s.handleRefreshHost) // P7000: Per-host status refresh
-			r.Post("/hosts/{hostID}/refresh-git", s.handleRefreshGit) // P2800: Force-refresh git status
-			r.Post("/hosts/{hostID}/theme-color", s.handleSetThemeColor) // P2950: Color picker
-			r.Post("/hosts/{hostID}/reboot", s.handleReboot)             // P6900: Reboot with TOTP
-			r.Delete("/hosts/{hostID}", s.handleDeleteHost)
-			r.Get("/hosts/{hostID}/logs", s.handleGetLogs)
-			r.Get("/hosts/{hostID}/output", s.handleGetOutput)           // P3300: Get command output
+		// Per-host routes; a token with a host selector only reaches its hosts
+		r.Route("/hosts/{hostID}", func(r chi.Router) {
+			r.Use(s.requireTokenHost)
+
+			r.Post("/command", s.handleCommand)
+			r.Post("/refresh", s.handleRefreshHost)       // P7000: Per-host status refresh
+			r.Post("/refresh-git", s.handleRefreshGit)    // P2800: Force-refresh git status
+			r.Post("/theme-color", s.handleSetThemeColor) // P2950: Color picker
+			r.Post("/git-ref", s.handleSetGitRef)         // Pin pull/pull-switch to a git ref
+
+			// Admin only: P6900 reboot (with TOTP or a passkey) and removing the host
+			r.With(s.requireScope(ScopeAdmin)).Post("/reboot", s.handleReboot)
+			r.With(s.requireScope(ScopeAdmin)).Delete("/", s.handleDeleteHost)
+
+			r.Get("/logs", s.handleGetLogs)
+			r.Get("/output", s.handleGetOutput)    // P3300: Get command output
+			r.Get("/tests", s.handleGetTestReport) // Latest test report
+			r.Get("/tests/junit.xml", s.handleGetTestReportJUnit)
+			r.Get("/tests/history", s.handleGetTestHistory) // Pass/flip rates, flaky tests
+			r.Get("/facts", s.handleGetHostFacts)           // Hardware and OS inventory
+			r.Get("/events", s.handleGetHostEvents)         // Get host events
 
 			// P2800: Command state machine endpoints
-			r.Post("/hosts/{hostID}/kill", s.handleKillCommand)               // Kill running command
-			r.Post("/hosts/{hostID}/timeout-action", s.handleTimeoutAction)   // Handle timeout user action
-			r.Get("/command-states", s.handleGetCommandStates)                 // Get all command states
-
-			// System log (P2800)
-			r.Get("/system-log", s.handleGetSystemLogs)
-
-			// Flake updates (P5300)
-			r.Route("/flake-updates", func(r chi.Router) {
-				r.Get("/status", s.handleFlakeUpdateStatus)
-				r.Post("/check", s.handleFlakeUpdateCheck)
-				r.Post("/merge-and-deploy", s.handleMergeAndDeploy) // Legacy
-				r.Post("/merge-pr", s.handleMergePR)                // P4700: Merge only (no deploy)
-			})
-
-			// v3 Op Engine API (CORE-001 through CORE-004)
-			r.Post("/dispatch", s.handleDispatchOp)            // Execute op(s) on hosts
-			r.Post("/dispatch/pipeline", s.handleDispatchPipeline) // Execute pipeline on hosts
-			r.Get("/ops", s.handleGetOps)                       // List available ops
-			r.Get("/pipelines", s.handleGetPipelines)           // List available pipelines
-			r.Get("/events", s.handleGetEventLog)               // Get recent events
-			r.Get("/hosts/{hostID}/events", s.handleGetHostEvents) // Get host events
+			r.Post("/kill", s.handleKillCommand)             // Kill running command
+			r.Post("/timeout-action", s.handleTimeoutAction) // Handle timeout user action
+		})
+		r.Get("/command-states", s.handleGetCommandStates) // Get all command states
+
+		// Flake input inventory: revisions and drift across the fleet
+		r.Get("/flake-inputs", s.handleGetFlakeInputs)
+
+		// System log (P2800)
+		r.Get("/system-log", s.handleGetSystemLogs)
+
+		// Flake updates (P5300)
+		r.Route("/flake-updates", func(r chi.Router) {
+			r.Get("/status", s.handleFlakeUpdateStatus)
+			r.Post("/check", s.handleFlakeUpdateCheck)
+			r.With(s.requireScope(ScopeAdmin)).Post("/merge-and-deploy", s.handleMergeAndDeploy) // Legacy
+			r.With(s.requireScope(ScopeAdmin)).Post("/merge-pr", s.handleMergePR)                // P4700: Merge only (no deploy)
+		})
+
+		// v3 Op Engine API (CORE-001 through CORE-004)
+		r.Post("/dispatch", s.handleDispatchOp)                // Execute op(s) on hosts
+		r.Post("/dispatch/pipeline", s.handleDispatchPipeline) // Execute pipeline on hosts
+		r.Get("/ops", s.handleGetOps)                          // List available ops
+		r.Get("/pipelines", s.handleGetPipelines)              // List available pipelines
+		r.Get("/events", s.handleGetEventLog)                  // Get recent events
+
+		// User accounts and roles
+		r.Route("/users", func(r chi.Router) {
+			r.Use(s.requireScope(ScopeAdmin))
+			r.Get("/", s.handleGetUsers)
+			r.Post("/", s.handleCreateUser)
+			r.Patch("/{username}", s.handleUpdateUser)
+			r.Delete("/{username}", s.handleDeleteUser)
+		})
+
+		// API tokens for scripts and automation
+		r.Route("/tokens", func(r chi.Router) {
+			r.Use(s.requireScope(ScopeAdmin))
+			r.Get("/", s.handleGetAPITokens)
+			r.Post("/", s.handleCreateAPIToken)
+			r.Delete("/{name}", s.handleRevokeAPIToken)
 		})
 	})
 
@@ -333,6 +391,12 @@ func (s *Server) requireCSRF(next http.Handler) http.Handler {
 			return
 		}
 
+		// Bearer tokens are not sent by the browser on its own, so CSRF does not apply
+		if apiTokenFromContext(r.Context()) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		session := sessionFromContext(r.Context())
 		if session == nil {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
@@ -0,0 +1,31 @@
+package dashboard
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/markus-barta/nixfleet/internal/ops"
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// SERVICE OPS - restart/stop/start an allowlisted unit
+// ═══════════════════════════════════════════════════════════════════════════
+
+// serviceUnitAllowed checks a service op's unit against the allowlist the
+// host's agent advertised, so a refused unit is blocked before it reaches
+// the agent (which checks its own allowlist again). Agents without the op
+// pass through to the op engine's agent_too_old check, a missing unit to
+// ValidateArgs.
+func serviceUnitAllowed(caps protocol.Capabilities, opID, unit string) *ops.ValidationError {
+	if !caps.HasCommand(opID) || unit == "" {
+		return nil
+	}
+	if !slices.Contains(caps.ServiceUnits, unit) {
+		return &ops.ValidationError{
+			Code:    "unit_not_allowed",
+			Message: fmt.Sprintf("%q is not in the host's service units", unit),
+		}
+	}
+	return nil
+}
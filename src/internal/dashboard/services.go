@@ -0,0 +1,90 @@
+package dashboard
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+	"github.com/markus-barta/nixfleet/internal/templates"
+)
+
+// recordServiceTransitions compares the failed systemd units in a heartbeat
+// with the set persisted from the previous one and writes an event_log entry
+// for every unit that started or stopped failing.
+// Must run before the heartbeat UPDATE overwrites services_json.
+func (h *Hub) recordServiceTransitions(hostname string, current *protocol.ServicesStatus) {
+	if h.events == nil || current == nil {
+		return
+	}
+
+	var prevJSON sql.NullString
+	if err := h.db.QueryRow(`SELECT services_json FROM hosts WHERE hostname = ?`, hostname).Scan(&prevJSON); err != nil {
+		return
+	}
+
+	var previous []protocol.FailedUnit
+	if prevJSON.Valid && prevJSON.String != "" {
+		var prev protocol.ServicesStatus
+		if err := json.Unmarshal([]byte(prevJSON.String), &prev); err == nil {
+			previous = prev.Failed
+		}
+	}
+
+	started, recovered := diffFailedUnits(previous, current.Failed)
+	if len(started) == 0 && len(recovered) == 0 {
+		return
+	}
+
+	hostID := h.hostKey(hostname)
+	for _, u := range started {
+		msg := fmt.Sprintf("%s: unit %s failed", hostname, u.Name)
+		if u.Result != "" {
+			msg += " (" + u.Result + ")"
+		}
+		h.events.LogEvent("services", "error", "agent", hostID, "unit:failed", msg, map[string]any{
+			"unit":   u.Name,
+			"result": u.Result,
+			"since":  u.Since,
+		})
+	}
+	for _, u := range recovered {
+		h.events.LogEvent("services", "success", "agent", hostID, "unit:recovered",
+			fmt.Sprintf("%s: unit %s is no longer failed", hostname, u.Name),
+			map[string]any{"unit": u.Name})
+	}
+}
+
+// diffFailedUnits returns the units present only in current (started failing)
+// and those present only in previous (recovered), matched by unit name.
+func diffFailedUnits(previous, current []protocol.FailedUnit) (started, recovered []protocol.FailedUnit) {
+	prev := make(map[string]bool, len(previous))
+	for _, u := range previous {
+		prev[u.Name] = true
+	}
+	cur := make(map[string]bool, len(current))
+	for _, u := range current {
+		cur[u.Name] = true
+		if !prev[u.Name] {
+			started = append(started, u)
+		}
+	}
+	for _, u := range previous {
+		if !cur[u.Name] {
+			recovered = append(recovered, u)
+		}
+	}
+	return started, recovered
+}
+
+// parseServicesJSON parses the persisted failed-unit report (nil if absent).
+func parseServicesJSON(servicesJSON *string) *templates.ServicesStatus {
+	if servicesJSON == nil || *servicesJSON == "" {
+		return nil
+	}
+	var s templates.ServicesStatus
+	if err := json.Unmarshal([]byte(*servicesJSON), &s); err != nil {
+		return nil
+	}
+	return &s
+}
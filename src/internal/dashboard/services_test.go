@@ -0,0 +1,25 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+func TestDiffFailedUnits(t *testing.T) {
+	previous := []protocol.FailedUnit{{Name: "backup.service"}, {Name: "nginx.service"}}
+	current := []protocol.FailedUnit{{Name: "nginx.service"}, {Name: "postgres.service", Result: "exit-code"}}
+
+	started, recovered := diffFailedUnits(previous, current)
+	if len(started) != 1 || started[0].Name != "postgres.service" || started[0].Result != "exit-code" {
+		t.Errorf("started = %+v; want [postgres.service]", started)
+	}
+	if len(recovered) != 1 || recovered[0].Name != "backup.service" {
+		t.Errorf("recovered = %+v; want [backup.service]", recovered)
+	}
+
+	started, recovered = diffFailedUnits(current, current)
+	if len(started) != 0 || len(recovered) != 0 {
+		t.Errorf("unchanged set produced started=%v recovered=%v", started, recovered)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/markus-barta/nixfleet/internal/ops"
+	"github.com/markus-barta/nixfleet/internal/protocol"
 	"github.com/markus-barta/nixfleet/internal/sync"
 )
 
@@ -54,7 +55,9 @@ func (p *DashboardStateProvider) getHosts() []any {
 		       last_seen, generation, pending_command, theme_color,
 		       location, device_type, metrics_json,
 		       lock_status_json, system_status_json, tests_status_json, tests_generation,
-		       repo_url, repo_dir
+		       repo_url, repo_dir, disk_json, services_json,
+		       reboot_required, reboot_reason, targets_status_json,
+		       git_ref, git_ref_commit, protocol_version, capabilities_json
 		FROM hosts
 		ORDER BY hostname
 	`)
@@ -70,14 +73,20 @@ func (p *DashboardStateProvider) getHosts() []any {
 			AgentVersion, LastSeen, Generation, PendingCommand             sql.NullString
 			ThemeColor, Location, DeviceType                               sql.NullString
 			MetricsJSON, LockStatusJSON, SystemStatusJSON, TestsStatusJSON  sql.NullString
-			TestsGeneration, RepoURL, RepoDir                               sql.NullString
+			TestsGeneration, RepoURL, RepoDir, DiskJSON                     sql.NullString
+			ServicesJSON, RebootReason, TargetsJSON                        sql.NullString
+			GitRef, GitRefCommit, CapabilitiesJSON                         sql.NullString
+			ProtocolVersion                                                sql.NullInt64
+			RebootRequired                                                 sql.NullBool
 		}
 		if err := rows.Scan(
 			&h.ID, &h.Hostname, &h.HostType, &h.AgentVersion,
 			&h.Status, &h.LastSeen, &h.Generation, &h.PendingCommand,
 			&h.ThemeColor, &h.Location, &h.DeviceType, &h.MetricsJSON,
 			&h.LockStatusJSON, &h.SystemStatusJSON, &h.TestsStatusJSON, &h.TestsGeneration,
-			&h.RepoURL, &h.RepoDir,
+			&h.RepoURL, &h.RepoDir, &h.DiskJSON, &h.ServicesJSON,
+			&h.RebootRequired, &h.RebootReason, &h.TargetsJSON,
+			&h.GitRef, &h.GitRefCommit, &h.ProtocolVersion, &h.CapabilitiesJSON,
 		); err != nil {
 			continue
 		}
@@ -99,7 +108,7 @@ func (p *DashboardStateProvider) getHosts() []any {
 			ThemeColor:     h.ThemeColor,
 			Location:       h.Location,
 			DeviceType:     h.DeviceType,
-		})
+		}, parseCapabilities(h.CapabilitiesJSON))
 
 		// Compute agent_outdated (dashboard-side)
 		agentVersion := nullStr(h.AgentVersion)
@@ -114,8 +123,30 @@ func (p *DashboardStateProvider) getHosts() []any {
 			}
 		}
 
+		// Parse disk usage JSON
+		var disk any
+		if h.DiskJSON.Valid && h.DiskJSON.String != "" {
+			var d any
+			if err := json.Unmarshal([]byte(h.DiskJSON.String), &d); err == nil {
+				disk = d
+			}
+		}
+
+		// Parse failed systemd units JSON (nil = not reported)
+		var services any
+		if h.ServicesJSON.Valid && h.ServicesJSON.String != "" {
+			var sv any
+			if err := json.Unmarshal([]byte(h.ServicesJSON.String), &sv); err == nil {
+				services = sv
+			}
+		}
+
 		// Build update_status (git from VersionFetcher, lock/system from DB)
-		updateStatus := p.buildUpdateStatus(nullStr(h.Generation), h.LockStatusJSON, h.SystemStatusJSON, h.TestsStatusJSON, h.TestsGeneration, h.RepoURL, h.RepoDir)
+		pin := GitPin{Ref: nullStr(h.GitRef), Commit: nullStr(h.GitRefCommit)}
+		updateStatus := p.buildUpdateStatus(nullStr(h.Generation), pin, h.LockStatusJSON, h.SystemStatusJSON, h.TestsStatusJSON, h.TestsGeneration, h.RepoURL, h.RepoDir)
+		if targets := parseTargetStatuses(h.TargetsJSON); len(targets) > 0 {
+			updateStatus["targets"] = targets
+		}
 
 		hosts = append(hosts, map[string]any{
 			"id":              h.ID,
@@ -132,16 +163,22 @@ func (p *DashboardStateProvider) getHosts() []any {
 			"device_type":     nullStr(h.DeviceType),
 			"available_ops":   availableOps,
 			"metrics":         metrics,
+			"disk":            disk,
+			"services":        services,
+			"reboot_required": h.RebootRequired.Valid && h.RebootRequired.Bool,
+			"reboot_reason":   nullStr(h.RebootReason),
+			"git_ref":         pin.Ref,
+			"protocol_version": h.ProtocolVersion.Int64,
 			"update_status":   updateStatus,
 		})
 	}
 	return hosts
 }
 
-func (p *DashboardStateProvider) buildUpdateStatus(generation string, lockJSON, systemJSON, testsJSON, testsGen, repoURL, repoDir sql.NullString) map[string]any {
+func (p *DashboardStateProvider) buildUpdateStatus(generation string, pin GitPin, lockJSON, systemJSON, testsJSON, testsGen, repoURL, repoDir sql.NullString) map[string]any {
 	var gitStatus map[string]any
 	if p.vf != nil {
-		status, msg, checked := p.vf.GetGitStatus(generation)
+		status, msg, checked := p.vf.GetGitStatus(generation, pin)
 		gitStatus = map[string]any{"status": status, "message": msg, "checked_at": checked}
 	} else {
 		gitStatus = map[string]any{
@@ -247,7 +284,7 @@ func (p *DashboardStateProvider) calculateAvailableOps(h *struct {
 	ID, Hostname, HostType, Status                      string
 	AgentVersion, LastSeen, Generation, PendingCommand  sql.NullString
 	ThemeColor, Location, DeviceType                    sql.NullString
-}) []string {
+}, caps protocol.Capabilities) []string {
 	available := make([]string, 0)
 
 	// Host must be online (status = "online")
@@ -264,15 +301,19 @@ func (p *DashboardStateProvider) calculateAvailableOps(h *struct {
 	// Standard ops available for all online, idle hosts
 	available = append(available, "pull", "switch", "test")
 
+	// Nix store garbage collection
+	available = append(available, "gc")
+
 	// Reboot requires TOTP (always show if online + idle)
 	available = append(available, "reboot")
 
-	return available
+	// Only what the agent can run
+	return supportedOps(available, caps.HasCommand)
 }
 
 func (p *DashboardStateProvider) getRecentCommands(limit int) []any {
 	rows, err := p.db.Query(`
-		SELECT id, host_id, op, status, created_at, started_at, finished_at, exit_code, error
+		SELECT id, host_id, op, status, created_at, started_at, finished_at, exit_code, error, args_json
 		FROM commands
 		WHERE status NOT IN ('SUCCESS', 'ERROR', 'TIMEOUT', 'SKIPPED', 'BLOCKED')
 		   OR created_at > datetime('now', '-1 hour')
@@ -290,10 +331,10 @@ func (p *DashboardStateProvider) getRecentCommands(limit int) []any {
 			ID, HostID, Op, Status               string
 			CreatedAt, StartedAt, FinishedAt     sql.NullString
 			ExitCode                             sql.NullInt64
-			Error                                sql.NullString
+			Error, ArgsJSON                      sql.NullString
 		}
 		if err := rows.Scan(&c.ID, &c.HostID, &c.Op, &c.Status,
-			&c.CreatedAt, &c.StartedAt, &c.FinishedAt, &c.ExitCode, &c.Error); err != nil {
+			&c.CreatedAt, &c.StartedAt, &c.FinishedAt, &c.ExitCode, &c.Error, &c.ArgsJSON); err != nil {
 			continue
 		}
 		cmd := map[string]any{
@@ -311,6 +352,12 @@ func (p *DashboardStateProvider) getRecentCommands(limit int) []any {
 		if c.Error.Valid {
 			cmd["error"] = c.Error.String
 		}
+		if c.ArgsJSON.Valid {
+			var args map[string]any
+			if err := json.Unmarshal([]byte(c.ArgsJSON.String), &args); err == nil {
+				cmd["args"] = args
+			}
+		}
 		commands = append(commands, cmd)
 	}
 	return commands
@@ -0,0 +1,106 @@
+package dashboard
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// DEPLOY TARGETS - per-target System status (system + home-manager users)
+// ═══════════════════════════════════════════════════════════════════════════
+
+// statusRank orders compartment statuses from best to worst; the host-wide
+// System compartment shows the worst of its targets.
+var statusRank = map[string]int{"ok": 0, "unknown": 1, "outdated": 2, "error": 3, "working": 4}
+
+// loadTargetStatuses returns the stored per-target statuses of a host.
+func (h *Hub) loadTargetStatuses(hostID string) []protocol.TargetStatus {
+	var raw sql.NullString
+	_ = h.db.QueryRow(`SELECT targets_status_json FROM hosts WHERE hostname = ?`, hostID).Scan(&raw)
+	return parseTargetStatuses(raw)
+}
+
+// saveTargetStatuses stores the per-target statuses of a host.
+func (h *Hub) saveTargetStatuses(hostID string, targets []protocol.TargetStatus) {
+	data, err := json.Marshal(targets)
+	if err != nil {
+		return
+	}
+	if _, err := h.db.Exec(`UPDATE hosts SET targets_status_json = ? WHERE hostname = ?`, string(data), hostID); err != nil {
+		h.log.Error().Err(err).Str("host", hostID).Msg("failed to store target status")
+	}
+}
+
+func parseTargetStatuses(raw sql.NullString) []protocol.TargetStatus {
+	if !raw.Valid || raw.String == "" {
+		return nil
+	}
+	var targets []protocol.TargetStatus
+	if err := json.Unmarshal([]byte(raw.String), &targets); err != nil {
+		return nil
+	}
+	return targets
+}
+
+// mergeTargetStatuses takes the target list (and order) the agent reports.
+// A target the agent has not checked yet keeps its stored status: the agent
+// restarts after a switch and forgets what it reported.
+func mergeTargetStatuses(stored, reported []protocol.TargetStatus) []protocol.TargetStatus {
+	merged := make([]protocol.TargetStatus, len(reported))
+	for i, t := range reported {
+		merged[i] = t
+		if t.System.Status != "" {
+			continue
+		}
+		for _, old := range stored {
+			if old.Name == t.Name {
+				merged[i].System = old.System
+				break
+			}
+		}
+	}
+	return merged
+}
+
+// setTargetSystem records the outcome of a command on one target (or on all
+// targets if name is empty) and returns the resulting host-wide System
+// status. Hosts without stored targets just get sys.
+func setTargetSystem(targets []protocol.TargetStatus, name string, sys protocol.StatusCheck) protocol.StatusCheck {
+	for i := range targets {
+		if name == "" || targets[i].Name == name {
+			targets[i].System = sys
+		}
+	}
+	if name == "" || len(targets) == 0 {
+		return sys
+	}
+	return worstTargetStatus(targets)
+}
+
+// worstTargetStatus combines per-target statuses into the host-wide System
+// status: the worst status wins, with the messages of the targets not ok.
+func worstTargetStatus(targets []protocol.TargetStatus) protocol.StatusCheck {
+	combined := protocol.StatusCheck{Status: "ok", CheckedAt: time.Now().UTC().Format(time.RFC3339)}
+	var messages []string
+	for _, t := range targets {
+		if t.System.Status == "" {
+			continue
+		}
+		if statusRank[t.System.Status] > statusRank[combined.Status] {
+			combined.Status = t.System.Status
+		}
+		if t.System.Status != "ok" {
+			messages = append(messages, t.Name+": "+t.System.Message)
+		}
+	}
+	if len(messages) == 0 {
+		combined.Message = "All targets current"
+	} else {
+		combined.Message = strings.Join(messages, "; ")
+	}
+	return combined
+}
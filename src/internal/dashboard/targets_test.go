@@ -0,0 +1,39 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+func TestTargetStatuses(t *testing.T) {
+	stored := []protocol.TargetStatus{
+		{Name: "system", Type: "nixos", System: protocol.StatusCheck{Status: "ok", Message: "System is current"}},
+		{Name: "alice", Type: "home-manager", User: "alice", System: protocol.StatusCheck{Status: "error", Message: "switch failed"}},
+	}
+
+	// A freshly restarted agent has not checked alice yet: keep the stored status
+	targets := mergeTargetStatuses(stored, []protocol.TargetStatus{
+		{Name: "system", Type: "nixos", System: protocol.StatusCheck{Status: "outdated", Message: "System needs switch"}},
+		{Name: "alice", Type: "home-manager", User: "alice"},
+		{Name: "bob", Type: "home-manager", User: "bob"},
+	})
+	if len(targets) != 3 || targets[0].System.Status != "outdated" || targets[1].System.Status != "error" || targets[2].System.Status != "" {
+		t.Fatalf("merge = %+v", targets)
+	}
+
+	// A switch of one target only changes that target
+	sys := setTargetSystem(targets, "alice", protocol.StatusCheck{Status: "ok", Message: "Last switch succeeded"})
+	if targets[1].System.Status != "ok" || targets[0].System.Status != "outdated" {
+		t.Errorf("targets = %+v", targets)
+	}
+	if sys.Status != "outdated" || sys.Message != "system: System needs switch" {
+		t.Errorf("host system = %+v", sys)
+	}
+
+	// Without a target the outcome applies to all of them
+	sys = setTargetSystem(targets, "", protocol.StatusCheck{Status: "ok", Message: "Last switch succeeded"})
+	if sys.Message != "Last switch succeeded" || worstTargetStatus(targets).Status != "ok" {
+		t.Errorf("all targets: %+v / %+v", sys, targets)
+	}
+}
@@ -0,0 +1,266 @@
+package dashboard
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// TEST HISTORY + FLAKY DETECTION
+// ═══════════════════════════════════════════════════════════════════════════
+
+const (
+	// testHistoryWindow is how many recent runs per test the stats look at.
+	testHistoryWindow = 20
+	// testHistoryRetention is how long per-test results are kept.
+	testHistoryRetention = 90 * 24 * time.Hour
+	// testConsistentFailures: a test that failed this many runs in a row is
+	// broken, not flaky, whatever its history says.
+	testConsistentFailures = 3
+)
+
+// testRun is one stored outcome of a test (newest first when loaded).
+type testRun struct {
+	Generation string    `json:"generation"`
+	RunAt      time.Time `json:"run_at"`
+	Status     string    `json:"status"` // "pass", "fail", "error", "skipped"
+	Duration   float64   `json:"duration"`
+	Message    string    `json:"message,omitempty"`
+}
+
+// TestStats summarizes the recent history of one test on one host.
+type TestStats struct {
+	Name       string    `json:"name"`
+	Runs       int       `json:"runs"`
+	Passed     int       `json:"passed"`
+	Failed     int       `json:"failed"` // fail + error
+	Skipped    int       `json:"skipped"`
+	PassRate   float64   `json:"pass_rate"` // passed / (passed + failed), 0..1
+	FlipRate   float64   `json:"flip_rate"` // outcome changes / (outcomes - 1), 0..1
+	Flaky      bool      `json:"flaky"`
+	LastStatus string    `json:"last_status"`
+	Recent     []testRun `json:"recent"` // newest first
+}
+
+// computeTestStats derives pass-rate, flip-rate and the flaky verdict from
+// a test's runs (newest first). Skipped runs count for nothing.
+//
+// A test is flaky when it failed and then passed again on the same
+// generation, i.e. it recovered without a deploy, and is not currently
+// failing consistently. A first failure is never flaky, so a real breakage
+// still turns the compartment red.
+func computeTestStats(name string, runs []testRun) TestStats {
+	st := TestStats{Name: name, Runs: len(runs), Recent: runs}
+	if len(runs) > 0 {
+		st.LastStatus = runs[0].Status
+	}
+
+	var outcomes []testRun // pass/fail only, newest first
+	for _, r := range runs {
+		switch r.Status {
+		case "pass":
+			st.Passed++
+		case "skipped":
+			st.Skipped++
+			continue
+		default:
+			st.Failed++
+		}
+		outcomes = append(outcomes, r)
+	}
+	if n := st.Passed + st.Failed; n > 0 {
+		st.PassRate = float64(st.Passed) / float64(n)
+	}
+
+	flips := 0
+	recovered := false
+	for i := 1; i < len(outcomes); i++ {
+		newer, older := outcomes[i-1], outcomes[i]
+		if (newer.Status == "pass") == (older.Status == "pass") {
+			continue
+		}
+		flips++
+		if newer.Status == "pass" && newer.Generation == older.Generation {
+			recovered = true
+		}
+	}
+	if len(outcomes) > 1 {
+		st.FlipRate = float64(flips) / float64(len(outcomes)-1)
+	}
+
+	consistent := len(outcomes) >= testConsistentFailures
+	for i := 0; consistent && i < testConsistentFailures; i++ {
+		consistent = outcomes[i].Status != "pass"
+	}
+	st.Flaky = recovered && !consistent
+	return st
+}
+
+// recordTestRun stores the per-test outcomes of a report against the
+// generation the host is currently on, and prunes expired results.
+func (h *Hub) recordTestRun(hostname string, report protocol.TestReportPayload) {
+	var gen sql.NullString
+	_ = h.db.QueryRow(`SELECT generation FROM hosts WHERE hostname = ?`, hostname).Scan(&gen)
+
+	runAt := time.Now().UTC()
+	if t, err := time.Parse(time.RFC3339, report.Timestamp); err == nil {
+		runAt = t.UTC()
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		h.log.Error().Err(err).Str("host", hostname).Msg("failed to record test run")
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	hostID := h.hostKey(hostname)
+	for _, c := range report.Cases {
+		if _, err := tx.Exec(`
+			INSERT INTO test_results (host_id, generation, run_at, test_name, status, duration, message)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, hostID, gen.String, runAt, c.Name, c.Status, c.Duration, c.Message); err != nil {
+			h.log.Error().Err(err).Str("host", hostname).Msg("failed to record test run")
+			return
+		}
+	}
+	_, _ = tx.Exec(`DELETE FROM test_results WHERE host_id = ? AND run_at < ?`, hostID, runAt.Add(-testHistoryRetention))
+	if err := tx.Commit(); err != nil {
+		h.log.Error().Err(err).Str("host", hostname).Msg("failed to record test run")
+	}
+}
+
+// loadTestStats returns the stats of every test seen on a host (by host ID),
+// or only of the named tests if names is non-empty. Sorted by name.
+func loadTestStats(db *sql.DB, hostID string, names []string) ([]TestStats, error) {
+	query := `SELECT test_name, generation, run_at, status, duration, message
+		FROM test_results WHERE host_id = ?`
+	args := []any{hostID}
+	if len(names) > 0 {
+		query += ` AND test_name IN (?` + strings.Repeat(", ?", len(names)-1) + `)`
+		for _, n := range names {
+			args = append(args, n)
+		}
+	}
+	query += ` ORDER BY test_name, run_at DESC, id DESC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byName := make(map[string][]testRun)
+	for rows.Next() {
+		var name string
+		var gen, msg sql.NullString
+		var dur sql.NullFloat64
+		var r testRun
+		if err := rows.Scan(&name, &gen, &r.RunAt, &r.Status, &dur, &msg); err != nil {
+			return nil, err
+		}
+		if len(byName[name]) >= testHistoryWindow {
+			continue
+		}
+		r.Generation, r.Duration, r.Message = gen.String, dur.Float64, msg.String
+		byName[name] = append(byName[name], r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := make([]TestStats, 0, len(byName))
+	for name, runs := range byName {
+		stats = append(stats, computeTestStats(name, runs))
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats, nil
+}
+
+// allFlaky reports whether stats covers want tests and all of them are flaky.
+func allFlaky(stats []TestStats, want int) bool {
+	if want == 0 || len(stats) != want {
+		return false
+	}
+	for _, st := range stats {
+		if !st.Flaky {
+			return false
+		}
+	}
+	return true
+}
+
+// flakyTestsStatus turns a red Tests compartment into "flaky" when every
+// test that failed in the host's latest report is known to be flaky.
+// Other statuses are returned unchanged.
+func (h *Hub) flakyTestsStatus(hostname string, check protocol.StatusCheck) protocol.StatusCheck {
+	if check.Status != "error" {
+		return check
+	}
+
+	var raw sql.NullString
+	if err := h.db.QueryRow(`SELECT test_report_json FROM hosts WHERE hostname = ?`, hostname).Scan(&raw); err != nil || !raw.Valid {
+		return check
+	}
+	var report protocol.TestReportPayload
+	if err := json.Unmarshal([]byte(raw.String), &report); err != nil {
+		return check
+	}
+	var failing []string
+	for _, c := range report.Cases {
+		if c.Status == "fail" || c.Status == "error" {
+			failing = append(failing, c.Name)
+		}
+	}
+	if len(failing) == 0 {
+		return check
+	}
+
+	stats, err := loadTestStats(h.db, h.hostKey(hostname), failing)
+	if err != nil || !allFlaky(stats, len(failing)) {
+		return check
+	}
+
+	check.Status = "flaky"
+	check.Message = fmt.Sprintf("Flaky: %s failed (passes intermittently)", strings.Join(failing, ", "))
+	return check
+}
+
+// handleGetTestHistory returns per-test pass/flip rates and recent runs.
+// GET /api/hosts/{hostID}/tests/history
+func (s *Server) handleGetTestHistory(w http.ResponseWriter, r *http.Request) {
+	hostID := chi.URLParam(r, "hostID")
+
+	var id string
+	err := s.db.QueryRow(`SELECT id FROM hosts WHERE id = ? OR hostname = ?`, hostID, hostID).Scan(&id)
+	if err == sql.ErrNoRows {
+		s.jsonError(w, "Host not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, "Failed to load host", http.StatusInternalServerError)
+		return
+	}
+
+	stats, err := loadTestStats(s.db, id, nil)
+	if err != nil {
+		s.log.Error().Err(err).Str("host", hostID).Msg("failed to load test history")
+		s.jsonError(w, "Failed to load test history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"host_id": id,
+		"window":  testHistoryWindow,
+		"tests":   stats,
+	})
+}
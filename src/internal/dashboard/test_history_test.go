@@ -0,0 +1,89 @@
+package dashboard
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// runs builds a history from oldest to newest ("gen:status" entries) and
+// returns it newest first, as loadTestStats does.
+func runs(entries ...string) []testRun {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]testRun, len(entries))
+	for i, e := range entries {
+		gen, status := e[:1], e[2:]
+		out[len(entries)-1-i] = testRun{Generation: gen, Status: status, RunAt: base.Add(time.Duration(i) * time.Hour)}
+	}
+	return out
+}
+
+func TestComputeTestStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		runs  []testRun
+		flaky bool
+	}{
+		{"always passes", runs("a:pass", "a:pass", "a:pass"), false},
+		{"first failure is not flaky", runs("a:pass", "a:pass", "a:fail"), false},
+		{"recovered on same generation", runs("a:pass", "a:fail", "a:pass", "a:fail"), true},
+		{"fixed by a deploy", runs("a:fail", "b:pass", "b:fail"), false},
+		{"consistently failing again", runs("a:fail", "a:pass", "a:fail", "a:fail", "a:error"), false},
+		{"skips are ignored", runs("a:fail", "a:skipped", "a:pass", "a:fail"), true},
+	}
+	for _, tt := range tests {
+		if st := computeTestStats("t", tt.runs); st.Flaky != tt.flaky {
+			t.Errorf("%s: flaky = %v, want %v (%+v)", tt.name, st.Flaky, tt.flaky, st)
+		}
+	}
+
+	st := computeTestStats("t", runs("a:pass", "a:fail", "a:pass", "a:pass", "a:skipped"))
+	if st.Runs != 5 || st.Passed != 3 || st.Failed != 1 || st.Skipped != 1 {
+		t.Errorf("counts = %+v", st)
+	}
+	if st.PassRate != 0.75 || st.FlipRate != 2.0/3.0 || st.LastStatus != "skipped" {
+		t.Errorf("pass_rate=%v flip_rate=%v last=%s", st.PassRate, st.FlipRate, st.LastStatus)
+	}
+}
+
+func TestLoadTestStats(t *testing.T) {
+	db, err := InitDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	base := time.Now().UTC().Add(-time.Hour)
+	insert := func(host, name, status string, i int) {
+		if _, err := db.Exec(`INSERT INTO test_results (host_id, generation, run_at, test_name, status, duration)
+			VALUES (?, 'g1', ?, ?, ?, 1.5)`, host, base.Add(time.Duration(i)*time.Minute), name, status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, s := range []string{"pass", "fail", "pass", "fail"} {
+		insert("web01", "01-http.sh", s, i)
+	}
+	for i := 0; i < testHistoryWindow+5; i++ {
+		insert("web01", "02-disk.sh", "pass", i)
+	}
+	insert("db01", "01-http.sh", "fail", 0)
+
+	stats, err := loadTestStats(db, "web01", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || stats[0].Name != "01-http.sh" || stats[1].Name != "02-disk.sh" {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if !stats[0].Flaky || stats[0].LastStatus != "fail" || stats[0].Recent[0].Duration != 1.5 {
+		t.Errorf("01-http.sh = %+v", stats[0])
+	}
+	if stats[1].Runs != testHistoryWindow || stats[1].Flaky {
+		t.Errorf("02-disk.sh runs = %d, want window %d", stats[1].Runs, testHistoryWindow)
+	}
+
+	only, err := loadTestStats(db, "web01", []string{"01-http.sh"})
+	if err != nil || len(only) != 1 || !allFlaky(only, 1) {
+		t.Errorf("filtered stats = %+v, %v", only, err)
+	}
+}
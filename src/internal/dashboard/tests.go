@@ -0,0 +1,272 @@
+package dashboard
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// TEST REPORTS - per-script results from hosts/<host>/tests/
+// ═══════════════════════════════════════════════════════════════════════════
+
+// handleTestProgress persists the live counters (shown by the Tests cell on
+// page load) and forwards them to browsers.
+func (h *Hub) handleTestProgress(hostname string, payload protocol.TestProgressPayload) {
+	if data, err := json.Marshal(payload); err == nil {
+		_, _ = h.db.Exec(`UPDATE hosts SET test_progress = ? WHERE hostname = ?`, string(data), hostname)
+	}
+
+	h.BroadcastToBrowsers(map[string]any{
+		"type": "test_progress",
+		"payload": map[string]any{
+			"host_id": hostname,
+			"current": payload.Current,
+			"total":   payload.Total,
+			"passed":  payload.Passed,
+			"failed":  payload.Failed,
+			"running": payload.Running,
+			"result":  payload.Result,
+			"name":    payload.Name,
+		},
+	})
+}
+
+// handleTestReport stores the latest report of a host (one per host, the
+// previous run is replaced) and logs the outcome to the event log.
+func (h *Hub) handleTestReport(hostname string, report protocol.TestReportPayload) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+	if _, err := h.db.Exec(`UPDATE hosts SET test_report_json = ? WHERE hostname = ?`, string(data), hostname); err != nil {
+		h.log.Error().Err(err).Str("host", hostname).Msg("failed to store test report")
+	}
+	h.recordTestRun(hostname, report)
+
+	bad := report.Failures + report.Errors
+	h.log.Info().
+		Str("host", hostname).
+		Int("tests", report.Tests).
+		Int("failed", bad).
+		Int("skipped", report.Skipped).
+		Msg("test report received")
+
+	if h.events != nil {
+		level := "success"
+		msg := fmt.Sprintf("%s: %d/%d tests passed", hostname, report.Tests-bad-report.Skipped, report.Tests)
+		if bad > 0 {
+			level = "error"
+			var names []string
+			for _, c := range report.Cases {
+				if c.Status == "fail" || c.Status == "error" {
+					names = append(names, c.Name)
+				}
+			}
+			msg += " (failed: " + strings.Join(names, ", ") + ")"
+			// Known-flaky failures are worth a note, not an alarm
+			if stats, err := loadTestStats(h.db, h.hostKey(hostname), names); err == nil && allFlaky(stats, len(names)) {
+				level = "warn"
+				msg += ", all flaky"
+			}
+		}
+		h.events.LogEvent("tests", level, "agent", h.hostKey(hostname), "tests:report", msg, map[string]any{
+			"tests":    report.Tests,
+			"failures": report.Failures,
+			"errors":   report.Errors,
+			"skipped":  report.Skipped,
+			"duration": report.Duration,
+		})
+	}
+
+	h.BroadcastToBrowsers(map[string]any{
+		"type": "test_report",
+		"payload": map[string]any{
+			"host_id": hostname,
+			"report":  report,
+		},
+	})
+}
+
+// loadTestReport returns the stored report for a host ID or hostname
+// (nil if the host never reported one).
+func (s *Server) loadTestReport(hostID string) (*protocol.TestReportPayload, error) {
+	var raw sql.NullString
+	err := s.db.QueryRow(`SELECT test_report_json FROM hosts WHERE id = ? OR hostname = ?`, hostID, hostID).Scan(&raw)
+	if err == sql.ErrNoRows || (err == nil && (!raw.Valid || raw.String == "")) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report protocol.TestReportPayload
+	if err := json.Unmarshal([]byte(raw.String), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// handleGetTestReport returns the latest test report of a host.
+// GET /api/hosts/{hostID}/tests
+func (s *Server) handleGetTestReport(w http.ResponseWriter, r *http.Request) {
+	hostID := chi.URLParam(r, "hostID")
+
+	report, err := s.loadTestReport(hostID)
+	if err != nil {
+		s.log.Error().Err(err).Str("host", hostID).Msg("failed to load test report")
+		s.jsonError(w, "Failed to load test report", http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		s.jsonError(w, "No test report", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// handleGetTestReportJUnit returns the latest test report as JUnit XML,
+// for CI tooling that already understands that format.
+// GET /api/hosts/{hostID}/tests/junit.xml
+func (s *Server) handleGetTestReportJUnit(w http.ResponseWriter, r *http.Request) {
+	hostID := chi.URLParam(r, "hostID")
+
+	report, err := s.loadTestReport(hostID)
+	if err != nil {
+		s.log.Error().Err(err).Str("host", hostID).Msg("failed to load test report")
+		http.Error(w, "Failed to load test report", http.StatusInternalServerError)
+		return
+	}
+	if report == nil {
+		http.Error(w, "No test report", http.StatusNotFound)
+		return
+	}
+
+	data, err := junitXML(report)
+	if err != nil {
+		http.Error(w, "Failed to encode test report", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	_, _ = w.Write(data)
+}
+
+type junitSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr,omitempty"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// junitXML converts a report into a JUnit <testsuite>. Scripts that speak
+// TAP contribute one testcase per assertion (classname = script), other
+// scripts are a single testcase each. Suite counters are recomputed from
+// the emitted cases so they always match.
+func junitXML(report *protocol.TestReportPayload) ([]byte, error) {
+	suite := junitSuite{
+		Name:      report.Suite,
+		Time:      fmt.Sprintf("%.3f", report.Duration),
+		Timestamp: report.Timestamp,
+	}
+
+	add := func(jc junitCase) {
+		suite.Tests++
+		switch {
+		case jc.Failure != nil:
+			suite.Failures++
+		case jc.Error != nil:
+			suite.Errors++
+		case jc.Skipped != nil:
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, jc)
+	}
+
+	for _, tc := range report.Cases {
+		// Timeouts and crashes are reported on the script, not its assertions
+		if len(tc.TAP) > 0 && tc.Status != "error" {
+			for _, t := range tc.TAP {
+				name := fmt.Sprintf("%d", t.Number)
+				if t.Description != "" {
+					name += " - " + t.Description
+				}
+				jc := junitCase{Name: name, Classname: tc.Name, Time: "0"}
+				switch t.Status {
+				case "fail":
+					jc.Failure = &junitMessage{Message: "not ok " + name}
+				case "skip":
+					jc.Skipped = &junitMessage{Message: t.Directive}
+				}
+				add(jc)
+			}
+			// A failing exit code with passing assertions still has to show up
+			if tc.Status == "fail" && !tapHasFailure(tc.TAP) {
+				add(junitCase{
+					Name:      "exit status",
+					Classname: tc.Name,
+					Time:      fmt.Sprintf("%.3f", tc.Duration),
+					Failure:   &junitMessage{Message: tc.Message},
+					SystemOut: tc.Output,
+				})
+			}
+			continue
+		}
+
+		jc := junitCase{
+			Name:      tc.Name,
+			Classname: report.Suite,
+			Time:      fmt.Sprintf("%.3f", tc.Duration),
+			SystemOut: tc.Output,
+		}
+		switch tc.Status {
+		case "fail":
+			jc.Failure = &junitMessage{Message: tc.Message}
+		case "error":
+			jc.Error = &junitMessage{Message: tc.Message}
+		case "skipped":
+			jc.Skipped = &junitMessage{Message: tc.Message}
+		}
+		add(jc)
+	}
+
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func tapHasFailure(results []protocol.TAPResult) bool {
+	for _, r := range results {
+		if r.Status == "fail" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package dashboard
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/markus-barta/nixfleet/internal/protocol"
+)
+
+func TestJUnitXML(t *testing.T) {
+	report := &protocol.TestReportPayload{
+		Suite:    "hosts/web01/tests",
+		Duration: 4.5,
+		Cases: []protocol.TestCase{
+			{Name: "01-nginx.sh", Status: "fail", TAP: []protocol.TAPResult{
+				{Number: 1, Description: "nginx is running", Status: "pass"},
+				{Number: 2, Description: "port 443 answers", Status: "fail"},
+				{Number: 3, Status: "skip", Directive: "no ipv6"},
+			}},
+			{Name: "02-backup.sh", Status: "pass", Duration: 1.25},
+			{Name: "03-slow.sh", Status: "error", Message: "timed out after 5m0s", Output: "waiting <for> db"},
+			{Name: "04-exit.sh", Status: "fail", Message: "exit code 2", TAP: []protocol.TAPResult{
+				{Number: 1, Status: "pass"},
+			}},
+		},
+	}
+
+	data, err := junitXML(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "<?xml") {
+		t.Errorf("missing XML header: %s", data)
+	}
+
+	var suite junitSuite
+	if err := xml.Unmarshal(data, &suite); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, data)
+	}
+	// 3 TAP cases + 1 plain + 1 error + 1 TAP pass + 1 synthetic exit status failure
+	if suite.Tests != 7 || suite.Failures != 2 || suite.Errors != 1 || suite.Skipped != 1 {
+		t.Errorf("counters tests=%d failures=%d errors=%d skipped=%d", suite.Tests, suite.Failures, suite.Errors, suite.Skipped)
+	}
+	if len(suite.Cases) != 7 {
+		t.Fatalf("got %d cases", len(suite.Cases))
+	}
+	if c := suite.Cases[1]; c.Classname != "01-nginx.sh" || c.Name != "2 - port 443 answers" || c.Failure == nil {
+		t.Errorf("TAP failure case = %+v", c)
+	}
+	if c := suite.Cases[4]; c.Error == nil || c.Error.Message != "timed out after 5m0s" || c.SystemOut != "waiting <for> db" {
+		t.Errorf("error case = %+v", c)
+	}
+	if c := suite.Cases[6]; c.Name != "exit status" || c.Failure == nil || c.Failure.Message != "exit code 2" {
+		t.Errorf("exit status case = %+v", c)
+	}
+}
@@ -0,0 +1,386 @@
+package dashboard
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// USERS - accounts with their own password, TOTP and role
+// ═══════════════════════════════════════════════════════════════════════════
+
+// User roles. Each maps to the API token scope of the same reach: viewers
+// only read, operators dispatch ops, admins also reboot, add and remove
+// hosts, and manage users and tokens.
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+var roleScopes = map[string]string{
+	RoleViewer:   ScopeRead,
+	RoleOperator: ScopeDispatch,
+	RoleAdmin:    ScopeAdmin,
+}
+
+// builtinAdminName is the account configured by NIXFLEET_PASSWORD_HASH and
+// NIXFLEET_TOTP_SECRET. It is not stored in the users table.
+const builtinAdminName = "admin"
+
+const minPasswordLength = 8
+
+var usernamePattern = regexp.MustCompile(`^[a-z][a-z0-9._-]{0,31}$`)
+
+// User is a dashboard account.
+type User struct {
+	ID           int64     `json:"id"` // 0 for the built-in admin
+	Username     string    `json:"username"`
+	Role         string    `json:"role"`
+	PasswordHash string    `json:"-"`
+	TOTPSecret   string    `json:"-"`
+	OIDCSubject  string    `json:"-"` // set for accounts created by single sign-on
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// HasTOTP reports whether the user has a TOTP secret, making TOTP part of
+// their login and the step-up check for TOTP-gated ops.
+func (u *User) HasTOTP() bool {
+	return u.TOTPSecret != ""
+}
+
+// Scope is the API scope the user's role grants.
+func (u *User) Scope() string {
+	return roleScopes[u.Role]
+}
+
+// MarshalJSON adds has_totp, since the secret itself is never sent.
+func (u *User) MarshalJSON() ([]byte, error) {
+	type user User
+	return json.Marshal(struct {
+		*user
+		HasTOTP bool `json:"has_totp"`
+		Builtin bool `json:"builtin,omitempty"`
+		SSO     bool `json:"sso,omitempty"`
+	}{(*user)(u), u.HasTOTP(), u.ID == 0, u.OIDCSubject != ""})
+}
+
+func (a *AuthService) builtinAdmin() *User {
+	return &User{Username: builtinAdminName, Role: RoleAdmin, PasswordHash: a.cfg.PasswordHash, TOTPSecret: a.cfg.TOTPSecret}
+}
+
+var errInvalidLogin = errors.New("invalid username or password")
+
+// Authenticate checks a username and password. An empty username is the
+// built-in admin, so the single-password login keeps working.
+func (a *AuthService) Authenticate(username, password string) (*User, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if username == "" || username == builtinAdminName {
+		if !a.CheckPassword(password) {
+			return nil, errInvalidLogin
+		}
+		return a.builtinAdmin(), nil
+	}
+
+	user, err := getUserByName(a.db, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Compare anyway, so unknown names take as long as wrong passwords
+		_ = bcrypt.CompareHashAndPassword([]byte(a.cfg.PasswordHash), []byte(password))
+		return nil, errInvalidLogin
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, errInvalidLogin
+	}
+	return user, nil
+}
+
+const userColumns = `id, username, role, password_hash, totp_secret, COALESCE(oidc_subject, ''), created_at`
+
+func scanUser(row interface{ Scan(...any) error }) (*User, error) {
+	var u User
+	if err := row.Scan(&u.ID, &u.Username, &u.Role, &u.PasswordHash, &u.TOTPSecret, &u.OIDCSubject, &u.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func getUserByID(db *sql.DB, id int64) (*User, error) {
+	return scanUser(db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+}
+
+func getUserByName(db *sql.DB, username string) (*User, error) {
+	return scanUser(db.QueryRow(`SELECT `+userColumns+` FROM users WHERE username = ?`, username))
+}
+
+func listUsers(db *sql.DB) ([]*User, error) {
+	rows, err := db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var users []*User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func validateRole(role string) error {
+	if _, ok := roleScopes[role]; !ok {
+		return fmt.Errorf("invalid role %q (want viewer, operator or admin)", role)
+	}
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must have at least %d characters", minPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// newTOTPSecret returns a secret and the otpauth:// URL to enroll it.
+func newTOTPSecret(username string) (secret, url string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "NixFleet", AccountName: username})
+	if err != nil {
+		return "", "", err
+	}
+	return key.Secret(), key.URL(), nil
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// HANDLERS
+// ═══════════════════════════════════════════════════════════════════════════
+
+// handleGetUsers lists the accounts, the built-in admin first.
+// GET /api/users
+func (s *Server) handleGetUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := listUsers(s.db)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to list users")
+		s.jsonError(w, "Failed to list users", http.StatusInternalServerError)
+		return
+	}
+	users = append([]*User{s.auth.builtinAdmin()}, users...)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"users": users})
+}
+
+// handleCreateUser adds an account. With "totp": true it gets a TOTP
+// secret, returned once as an otpauth:// URL.
+// POST /api/users
+func (s *Server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Role     string `json:"role"`
+		TOTP     bool   `json:"totp,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !usernamePattern.MatchString(req.Username) || req.Username == builtinAdminName {
+		s.jsonError(w, fmt.Sprintf("invalid username %q (lowercase letters, digits, . _ -; not %q)", req.Username, builtinAdminName), http.StatusBadRequest)
+		return
+	}
+	if err := validateRole(req.Role); err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	user := &User{Username: req.Username, Role: req.Role, PasswordHash: hash, CreatedAt: time.Now()}
+	var totpURL string
+	if req.TOTP {
+		if user.TOTPSecret, totpURL, err = newTOTPSecret(user.Username); err != nil {
+			s.jsonError(w, "Failed to generate TOTP secret", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	res, err := s.db.Exec(`
+		INSERT INTO users (username, password_hash, totp_secret, role, created_at) VALUES (?, ?, ?, ?, ?)
+	`, user.Username, user.PasswordHash, user.TOTPSecret, user.Role, user.CreatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			s.jsonError(w, fmt.Sprintf("user %s already exists", user.Username), http.StatusConflict)
+			return
+		}
+		s.log.Error().Err(err).Msg("failed to create user")
+		s.jsonError(w, "Failed to create user", http.StatusInternalServerError)
+		return
+	}
+	user.ID, _ = res.LastInsertId()
+	s.stateStore.LogEvent("audit", "info", requestActor(r), "", "user:create",
+		fmt.Sprintf("Created user %s (%s)", user.Username, user.Role), nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{"user": user, "totp_url": totpURL})
+}
+
+// handleUpdateUser changes an account's role or password, or replaces
+// ("totp": true) or removes ("totp": false) its TOTP secret.
+// PATCH /api/users/{username}
+func (s *Server) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	var req struct {
+		Role     *string `json:"role"`
+		Password *string `json:"password"`
+		TOTP     *bool   `json:"totp"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if username == builtinAdminName {
+		s.jsonError(w, "the built-in admin is configured through the environment", http.StatusBadRequest)
+		return
+	}
+
+	user, err := getUserByName(s.db, username)
+	if err != nil {
+		s.jsonError(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	var changes []string
+	var totpURL string
+	if req.Role != nil {
+		if err := validateRole(*req.Role); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		user.Role = *req.Role
+		changes = append(changes, "role "+user.Role)
+	}
+	if req.Password != nil {
+		if user.PasswordHash, err = hashPassword(*req.Password); err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		changes = append(changes, "password")
+	}
+	if req.TOTP != nil {
+		user.TOTPSecret = ""
+		if *req.TOTP {
+			if user.TOTPSecret, totpURL, err = newTOTPSecret(user.Username); err != nil {
+				s.jsonError(w, "Failed to generate TOTP secret", http.StatusInternalServerError)
+				return
+			}
+		}
+		changes = append(changes, "TOTP")
+	}
+	if len(changes) == 0 {
+		s.jsonError(w, "nothing to change (role, password, totp)", http.StatusBadRequest)
+		return
+	}
+
+	_, err = s.db.Exec(`UPDATE users SET role = ?, password_hash = ?, totp_secret = ? WHERE id = ?`,
+		user.Role, user.PasswordHash, user.TOTPSecret, user.ID)
+	if err != nil {
+		s.log.Error().Err(err).Str("user", username).Msg("failed to update user")
+		s.jsonError(w, "Failed to update user", http.StatusInternalServerError)
+		return
+	}
+	// A new password signs the user out everywhere
+	if req.Password != nil {
+		s.endUserSessions(user.ID)
+	}
+	s.stateStore.LogEvent("audit", "info", requestActor(r), "", "user:update",
+		fmt.Sprintf("Changed %s of user %s", strings.Join(changes, ", "), user.Username), nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"user": user, "totp_url": totpURL})
+}
+
+// handleDeleteUser removes an account, its passkeys and its sessions.
+// DELETE /api/users/{username}
+func (s *Server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if username == builtinAdminName {
+		s.jsonError(w, "the built-in admin cannot be deleted", http.StatusBadRequest)
+		return
+	}
+	user, err := getUserByName(s.db, username)
+	if err != nil {
+		s.jsonError(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	s.endUserSessions(user.ID)
+	_, _ = s.db.Exec(`DELETE FROM webauthn_credentials WHERE user_id = ?`, user.ID)
+	if _, err := s.db.Exec(`DELETE FROM users WHERE id = ?`, user.ID); err != nil {
+		s.log.Error().Err(err).Str("user", username).Msg("failed to delete user")
+		s.jsonError(w, "Failed to delete user", http.StatusInternalServerError)
+		return
+	}
+	s.stateStore.LogEvent("audit", "warn", requestActor(r), "", "user:delete", "Deleted user "+username, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// endUserSessions deletes a user's sessions and disconnects the dashboards
+// open on them, which would otherwise keep getting updates and starting
+// log streams.
+func (s *Server) endUserSessions(userID int64) {
+	var sessionIDs []string
+	rows, err := s.db.Query(`SELECT id FROM sessions WHERE user_id = ?`, userID)
+	if err == nil {
+		for rows.Next() {
+			var id string
+			if rows.Scan(&id) == nil {
+				sessionIDs = append(sessionIDs, id)
+			}
+		}
+		_ = rows.Close()
+	}
+	_, _ = s.db.Exec(`DELETE FROM sessions WHERE user_id = ?`, userID)
+	s.hub.closeBrowserSessions(sessionIDs)
+}
+
+// adminOnlyOps are ops operators may not dispatch.
+var adminOnlyOps = map[string]bool{"reboot": true}
+
+// allowOp reports whether the request may run opID, answering 403 if not.
+func (s *Server) allowOp(w http.ResponseWriter, r *http.Request, opID string) bool {
+	if adminOnlyOps[opID] {
+		return s.checkScope(w, r, ScopeAdmin)
+	}
+	return true
+}
+
+// stepUpUser is whose TOTP secret or passkey confirms a TOTP-gated op: the
+// session's user, or the built-in admin for API tokens.
+func (s *Server) stepUpUser(r *http.Request) *User {
+	if session := sessionFromContext(r.Context()); session != nil && session.User != nil {
+		return session.User
+	}
+	return s.auth.builtinAdmin()
+}
@@ -0,0 +1,46 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestUserRoles(t *testing.T) {
+	tests := []struct {
+		role, need string
+		want       bool
+	}{
+		{RoleViewer, ScopeRead, true},
+		{RoleViewer, ScopeDispatch, false},
+		{RoleOperator, ScopeDispatch, true},
+		{RoleOperator, ScopeAdmin, false},
+		{RoleAdmin, ScopeAdmin, true},
+		{"", ScopeRead, false},
+	}
+	for _, tt := range tests {
+		u := &User{Role: tt.role}
+		if got := scopeCovers(u.Scope(), tt.need); got != tt.want {
+			t.Errorf("%q needs %s = %v, want %v", tt.role, tt.need, got, tt.want)
+		}
+	}
+
+	if validateRole("superuser") == nil || validateRole(RoleOperator) != nil {
+		t.Error("validateRole")
+	}
+	if _, err := hashPassword("short"); err == nil {
+		t.Error("short password accepted")
+	}
+}
+
+func TestUserJSON(t *testing.T) {
+	data, err := json.Marshal(&User{ID: 3, Username: "otto", Role: RoleOperator, PasswordHash: "$2a$hash", TOTPSecret: "SECRET"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(data)
+	if strings.Contains(s, "hash") || strings.Contains(s, "SECRET") || !strings.Contains(s, `"has_totp":true`) ||
+		strings.Contains(s, "builtin") {
+		t.Errorf("user JSON = %s", s)
+	}
+}
@@ -101,9 +101,14 @@ func (vf *VersionFetcher) GetLatest() *RemoteVersion {
 	return vf.cached
 }
 
-// GetGitStatus compares the given generation with the latest.
+// GetGitStatus compares the given generation with the latest, or with the
+// host's pinned ref if it has one.
 // Returns: status ("ok", "outdated", "unknown"), message string, checkedAt timestamp
-func (vf *VersionFetcher) GetGitStatus(agentGeneration string) (status, message, checkedAt string) {
+func (vf *VersionFetcher) GetGitStatus(agentGeneration string, pin GitPin) (status, message, checkedAt string) {
+	if pin.Ref != "" {
+		return pin.status(agentGeneration)
+	}
+
 	vf.mu.RLock()
 	defer vf.mu.RUnlock()
 
@@ -149,3 +149,28 @@ func containsHelper(s, substr string) bool {
 	return false
 }
 
+
+func TestGetGitStatus_Pinned(t *testing.T) {
+	// A pinned host does not depend on version.json
+	vf := &VersionFetcher{ttl: 5 * time.Second, cached: nil}
+
+	sha := "abc1234567890def1234567890abcdef12345678"
+	status, message, _ := vf.GetGitStatus("abc1234", GitPin{Ref: sha})
+	if status != "ok" || !contains(message, "Pinned to "+sha) {
+		t.Errorf("pinned SHA: %s %q", status, message)
+	}
+
+	// Tags and branches are compared with the commit their last pull checked out
+	status, _, _ = vf.GetGitStatus("abc1234", GitPin{Ref: "v1.2.0"})
+	if status != "outdated" {
+		t.Errorf("unresolved tag: expected 'outdated', got '%s'", status)
+	}
+	status, message, _ = vf.GetGitStatus("abc1234", GitPin{Ref: "v1.2.0", Commit: "def5678"})
+	if status != "outdated" || !contains(message, "abc1234 → def5678") {
+		t.Errorf("other commit: %s %q", status, message)
+	}
+	status, _, _ = vf.GetGitStatus("def5678", GitPin{Ref: "v1.2.0", Commit: "def5678"})
+	if status != "ok" {
+		t.Errorf("pinned tag: expected 'ok', got '%s'", status)
+	}
+}
@@ -0,0 +1,683 @@
+package dashboard
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// WEBAUTHN - passkeys for login and as step-up for TOTP-gated ops
+// ═══════════════════════════════════════════════════════════════════════════
+
+const (
+	webauthnTimeout    = 2 * time.Minute
+	webauthnMaxPending = 1000
+)
+
+// Ceremonies a challenge is issued for.
+const (
+	webauthnRegister = "register"
+	webauthnLogin    = "login"
+	webauthnStepUp   = "step-up"
+)
+
+// Step-up methods, as recorded in the audit log.
+const (
+	stepUpTOTP     = "totp"
+	stepUpWebAuthn = "webauthn"
+)
+
+// COSE algorithms offered at registration.
+const (
+	coseES256 = -7
+	coseEdDSA = -8
+	coseRS256 = -257
+)
+
+// Authenticator data flags.
+const (
+	authFlagUserPresent  = 0x01
+	authFlagUserVerified = 0x04
+	authFlagAttested     = 0x40
+)
+
+// webauthnCredential is a registered passkey. UserID 0 is the built-in admin.
+type webauthnCredential struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"-"`
+	CredentialID string     `json:"-"` // base64url
+	PublicKey    []byte     `json:"-"` // COSE_Key
+	SignCount    uint32     `json:"-"`
+	Name         string     `json:"name"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+}
+
+// webauthnAttestation is the browser's navigator.credentials.create()
+// result, binary fields base64url-encoded.
+type webauthnAttestation struct {
+	ID                string `json:"id"`
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AttestationObject string `json:"attestationObject"`
+}
+
+// webauthnAssertion is the browser's navigator.credentials.get() result.
+type webauthnAssertion struct {
+	ID                string `json:"id"`
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle,omitempty"`
+}
+
+// ───────────────────────────────────────────────────────────────────────────
+// Challenges
+// ───────────────────────────────────────────────────────────────────────────
+
+type webauthnChallenge struct {
+	ceremony string
+	userID   int64 // -1 for login, where the credential names the user
+	expires  time.Time
+}
+
+// webauthnChallenges holds the challenges of ceremonies in progress. The
+// browser signs the challenge into clientDataJSON, so it is also the key.
+type webauthnChallenges struct {
+	mu      sync.Mutex
+	pending map[string]*webauthnChallenge
+}
+
+func newWebAuthnChallenges() *webauthnChallenges {
+	return &webauthnChallenges{pending: make(map[string]*webauthnChallenge)}
+}
+
+func (c *webauthnChallenges) issue(ceremony string, userID int64) (string, error) {
+	challenge, err := randomURLString(32)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, ch := range c.pending {
+		if now.After(ch.expires) {
+			delete(c.pending, k)
+		}
+	}
+	if len(c.pending) >= webauthnMaxPending {
+		return "", errors.New("too many passkey ceremonies in progress")
+	}
+	c.pending[challenge] = &webauthnChallenge{ceremony: ceremony, userID: userID, expires: now.Add(webauthnTimeout)}
+	return challenge, nil
+}
+
+// take consumes a challenge; it is valid once, for its ceremony and user.
+func (c *webauthnChallenges) take(challenge, ceremony string, userID int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := c.pending[challenge]
+	delete(c.pending, challenge)
+	return ch != nil && time.Now().Before(ch.expires) && ch.ceremony == ceremony && ch.userID == userID
+}
+
+// ───────────────────────────────────────────────────────────────────────────
+// Verification
+// ───────────────────────────────────────────────────────────────────────────
+
+func decodeB64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// verifyClientData checks the browser's clientDataJSON: the ceremony type,
+// one of our challenges and our origin.
+func (s *Server) verifyClientData(raw []byte, typ, ceremony string, userID int64) error {
+	var cd struct {
+		Type      string `json:"type"`
+		Challenge string `json:"challenge"`
+		Origin    string `json:"origin"`
+	}
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("client data: %w", err)
+	}
+	if !s.webauthn.take(cd.Challenge, ceremony, userID) {
+		return errors.New("unknown or expired challenge")
+	}
+	if cd.Type != typ {
+		return fmt.Errorf("client data type %q, want %q", cd.Type, typ)
+	}
+	if cd.Origin != s.cfg.WebAuthnOrigin {
+		return fmt.Errorf("origin %q, want %q", cd.Origin, s.cfg.WebAuthnOrigin)
+	}
+	return nil
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte // registration only
+	publicKey    []byte // COSE_Key, registration only
+}
+
+func parseAuthenticatorData(b []byte) (*authenticatorData, error) {
+	if len(b) < 37 {
+		return nil, errors.New("authenticator data too short")
+	}
+	ad := &authenticatorData{rpIDHash: b[:32], flags: b[32], signCount: binary.BigEndian.Uint32(b[33:37])}
+	if ad.flags&authFlagAttested == 0 {
+		return ad, nil
+	}
+
+	// Attested credential data: AAGUID, credential ID length and ID, COSE key
+	rest := b[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data too short")
+	}
+	n := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < n {
+		return nil, errors.New("credential ID truncated")
+	}
+	ad.credentialID, rest = rest[:n], rest[n:]
+	_, after, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, fmt.Errorf("credential public key: %w", err)
+	}
+	ad.publicKey = rest[:len(rest)-len(after)]
+	return ad, nil
+}
+
+// check requires our RP ID and a present, verified user (PIN, biometrics).
+func (ad *authenticatorData) check(rpID string) error {
+	want := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(ad.rpIDHash, want[:]) {
+		return errors.New("authenticator data is for another site")
+	}
+	if ad.flags&authFlagUserPresent == 0 || ad.flags&authFlagUserVerified == 0 {
+		return errors.New("user presence and verification are required")
+	}
+	return nil
+}
+
+// parseCOSEKey returns the public key in a COSE_Key and its algorithm.
+func parseCOSEKey(b []byte) (crypto.PublicKey, int64, error) {
+	v, _, err := decodeCBOR(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, 0, errors.New("COSE key is not a map")
+	}
+	kty, _ := m[int64(1)].(int64)
+	alg, _ := m[int64(3)].(int64)
+	crv, _ := m[int64(-1)].(int64)
+	x, _ := m[int64(-2)].([]byte)
+
+	switch alg {
+	case coseES256:
+		y, _ := m[int64(-3)].([]byte)
+		if kty != 2 || crv != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("invalid ES256 key")
+		}
+		// ecdh rejects points that are not on the curve
+		if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, 0, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, alg, nil
+	case coseEdDSA:
+		if kty != 1 || crv != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), alg, nil
+	case coseRS256:
+		n, _ := m[int64(-1)].([]byte)
+		e := x // RSA keys carry the exponent in -2
+		if kty != 3 || len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, errors.New("invalid RS256 key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, alg, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported COSE algorithm %d", alg)
+}
+
+func verifyCOSESignature(key crypto.PublicKey, alg int64, data, sig []byte) bool {
+	digest := sha256.Sum256(data)
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		return alg == coseES256 && ecdsa.VerifyASN1(pub, digest[:], sig)
+	case ed25519.PublicKey:
+		return alg == coseEdDSA && ed25519.Verify(pub, data, sig)
+	case *rsa.PublicKey:
+		return alg == coseRS256 && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) == nil
+	}
+	return false
+}
+
+// verifyAssertion checks a passkey assertion for a challenge issued for
+// ceremony and userID (-1 for login) and returns the credential used. The
+// signature counter must grow, unless the authenticator keeps none.
+func (s *Server) verifyAssertion(a *webauthnAssertion, ceremony string, userID int64) (*webauthnCredential, error) {
+	clientData, err := decodeB64URL(a.ClientDataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("client data: %w", err)
+	}
+	if err := s.verifyClientData(clientData, "webauthn.get", ceremony, userID); err != nil {
+		return nil, err
+	}
+
+	cred, err := getWebAuthnCredential(s.db, strings.TrimRight(a.ID, "="))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("unknown passkey")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if userID >= 0 && cred.UserID != userID {
+		return nil, errors.New("passkey belongs to another user")
+	}
+
+	authData, err := decodeB64URL(a.AuthenticatorData)
+	if err != nil {
+		return nil, fmt.Errorf("authenticator data: %w", err)
+	}
+	ad, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return nil, err
+	}
+	if err := ad.check(s.cfg.WebAuthnRPID); err != nil {
+		return nil, err
+	}
+	sig, err := decodeB64URL(a.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("signature: %w", err)
+	}
+	key, alg, err := parseCOSEKey(cred.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientData)
+	if !verifyCOSESignature(key, alg, append(authData, clientDataHash[:]...), sig) {
+		return nil, errors.New("invalid passkey signature")
+	}
+	if (ad.signCount != 0 || cred.SignCount != 0) && ad.signCount <= cred.SignCount {
+		return nil, errors.New("signature counter did not increase; the passkey may have been cloned")
+	}
+
+	cred.SignCount = ad.signCount
+	now := time.Now()
+	cred.LastUsedAt = &now
+	_, _ = s.db.Exec(`UPDATE webauthn_credentials SET sign_count = ?, last_used_at = ? WHERE id = ?`, cred.SignCount, now, cred.ID)
+	return cred, nil
+}
+
+// ───────────────────────────────────────────────────────────────────────────
+// Step-up
+// ───────────────────────────────────────────────────────────────────────────
+
+// hasStepUp reports whether the user can confirm TOTP-gated ops at all.
+func (s *Server) hasStepUp(user *User) bool {
+	if user.HasTOTP() {
+		return true
+	}
+	var n int
+	_ = s.db.QueryRow(`SELECT COUNT(*) FROM webauthn_credentials WHERE user_id = ?`, user.ID).Scan(&n)
+	return n > 0
+}
+
+// checkStepUp confirms a TOTP-gated op with the user's TOTP code or a
+// passkey assertion for a step-up challenge. It returns the method that
+// succeeded (stepUpTOTP or stepUpWebAuthn), or "" if neither did.
+func (s *Server) checkStepUp(user *User, code string, assertion *webauthnAssertion) string {
+	if assertion != nil {
+		if _, err := s.verifyAssertion(assertion, webauthnStepUp, user.ID); err != nil {
+			s.log.Warn().Err(err).Str("user", user.Username).Msg("passkey step-up failed")
+			return ""
+		}
+		return stepUpWebAuthn
+	}
+	if code != "" && user.HasTOTP() && s.auth.CheckTOTP(user, code) {
+		return stepUpTOTP
+	}
+	return ""
+}
+
+// stepUpRefusal is the audit reason and error message for a failed checkStepUp.
+func stepUpRefusal(assertion *webauthnAssertion) (reason, msg string) {
+	if assertion != nil {
+		return "invalid_webauthn", "Passkey verification failed"
+	}
+	return "invalid_totp", "Invalid TOTP code"
+}
+
+// ───────────────────────────────────────────────────────────────────────────
+// Storage
+// ───────────────────────────────────────────────────────────────────────────
+
+const webauthnColumns = `id, user_id, credential_id, public_key, sign_count, name, created_at, last_used_at`
+
+func scanWebAuthnCredential(row interface{ Scan(...any) error }) (*webauthnCredential, error) {
+	var c webauthnCredential
+	var lastUsed sql.NullTime
+	if err := row.Scan(&c.ID, &c.UserID, &c.CredentialID, &c.PublicKey, &c.SignCount, &c.Name, &c.CreatedAt, &lastUsed); err != nil {
+		return nil, err
+	}
+	if lastUsed.Valid {
+		c.LastUsedAt = &lastUsed.Time
+	}
+	return &c, nil
+}
+
+func getWebAuthnCredential(db *sql.DB, credentialID string) (*webauthnCredential, error) {
+	return scanWebAuthnCredential(db.QueryRow(`SELECT `+webauthnColumns+` FROM webauthn_credentials WHERE credential_id = ?`, credentialID))
+}
+
+func listWebAuthnCredentials(db *sql.DB, userID int64) ([]*webauthnCredential, error) {
+	rows, err := db.Query(`SELECT `+webauthnColumns+` FROM webauthn_credentials WHERE user_id = ? ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	creds := []*webauthnCredential{}
+	for rows.Next() {
+		c, err := scanWebAuthnCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, c)
+	}
+	return creds, rows.Err()
+}
+
+// credentialDescriptors lists credentials for allow/excludeCredentials.
+func credentialDescriptors(creds []*webauthnCredential) []map[string]string {
+	out := make([]map[string]string, 0, len(creds))
+	for _, c := range creds {
+		out = append(out, map[string]string{"type": "public-key", "id": c.CredentialID})
+	}
+	return out
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// HANDLERS
+// ═══════════════════════════════════════════════════════════════════════════
+
+// handleGetWebAuthnCredentials lists the signed-in user's passkeys.
+// GET /webauthn/credentials
+func (s *Server) handleGetWebAuthnCredentials(w http.ResponseWriter, r *http.Request) {
+	user := sessionFromContext(r.Context()).User
+	creds, err := listWebAuthnCredentials(s.db, user.ID)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to list passkeys")
+		s.jsonError(w, "Failed to list passkeys", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"credentials": creds})
+}
+
+// handleWebAuthnRegisterBegin returns the options for
+// navigator.credentials.create().
+// POST /webauthn/register/begin
+func (s *Server) handleWebAuthnRegisterBegin(w http.ResponseWriter, r *http.Request) {
+	user := sessionFromContext(r.Context()).User
+	creds, err := listWebAuthnCredentials(s.db, user.ID)
+	if err != nil {
+		s.jsonError(w, "Failed to list passkeys", http.StatusInternalServerError)
+		return
+	}
+	challenge, err := s.webauthn.issue(webauthnRegister, user.ID)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"publicKey": map[string]any{
+		"challenge": challenge,
+		"rp":        map[string]string{"id": s.cfg.WebAuthnRPID, "name": "NixFleet"},
+		"user": map[string]string{
+			"id":          base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(user.ID, 10))),
+			"name":        user.Username,
+			"displayName": user.Username,
+		},
+		"pubKeyCredParams": []map[string]any{
+			{"type": "public-key", "alg": coseES256},
+			{"type": "public-key", "alg": coseEdDSA},
+			{"type": "public-key", "alg": coseRS256},
+		},
+		"timeout":                webauthnTimeout.Milliseconds(),
+		"attestation":            "none",
+		"authenticatorSelection": map[string]string{"residentKey": "preferred", "userVerification": "required"},
+		"excludeCredentials":     credentialDescriptors(creds),
+	}})
+}
+
+// handleWebAuthnRegisterFinish stores a new passkey for the signed-in user.
+// Attestation statements are not checked; any authenticator is accepted.
+// POST /webauthn/register/finish
+func (s *Server) handleWebAuthnRegisterFinish(w http.ResponseWriter, r *http.Request) {
+	user := sessionFromContext(r.Context()).User
+	var req struct {
+		Name       string              `json:"name"`
+		Credential webauthnAttestation `json:"credential"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+	if len(name) > 64 {
+		s.jsonError(w, "name is too long (max 64 characters)", http.StatusBadRequest)
+		return
+	}
+
+	cred, err := s.verifyAttestation(&req.Credential, user.ID)
+	if err != nil {
+		s.log.Warn().Err(err).Str("user", user.Username).Msg("passkey registration failed")
+		s.jsonError(w, "Passkey registration failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	cred.Name, cred.CreatedAt = name, time.Now()
+
+	res, err := s.db.Exec(`
+		INSERT INTO webauthn_credentials (user_id, credential_id, public_key, sign_count, name, created_at) VALUES (?, ?, ?, ?, ?, ?)
+	`, cred.UserID, cred.CredentialID, cred.PublicKey, cred.SignCount, cred.Name, cred.CreatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			s.jsonError(w, "this passkey is already registered", http.StatusConflict)
+			return
+		}
+		s.log.Error().Err(err).Msg("failed to store passkey")
+		s.jsonError(w, "Failed to store passkey", http.StatusInternalServerError)
+		return
+	}
+	cred.ID, _ = res.LastInsertId()
+	s.stateStore.LogEvent("audit", "info", requestActor(r), "", "webauthn:register", "Registered passkey "+cred.Name, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{"credential": cred})
+}
+
+// verifyAttestation checks a navigator.credentials.create() result and
+// returns the credential it creates, not yet stored.
+func (s *Server) verifyAttestation(a *webauthnAttestation, userID int64) (*webauthnCredential, error) {
+	clientData, err := decodeB64URL(a.ClientDataJSON)
+	if err != nil {
+		return nil, fmt.Errorf("client data: %w", err)
+	}
+	if err := s.verifyClientData(clientData, "webauthn.create", webauthnRegister, userID); err != nil {
+		return nil, err
+	}
+
+	raw, err := decodeB64URL(a.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("attestation object: %w", err)
+	}
+	v, _, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, fmt.Errorf("attestation object: %w", err)
+	}
+	obj, _ := v.(map[any]any)
+	authData, _ := obj["authData"].([]byte)
+	ad, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return nil, err
+	}
+	if err := ad.check(s.cfg.WebAuthnRPID); err != nil {
+		return nil, err
+	}
+	if ad.credentialID == nil {
+		return nil, errors.New("no credential in authenticator data")
+	}
+	if _, _, err := parseCOSEKey(ad.publicKey); err != nil {
+		return nil, err
+	}
+	credentialID := base64.RawURLEncoding.EncodeToString(ad.credentialID)
+	if strings.TrimRight(a.ID, "=") != credentialID {
+		return nil, errors.New("credential ID mismatch")
+	}
+	return &webauthnCredential{UserID: userID, CredentialID: credentialID, PublicKey: ad.publicKey, SignCount: ad.signCount}, nil
+}
+
+// handleDeleteWebAuthnCredential removes one of the signed-in user's passkeys.
+// DELETE /webauthn/credentials/{id}
+func (s *Server) handleDeleteWebAuthnCredential(w http.ResponseWriter, r *http.Request) {
+	user := sessionFromContext(r.Context()).User
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.jsonError(w, "invalid passkey id", http.StatusBadRequest)
+		return
+	}
+	var name string
+	err = s.db.QueryRow(`DELETE FROM webauthn_credentials WHERE id = ? AND user_id = ? RETURNING name`, id, user.ID).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		s.jsonError(w, "passkey not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to delete passkey")
+		s.jsonError(w, "Failed to delete passkey", http.StatusInternalServerError)
+		return
+	}
+	s.stateStore.LogEvent("audit", "warn", requestActor(r), "", "webauthn:remove", "Removed passkey "+name, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
+
+// handleWebAuthnStepUpBegin returns navigator.credentials.get() options to
+// confirm a TOTP-gated op with one of the signed-in user's passkeys. The
+// assertion goes into the op request as "webauthn".
+// POST /webauthn/step-up/begin
+func (s *Server) handleWebAuthnStepUpBegin(w http.ResponseWriter, r *http.Request) {
+	user := sessionFromContext(r.Context()).User
+	creds, err := listWebAuthnCredentials(s.db, user.ID)
+	if err != nil {
+		s.jsonError(w, "Failed to list passkeys", http.StatusInternalServerError)
+		return
+	}
+	if len(creds) == 0 {
+		s.jsonError(w, "no passkeys registered", http.StatusBadRequest)
+		return
+	}
+	s.writeAssertionOptions(w, r, webauthnStepUp, user.ID, creds)
+}
+
+// handleWebAuthnLoginBegin returns navigator.credentials.get() options for
+// a passkey sign-in. No credentials are listed, so the browser offers the
+// passkeys it has for this site.
+// POST /login/webauthn/begin
+func (s *Server) handleWebAuthnLoginBegin(w http.ResponseWriter, r *http.Request) {
+	s.writeAssertionOptions(w, r, webauthnLogin, -1, nil)
+}
+
+func (s *Server) writeAssertionOptions(w http.ResponseWriter, r *http.Request, ceremony string, userID int64, creds []*webauthnCredential) {
+	challenge, err := s.webauthn.issue(ceremony, userID)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"publicKey": map[string]any{
+		"challenge":        challenge,
+		"rpId":             s.cfg.WebAuthnRPID,
+		"timeout":          webauthnTimeout.Milliseconds(),
+		"userVerification": "required",
+		"allowCredentials": credentialDescriptors(creds),
+	}})
+}
+
+// handleWebAuthnLoginFinish signs in with a passkey assertion. A verified
+// passkey replaces both password and TOTP.
+// POST /login/webauthn/finish
+func (s *Server) handleWebAuthnLoginFinish(w http.ResponseWriter, r *http.Request) {
+	// JSON only, which a cross-site form cannot send
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+		s.jsonError(w, "expected application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	ip := clientIP(r)
+	if s.auth.IsRateLimited(ip) {
+		s.jsonError(w, "Too many attempts. Please wait.", http.StatusTooManyRequests)
+		return
+	}
+	var assertion webauthnAssertion
+	if err := json.NewDecoder(r.Body).Decode(&assertion); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	cred, err := s.verifyAssertion(&assertion, webauthnLogin, -1)
+	if err != nil {
+		s.log.Warn().Err(err).Str("ip", ip).Msg("failed login attempt: passkey")
+		s.jsonError(w, "Passkey sign-in failed", http.StatusUnauthorized)
+		return
+	}
+	user := s.auth.builtinAdmin()
+	if cred.UserID != 0 {
+		if user, err = getUserByID(s.db, cred.UserID); err != nil {
+			s.jsonError(w, "Passkey sign-in failed", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	session, err := s.auth.CreateSession(user)
+	if err != nil {
+		s.log.Error().Err(err).Msg("failed to create session")
+		s.jsonError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+	s.auth.ResetRateLimit(ip)
+	s.auth.SetSessionCookie(w, r, session)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
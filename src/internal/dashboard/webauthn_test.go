@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	// {1: 2, 3: -7, -2: h'0102', "fmt": "none", "list": [true, null]} followed by 0xff
+	data := []byte{0xa5, 0x01, 0x02, 0x03, 0x26, 0x21, 0x42, 0x01, 0x02,
+		0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e',
+		0x64, 'l', 'i', 's', 't', 0x82, 0xf5, 0xf6, 0xff}
+	v, rest, err := decodeCBOR(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := v.(map[any]any)
+	if m[int64(1)] != int64(2) || m[int64(3)] != int64(-7) || !bytes.Equal(m[int64(-2)].([]byte), []byte{1, 2}) ||
+		m["fmt"] != "none" || len(m["list"].([]any)) != 2 || !bytes.Equal(rest, []byte{0xff}) {
+		t.Errorf("decoded %v, rest %x", v, rest)
+	}
+
+	for _, bad := range [][]byte{
+		{},
+		{0x42, 0x01},       // byte string longer than the data
+		{0x9f},             // indefinite length
+		{0xa1, 0x80, 0x01}, // array as map key
+		{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	} {
+		if _, _, err := decodeCBOR(bad); err == nil {
+			t.Errorf("expected error for %x", bad)
+		}
+	}
+}
+
+func TestWebAuthnChallenges(t *testing.T) {
+	c := newWebAuthnChallenges()
+	ch, err := c.issue(webauthnStepUp, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.take(ch, webauthnStepUp, 4) {
+		t.Error("challenge accepted for another user")
+	}
+	ch, _ = c.issue(webauthnStepUp, 3)
+	if c.take(ch, webauthnLogin, -1) {
+		t.Error("step-up challenge accepted for login")
+	}
+	ch, _ = c.issue(webauthnStepUp, 3)
+	if !c.take(ch, webauthnStepUp, 3) || c.take(ch, webauthnStepUp, 3) {
+		t.Error("challenge not valid exactly once")
+	}
+}
+
+func TestValidateWebAuthnOrigin(t *testing.T) {
+	for _, tt := range []struct {
+		origin, rpID string
+		ok           bool
+	}{
+		{"https://fleet.example.com", "fleet.example.com", true},
+		{"https://fleet.example.com", "example.com", true},
+		{"http://localhost:8000", "localhost", true},
+		{"https://fleet.example.com", "other.com", false},
+		{"https://fleet.example.com", "ample.com", false},
+		{"https://fleet.example.com/dashboard", "fleet.example.com", false},
+		{"fleet.example.com", "fleet.example.com", false},
+	} {
+		if err := validateWebAuthnOrigin(tt.origin, tt.rpID); (err == nil) != tt.ok {
+			t.Errorf("validateWebAuthnOrigin(%q, %q) = %v", tt.origin, tt.rpID, err)
+		}
+	}
+}
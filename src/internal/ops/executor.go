@@ -14,6 +14,9 @@ import (
 // This abstracts the Hub dependency.
 type CommandSender interface {
 	SendCommand(hostID, command string) bool
+	// SendOpCommand sends cmd with its ID and args, so the agent's output
+	// and status can be matched to it by ID.
+	SendOpCommand(hostID string, cmd *Command) bool
 	GetOnlineHosts() []string
 }
 
@@ -85,6 +88,9 @@ func (e *Executor) ExecuteOp(ctx context.Context, opID string, host Host, force
 		Status:    StatusPending,
 		CreatedAt: time.Now(),
 	}
+	if RefOps[opID] {
+		cmd.Args.Ref = host.GetGitRef() // pinned ref, empty = branch tip
+	}
 
 	// Persist to store
 	if e.store != nil {
@@ -104,14 +110,17 @@ func (e *Executor) ExecuteOp(ctx context.Context, opID string, host Host, force
 	e.updateStatus(cmd, StatusValidating, nil, "")
 	e.logEvent("audit", "info", "user", hostID, "op:"+opID, fmt.Sprintf("Validating %s", opID), nil)
 
-	if !force && op.Validate != nil {
-		if verr := op.Validate(host); verr != nil {
-			cmd.Status = StatusBlocked
-			cmd.Error = verr.Message
-			e.updateStatus(cmd, StatusBlocked, nil, verr.Message)
-			e.logEvent("audit", "warn", "user", hostID, "op:"+opID, fmt.Sprintf("Blocked: %s", verr.Message), map[string]any{"code": verr.Code})
-			return cmd, verr
-		}
+	// Agent support is checked even when forced
+	verr := ValidateAgentSupport(op, host, cmd.Args)
+	if verr == nil && !force && op.Validate != nil {
+		verr = op.Validate(host)
+	}
+	if verr != nil {
+		cmd.Status = StatusBlocked
+		cmd.Error = verr.Message
+		e.updateStatus(cmd, StatusBlocked, nil, verr.Message)
+		e.logEvent("audit", "warn", "user", hostID, "op:"+opID, fmt.Sprintf("Blocked: %s", verr.Message), map[string]any{"code": verr.Code})
+		return cmd, verr
 	}
 
 	// 5. Execute
@@ -127,7 +136,7 @@ func (e *Executor) ExecuteOp(ctx context.Context, opID string, host Host, force
 	var execErr error
 	if op.Executor == ExecutorAgent {
 		// Send to agent
-		if !e.sender.SendCommand(hostID, opID) {
+		if !e.sender.SendOpCommand(hostID, cmd) {
 			execErr = fmt.Errorf("failed to send command to agent")
 		}
 		// Note: actual execution result comes back via WebSocket
@@ -81,6 +81,43 @@ func (h *HostAdapter) GetSystemStatus() string {
 	return h.host.UpdateStatus.System.Status
 }
 
+// GetStoreFreeBytes implements Host.GetStoreFreeBytes.
+func (h *HostAdapter) GetStoreFreeBytes() int64 {
+	if h.host.Disk == nil {
+		return -1
+	}
+	return h.host.Disk.FreeBytes
+}
+
+// GetGitRef implements Host.GetGitRef.
+func (h *HostAdapter) GetGitRef() string {
+	return h.host.GitRef
+}
+
+// GetProtocolVersion implements Host.GetProtocolVersion.
+func (h *HostAdapter) GetProtocolVersion() int {
+	return h.host.ProtocolVersion
+}
+
+// SupportsCommand implements Host.SupportsCommand.
+func (h *HostAdapter) SupportsCommand(command string) bool {
+	return containsString(h.host.AgentCommands, command)
+}
+
+// SupportsFeature implements Host.SupportsFeature.
+func (h *HostAdapter) SupportsFeature(feature string) bool {
+	return containsString(h.host.AgentFeatures, feature)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // Underlying returns the underlying templates.Host.
 func (h *HostAdapter) Underlying() *templates.Host {
 	return h.host
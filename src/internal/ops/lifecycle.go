@@ -8,6 +8,9 @@
 package ops
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -67,6 +70,15 @@ type ActiveCommand struct {
 	cancelReconnect chan struct{} // Signal to stop reconnect watcher
 }
 
+// matches reports whether an agent message is about this command: by ID
+// if the agent echoed one, by op name otherwise (older agents).
+func (c *ActiveCommand) matches(commandID, opID string) bool {
+	if commandID != "" {
+		return commandID == c.ID
+	}
+	return opID == c.OpID
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // LIFECYCLE MANAGER
 // ═══════════════════════════════════════════════════════════════════════════
@@ -153,12 +165,77 @@ func (lm *LifecycleManager) SetPendingCommandStore(pcs PendingCommandStore) {
 // COMMAND EXECUTION
 // ═══════════════════════════════════════════════════════════════════════════
 
+// TargetOps are the ops that can run on a single deploy target of a host
+// (e.g. only a user's home-manager configuration).
+var TargetOps = map[string]bool{
+	"switch":         true,
+	"pull-switch":    true,
+	"rollback":       true,
+	"refresh-system": true,
+}
+
+// RefOps are the ops that can deploy a git ref other than the branch tip.
+var RefOps = map[string]bool{
+	"pull":        true,
+	"pull-switch": true,
+}
+
+// ServiceOps are the ops that control one unit of a host (Args.Unit), checked
+// against the agent's allowlist.
+var ServiceOps = map[string]bool{
+	"service-restart": true,
+	"service-stop":    true,
+	"service-start":   true,
+}
+
+// ValidateArgs checks that opID accepts the arguments that are set.
+func ValidateArgs(opID string, args Args) *ValidationError {
+	if args.Target != "" && !TargetOps[opID] {
+		return &ValidationError{Code: "invalid_args", Message: "Operation " + opID + " does not take a target"}
+	}
+	if args.Generation < 0 || (args.Generation != 0 && opID != "rollback") {
+		return &ValidationError{Code: "invalid_args", Message: "Only rollback takes a generation (a positive number)"}
+	}
+	if args.Ref != "" && !RefOps[opID] {
+		return &ValidationError{Code: "invalid_args", Message: "Operation " + opID + " does not take a git ref"}
+	}
+	if opID == "exec" && args.Exec == "" {
+		return &ValidationError{Code: "invalid_args", Message: "exec needs a command name"}
+	}
+	if opID != "exec" && (args.Exec != "" || len(args.Params) > 0) {
+		return &ValidationError{Code: "invalid_args", Message: "Operation " + opID + " does not take an exec command"}
+	}
+	if ServiceOps[opID] && (args.Unit == "" || strings.HasPrefix(args.Unit, "-")) {
+		return &ValidationError{Code: "invalid_args", Message: opID + " needs a unit name"}
+	}
+	if !ServiceOps[opID] && args.Unit != "" {
+		return &ValidationError{Code: "invalid_args", Message: "Operation " + opID + " does not take a unit"}
+	}
+	return nil
+}
+
 // ExecuteOp starts a command with full lifecycle management.
 func (lm *LifecycleManager) ExecuteOp(opID string, host Host, force bool) (*ActiveCommand, error) {
+	return lm.ExecuteOpWithArgs(opID, host, force, Args{})
+}
+
+// ExecuteOpWithArgs is ExecuteOp with typed arguments for the agent.
+func (lm *LifecycleManager) ExecuteOpWithArgs(opID string, host Host, force bool, args Args) (*ActiveCommand, error) {
 	op := lm.registry.Get(opID)
 	if op == nil {
 		return nil, &ValidationError{Code: "unknown_op", Message: "Unknown operation: " + opID}
 	}
+	if verr := ValidateArgs(opID, args); verr != nil {
+		return nil, verr
+	}
+	// Pulls go to the host's pinned ref unless the caller names one; filled
+	// in first so an agent that would ignore the ref is refused
+	if args.Ref == "" && RefOps[opID] {
+		args.Ref = host.GetGitRef()
+	}
+	if verr := ValidateAgentSupport(op, host, args); verr != nil {
+		return nil, verr
+	}
 
 	hostID := host.GetID()
 
@@ -183,6 +260,7 @@ func (lm *LifecycleManager) ExecuteOp(opID string, host Host, force bool) (*Acti
 			OpID:      opID,
 			Status:    StatusPending,
 			CreatedAt: now,
+			Args:      args,
 		},
 		TimeoutConfig:   GetTimeoutConfig(opID),
 		cancelTimeout:   make(chan struct{}),
@@ -224,11 +302,30 @@ func (lm *LifecycleManager) ExecuteOp(opID string, host Host, force bool) (*Acti
 		}
 	}
 
+	// Low store space in warn-only mode (or forced): surface it, but let the op run
+	if opID == "switch" || opID == "pull-switch" {
+		if warn := lm.registry.CheckStoreSpace(host); warn != nil {
+			lm.logEvent("warn", hostID, opID, warn.Message)
+			if lm.broadcast != nil {
+				lm.broadcast.BroadcastToast(hostID, "warning", warn.Message)
+			}
+		}
+	}
+
 	// Execute
 	cmd.StartedAt = time.Now()
 	cmd.Status = StatusExecuting
 	lm.updateAndBroadcast(cmd)
-	lm.logEvent("info", hostID, opID, "Executing "+opID)
+	switch {
+	case args.Target != "":
+		lm.logEvent("info", hostID, opID, "Executing "+opID+" on target "+args.Target)
+	case args.Exec != "":
+		lm.logEvent("info", hostID, opID, "Executing "+opID+" "+args.Exec)
+	case args.Unit != "":
+		lm.logEvent("info", hostID, opID, "Executing "+opID+" "+args.Unit)
+	default:
+		lm.logEvent("info", hostID, opID, "Executing "+opID)
+	}
 
 	// Send to agent
 	if op.Executor == ExecutorAgent {
@@ -240,7 +337,7 @@ func (lm *LifecycleManager) ExecuteOp(opID string, host Host, force bool) (*Acti
 			}
 		}
 
-		if !lm.sender.SendCommand(hostID, opID) {
+		if !lm.sender.SendOpCommand(hostID, &cmd.Command) {
 			cmd.Status = StatusError
 			cmd.Error = "Failed to send command to agent"
 			cmd.FinishedAt = time.Now()
@@ -272,17 +369,64 @@ func (lm *LifecycleManager) ExecuteOp(opID string, host Host, force bool) (*Acti
 	return lm.completeWithSuccess(cmd, host)
 }
 
+// RunOp starts an op like ExecuteOpWithArgs and waits until it has left the
+// active commands (success, error, timeout), or for at most the op's hard
+// and reconnect timeouts. Implements OpRunner for pipelines; PARTIAL counts
+// as done, like a deploy whose post-check is still catching up.
+func (lm *LifecycleManager) RunOp(ctx context.Context, opID string, host Host, args Args) (*Command, error) {
+	// Earlier stages changed the host, validate against its current state
+	if fresh, err := lm.getHost(host.GetID()); err == nil {
+		host = fresh
+	}
+	cmd, err := lm.ExecuteOpWithArgs(opID, host, false, args)
+	if err != nil {
+		if cmd != nil {
+			return &cmd.Command, err
+		}
+		return nil, err
+	}
+
+	timeouts := GetTimeoutConfig(opID)
+	ctx, cancel := context.WithTimeout(ctx, timeouts.HardTimeout+timeouts.ReconnectTimeout+time.Minute)
+	defer cancel()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		lm.activeMu.RLock()
+		running := lm.active[cmd.HostID] == cmd
+		lm.activeMu.RUnlock()
+		if !running {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s on %s did not finish: %w", opID, cmd.HostID, ctx.Err())
+		case <-lm.done:
+			return nil, fmt.Errorf("shutting down")
+		case <-ticker.C:
+		}
+	}
+
+	// clearActive took activeMu after the final status was set
+	if cmd.Status != StatusSuccess && cmd.Status != StatusPartial {
+		return &cmd.Command, fmt.Errorf("%s: %s", cmd.Status, cmd.Error)
+	}
+	return &cmd.Command, nil
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // COMMAND COMPLETION
 // ═══════════════════════════════════════════════════════════════════════════
 
 // HandleCommandComplete processes command completion from agent.
-func (lm *LifecycleManager) HandleCommandComplete(hostID, opID string, exitCode int, message string) (*ActiveCommand, error) {
+// commandID is the ID the agent echoed; agents that send none are matched
+// by op name.
+func (lm *LifecycleManager) HandleCommandComplete(hostID, commandID, opID string, exitCode int, message string) (*ActiveCommand, error) {
 	lm.activeMu.RLock()
 	cmd := lm.active[hostID]
 	lm.activeMu.RUnlock()
 
-	if cmd == nil || cmd.OpID != opID {
+	if cmd == nil || !cmd.matches(commandID, opID) {
 		lm.log.Debug().Str("host", hostID).Str("op", opID).Msg("completion for untracked command")
 		return nil, nil // Not an error - just not tracked by us
 	}
@@ -322,12 +466,13 @@ func (lm *LifecycleManager) HandleCommandComplete(hostID, opID string, exitCode
 
 // HandleCommandRejected reconciles an agent-side rejection (e.g. agent thinks it's busy).
 // This prevents stuck pending_command / UI showing "working" when nothing is running.
-func (lm *LifecycleManager) HandleCommandRejected(hostID, reason, currentCommand string, currentPID int) {
+// A rejection that names another command's ID is ignored.
+func (lm *LifecycleManager) HandleCommandRejected(hostID, commandID, reason, currentCommand string, currentPID int) {
 	lm.activeMu.RLock()
 	cmd := lm.active[hostID]
 	lm.activeMu.RUnlock()
 
-	if cmd == nil || cmd.Status.IsTerminal() {
+	if cmd == nil || cmd.Status.IsTerminal() || (commandID != "" && commandID != cmd.ID) {
 		return
 	}
 
@@ -678,7 +823,8 @@ func (lm *LifecycleManager) completeWithError(cmd *ActiveCommand, exitCode int,
 
 func (lm *LifecycleManager) completeWithPostCheck(cmd *ActiveCommand, host Host, exitCode int) (*ActiveCommand, error) {
 	op := lm.registry.Get(cmd.OpID)
-	if op == nil || op.PostCheck == nil {
+	// Post-checks look at the host-wide status, which other targets may still hold back
+	if op == nil || op.PostCheck == nil || cmd.Args.Target != "" {
 		return lm.completeWithSuccess(cmd, host)
 	}
 
@@ -58,9 +58,14 @@ type Host interface {
 	GetGeneration() string
 	GetAgentVersion() string
 	IsAgentOutdated() bool
-	GetGitStatus() string    // "ok", "outdated", "unknown"
-	GetLockStatus() string   // "ok", "outdated", "unknown"
-	GetSystemStatus() string // "ok", "outdated", "unknown"
+	GetGitStatus() string     // "ok", "outdated", "unknown"
+	GetLockStatus() string    // "ok", "outdated", "unknown"
+	GetSystemStatus() string  // "ok", "outdated", "unknown"
+	GetStoreFreeBytes() int64 // free space on /nix/store, -1 if not reported
+	GetGitRef() string        // pinned git ref, empty = branch tip
+	GetProtocolVersion() int  // agent protocol version, 0 = agent without capabilities
+	SupportsCommand(command string) bool
+	SupportsFeature(feature string) bool
 }
 
 // Op defines an atomic operation on a single host.
@@ -115,6 +120,23 @@ type Command struct {
 	ExitCode   *int      `json:"exit_code"`   // Process exit code (nil if not finished)
 	Error      string    `json:"error"`       // Error message if failed
 	OutputFile string    `json:"output_file"` // Path to output log file
+	Args       Args      `json:"args"`        // Typed parameters sent to the agent
+}
+
+// Args are the typed parameters of an op, sent to the agent with the
+// command (protocol.CommandArgs). Zero values mean "not set".
+type Args struct {
+	Target     string            `json:"target,omitempty"`     // Deploy target (TargetOps), empty = all
+	Generation int               `json:"generation,omitempty"` // rollback: this generation instead of the previous one
+	Ref        string            `json:"ref,omitempty"`        // pull/pull-switch: git ref instead of the branch tip
+	Exec       string            `json:"exec,omitempty"`       // exec: name of the command in the agent's allowlist
+	Params     map[string]string `json:"params,omitempty"`     // exec: values for the command's placeholders
+	Unit       string            `json:"unit,omitempty"`       // ServiceOps: unit in the agent's allowlist
+}
+
+// IsZero reports whether no argument is set.
+func (a Args) IsZero() bool {
+	return a.Target == "" && a.Generation == 0 && a.Ref == "" && a.Exec == "" && len(a.Params) == 0 && a.Unit == ""
 }
 
 // IsTerminal returns true if the status represents a completed command.
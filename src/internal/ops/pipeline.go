@@ -124,13 +124,25 @@ func DefaultPipelineRegistry() *PipelineRegistry {
 		Description: "Force rebuild with cache bypass",
 	})
 
+	r.Register(&Pipeline{
+		ID:          "switch-restart",
+		Ops:         []string{"switch", "service-restart"},
+		Description: "Deploy, then restart a service",
+	})
+
 	return r
 }
 
+// OpRunner runs one op on a host and waits for its outcome, so the next
+// stage only starts on hosts where it succeeded.
+type OpRunner interface {
+	RunOp(ctx context.Context, opID string, host Host, args Args) (*Command, error)
+}
+
 // PipelineExecutor orchestrates multi-op sequences.
 type PipelineExecutor struct {
 	log       zerolog.Logger
-	opExec    *Executor
+	runner    OpRunner
 	registry  *PipelineRegistry
 	store     PipelineStore
 	events    EventLogger
@@ -149,10 +161,10 @@ type PipelineStore interface {
 }
 
 // NewPipelineExecutor creates a new pipeline executor.
-func NewPipelineExecutor(log zerolog.Logger, opExec *Executor, registry *PipelineRegistry, store PipelineStore, events EventLogger) *PipelineExecutor {
+func NewPipelineExecutor(log zerolog.Logger, runner OpRunner, registry *PipelineRegistry, store PipelineStore, events EventLogger) *PipelineExecutor {
 	return &PipelineExecutor{
 		log:      log.With().Str("component", "pipeline_executor").Logger(),
-		opExec:   opExec,
+		runner:   runner,
 		registry: registry,
 		store:    store,
 		events:   events,
@@ -160,9 +172,26 @@ func NewPipelineExecutor(log zerolog.Logger, opExec *Executor, registry *Pipelin
 	}
 }
 
+type actorKey struct{}
+
+// WithActor records who started a pipeline ("user", "token:<name>") for its
+// audit events.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFromContext returns the actor set by WithActor, "user" if none.
+func actorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return "user"
+}
+
 // Execute runs a pipeline on the given hosts with && semantics.
-// Hosts that fail are excluded from subsequent ops.
-func (pe *PipelineExecutor) Execute(ctx context.Context, pipelineID string, hosts []Host) (*PipelineRecord, error) {
+// Hosts that fail are excluded from subsequent ops. args.Unit goes to the
+// pipeline's ServiceOps stages only.
+func (pe *PipelineExecutor) Execute(ctx context.Context, pipelineID string, hosts []Host, args Args) (*PipelineRecord, error) {
 	// Get pipeline definition
 	pipeline := pe.registry.Get(pipelineID)
 	if pipeline == nil {
@@ -200,7 +229,7 @@ func (pe *PipelineExecutor) Execute(ctx context.Context, pipelineID string, host
 	pe.active[record.ID] = record
 	pe.activeMu.Unlock()
 
-	pe.logEvent("audit", "info", "user", "", "pipeline:"+pipelineID,
+	pe.logEvent("audit", "info", actorFromContext(ctx), "", "pipeline:"+pipelineID,
 		fmt.Sprintf("Starting pipeline %s on %d hosts", pipelineID, len(hosts)), nil)
 
 	// Execute stages
@@ -224,7 +253,11 @@ func (pe *PipelineExecutor) Execute(ctx context.Context, pipelineID string, host
 			fmt.Sprintf("Stage %d/%d: %s on %d hosts", stageIdx+1, len(pipeline.Ops), opID, len(activeHosts)), nil)
 
 		// Execute op on all active hosts (parallel)
-		results := pe.executeStage(ctx, opID, record.ID, activeHosts)
+		var stageArgs Args
+		if ServiceOps[opID] {
+			stageArgs.Unit = args.Unit
+		}
+		results := pe.executeStage(ctx, opID, record.ID, activeHosts, stageArgs)
 
 		// Filter to successful hosts for next stage
 		var stillActive []Host
@@ -288,7 +321,7 @@ func (pe *PipelineExecutor) Execute(ctx context.Context, pipelineID string, host
 }
 
 // executeStage runs an op on all hosts in parallel and collects results.
-func (pe *PipelineExecutor) executeStage(ctx context.Context, opID, pipelineID string, hosts []Host) []OpResult {
+func (pe *PipelineExecutor) executeStage(ctx context.Context, opID, pipelineID string, hosts []Host, args Args) []OpResult {
 	var wg sync.WaitGroup
 	results := make([]OpResult, len(hosts))
 
@@ -297,7 +330,7 @@ func (pe *PipelineExecutor) executeStage(ctx context.Context, opID, pipelineID s
 		go func(idx int, h Host) {
 			defer wg.Done()
 
-			cmd, err := pe.opExec.ExecuteOp(ctx, opID, h, false)
+			cmd, err := pe.runner.RunOp(ctx, opID, h, args)
 			results[idx] = OpResult{
 				Command: cmd,
 				Host:    h,
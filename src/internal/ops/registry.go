@@ -8,17 +8,41 @@ import (
 
 // Registry holds all registered ops and provides lookup by ID.
 type Registry struct {
-	ops map[string]*Op
-	mu  sync.RWMutex
+	ops        map[string]*Op
+	storeSpace StoreSpacePolicy
+	mu         sync.RWMutex
 }
 
 // NewRegistry creates an empty registry.
 func NewRegistry() *Registry {
 	return &Registry{
-		ops: make(map[string]*Op),
+		ops:        make(map[string]*Op),
+		storeSpace: DefaultStoreSpace,
 	}
 }
 
+// SetStoreSpace replaces the /nix/store free space policy for this registry's ops.
+func (r *Registry) SetStoreSpace(p StoreSpacePolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.storeSpace = p
+}
+
+// CheckStoreSpace reports low disk space on host under the registry's policy,
+// whether or not the policy blocks.
+func (r *Registry) CheckStoreSpace(host Host) *ValidationError {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.storeSpace.Check(host)
+}
+
+// ValidateStoreSpace blocks on low disk space only when the registry's policy says so.
+func (r *Registry) ValidateStoreSpace(host Host) *ValidationError {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.storeSpace.Validate(host)
+}
+
 // Register adds an op to the registry.
 // Panics if an op with the same ID is already registered.
 func (r *Registry) Register(op *Op) {
@@ -78,7 +102,7 @@ func DefaultRegistry() *Registry {
 
 	// Host Ops (Agent-Executed)
 	r.Register(opPull())
-	r.Register(opSwitch())
+	r.Register(opSwitch(r))
 	r.Register(opTest())
 	r.Register(opRollback()) // P4600: Rollback to previous generation
 	r.Register(opRestart())
@@ -90,6 +114,11 @@ func DefaultRegistry() *Registry {
 	r.Register(opRefreshSystem())
 	r.Register(opBumpFlake())
 	r.Register(opForceRebuild())
+	r.Register(opGC())
+	r.Register(opExec())
+	r.Register(opService("restart", "Restart a service"))
+	r.Register(opService("stop", "Stop a service"))
+	r.Register(opService("start", "Start a service"))
 
 	// Dashboard Ops (Server-Side)
 	r.Register(opMergePR())
@@ -133,7 +162,7 @@ func opPull() *Op {
 	}
 }
 
-func opSwitch() *Op {
+func opSwitch(r *Registry) *Op {
 	return &Op{
 		ID:          "switch",
 		Description: "nixos-rebuild/hm switch",
@@ -144,6 +173,10 @@ func opSwitch() *Op {
 			if host.HasPendingCommand() {
 				return &ValidationError{"busy", fmt.Sprintf("Command %q already running", host.GetPendingCommand())}
 			}
+			// Builds fail halfway when the store fills up
+			if verr := r.ValidateStoreSpace(host); verr != nil {
+				return verr
+			}
 			// Check prerequisites: lock should be ok (or force)
 			lock := host.GetLockStatus()
 			if lock == "outdated" {
@@ -403,6 +436,75 @@ func opForceRebuild() *Op {
 	}
 }
 
+// opGC collects garbage and optimises the Nix store.
+// Retention (--delete-older-than) is configured on the agent.
+func opGC() *Op {
+	return &Op{
+		ID:          "gc",
+		Description: "nix-collect-garbage + nix store optimise",
+		Validate: func(host Host) *ValidationError {
+			if !host.IsOnline() {
+				return &ValidationError{"offline", "Host is offline"}
+			}
+			if host.HasPendingCommand() {
+				return &ValidationError{"busy", fmt.Sprintf("Command %q already running", host.GetPendingCommand())}
+			}
+			return nil
+		},
+		Timeout:        60 * time.Minute,
+		WarningTimeout: 15 * time.Minute,
+		Retryable:      true,
+		Executor:       ExecutorAgent,
+	}
+}
+
+// opExec runs a command from the agent's allowlist (NIXFLEET_EXEC_COMMANDS).
+// The agent enforces the command's own timeout; the op's timeout is the cap.
+func opExec() *Op {
+	return &Op{
+		ID:          "exec",
+		Description: "Run an allowlisted command",
+		Validate: func(host Host) *ValidationError {
+			if !host.IsOnline() {
+				return &ValidationError{"offline", "Host is offline"}
+			}
+			if host.HasPendingCommand() {
+				return &ValidationError{"busy", fmt.Sprintf("Command %q already running", host.GetPendingCommand())}
+			}
+			return nil
+		},
+		Timeout:        35 * time.Minute,
+		WarningTimeout: 10 * time.Minute,
+		Retryable:      false, // commands need not be idempotent
+		Executor:       ExecutorAgent,
+		RequiresTotp:   true,
+	}
+}
+
+// opService restarts, stops or starts a unit from the agent's allowlist
+// (NIXFLEET_SERVICE_UNITS). There is no PostCheck: the host's status does not
+// carry unit states, so the agent checks the unit's ActiveState itself and
+// fails the command if it is wrong.
+func opService(action, description string) *Op {
+	return &Op{
+		ID:          "service-" + action,
+		Description: description,
+		Validate: func(host Host) *ValidationError {
+			if !host.IsOnline() {
+				return &ValidationError{"offline", "Host is offline"}
+			}
+			if host.HasPendingCommand() {
+				return &ValidationError{"busy", fmt.Sprintf("Command %q already running", host.GetPendingCommand())}
+			}
+			return nil
+		},
+		Timeout:        2 * time.Minute,
+		WarningTimeout: 1 * time.Minute,
+		Retryable:      true,
+		Executor:       ExecutorAgent,
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // DASHBOARD OPS (Server-Side) — CORE-001 Table
 // ═══════════════════════════════════════════════════════════════════════════
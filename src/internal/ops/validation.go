@@ -39,6 +39,31 @@ var DefaultTimeouts = map[string]TimeoutConfig{
 		HardTimeout:      10 * time.Minute,
 		ReconnectTimeout: 0, // N/A for test
 	},
+	"gc": {
+		WarningTimeout:   15 * time.Minute,
+		HardTimeout:      60 * time.Minute, // optimise walks the whole store
+		ReconnectTimeout: 0,                // N/A for gc
+	},
+	"exec": {
+		WarningTimeout:   10 * time.Minute,
+		HardTimeout:      35 * time.Minute, // the agent stops the command after at most 30
+		ReconnectTimeout: 0,                // N/A for exec
+	},
+	"service-restart": {
+		WarningTimeout:   1 * time.Minute,
+		HardTimeout:      2 * time.Minute, // stop/start timeouts plus the agent's 30s settle wait
+		ReconnectTimeout: 0,               // N/A for services
+	},
+	"service-stop": {
+		WarningTimeout:   1 * time.Minute,
+		HardTimeout:      2 * time.Minute,
+		ReconnectTimeout: 0,
+	},
+	"service-start": {
+		WarningTimeout:   1 * time.Minute,
+		HardTimeout:      2 * time.Minute,
+		ReconnectTimeout: 0,
+	},
 }
 
 // GetTimeoutConfig returns timeout config for an op.
@@ -100,6 +125,87 @@ func CompareFreshness(before, after AgentFreshness) (FreshnessVerdict, string) {
 	return FreshnessStale, "Agent running old binary - run nix-collect-garbage -d and switch again"
 }
 
+// ═══════════════════════════════════════════════════════════════════════════
+// NIX STORE DISK SPACE
+// ═══════════════════════════════════════════════════════════════════════════
+
+// StoreSpacePolicy configures the pre-switch free space check on /nix/store.
+// Each Registry carries its own; see Registry.SetStoreSpace.
+type StoreSpacePolicy struct {
+	MinFreeBytes int64 // Threshold; 0 disables the check
+	Block        bool  // true = block the op, false = warn only
+}
+
+// DefaultStoreSpace is the policy a new registry starts with.
+var DefaultStoreSpace = StoreSpacePolicy{MinFreeBytes: 5 << 30}
+
+// Check reports a low-disk condition regardless of the policy's Block setting.
+// Returns nil if the check is disabled, the host hasn't reported disk usage yet,
+// or there is enough free space.
+func (p StoreSpacePolicy) Check(host Host) *ValidationError {
+	if p.MinFreeBytes <= 0 {
+		return nil
+	}
+	free := host.GetStoreFreeBytes()
+	if free < 0 || free >= p.MinFreeBytes {
+		return nil
+	}
+	return &ValidationError{
+		Code: "low_disk",
+		Message: fmt.Sprintf("Only %.1f GiB free on /nix/store (minimum %.1f GiB) - run gc first",
+			float64(free)/(1<<30), float64(p.MinFreeBytes)/(1<<30)),
+	}
+}
+
+// Validate blocks on low disk space only when the policy says so.
+// In warn mode the caller is expected to surface Check itself.
+func (p StoreSpacePolicy) Validate(host Host) *ValidationError {
+	if !p.Block {
+		return nil
+	}
+	return p.Check(host)
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// AGENT CAPABILITIES
+// ═══════════════════════════════════════════════════════════════════════════
+
+// argFeatures are the agent features (protocol.Feature*) each set argument needs.
+var argFeatures = []struct {
+	feature, what string
+	used      func(Args) bool
+}{
+	{"targets", "a single deploy target", func(a Args) bool { return a.Target != "" }},
+	{"rollback_generation", "a specific generation", func(a Args) bool { return a.Generation != 0 }},
+	{"git_ref", "a pinned git ref", func(a Args) bool { return a.Ref != "" }},
+}
+
+// ValidateAgentSupport checks that the host's agent can run op with args, so
+// an old agent gets a clear block instead of ignoring the command. Unlike
+// op.Validate it is not skipped by force.
+func ValidateAgentSupport(op *Op, host Host, args Args) *ValidationError {
+	if op.Executor != ExecutorAgent {
+		return nil
+	}
+	if !host.SupportsCommand(op.ID) {
+		return agentTooOld(host, op.ID)
+	}
+	for _, f := range argFeatures {
+		if f.used(args) && !host.SupportsFeature(f.feature) {
+			return agentTooOld(host, op.ID+" with "+f.what)
+		}
+	}
+	return nil
+}
+
+func agentTooOld(host Host, what string) *ValidationError {
+	return &ValidationError{
+		Code: "agent_too_old",
+		Message: fmt.Sprintf("Agent too old for %s (agent %s, protocol v%d) - update the agent on %s",
+			what, host.GetAgentVersion(), host.GetProtocolVersion(), host.GetHostname()),
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // PRE-CONDITION VALIDATORS
 // These are used by Op.Validate functions.
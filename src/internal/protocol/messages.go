@@ -1,7 +1,14 @@
 // Package protocol defines the WebSocket message types shared between agent and dashboard.
 package protocol
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
 
 // Message is the envelope for all WebSocket messages.
 type Message struct {
@@ -31,20 +38,98 @@ const (
 	TypeRegister          = "register"
 	TypeHeartbeat         = "heartbeat"
 	TypeOutput            = "output"
+	TypeOutputBatch       = "output_batch" // several output lines, if the dashboard supports it
 	TypeStatus            = "status"
 	TypeRejected          = "command_rejected"
 	TypeTestProgress      = "test_progress"
+	TypeTestReport        = "test_report"        // JUnit-style result of a test run
 	TypeOperationProgress = "operation_progress" // P2800: phase-by-phase progress
+	TypeCommandProgress   = "command_progress"   // build/download progress parsed from nix
 	TypeCommandComplete   = "command_complete"   // P2800: command completion with fresh status
+	TypeLogStream         = "log_stream"         // lines of a log stream session
+	TypeLogStreamEnd      = "log_stream_end"     // a log stream session ended on the agent
 )
 
 // Message types (dashboard → agent)
 const (
-	TypeRegistered  = "registered"
-	TypeCommand     = "command"
-	TypeKillCommand = "kill_command" // P2800: kill running command
+	TypeRegistered     = "registered"
+	TypeCommand        = "command"
+	TypeKillCommand    = "kill_command"     // P2800: kill running command
+	TypeLogStreamStart = "log_stream_start" // follow a unit's log, alongside commands
+	TypeLogStreamStop  = "log_stream_stop"
 )
 
+// ProtocolVersion is the protocol version this build speaks, sent in
+// RegisterPayload. Agents that register without one speak version 0.
+//
+//	1: capabilities, command IDs, typed command arguments
+const ProtocolVersion = 1
+
+// Optional features an agent announces in Capabilities.Features; the
+// dashboard announces its own in RegisteredPayload.Features.
+const (
+	FeatureKill               = "kill"                // kill_command stops the running command
+	FeatureFreshness          = "freshness"           // register carries source commit, store path and binary hash
+	FeatureStructuredTests    = "structured_tests"    // test runs send test_report with per-test results
+	FeatureCommandIDs         = "command_ids"         // replies echo CommandPayload.ID
+	FeatureTargets            = "targets"             // CommandArgs.Target
+	FeatureRollbackGeneration = "rollback_generation" // CommandArgs.Generation
+	FeatureGitRef             = "git_ref"             // CommandArgs.Ref
+	FeatureOutputBatch        = "output_batch"        // output arrives batched as output_batch
+	FeatureLogStream          = "log_stream"          // log_stream_start/stop sessions
+)
+
+// Capabilities is what an agent supports: the commands it executes, the
+// message types it sends and its optional features.
+type Capabilities struct {
+	Commands     []string               `json:"commands"`
+	MessageTypes []string               `json:"message_types"`
+	Features     []string               `json:"features"`
+	Exec         map[string]ExecCommand `json:"exec,omitempty"`          // commands the exec op may run, by name
+	ServiceUnits []string               `json:"service_units,omitempty"` // units the service-* ops may control
+}
+
+// LegacyCapabilities are assumed for agents that register without
+// capabilities (protocol version 0).
+func LegacyCapabilities() Capabilities {
+	return Capabilities{
+		Commands: []string{
+			"pull", "switch", "pull-switch", "test", "rollback", "stop", "restart", "reboot",
+			"update", "force-update", "check-version",
+			"refresh-git", "refresh-lock", "refresh-system", "refresh-all",
+		},
+		MessageTypes: []string{
+			TypeRegister, TypeHeartbeat, TypeOutput, TypeStatus, TypeRejected,
+			TypeTestProgress, TypeOperationProgress,
+		},
+		Features: []string{FeatureKill, FeatureFreshness},
+	}
+}
+
+// HasCommand reports whether the agent executes command.
+func (c Capabilities) HasCommand(command string) bool {
+	return contains(c.Commands, command)
+}
+
+// HasMessageType reports whether the agent sends messages of type t.
+func (c Capabilities) HasMessageType(t string) bool {
+	return contains(c.MessageTypes, t)
+}
+
+// HasFeature reports whether the agent supports feature.
+func (c Capabilities) HasFeature(feature string) bool {
+	return contains(c.Features, feature)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // RegisterPayload is sent by the agent when connecting.
 type RegisterPayload struct {
 	Hostname          string `json:"hostname"`
@@ -60,15 +145,29 @@ type RegisterPayload struct {
 	RepoURL           string `json:"repo_url"`    // git repo URL (isolated mode)
 	RepoDir           string `json:"repo_dir"`    // local repo path
 
+	// macOS host mode: "home-manager", "nix-darwin" or "both" (empty elsewhere)
+	DarwinMode string `json:"darwin_mode,omitempty"`
+
+	// Endpoint the agent connected to, one of its dashboard URLs in failover order
+	DashboardURL string `json:"dashboard_url,omitempty"`
+
 	// P2800: 3-layer binary freshness detection
 	SourceCommit string `json:"source_commit,omitempty"` // Git commit agent was built from (ldflags)
 	StorePath    string `json:"store_path,omitempty"`    // Nix store path of running binary
 	BinaryHash   string `json:"binary_hash,omitempty"`   // SHA256 of agent binary
+
+	// Capability negotiation; nil for agents older than protocol version 1
+	ProtocolVersion int           `json:"protocol_version,omitempty"`
+	Capabilities    *Capabilities `json:"capabilities,omitempty"`
+
+	// Hardware and OS inventory; nil for older agents
+	Facts *HostFacts `json:"facts,omitempty"`
 }
 
 // RegisteredPayload is sent by the dashboard to confirm registration.
 type RegisteredPayload struct {
-	HostID string `json:"host_id"`
+	HostID   string   `json:"host_id"`
+	Features []string `json:"features,omitempty"` // dashboard features, e.g. FeatureOutputBatch
 }
 
 // HeartbeatPayload is sent periodically by the agent.
@@ -87,6 +186,38 @@ type HeartbeatPayload struct {
 
 	// P3700: Lock compartment version tracking
 	LockHash string `json:"lock_hash,omitempty"` // SHA256 of flake.lock content
+
+	// Direct inputs of flake.lock; only sent when LockHash changed since the
+	// inputs were last sent on this connection, nil otherwise
+	FlakeInputs []FlakeInput `json:"flake_inputs,omitempty"`
+
+	// Nix store disk pressure (nil if statfs failed)
+	Disk *DiskUsage `json:"disk,omitempty"`
+
+	// Failed systemd units (nil on hosts without systemd, e.g. macOS)
+	Services *ServicesStatus `json:"services,omitempty"`
+
+	// Booted kernel/initrd differs from the current system (NixOS only)
+	RebootRequired bool   `json:"reboot_required,omitempty"`
+	RebootReason   string `json:"reboot_reason,omitempty"` // e.g. "kernel (linux-6.6.30 → linux-6.6.32), initrd changed"
+
+	// Host facts; only sent when they changed since registration or the
+	// last time they were sent, nil otherwise
+	Facts *HostFacts `json:"facts,omitempty"`
+}
+
+// FlakeInput is one locked input of the flake.lock in the agent's repo.
+type FlakeInput struct {
+	Name         string `json:"name"`               // input name in the root flake, e.g. "nixpkgs"
+	Type         string `json:"type"`               // "github", "gitlab", "git", "tarball", "path", ...
+	Owner        string `json:"owner,omitempty"`    // github/gitlab/sourcehut
+	Repo         string `json:"repo,omitempty"`     // github/gitlab/sourcehut
+	URL          string `json:"url,omitempty"`      // git/tarball/path
+	Ref          string `json:"ref,omitempty"`      // branch or tag asked for, e.g. "nixos-unstable"
+	Pinned       bool   `json:"pinned,omitempty"`   // flake.nix asks for a fixed rev
+	Rev          string `json:"rev,omitempty"`      // locked commit
+	LastModified int64  `json:"last_modified"`      // Unix time of the locked revision
+	NarHash      string `json:"nar_hash,omitempty"` // e.g. "sha256-..."
 }
 
 // Metrics contains system metrics from StaSysMo.
@@ -97,57 +228,361 @@ type Metrics struct {
 	Load float64 `json:"load"` // 1-minute load average
 }
 
+// DiskUsage describes the filesystem holding /nix/store.
+type DiskUsage struct {
+	Path        string  `json:"path"`         // path that was checked (normally /nix/store)
+	TotalBytes  int64   `json:"total_bytes"`  // filesystem size
+	FreeBytes   int64   `json:"free_bytes"`   // space available to the agent
+	UsedPercent float64 `json:"used_percent"` // percentage 0-100
+	StoreBytes  int64   `json:"store_bytes"`  // size of /nix/store itself, 0 until first measured
+}
+
+// HostFacts is a host's hardware and OS inventory. Fields the agent cannot
+// read (e.g. serial numbers without root) are empty.
+type HostFacts struct {
+	CPUModel       string          `json:"cpu_model,omitempty"`
+	CPUCores       int             `json:"cpu_cores"`                // logical CPUs
+	MemoryBytes    int64           `json:"memory_bytes"`             // physical memory
+	Kernel         string          `json:"kernel,omitempty"`         // kernel release, e.g. "6.6.32"
+	Arch           string          `json:"arch"`                     // CPU architecture, e.g. "x86_64"
+	System         string          `json:"system"`                   // Nix system double, e.g. "x86_64-linux"
+	BootTime       string          `json:"boot_time,omitempty"`      // RFC3339
+	Virtualization string          `json:"virtualization,omitempty"` // e.g. "kvm", "qemu", "apple", empty = bare metal
+	Vendor         string          `json:"vendor,omitempty"`         // system vendor, e.g. "LENOVO", "Apple Inc."
+	Model          string          `json:"model,omitempty"`          // product name, e.g. "MacBookPro18,3"
+	SerialNumber   string          `json:"serial_number,omitempty"`  // system serial
+	BoardSerial    string          `json:"board_serial,omitempty"`   // mainboard serial (Linux)
+	Disks          []DiskFact      `json:"disks"`
+	Interfaces     []InterfaceFact `json:"interfaces"`
+}
+
+// DiskFact is one mounted local filesystem.
+type DiskFact struct {
+	Mount      string `json:"mount"`
+	Device     string `json:"device"`
+	FSType     string `json:"fs_type"`
+	TotalBytes int64  `json:"total_bytes"`
+}
+
+// InterfaceFact is one network interface other than loopback.
+type InterfaceFact struct {
+	Name      string   `json:"name"`
+	MAC       string   `json:"mac,omitempty"`
+	Up        bool     `json:"up"`
+	Addresses []string `json:"addresses"` // CIDR, IPv4 and IPv6
+}
+
+// ServicesStatus summarises systemd unit health on a host.
+// An empty Failed list means systemd was queried and nothing is failing.
+type ServicesStatus struct {
+	Failed    []FailedUnit `json:"failed"`
+	CheckedAt string       `json:"checked_at"` // RFC3339 time of the systemctl query
+}
+
+// FailedUnit is one entry from `systemctl --failed`.
+type FailedUnit struct {
+	Name   string `json:"name"`             // unit name, e.g. "nginx.service"
+	Since  string `json:"since,omitempty"`  // RFC3339 time the unit entered the failed state
+	Result string `json:"result,omitempty"` // systemd Result, e.g. "exit-code", "timeout", "signal"
+}
+
 // CommandPayload is sent by the dashboard to request command execution.
+// The agent echoes ID as command_id in every output, operation_progress,
+// status and command_complete message of the command.
 type CommandPayload struct {
-	Command string `json:"command"` // "pull", "switch", "test", etc.
+	ID      string      `json:"id,omitempty"` // commands.id (UUID), empty for untracked commands
+	Command string      `json:"command"`      // "pull", "switch", "test", etc.
+	Args    CommandArgs `json:"args"`
+}
+
+// CommandArgs are the typed parameters of a command. Zero values mean
+// "not set"; which ones a command accepts is checked by the op and the agent.
+type CommandArgs struct {
+	Target     string            `json:"target,omitempty"`     // switch/pull-switch/rollback/refresh-system: deploy target, empty = all
+	Generation int               `json:"generation,omitempty"` // rollback: activate this generation instead of the previous one
+	Ref        string            `json:"ref,omitempty"`        // pull/pull-switch: commit SHA, tag or branch instead of the branch tip
+	Exec       string            `json:"exec,omitempty"`       // exec: name of the allowlisted command (Capabilities.Exec)
+	Params     map[string]string `json:"params,omitempty"`     // exec: values for the command's {param} placeholders
+	Unit       string            `json:"unit,omitempty"`       // service-restart/-stop/-start: unit in Capabilities.ServiceUnits
+}
+
+// ValidGitRef reports whether ref can be sent as CommandArgs.Ref: a commit
+// SHA, tag or branch name that is safe to pass to git fetch, never an option.
+func ValidGitRef(ref string) bool {
+	if ref == "" || strings.HasPrefix(ref, "-") || strings.Contains(ref, "..") {
+		return false
+	}
+	for _, r := range ref {
+		if r <= ' ' || r == 0x7f || strings.ContainsRune("~^:?*[\\'\"", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// AbbreviatedCommit reports whether ref could be a commit SHA shorter than
+// the full 40 hex digits. git cannot fetch those from a remote by name, so
+// agents resolve them locally if fetching ref as a tag or branch fails
+// (names like "20241018" or "deadbeef" can be either).
+func AbbreviatedCommit(ref string) bool {
+	if len(ref) < 7 || len(ref) >= 40 {
+		return false
+	}
+	for _, c := range ref {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Exec timeouts: the default for commands that set none, and the most a
+// command may set.
+const (
+	DefaultExecTimeout = 5 * time.Minute
+	MaxExecTimeout     = 30 * time.Minute
+)
+
+// ExecCommand is a command the exec op may run, allowlisted on the agent and
+// advertised in Capabilities.Exec. Command is run without a shell; its
+// elements may contain {param} placeholders, each replaced by the value of
+// CommandArgs.Params that fully matches the param's pattern.
+//
+//	{"command": ["journalctl", "-u", "{unit}", "-n", "200"], "params": {"unit": "[a-z0-9@._-]+"}}
+type ExecCommand struct {
+	Command     []string          `json:"command"`
+	Params      map[string]string `json:"params,omitempty"`  // param name → regexp its value must match
+	Timeout     int               `json:"timeout,omitempty"` // seconds, 0 = DefaultExecTimeout
+	Description string            `json:"description,omitempty"`
+}
+
+var execPlaceholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Check reports configuration errors: an empty command, a bad timeout, a
+// pattern that does not compile or a placeholder without a pattern.
+func (c ExecCommand) Check() error {
+	if len(c.Command) == 0 || c.Command[0] == "" {
+		return errors.New("command is empty")
+	}
+	if c.Timeout < 0 || time.Duration(c.Timeout)*time.Second > MaxExecTimeout {
+		return fmt.Errorf("timeout must be between 0 and %d seconds", int(MaxExecTimeout.Seconds()))
+	}
+	for name, pattern := range c.Params {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("param %s: %w", name, err)
+		}
+	}
+	if execPlaceholder.MatchString(c.Command[0]) {
+		return errors.New("the program name cannot be a placeholder")
+	}
+	for _, arg := range c.Command {
+		for _, m := range execPlaceholder.FindAllStringSubmatch(arg, -1) {
+			if _, ok := c.Params[m[1]]; !ok {
+				return fmt.Errorf("placeholder {%s} has no pattern in params", m[1])
+			}
+		}
+	}
+	return nil
+}
+
+// TimeoutDuration returns the command's timeout.
+func (c ExecCommand) TimeoutDuration() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultExecTimeout
+	}
+	return min(time.Duration(c.Timeout)*time.Second, MaxExecTimeout)
+}
+
+// Expand returns the argv with the placeholders replaced by params. Every
+// param must be declared and match its pattern, and no value may start with
+// "-", so a value can never turn into an option.
+func (c ExecCommand) Expand(params map[string]string) ([]string, error) {
+	for name, value := range params {
+		pattern, ok := c.Params[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown param %q", name)
+		}
+		re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("param %s: %w", name, err)
+		}
+		if strings.HasPrefix(value, "-") || !re.MatchString(value) {
+			return nil, fmt.Errorf("invalid value %q for param %s", value, name)
+		}
+	}
+
+	argv := make([]string, len(c.Command))
+	var missing []string
+	for i, arg := range c.Command {
+		argv[i] = execPlaceholder.ReplaceAllStringFunc(arg, func(m string) string {
+			name := m[1 : len(m)-1]
+			value, ok := params[name]
+			if !ok {
+				missing = append(missing, name)
+			}
+			return value
+		})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing param %s", strings.Join(missing, ", "))
+	}
+	return argv, nil
+}
+
+// FormatCommandLine joins argv into a command line for sh, quoting the
+// arguments that need it.
+func FormatCommandLine(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./#:=@") == "" {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
 }
 
 // OutputPayload is sent by the agent to stream command output.
 type OutputPayload struct {
-	Line    string `json:"line"`
-	Stream  string `json:"stream"`   // "stdout" or "stderr"
-	Command string `json:"command"`  // command that produced this output
-	IsError bool   `json:"is_error"` // true if this is from stderr
+	CommandID string `json:"command_id,omitempty"` // CommandPayload.ID of the command
+	Line      string `json:"line"`
+	Stream    string `json:"stream"`   // "stdout" or "stderr"
+	Command   string `json:"command"`  // command that produced this output
+	IsError   bool   `json:"is_error"` // true if this is from stderr
+}
+
+// OutputBatchPayload carries the output lines of one command read within a
+// short window, instead of one output message per line.
+type OutputBatchPayload struct {
+	CommandID string       `json:"command_id,omitempty"` // CommandPayload.ID of the command
+	Command   string       `json:"command"`              // command that produced this output
+	Lines     []OutputLine `json:"lines"`
+}
+
+// OutputLine is one line of an OutputBatchPayload.
+type OutputLine struct {
+	Line   string `json:"line"`
+	Stream string `json:"stream"` // "stdout" or "stderr"
+	Time   int64  `json:"ts"`     // when the agent read it, Unix milliseconds
 }
 
 // StatusPayload is sent by the agent when a command completes.
 type StatusPayload struct {
-	Status     string `json:"status"` // "ok" or "error"
+	CommandID  string `json:"command_id,omitempty"` // CommandPayload.ID of the command
+	Status     string `json:"status"`               // "ok" or "error"
 	Command    string `json:"command"`
 	ExitCode   int    `json:"exit_code"`
 	Message    string `json:"message,omitempty"`
 	Generation string `json:"generation,omitempty"` // Current git commit hash (for compartment update after pull)
+	Target     string `json:"target,omitempty"`     // deploy target the command ran on, empty = all
+	Ref        string `json:"ref,omitempty"`        // git ref a pull checked out (Generation is its commit)
 }
 
 // CommandRejectedPayload is sent when a command cannot be executed.
 type CommandRejectedPayload struct {
+	CommandID      string `json:"command_id,omitempty"` // ID of the rejected command
 	Reason         string `json:"reason"`
 	CurrentCommand string `json:"current_command,omitempty"`
 	CurrentPID     int    `json:"current_pid,omitempty"`
 }
 
+// LogStreamStartPayload starts a log stream session: a live tail of a unit's
+// log (journalctl -fu on Linux, log stream on macOS). Sessions run next to
+// commands and do not make the agent busy.
+type LogStreamStartPayload struct {
+	StreamID string `json:"stream_id"`       // chosen by the dashboard, echoed in every message
+	Unit     string `json:"unit"`            // systemd unit (Linux) or process/subsystem (macOS)
+	Lines    int    `json:"lines,omitempty"` // Linux: journal lines to show before following, 0 = agent default
+}
+
+// LogStreamStopPayload stops a log stream session.
+type LogStreamStopPayload struct {
+	StreamID string `json:"stream_id"`
+}
+
+// LogStreamPayload carries lines of a log stream session.
+type LogStreamPayload struct {
+	StreamID string   `json:"stream_id"`
+	Lines    []string `json:"lines"`
+}
+
+// LogStreamEndPayload reports that a session ended on the agent.
+type LogStreamEndPayload struct {
+	StreamID string `json:"stream_id"`
+	Error    string `json:"error,omitempty"` // empty if it was stopped
+}
+
+// ValidUnitName reports whether unit can be sent as LogStreamStartPayload.Unit:
+// a systemd unit or process name, never an option.
+func ValidUnitName(unit string) bool {
+	if unit == "" || len(unit) > 256 || strings.HasPrefix(unit, "-") {
+		return false
+	}
+	return strings.Trim(unit, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@._-") == ""
+}
+
 // TestProgressPayload is sent during test execution.
 type TestProgressPayload struct {
-	Current int    `json:"current"` // current test number
-	Total   int    `json:"total"`   // total tests
-	Passed  int    `json:"passed"`  // passed so far
-	Running bool   `json:"running"` // still running
-	Result  string `json:"result"`  // summary result when done
+	Current int    `json:"current"`        // current test number
+	Total   int    `json:"total"`          // total tests
+	Passed  int    `json:"passed"`         // passed so far
+	Running bool   `json:"running"`        // still running
+	Result  string `json:"result"`         // summary result when done
+	Failed  int    `json:"failed"`         // failed (or errored) so far
+	Name    string `json:"name,omitempty"` // script currently running
+}
+
+// TestReportPayload is the result of one test run, modelled on a JUnit
+// <testsuite>: one case per script in hosts/<host>/tests/.
+type TestReportPayload struct {
+	Suite     string     `json:"suite"`     // e.g. "hosts/web01/tests"
+	Timestamp string     `json:"timestamp"` // run start (RFC3339)
+	Duration  float64    `json:"duration"`  // seconds
+	Tests     int        `json:"tests"`
+	Failures  int        `json:"failures"`
+	Errors    int        `json:"errors"` // timeouts, scripts that could not start
+	Skipped   int        `json:"skipped"`
+	Cases     []TestCase `json:"cases"`
+}
+
+// TestCase is the result of a single test script.
+type TestCase struct {
+	Name     string      `json:"name"`   // script file name
+	Status   string      `json:"status"` // "pass", "fail", "error", "skipped"
+	Duration float64     `json:"duration"`
+	ExitCode int         `json:"exit_code"`
+	Message  string      `json:"message,omitempty"` // why it failed
+	Output   string      `json:"output,omitempty"`  // captured output (tail, truncated)
+	TAP      []TAPResult `json:"tap,omitempty"`     // assertions, if the script speaks TAP
+}
+
+// TAPResult is one "ok"/"not ok" line of TAP output.
+type TAPResult struct {
+	Number      int    `json:"number"`
+	Description string `json:"description,omitempty"`
+	Status      string `json:"status"`              // "pass", "fail", "skip", "todo"
+	Directive   string `json:"directive,omitempty"` // text after # SKIP / # TODO
 }
 
 // OperationProgressPayload is sent during command execution (P2800).
 // This drives the status column progress dots in the dashboard.
 type OperationProgressPayload struct {
-	Progress OperationProgress `json:"progress"` // full progress state
+	CommandID string            `json:"command_id,omitempty"` // CommandPayload.ID of the command
+	Progress  OperationProgress `json:"progress"`             // full progress state
 }
 
 // CommandProgressPayload is sent during command execution (P2700/P2800).
+// Switches report the progress of the nix build: derivations for the
+// "build" phase, bytes for the "download" phase.
 type CommandProgressPayload struct {
-	Command     string `json:"command"`     // "pull", "switch", etc.
-	Phase       string `json:"phase"`       // "fetch", "merge", "build", "activate"
-	Current     int    `json:"current"`     // current step within phase
-	Total       int    `json:"total"`       // total steps in phase
-	Description string `json:"description"` // e.g., "Building derivation 12/47"
+	CommandID   string `json:"command_id,omitempty"`  // CommandPayload.ID of the command
+	Command     string `json:"command"`               // "pull", "switch", etc.
+	Phase       string `json:"phase"`                 // "fetch", "merge", "build", "download", "activate"
+	Current     int64  `json:"current"`               // current step within phase
+	Total       int64  `json:"total"`                 // total steps in phase (0 = unknown)
+	Description string `json:"description"`           // e.g., "building 12/47 derivations"
+	ETASeconds  int    `json:"eta_seconds,omitempty"` // estimated time left in the phase
 }
 
 // OperationProgress tracks detailed progress for STATUS column (P2700).
@@ -179,6 +614,18 @@ type UpdateStatus struct {
 	Lock   StatusCheck `json:"lock"`
 	System StatusCheck `json:"system"`
 	Tests  StatusCheck `json:"tests"` // P3900: 5th compartment for test results
+
+	// Per deploy target System status; System above is the worst of these
+	Targets []TargetStatus `json:"targets,omitempty"`
+}
+
+// TargetStatus is the System compartment of one deploy target
+// (NixOS/nix-darwin system or a user's home-manager configuration).
+type TargetStatus struct {
+	Name   string      `json:"name"`
+	Type   string      `json:"type"` // "nixos", "nix-darwin", "home-manager"
+	User   string      `json:"user,omitempty"`
+	System StatusCheck `json:"system"`
 }
 
 // StatusCheck represents a single status check result.
@@ -195,6 +642,7 @@ type StatusCheck struct {
 // CommandCompletePayload is sent by agent when a non-switch command completes.
 // For switch commands, the agent exits and reconnection is used instead.
 type CommandCompletePayload struct {
+	CommandID   string        `json:"command_id,omitempty"` // CommandPayload.ID of the command
 	Command     string        `json:"command"`
 	ExitCode    int           `json:"exit_code"`
 	FreshStatus *UpdateStatus `json:"fresh_status,omitempty"` // Updated status after command
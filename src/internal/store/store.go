@@ -120,6 +120,7 @@ func runMigrations(db *sql.DB) error {
 		exit_code   INTEGER,
 		error       TEXT,
 		output_file TEXT,
+		args_json   TEXT,
 		FOREIGN KEY (host_id) REFERENCES hosts(id),
 		FOREIGN KEY (pipeline_id) REFERENCES pipelines(id)
 	);
@@ -230,10 +231,15 @@ func (s *StateStore) LoadVersion() error {
 
 // CreateCommand persists a new command record.
 func (s *StateStore) CreateCommand(cmd *ops.Command) error {
+	var argsJSON sql.NullString
+	if !cmd.Args.IsZero() {
+		data, _ := json.Marshal(cmd.Args)
+		argsJSON = sql.NullString{String: string(data), Valid: true}
+	}
 	_, err := s.db.Exec(`
-		INSERT INTO commands (id, host_id, op, pipeline_id, status, created_at, started_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, cmd.ID, cmd.HostID, cmd.OpID, nullString(cmd.PipelineID), string(cmd.Status), cmd.CreatedAt, nullTime(cmd.StartedAt))
+		INSERT INTO commands (id, host_id, op, pipeline_id, status, created_at, started_at, args_json)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, cmd.ID, cmd.HostID, cmd.OpID, nullString(cmd.PipelineID), string(cmd.Status), cmd.CreatedAt, nullTime(cmd.StartedAt), argsJSON)
 	if err != nil {
 		return fmt.Errorf("create command: %w", err)
 	}
@@ -261,15 +267,15 @@ func (s *StateStore) UpdateCommandStatus(cmdID string, status ops.OpStatus, exit
 // GetCommand retrieves a command by ID.
 func (s *StateStore) GetCommand(cmdID string) (*ops.Command, error) {
 	var cmd ops.Command
-	var pipelineID, errStr, outputFile sql.NullString
+	var pipelineID, errStr, outputFile, argsJSON sql.NullString
 	var startedAt, finishedAt sql.NullTime
 	var exitCode sql.NullInt64
 	var status string
 
 	err := s.db.QueryRow(`
-		SELECT id, host_id, op, pipeline_id, status, created_at, started_at, finished_at, exit_code, error, output_file
+		SELECT id, host_id, op, pipeline_id, status, created_at, started_at, finished_at, exit_code, error, output_file, args_json
 		FROM commands WHERE id = ?
-	`, cmdID).Scan(&cmd.ID, &cmd.HostID, &cmd.OpID, &pipelineID, &status, &cmd.CreatedAt, &startedAt, &finishedAt, &exitCode, &errStr, &outputFile)
+	`, cmdID).Scan(&cmd.ID, &cmd.HostID, &cmd.OpID, &pipelineID, &status, &cmd.CreatedAt, &startedAt, &finishedAt, &exitCode, &errStr, &outputFile, &argsJSON)
 	if err != nil {
 		return nil, fmt.Errorf("get command: %w", err)
 	}
@@ -294,6 +300,9 @@ func (s *StateStore) GetCommand(cmdID string) (*ops.Command, error) {
 	if outputFile.Valid {
 		cmd.OutputFile = outputFile.String
 	}
+	if argsJSON.Valid {
+		_ = json.Unmarshal([]byte(argsJSON.String), &cmd.Args)
+	}
 
 	return &cmd, nil
 }
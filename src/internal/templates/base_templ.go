@@ -43,20 +43,20 @@ func Base(title string, csrfToken string) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</title><link rel=\"icon\" type=\"image/png\" href=\"/static/nixfleet_favicon.png\"><link rel=\"preconnect\" href=\"https://fonts.googleapis.com\"><link rel=\"preconnect\" href=\"https://fonts.gstatic.com\" crossorigin><link href=\"https://fonts.googleapis.com/css2?family=JetBrains+Mono:wght@400;500;600&display=swap\" rel=\"stylesheet\"><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><script defer src=\"https://unpkg.com/alpinejs@3.13.3/dist/cdn.min.js\"></script><script src=\"/static/js/state-sync.js\"></script><style>\n\t\t/* Tokyo Night Color Palette */\n\t\t:root {\n\t\t\t--bg: #1a1b26;\n\t\t\t--bg-dark: #16161e;\n\t\t\t--bg-highlight: #292e42;\n\t\t\t--bg-float: #24283b;\n\t\t\t--border: #3b4261;\n\t\t\t--fg: #e8ecf5;\n\t\t\t/* Brightened ~90% white */\n\t\t\t--fg-dark: #565f89;\n\t\t\t--fg-gutter: #3b4261;\n\t\t\t--blue: #7aa2f7;\n\t\t\t--cyan: #7dcfff;\n\t\t\t--green: #9ece6a;\n\t\t\t--yellow: #e0af68;\n\t\t\t--orange: #ff9e64;\n\t\t\t--red: #f7768e;\n\t\t\t--purple: #bb9af7;\n\t\t\t--magenta: #bb9af7;\n\t\t}\n\n\t\t* {\n\t\t\tmargin: 0;\n\t\t\tpadding: 0;\n\t\t\tbox-sizing: border-box;\n\t\t}\n\n\t\t/* Themed scrollbars - Tokyo Night style */\n\t\t::-webkit-scrollbar {\n\t\t\twidth: 10px;\n\t\t\theight: 10px;\n\t\t}\n\n\t\t::-webkit-scrollbar-track {\n\t\t\tbackground: var(--bg-dark);\n\t\t}\n\n\t\t::-webkit-scrollbar-thumb {\n\t\t\tbackground: var(--border);\n\t\t\tborder-radius: 5px;\n\t\t\tborder: 2px solid var(--bg-dark);\n\t\t}\n\n\t\t::-webkit-scrollbar-thumb:hover {\n\t\t\tbackground: var(--fg-dark);\n\t\t}\n\n\t\t::-webkit-scrollbar-corner {\n\t\t\tbackground: var(--bg-dark);\n\t\t}\n\n\t\t/* Firefox scrollbar theming */\n\t\t* {\n\t\t\tscrollbar-color: var(--border) var(--bg-dark);\n\t\t\tscrollbar-width: thin;\n\t\t}\n\n\t\t/* Always show scrollbars to prevent layout jump */\n\t\thtml {\n\t\t\toverflow-y: scroll !important;\n\t\t\tscrollbar-gutter: stable !important;\n\t\t}\n\t\t\n\t\t/* Prevent any element from hiding the scrollbar */\n\t\thtml, body {\n\t\t\tmin-height: 100%;\n\t\t}\n\n\t\tbody {\n\t\t\tfont-family: \"JetBrains Mono\", \"Fira Code\", \"SF Mono\", monospace;\n\t\t\tbackground: var(--bg);\n\t\t\tcolor: var(--fg);\n\t\t\tline-height: 1.6;\n\t\t\tmin-height: 100vh;\n\t\t}\n\n\t\t/* Background watermark - shows through semi-transparent table */\n\t\tbody::after {\n\t\t\tcontent: \"\";\n\t\t\tposition: fixed;\n\t\t\ttop: 50%;\n\t\t\tleft: 50%;\n\t\t\ttransform: translate(-50%, -50%);\n\t\t\twidth: 500px;\n\t\t\theight: 440px;\n\t\t\tbackground: url(\"/static/nixfleet_fade_1k.png\") no-repeat center center;\n\t\t\tbackground-size: contain;\n\t\t\topacity: 0.06;\n\t\t\tpointer-events: none;\n\t\t\tz-index: 100;\n\t\t}\n\n\t\t/* Container */\n\t\t.container {\n\t\t\tmax-width: 1400px;\n\t\t\tmargin: 0 auto;\n\t\t\tpadding: 1rem;\n\t\t}\n\n\t\t@media (min-width: 768px) {\n\t\t\t.container {\n\t\t\t\tpadding: 1.5rem 2rem;\n\t\t\t}\n\t\t}\n\n\t\t/* Header - Single line layout */\n\t\theader {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tpadding-bottom: 1rem;\n\t\t\tmargin-bottom: 1.5rem;\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t\tgap: 1rem;\n\t\t\tmin-height: 48px;\n\t\t}\n\n\t\t.header-brand {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.brand-title {\n\t\t\tcolor: var(--blue);\n\t\t\tfont-size: 1.25rem;\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t.brand-logo {\n\t\t\theight: 28px;\n\t\t\twidth: 28px;\n\t\t\tborder-radius: 4px;\n\t\t}\n\n\t\t.header-center {\n\t\t\tflex: 1;\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: center;\n\t\t\tmin-width: 0;\n\t\t}\n\n\t\t.header-actions {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t@media (min-width: 768px) {\n\t\t\t.brand-title {\n\t\t\t\tfont-size: 1.5rem;\n\t\t\t}\n\n\t\t\t.brand-logo {\n\t\t\t\theight: 32px;\n\t\t\t\twidth: 32px;\n\t\t\t}\n\t\t}\n\n\t\t@media (max-width: 900px) {\n\t\t\t.header-center {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\t\t}\n\n\t\t/* Fleet Target line (replaces subtitle) */\n\t\t.fleet-target {\n\t\t\tfont-size: 0.75rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.4rem;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t.target-label {\n\t\t\tcolor: var(--fg-muted);\n\t\t}\n\n\t\t.target-icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tfill: var(--cyan);\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t.target-commit {\n\t\t\tfont-family: 'JetBrains Mono', 'Fira Code', monospace;\n\t\t\tfont-size: 0.7rem;\n\t\t\tcolor: var(--cyan);\n\t\t\tbackground: rgba(125, 207, 255, 0.1);\n\t\t\tpadding: 0.15rem 0.4rem;\n\t\t\tborder-radius: 3px;\n\t\t\ttext-decoration: none;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t.target-commit:hover {\n\t\t\tbackground: rgba(125, 207, 255, 0.2);\n\t\t\tcolor: var(--cyan);\n\t\t}\n\n\t\t.target-branch {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.7rem;\n\t\t}\n\n\t\t.target-separator {\n\t\t\tcolor: var(--border);\n\t\t\tmargin: 0 0.1rem;\n\t\t}\n\n\t\t.target-agent-label {\n\t\t\tfont-size: 0.7rem;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tmargin-right: 0.25rem;\n\t\t}\n\n\t\t.target-agent {\n\t\t\tfont-family: 'JetBrains Mono', 'Fira Code', monospace;\n\t\t\tfont-size: 0.7rem;\n\t\t\tcolor: var(--purple);\n\t\t\tbackground: rgba(187, 154, 247, 0.15);\n\t\t\tpadding: 0.15rem 0.4rem;\n\t\t\tborder-radius: 3px;\n\t\t}\n\n\t\t.target-unavailable {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-style: italic;\n\t\t}\n\n\t\t/* Buttons */\n\t\t.btn {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.4rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tfont-size: 0.8rem;\n\t\t\tfont-family: inherit;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tcolor: var(--fg);\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.2s ease;\n\t\t\ttext-decoration: none;\n\t\t}\n\n\t\t.btn:hover {\n\t\t\tborder-color: var(--blue);\n\t\t\tbackground: rgba(122, 162, 247, 0.1);\n\t\t}\n\n\t\t.btn-primary {\n\t\t\tbackground: var(--blue);\n\t\t\tborder-color: var(--blue);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t.btn-primary:hover {\n\t\t\tbackground: #8aacf7;\n\t\t}\n\n\t\t.btn-danger {\n\t\t\tborder-color: var(--red);\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.btn-danger:hover {\n\t\t\tbackground: var(--red);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t/* Header actions container */\n\t\t.header-actions {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0;\n\t\t}\n\n\t\t/* Header action buttons - consistent sizing */\n\t\t.btn-header {\n\t\t\theight: 36px;\n\t\t\tpadding: 0.5rem 1rem;\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tgap: 0.5rem;\n\t\t\tbox-sizing: border-box;\n\t\t}\n\n\t\t.bulk-actions-dropdown {\n\t\t\tmargin-right: 10px;\n\t\t}\n\n\t\t.header-actions form {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tmargin: 0;\n\t\t}\n\n\t\t.btn:disabled {\n\t\t\topacity: 0.5;\n\t\t\tcursor: not-allowed;\n\t\t}\n\n\t\t/* Cards (mobile-first) */\n\t\t.host-grid {\n\t\t\tdisplay: grid;\n\t\t\tgap: 1rem;\n\t\t}\n\n\t\t/* Desktop: table layout */\n\t\t@media (min-width: 1024px) {\n\t\t\t.host-grid {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\n\t\t\t.host-table {\n\t\t\t\tdisplay: table;\n\t\t\t}\n\t\t}\n\n\t\t/* Mobile: card layout */\n\t\t@media (max-width: 1023px) {\n\t\t\t.host-table {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\t\t}\n\n\t\t/* Host Card (mobile) */\n\t\t.host-card {\n\t\t\tbackground: rgba(36, 40, 59, 0.9);\n\t\t\t/* bg-float with 90% opacity */\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 8px;\n\t\t\toverflow: visible;\n\t\t\tposition: relative;\n\t\t\tz-index: 1;\n\t\t}\n\n\t\t.host-card-header {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tpadding: 0.75rem 1rem;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tcursor: pointer;\n\t\t}\n\n\t\t.host-card-header:hover {\n\t\t\tbackground: var(--bg);\n\t\t}\n\n\t\t.host-name {\n\t\t\tfont-weight: 600;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t}\n\n\t\t.host-card-body {\n\t\t\tpadding: 1rem;\n\t\t\tdisplay: none;\n\t\t}\n\n\t\t.host-card.expanded .host-card-body {\n\t\t\tdisplay: block;\n\t\t}\n\n\t\t.host-card-row {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\tpadding: 0.5rem 0;\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t}\n\n\t\t.host-card-row:last-child {\n\t\t\tborder-bottom: none;\n\t\t}\n\n\t\t.host-card-label {\n\t\t\tcolor: var(--fg-dark);\n\t\t\tfont-size: 0.8rem;\n\t\t}\n\n\t\t.host-card-actions {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-wrap: wrap;\n\t\t\tmargin-top: 1rem;\n\t\t}\n\n\t\t/* Host Table (desktop) */\n\t\t.host-table {\n\t\t\twidth: 100%;\n\t\t\tborder-collapse: collapse;\n\t\t\tbackground: rgba(36, 40, 59, 0.9);\n\t\t\t/* bg-float with 90% opacity */\n\t\t\tborder-radius: 8px;\n\t\t\toverflow: visible;\n\t\t\t/* Allow dropdown menus to extend beyond table */\n\t\t\tposition: relative;\n\t\t\tz-index: 1;\n\t\t\t/* NOTE: table-layout: fixed was removed - it broke auto-sizing and caused \n\t\t\t   rows to not fill table width. Content-based sizing is needed for hostnames. */\n\t\t}\n\n\t\t.host-table th,\n\t\t.host-table td {\n\t\t\tpadding: 0.75rem 1rem;\n\t\t\ttext-align: left;\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t\tfont-size: 13px;\n\t\t\tvertical-align: middle;\n\t\t}\n\n\t\t.host-table th {\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tfont-weight: 500;\n\t\t\tcolor: var(--fg-dark);\n\t\t\tfont-size: 0.8rem;\n\t\t\ttext-transform: uppercase;\n\t\t\tletter-spacing: 0.05em;\n\t\t}\n\n\t\t/* Column alignment classes */\n\t\t.col-center {\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.col-right {\n\t\t\ttext-align: right;\n\t\t}\n\n\t\t.col-hosts {\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t/* Online count highlight */\n\t\t.stat-online-positive {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.host-table tbody tr {\n\t\t\tposition: relative;\n\t\t\t/* Base dark background - gradient overlays this */\n\t\t\tbackground: rgba(10, 11, 16, 0.9);\n\t\t}\n\n\t\t.host-table tr:hover {\n\t\t\tbackground: rgba(30, 34, 48, 0.95);\n\t\t}\n\n\t\t.host-table tr:last-child td {\n\t\t\tborder-bottom: none;\n\t\t}\n\n\t\t/* Offline host row overlay */\n\t\t.host-table tr.host-offline {\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.host-table tr.host-offline::after {\n\t\t\tcontent: '';\n\t\t\tposition: absolute;\n\t\t\ttop: 0;\n\t\t\tleft: 0;\n\t\t\tright: 0;\n\t\t\tbottom: 0;\n\t\t\tbackground: rgba(0, 0, 0, 0.2);\n\t\t\tpointer-events: none;\n\t\t}\n\n\t\t/* Location, Device Type, and Host Type icons */\n\t\t.location-icon,\n\t\t.device-icon,\n\t\t.type-icon {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tcolor: var(--fg);\n\t\t\topacity: 0.85;\n\t\t}\n\n\t\t.location-icon:hover,\n\t\t.device-icon:hover,\n\t\t.type-icon:hover {\n\t\t\topacity: 1;\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t/* Tests cell */\n\t\t.tests-cell {\n\t\t\tfont-size: 0.85rem;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.test-progress {\n\t\t\tcolor: var(--yellow);\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.test-result {\n\t\t\tfont-weight: 500;\n\t\t\tpadding: 2px 6px;\n\t\t\tborder-radius: 4px;\n\t\t}\n\n\t\t.test-result.pass {\n\t\t\tcolor: var(--green);\n\t\t\tbackground: rgba(158, 206, 106, 0.15);\n\t\t}\n\n\t\t.test-result.fail {\n\t\t\tcolor: var(--red);\n\t\t\tbackground: rgba(247, 118, 142, 0.15);\n\t\t}\n\n\t\t.tests-na {\n\t\t\tcolor: var(--fg-dark);\n\t\t\topacity: 0.5;\n\t\t}\n\n\t\t/* Status indicators */\n\t\t.status-dot {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tmin-width: 12px;\n\t\t\tmin-height: 12px;\n\t\t\tborder-radius: 50%;\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tvertical-align: middle;\n\t\t\tflex-shrink: 0;\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.status-online {\n\t\t\tbackground: var(--green);\n\t\t\tbox-shadow: 0 0 6px var(--green);\n\t\t}\n\n\t\t.status-offline {\n\t\t\t/* Smaller muted dot - more visible */\n\t\t\tbackground: #6b7280;\n\t\t\twidth: 6px !important;\n\t\t\theight: 6px !important;\n\t\t\tmin-width: 6px !important;\n\t\t\tmin-height: 6px !important;\n\t\t\tmargin: 3px;\n\t\t\tbox-shadow: none;\n\t\t}\n\n\t\t.status-running {\n\t\t\tbackground: var(--yellow);\n\t\t\tanimation: pulse 1.5s infinite;\n\t\t\tbox-shadow: 0 0 4px var(--yellow);\n\t\t}\n\n\t\t.status-error {\n\t\t\tbackground: var(--red);\n\t\t}\n\n\t\t@keyframes pulse {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\topacity: 1;\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 0.5;\n\t\t\t}\n\t\t}\n\n\t/* Heartbeat indicator for online hosts */\n\t.status-ripple {\n\t\t/* Container is larger than the dot so the heartbeat glow can bloom outside */\n\t\twidth: 18px;\n\t\theight: 18px;\n\t\tposition: relative;\n\t\tdisplay: flex;\n\t\talign-items: center;\n\t\tjustify-content: center;\n\t\tcolor: var(--green);\n\t\t/* IMPORTANT: allow glow to render outside the box (overflow:hidden clips box-shadow) */\n\t\toverflow: visible;\n\t\t/* Keep it from affecting layout/scrollbars (NOTE: paint containment would CLIP glow) */\n\t\tcontain: layout;\n\t\tflex-shrink: 0;\n\t}\n\n\t\t/* Base state: small dot, minimal glow (same visual weight as offline dot) */\n\t\t.status-ripple .hb-core {\n\t\t\twidth: 6px;\n\t\t\theight: 6px;\n\t\t\tbackground: currentColor;\n\t\t\tborder-radius: 50%;\n\t\t\tposition: relative;\n\t\t\tz-index: 2;\n\t\t\tbox-shadow: 0 0 1px rgba(158, 206, 106, 0.25);\n\t\t}\n\n\t\t/* Waves hidden by default - only show on heartbeat */\n\t\t.status-ripple .hb-wave {\n\t\t\tposition: absolute;\n\t\t\ttop: 50%;\n\t\t\tleft: 50%;\n\t\t\twidth: 6px;\n\t\t\theight: 6px;\n\t\t\tmargin: -3px 0 0 -3px;\n\t\t\tbackground: currentColor;\n\t\t\tborder-radius: 50%;\n\t\t\topacity: 0;\n\t\t\t/* Use transform for GPU-accelerated animation (no layout recalc) */\n\t\t\twill-change: transform, opacity;\n\t\t\ttransform: scale(1);\n\t\t}\n\n\t\t/* Animate only when .heartbeat class is present */\n\t\t.status-ripple.heartbeat .hb-wave {\n\t\t\tanimation: ripple-wave 1.5s ease-out forwards;\n\t\t}\n\n\t\t/* P8800: Only shine on heartbeat (avoid constant glow) */\n\t\t.status-ripple.heartbeat .hb-core {\n\t\t\tbox-shadow:\n\t\t\t\t0 0 6px rgba(158, 206, 106, 0.65),\n\t\t\t\t0 0 14px rgba(158, 206, 106, 0.35);\n\t\t}\n\n\t\t.status-ripple.heartbeat .hb-wave:nth-child(2) {\n\t\t\tanimation-delay: 0.3s;\n\t\t}\n\n\t\t.status-ripple.heartbeat .hb-wave:nth-child(3) {\n\t\t\tanimation-delay: 0.6s;\n\t\t}\n\n\t\t@keyframes ripple-wave {\n\t\t\t0% {\n\t\t\t\ttransform: scale(1);\n\t\t\t\topacity: 0.8;\n\t\t\t}\n\n\t\t\t100% {\n\t\t\t\ttransform: scale(2.0); /* P8800: Fits within 16px container (8px * 2.0) */\n\t\t\t\topacity: 0;\n\t\t\t}\n\t\t}\n\n\t\t/* Offline host dimming */\n\t\ttr.host-offline,\n\t\t.host-card.host-offline {\n\t\t\topacity: 0.5;\n\t\t}\n\n\t\ttr.host-offline:hover,\n\t\t.host-card.host-offline:hover {\n\t\t\topacity: 0.8;\n\t\t}\n\n\t\t/* Log viewer */\n\t\t.log-panel {\n\t\t\tmargin-top: 1rem;\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\toverflow: hidden;\n\t\t}\n\n\t\t.log-header {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tpadding: 0.5rem 1rem;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tcursor: pointer;\n\t\t}\n\n\t\t.log-content {\n\t\t\tmax-height: 300px;\n\t\t\toverflow-y: auto;\n\t\t\tpadding: 0.75rem;\n\t\t\tfont-size: 0.8rem;\n\t\t\tline-height: 1.4;\n\t\t}\n\n\t\t.log-line {\n\t\t\tpadding: 0.1rem 0;\n\t\t\twhite-space: pre-wrap;\n\t\t\tword-break: break-all;\n\t\t\tdisplay: flex;\n\t\t\talign-items: flex-start;\n\t\t\tgap: 0.35rem;\n\t\t}\n\n\t\t.log-line.error {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.log-line.success {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t/* Small inline icon for host output lines */\n\t\t.log-line-icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tflex-shrink: 0;\n\t\t\tmargin-top: 0.15rem;\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t/* P4020: Tabbed Output Panel */\n\t\t.output-panel {\n\t\t\tmargin-top: 1rem;\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\toverflow: hidden;\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t}\n\n\t\t.output-panel.hidden {\n\t\t\tdisplay: none;\n\t\t}\n\n\t\t.output-panel.collapsed .output-content {\n\t\t\tdisplay: none;\n\t\t}\n\n\t\t.output-tabs {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t\toverflow: visible;  /* Allow dropdown to overflow */\n\t\t}\n\n\t\t.tab-list {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tflex: 1;\n\t\t\tmin-width: 0;\n\t\t\toverflow-x: auto;\n\t\t\tscrollbar-width: thin;\n\t\t}\n\n\t\t.tab-list::-webkit-scrollbar {\n\t\t\theight: 4px;\n\t\t}\n\n\t\t.tab-list::-webkit-scrollbar-thumb {\n\t\t\tbackground: var(--border);\n\t\t\tborder-radius: 2px;\n\t\t}\n\n\t\t.output-tab {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tbackground: transparent;\n\t\t\tborder: none;\n\t\t\tborder-bottom: 2px solid transparent;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.8rem;\n\t\t\tcursor: pointer;\n\t\t\twhite-space: nowrap;\n\t\t\ttransition: all 0.15s;\n\t\t}\n\n\t\t.output-tab:hover {\n\t\t\tbackground: rgba(255,255,255,0.05);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.output-tab.active {\n\t\t\tcolor: var(--fg);\n\t\t\tborder-bottom-color: var(--blue);\n\t\t\tbackground: rgba(122, 162, 247, 0.1);\n\t\t}\n\n\t\t.output-tab .tab-indicator {\n\t\t\twidth: 8px;\n\t\t\theight: 8px;\n\t\t\tborder-radius: 50%;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.output-tab .tab-indicator.running {\n\t\t\tbackground: var(--yellow);\n\t\t\tanimation: pulse 1.5s infinite;\n\t\t}\n\n\t\t.output-tab .tab-indicator.awaiting {\n\t\t\tbackground: var(--orange);\n\t\t\tanimation: pulse 1.5s infinite;\n\t\t}\n\n\t\t.output-tab .tab-indicator.success {\n\t\t\tbackground: var(--green);\n\t\t}\n\n\t\t.output-tab .tab-indicator.warning {\n\t\t\tbackground: var(--orange);\n\t\t}\n\n\t\t.output-tab .tab-indicator.error {\n\t\t\tbackground: var(--red);\n\t\t}\n\n\t\t.output-tab .tab-indicator.timeout {\n\t\t\tbackground: var(--yellow);\n\t\t}\n\n\t\t.output-tab .tab-indicator.unread {\n\t\t\tbackground: var(--blue);\n\t\t}\n\n\t\t@keyframes pulse {\n\t\t\t0%, 100% { opacity: 1; }\n\t\t\t50% { opacity: 0.5; }\n\t\t}\n\n\t\t.output-tab .tab-close {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tborder-radius: 3px;\n\t\t\tfont-size: 0.7rem;\n\t\t\tcolor: var(--fg-muted);\n\t\t\topacity: 0;\n\t\t\ttransition: opacity 0.15s;\n\t\t}\n\n\t\t.output-tab:hover .tab-close {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.output-tab .tab-close:hover {\n\t\t\tbackground: rgba(255,255,255,0.1);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.tab-actions {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0 0.75rem;\n\t\t\tborder-left: 1px solid var(--border);\n\t\t}\n\n\t\t.tab-action-btn {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.3rem;\n\t\t\tpadding: 0.3rem 0.5rem;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.7rem;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.15s;\n\t\t}\n\n\t\t.tab-action-btn:hover {\n\t\t\tbackground: rgba(255,255,255,0.05);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.output-content {\n\t\t\tmin-height: 50px;  /* Ensure resize handle works when empty */\n\t\t\tmax-height: 300px;\n\t\t\toverflow-y: scroll;  /* Always show scrollbar */\n\t\t\tpadding: 0.75rem;\n\t\t\tfont-size: 0.8rem;\n\t\t\tline-height: 1.4;\n\t\t}\n\n\t\t.output-content .command-separator {\n\t\t\tmargin: 0.75rem 0 0.25rem 0;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: inherit;  /* Inherit for A+/A- controls */\n\t\t\tfont-family: var(--font-mono);\n\t\t\ttext-align: center;\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t.output-content .command-separator.success {\n\t\t\tcolor: var(--success);\n\t\t\topacity: 0.9;\n\t\t}\n\n\t\t.output-content .command-separator.error {\n\t\t\tcolor: var(--error);\n\t\t\topacity: 0.9;\n\t\t}\n\n\t\t.output-content .status-line {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: inherit;  /* Inherit for A+/A- controls */\n\t\t\tpadding: 0.15rem 0;\n\t\t\topacity: 0.85;\n\t\t}\n\n\t\t/* Host output lines: icon provides visual distinction, small left margin */\n\t\t.output-content .host-output {\n\t\t\tmargin-left: 0.5rem;\n\t\t}\n\n\t\t.output-content .system-log-entry {\n\t\t\tdisplay: flex;\n\t\t\talign-items: flex-start;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.25rem 0;\n\t\t\tcolor: var(--fg-dark);  /* More gray than host log */\n\t\t}\n\n\t\t.output-content .system-log-entry .log-icon {\n\t\t\tflex-shrink: 0;\n\t\t\twidth: 1rem;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.output-content .system-log-entry .log-time {\n\t\t\tflex-shrink: 0;\n\t\t\tcolor: var(--fg-gutter);  /* Even more muted */\n\t\t\tfont-size: inherit;  /* Inherit for A+/A- controls */\n\t\t}\n\n\t\t.output-content .system-log-entry .log-message {\n\t\t\tflex: 1;\n\t\t}\n\n\t\t.output-content .system-log-entry.success .log-icon { color: var(--green); }\n\t\t.output-content .system-log-entry.warning .log-icon { color: var(--orange); }\n\t\t.output-content .system-log-entry.error .log-icon { color: var(--red); }\n\t\t.output-content .system-log-entry.info .log-icon { color: var(--blue); }\n\t\t.output-content .system-log-entry.pending .log-icon { color: var(--yellow); }\n\n\t\t.output-footer {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: flex-end;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder-top: 1px solid var(--border);\n\t\t}\n\n\t\t/* P4021: Tab overflow dropdown */\n\t\t.tab-overflow {\n\t\t\tposition: relative;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t.tab-overflow-btn {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t\tpadding: 0.4rem 0.6rem;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.75rem;\n\t\t\tcursor: pointer;\n\t\t\twhite-space: nowrap;\n\t\t\ttransition: all 0.15s;\n\t\t}\n\n\t\t.tab-overflow-btn:hover {\n\t\t\tbackground: rgba(255,255,255,0.05);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.tab-overflow-menu {\n\t\t\tposition: absolute;\n\t\t\ttop: 100%;  /* Show below the button, not above */\n\t\t\tright: 0;\n\t\t\tmargin-top: 4px;\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tmin-width: 200px;\n\t\t\tmax-height: 300px;\n\t\t\toverflow-y: auto;\n\t\t\tbox-shadow: 0 4px 12px rgba(0, 0, 0, 0.5);\n\t\t\tz-index: 1000;  /* Higher z-index to show above all content */\n\t\t}\n\n\t\t.tab-overflow-item {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tcursor: pointer;\n\t\t\twidth: 100%;\n\t\t\tbackground: none;\n\t\t\tborder: none;\n\t\t\tcolor: var(--fg);\n\t\t\tfont-size: 0.8rem;\n\t\t\tfont-family: inherit;\n\t\t\ttext-align: left;\n\t\t}\n\n\t\t.tab-overflow-item:hover {\n\t\t\tbackground: var(--bg-highlight);\n\t\t}\n\n\t\t.tab-overflow-item.active {\n\t\t\tbackground: rgba(122, 162, 247, 0.1);\n\t\t}\n\n\t\t.tab-overflow-item .tab-indicator {\n\t\t\twidth: 8px;\n\t\t\theight: 8px;\n\t\t\tborder-radius: 50%;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.tab-overflow-item .tab-toggle {\n\t\t\tmargin-left: auto;\n\t\t\tcolor: var(--green);\n\t\t\tfont-size: 0.9rem;\n\t\t}\n\n\t\t/* P4021: Resize handle (bottom of panel) */\n\t\t.output-resize-handle {\n\t\t\theight: 14px;\n\t\t\tbackground: var(--bg-secondary);\n\t\t\tcursor: ns-resize;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tborder-top: 1px solid var(--border);\n\t\t\ttransition: background 0.15s;\n\t\t}\n\n\t\t.output-resize-handle:hover,\n\t\t.output-resize-handle.resizing {\n\t\t\tbackground: rgba(122, 162, 247, 0.2);\n\t\t}\n\n\t\t.output-resize-handle .resize-grip {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 10px;\n\t\t\tletter-spacing: 2px;\n\t\t\topacity: 0.5;\n\t\t\ttransition: opacity 0.15s;\n\t\t}\n\n\t\t.output-resize-handle:hover .resize-grip {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t/* P4021: Mobile tab behavior */\n\t\t@media (max-width: 640px) {\n\t\t\t.tab-list .output-tab:not(.active) {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\t\t\t.tab-overflow {\n\t\t\t\tdisplay: flex;\n\t\t\t}\n\t\t}\n\n\t\t@media (min-width: 641px) {\n\t\t\t.tab-list .output-tab {\n\t\t\t\tdisplay: flex;\n\t\t\t}\n\t\t}\n\n\t\t/* P4021: Relative time styling */\n\t\t.log-time-relative {\n\t\t\tcolor: var(--fg-gutter);\n\t\t\tfont-size: 0.85em;  /* Proportionally smaller, scales with A+/A- */\n\t\t\tmargin-left: 0.25rem;\n\t\t}\n\n\t\t/* Progress indicator */\n\t\t.progress-badge {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.3rem;\n\t\t\tpadding: 0.25rem 0.5rem;\n\t\t\tbackground: rgba(224, 175, 104, 0.15);\n\t\t\tborder: 1px solid var(--yellow);\n\t\t\tborder-radius: 4px;\n\t\t\tfont-size: 0.75rem;\n\t\t\tcolor: var(--yellow);\n\t\t}\n\n\t\t/* Mini progress badge (next to status dot) */\n\t\t.status-with-badge {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t}\n\n\t/* For table cells: use flexbox for consistent status + badge alignment */\n\ttd.status-cell-with-badge {\n\t\tvertical-align: middle;\n\t\t/* Allow heartbeat glow to overdraw; rely on fixed table layout to prevent wiggle */\n\t\toverflow: visible;\n\t\t/* NOTE: paint containment would clip the glow; use layout containment only */\n\t\tcontain: layout;\n\t\twhite-space: nowrap; /* P8800: Prevent status + badge wrapping (wraps can change row height) */\n\t}\n\n\t\t/* P8800/P8900: Give STATUS column enough width so it can't overlap the menu (ellipsis) column. */\n\t\t.host-table th.col-status,\n\t\t.host-table td.status-cell {\n\t\t\t/* 5 compartments × 38px + gaps, plus cell padding. */\n\t\t\twidth: 280px;\n\t\t\tmin-width: 280px;\n\t\t\tmax-width: 280px;\n\t\t\twhite-space: nowrap;\n\t\t\toverflow: hidden; /* Prevent status content from painting into the menu column */\n\t\t\tpadding-left: 0.5rem;\n\t\t\tpadding-right: 0.5rem;\n\t\t}\n\n\t\t/* Status cell: left-align compartments (natural flow) */\n\t\t.host-table td.status-cell {\n\t\t\ttext-align: left;\n\t\t}\n\n\ttd.status-cell-with-badge .status-wrapper {\n\t\tdisplay: inline-flex;\n\t\talign-items: center;\n\t\tgap: 0.5rem;\n\t}\n\n\t\t.progress-badge-mini {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tpadding: 0.15rem 0.3rem;\n\t\t\tbackground: rgba(224, 175, 104, 0.15);\n\t\t\tborder: 1px solid var(--yellow);\n\t\t\tborder-radius: 3px;\n\t\t\tfont-size: 0.5rem;\n\t\t\tcolor: var(--yellow);\n\t\t\tline-height: 1;\n\t\t}\n\n\t\t/* P7240: Timeout indicator in Status column */\n\t\t.timeout-status-indicator {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t\tpadding: 0.2rem 0.4rem;\n\t\t\tbackground: rgba(234, 179, 8, 0.15);\n\t\t\tborder: 1px solid rgba(234, 179, 8, 0.4);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--yellow);\n\t\t\tfont-size: 0.7rem;\n\t\t\tfont-weight: 600;\n\t\t\tanimation: pulse-timeout 1.5s ease-in-out infinite;\n\t\t\tcursor: pointer;\n\t\t}\n\n\t\t.timeout-status-indicator:hover {\n\t\t\tbackground: rgba(234, 179, 8, 0.25);\n\t\t}\n\n\t\t.timeout-status-indicator .icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t}\n\n\t\t@keyframes pulse-timeout {\n\t\t\t0%, 100% { opacity: 0.7; }\n\t\t\t50% { opacity: 1; }\n\t\t}\n\n\t\t.hostname {\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t/* Clickable hostname for copy-to-clipboard */\n\t\t.hostname-copyable {\n\t\t\tcursor: pointer;\n\t\t\ttransition: filter 0.15s ease, transform 0.1s ease;\n\t\t\tborder-radius: 3px;\n\t\t\tpadding: 0 0.2rem;\n\t\t\tmargin: 0 -0.2rem;\n\t\t}\n\n\t\t.hostname-copyable:hover {\n\t\t\tfilter: brightness(1.3);\n\t\t\tbackground: rgba(255, 255, 255, 0.08);\n\t\t}\n\n\t\t.hostname-copyable:active {\n\t\t\ttransform: scale(0.98);\n\t\t}\n\n\t\t/* P7230: Device type icon prefix before hostname */\n\t\t.hostname-device-icon {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tmargin-left: 0.5rem;\n\t\t\tmargin-right: 0.2rem;\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t.hostname-device-icon .icon,\n\t\t.hostname-device-icon .device-icon {\n\t\t\twidth: 10px;\n\t\t\theight: 10px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t}\n\n\t\t/* Footer */\n\t\tfooter {\n\t\t\tmargin-top: 2rem;\n\t\t\tpadding-top: 1rem;\n\t\t\tborder-top: 1px solid var(--border);\n\t\t\tcolor: var(--fg-dark);\n\t\t\tfont-size: 0.75rem;\n\t\t}\n\n\t\t.site-footer {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tflex-wrap: wrap;\n\t\t\tgap: 0.5rem;\n\t\t}\n\n\t\t.footer-left,\n\t\t.footer-right {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t.footer-sep {\n\t\t\tcolor: var(--fg-gutter);\n\t\t}\n\n\t\t.footer-link {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.2rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t\ttext-decoration: none;\n\t\t\ttransition: color 0.2s;\n\t\t}\n\n\t\t.footer-link:hover {\n\t\t\tcolor: var(--blue);\n\t\t}\n\n\t\t.footer-link .icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t}\n\n\t\t.made-with {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.3rem;\n\t\t}\n\n\t\t.made-with a {\n\t\t\tcolor: var(--blue);\n\t\t\ttext-decoration: none;\n\t\t}\n\n\t\t.made-with a:hover {\n\t\t\ttext-decoration: underline;\n\t\t}\n\n\t\t.made-with .heart {\n\t\t\tcolor: var(--red);\n\t\t\tanimation: heartbeat 1.5s ease-in-out infinite;\n\t\t}\n\n\t\t@keyframes heartbeat {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\ttransform: scale(1);\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\ttransform: scale(1.15);\n\t\t\t}\n\t\t}\n\n\t\t@media (max-width: 640px) {\n\t\t\t.site-footer {\n\t\t\t\tflex-direction: column;\n\t\t\t\ttext-align: center;\n\t\t\t}\n\n\t\t\t.footer-left,\n\t\t\t.footer-right {\n\t\t\t\tjustify-content: center;\n\t\t\t}\n\t\t}\n\n\t\t/* Stats bar */\n\t\t.stats-bar {\n\t\t\tdisplay: flex;\n\t\t\tgap: 1rem;\n\t\t\tmargin-bottom: 1rem;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t.stat {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 1rem;\n\t\t\tbackground: var(--bg-float);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tfont-size: 0.85rem;\n\t\t}\n\n\t\t.stat-value {\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t.stat-value.online {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.stat-value.offline {\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t/* Connection indicator */\n\t\t.connection-indicator {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t\tfont-size: 0.75rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.connection-indicator .status-dot {\n\t\t\twidth: 6px;\n\t\t\theight: 6px;\n\t\t\tmin-width: 6px;\n\t\t\tmin-height: 6px;\n\t\t\tbackground: currentColor;\n\t\t\tmargin: 0;\n\t\t}\n\n\t\t.connection-indicator.connected {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.connection-indicator.disconnected {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\n\t\t/* Chevron icon */\n\t\t.chevron {\n\t\t\ttransition: transform 0.2s ease;\n\t\t}\n\n\t\t.expanded .chevron {\n\t\t\ttransform: rotate(180deg);\n\t\t}\n\n\t\t/* Hide utility */\n\t\t.hidden {\n\t\t\tdisplay: none !important;\n\t\t}\n\n\t\t/* Icon styles */\n\t\t.icon {\n\t\t\twidth: 14px;\n\t\t\theight: 14px;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.btn .icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t}\n\n\t\t.metric-icon {\n\t\t\twidth: 10px;\n\t\t\theight: 10px;\n\t\t\topacity: 0.7;\n\t\t\tflex-shrink: 0;\n\t\t\tmargin-right: -10px;\n\t\t}\n\n\t\t/* Metrics display */\n\t\ttd.metrics-cell {\n\t\t\tfont-size: 0.85rem;\n\t\t\tfont-family: var(--font-mono);\n\t\t\tvertical-align: middle;\n\t\t}\n\n\t\ttd.metrics-cell>span,\n\t\tspan.metrics-cell {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 25px;\n\t\t}\n\n\t\t.metric {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0;\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.metric-val {\n\t\t\tdisplay: inline-block;\n\t\t\twidth: 4ch;\n\t\t\ttext-align: right;\n\t\t}\n\n\t\t.metric.high {\n\t\t\tcolor: var(--red);\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t.metric.high .metric-icon {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.metrics-na {\n\t\t\tcolor: var(--fg-gutter);\n\t\t}\n\n\t\t/* Last seen time colors */\n\t\t.last-seen-ok {\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.last-seen-warn {\n\t\t\tcolor: var(--yellow);\n\t\t}\n\n\t\t.last-seen-stale {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t/* P8800: Last Seen column must not resize when text changes (\"9s\" -> \"10s\" -> \"1m\"). */\n\t\t.host-table th.col-last-seen,\n\t\t.host-table td.col-last-seen {\n\t\t\twidth: 120px;\n\t\t\tmin-width: 120px;\n\t\t\tmax-width: 120px;\n\t\t\twhite-space: nowrap;\n\t\t\toverflow: hidden;\n\t\t\ttext-overflow: ellipsis;\n\t\t\tfont-variant-numeric: tabular-nums;\n\t\t}\n\n\t\t/* Agent Version Column (P7300) */\n\t\t.agent-version-cell {\n\t\t\tfont-size: 0.8rem;\n\t\t\tfont-family: var(--font-mono);\n\t\t\ttext-align: center;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.agent-version {\n\t\t\tpadding: 0.15rem 0.4rem;\n\t\t\tborder-radius: 4px;\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.agent-version--ok {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.agent-version--outdated {\n\t\t\tcolor: var(--red);\n\t\t\tbackground: rgba(247, 118, 142, 0.15);\n\t\t}\n\n\t\t.agent-version--unknown {\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t/* P4500: Generation Column */\n\t\t.gen-cell {\n\t\t\tfont-size: 0.8rem;\n\t\t\tfont-family: var(--font-mono);\n\t\t\ttext-align: center;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.gen-hash {\n\t\t\tpadding: 0.15rem 0.4rem;\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tcursor: default;\n\t\t}\n\n\t\t.gen-hash:hover {\n\t\t\tcolor: var(--fg);\n\t\t\tbackground: rgba(255, 255, 255, 0.05);\n\t\t}\n\n\t\t.gen-unknown {\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.gen-drift {\n\t\t\tcolor: var(--yellow);\n\t\t\tbackground: rgba(224, 175, 104, 0.15);\n\t\t}\n\n\t\t/* Update Status Compartments (P5000 / P7300) */\n\t\t.update-status {\n\t\t\tdisplay: inline-flex;\n\t\t\tgap: 4px;  /* Slightly more spacing for larger compartments */\n\t\t}\n\n\t\t.update-compartment {\n\t\t\tposition: relative;\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\twidth: 24px;\n\t\t\theight: 24px;\n\t\t\tbackground: #374151;\n\t\t\tborder-radius: 4px;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t.update-compartment:hover {\n\t\t\tbackground: #4b5563;\n\t\t}\n\n\t\t/* P7230: Icon 20% bigger (11→13px), centered */\n\t\t.update-compartment .update-icon {\n\t\t\twidth: 13px;\n\t\t\theight: 13px;\n\t\t\tfill: #1f2937;\n\t\t\tstroke: #1f2937;\n\t\t\tcolor: #1f2937;\n\t\t}\n\n\t\t/* P5100: Simplified - icon always dark, only indicator dot shows status */\n\t\t/* Unknown state: same background, slightly dimmed to hint at stale data */\n\t\t.update-compartment.unknown {\n\t\t\topacity: 0.6;\n\t\t}\n\n\n\t\t/* Flake Update Banner (P5300) */\n\t\t.flake-update-banner {\n\t\t\tbackground: linear-gradient(135deg, #1e3a5f, #0d1a2d);\n\t\t\tborder: 1px solid #3b82f6;\n\t\t\tborder-radius: 8px;\n\t\t\tmargin: 0 1rem 1rem;\n\t\t\tpadding: 0;\n\t\t\tbox-shadow: 0 4px 12px rgba(59, 130, 246, 0.2);\n\t\t}\n\n\t\t.flake-update-content {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 1rem;\n\t\t\tpadding: 0.75rem 1rem;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t.flake-update-icon {\n\t\t\tfont-size: 1.25rem;\n\t\t}\n\n\t\t.flake-update-text {\n\t\t\tflex: 1;\n\t\t\tmin-width: 200px;\n\t\t\tcolor: #e2e8f0;\n\t\t}\n\n\t\t.flake-update-text a {\n\t\t\tcolor: #60a5fa;\n\t\t\ttext-decoration: none;\n\t\t}\n\n\t\t.flake-update-text a:hover {\n\t\t\ttext-decoration: underline;\n\t\t}\n\n\t\t.flake-update-success {\n\t\t\tborder-color: #22c55e;\n\t\t\tbackground: linear-gradient(135deg, #14532d, #052e16);\n\t\t}\n\n\t\t.flake-update-error {\n\t\t\tborder-color: #ef4444;\n\t\t\tbackground: linear-gradient(135deg, #7f1d1d, #450a0a);\n\t\t}\n\n\t\t.flake-update-progress {\n\t\t\tanimation: flake-update-pulse 2s ease-in-out infinite;\n\t\t}\n\n\t\t.flake-update-spinner {\n\t\t\tanimation: flake-update-spin 1.5s linear infinite;\n\t\t}\n\n\t\t@keyframes flake-update-pulse {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\topacity: 0.9;\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t}\n\t\t}\n\n\t\t@keyframes flake-update-spin {\n\t\t\tfrom {\n\t\t\t\ttransform: rotate(0deg);\n\t\t\t}\n\n\t\t\tto {\n\t\t\t\ttransform: rotate(360deg);\n\t\t\t}\n\t\t}\n\n\t\t.btn-sm {\n\t\t\tpadding: 0.35rem 0.75rem;\n\t\t\tfont-size: 0.8rem;\n\t\t}\n\n\t\t@keyframes pulse-glow {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\topacity: 0.3;\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t}\n\t\t}\n\n\t\t@keyframes indicator-pulse {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\topacity: 0.4;\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t}\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t   COMPARTMENT STATUS INDICATOR (P7300 simplified)\n\t\t   Single dot per compartment with 5 color states:\n\t\t   - gray: not checked / no data\n\t\t   - blue pulse: working / in progress\n\t\t   - green: ok / current\n\t\t   - yellow: warning / outdated\n\t\t   - red: error / failed\n\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\t\t/* P7230: Indicator dot 15% smaller (6→5px) */\n\t\t.compartment-indicator {\n\t\t\tposition: absolute;\n\t\t\tbottom: 4px;\n\t\t\tright: 4px;\n\t\t\twidth: 5px;\n\t\t\theight: 5px;\n\t\t\tborder-radius: 50%;\n\t\t\tpointer-events: none;\n\t\t}\n\n\t\t/* P1100: Status text label for accessibility (left of dot) */\n\t\t.compartment-indicator::before {\n\t\t\tcontent: attr(data-status);\n\t\t\tposition: absolute;\n\t\t\tright: 8px; /* left of the 5px dot + 3px gap */\n\t\t\ttop: 50%;\n\t\t\ttransform: translateY(-50%);\n\t\t\tfont-size: 6px;\n\t\t\tfont-weight: 600;\n\t\t\tletter-spacing: 0.5px;\n\t\t\ttext-transform: uppercase;\n\t\t\topacity: 0.35;\n\t\t\twhite-space: nowrap;\n\t\t\tcolor: currentColor;\n\t\t}\n\n\t\t/* Gray: Not checked / no data / offline */\n\t\t.compartment-indicator--gray,\n\t\t.compartment-indicator--unknown {\n\t\t\tbackground: hsl(220, 10%, 45%);\n\t\t\topacity: 0.5;\n\t\t}\n\n\t\t/* Blue pulse: Working / in progress */\n\t\t.compartment-indicator--working {\n\t\t\tbackground: hsl(210, 90%, 55%);\n\t\t\tbox-shadow: 0 0 4px hsla(210, 90%, 55%, 0.8);\n\t\t\tanimation: working-pulse 1.2s ease-in-out infinite;\n\t\t}\n\n\t\t@keyframes working-pulse {\n\t\t\t0%, 100% {\n\t\t\t\topacity: 0.5;\n\t\t\t\ttransform: scale(0.9);\n\t\t\t}\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t\ttransform: scale(1.1);\n\t\t\t}\n\t\t}\n\n\t\t/* Green: OK / current / up-to-date */\n\t\t.compartment-indicator--ok {\n\t\t\tbackground: hsl(142, 71%, 45%);\n\t\t\tbox-shadow: 0 0 3px hsla(142, 71%, 45%, 0.6);\n\t\t}\n\n\t\t/* Yellow: Warning / outdated but not critical */\n\t\t.compartment-indicator--warning {\n\t\t\tbackground: hsl(45, 90%, 50%);\n\t\t\tbox-shadow: 0 0 3px hsla(45, 90%, 50%, 0.6);\n\t\t}\n\n\t\t/* Red: Error / failed / critical */\n\t\t.compartment-indicator--error {\n\t\t\tbackground: hsl(0, 70%, 55%);\n\t\t\tbox-shadow: 0 0 3px hsla(0, 70%, 55%, 0.6);\n\t\t}\n\n\n\t\t/* Action Dropdown (P4380) */\n\t\t.action-buttons {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.25rem;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t.dropdown {\n\t\t\tposition: relative;\n\t\t\tdisplay: inline-block;\n\t\t}\n\n\t\t.btn-more {\n\t\t\tpadding: 0.4rem;\n\t\t\tmargin-left: 10px;\n\t\t\tmin-width: 30px;\n\t\t\tmin-height: 30px;\n\t\t\theight: 30px;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t}\n\n\t\t/* Stop button - replaces cmd buttons when command running */\n\t\t.btn-stop {\n\t\t\tbackground: var(--red);\n\t\t\tcolor: white;\n\t\t\tborder: none;\n\t\t\tborder-radius: 4px;\n\t\t\tpadding: 0.25rem 0.5rem;\n\t\t\tcursor: pointer;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t\tfont-size: 0.75rem;\n\t\t}\n\n\t\t.btn-stop:hover {\n\t\t\tbackground: hsl(0, 70%, 50%);\n\t\t}\n\n\t\t/* P7000: PR indicator on Lock compartment */\n\t\t.update-compartment.has-pr {\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.update-compartment.has-pr::after {\n\t\t\tcontent: \"\";\n\t\t\tposition: absolute;\n\t\t\ttop: -2px;\n\t\t\tright: -2px;\n\t\t\twidth: 6px;\n\t\t\theight: 6px;\n\t\t\tbackground: var(--color-blue);\n\t\t\tborder-radius: 50%;\n\t\t}\n\n\t\t.dropdown-menu {\n\t\t\tposition: absolute;\n\t\t\tright: 0;\n\t\t\ttop: 100%;\n\t\t\tmargin-top: 4px;\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tmin-width: 200px;\n\t\t\tmax-width: calc(100vw - 2rem);\n\t\t\tmax-height: calc(100vh - 100px);\n\t\t\toverflow-y: auto;\n\t\t\tbox-shadow: 0 4px 12px rgba(0, 0, 0, 0.3);\n\t\t\tz-index: 99999;\n\t\t}\n\n\t\t/* Ensure dropdown parent creates stacking context */\n\t\t.dropdown {\n\t\t\tposition: relative;\n\t\t\tz-index: 100;\n\t\t}\n\n\t\t.dropdown:has(.dropdown-menu[x-show=\"true\"]),\n\t\t.dropdown:has(.dropdown-menu:not([style*=\"display: none\"])) {\n\t\t\tz-index: 99999;\n\t\t}\n\n\t\t/* Header dropdown (id-based) starts hidden, uses .open class */\n\t\t#bulk-actions-menu {\n\t\t\tdisplay: none;\n\t\t}\n\n\t\t#bulk-actions-menu.open {\n\t\t\tdisplay: block;\n\t\t}\n\n\t\t/* x-cloak hides Alpine elements until initialized */\n\t\t[x-cloak] {\n\t\t\tdisplay: none !important;\n\t\t}\n\n\t\t/* Bulk Actions dropdown in header */\n\t\t.bulk-actions-dropdown {\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.bulk-actions-dropdown .dropdown-menu {\n\t\t\tright: auto;\n\t\t\tleft: 0;\n\t\t}\n\n\t\t.dropdown-item {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tcursor: pointer;\n\t\t\twidth: 100%;\n\t\t\tbackground: none;\n\t\t\tborder: none;\n\t\t\tcolor: var(--fg);\n\t\t\tfont-size: 0.85rem;\n\t\t\tfont-family: inherit;\n\t\t\ttext-align: left;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.dropdown-item:hover {\n\t\t\tbackground: var(--bg-highlight);\n\t\t}\n\n\t\t.dropdown-item.danger {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.dropdown-item.danger:hover {\n\t\t\tbackground: rgba(247, 118, 142, 0.1);\n\t\t}\n\n\t\t.dropdown-item:disabled {\n\t\t\topacity: 0.5;\n\t\t\tcursor: not-allowed;\n\t\t}\n\n\t\t.dropdown-item .icon {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tflex-shrink: 0;\n\t\t\tcolor: var(--fg-muted);\n\t\t}\n\n\t\t.dropdown-item:hover:not(:disabled) .icon {\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.dropdown-divider {\n\t\t\theight: 1px;\n\t\t\tbackground: var(--border);\n\t\t\tmargin: 0.25rem 0;\n\t\t\tborder: none;\n\t\t}\n\n\t\t/* P1060: Dropdown toggle button */\n\t\t.col-menu {\n\t\t\twidth: 70px;\n\t\t\tmin-width: 70px;\n\t\t\ttext-align: center;\n\t\t\tvertical-align: middle;\n\t\t\tpadding: 0.5rem !important;\n\t\t}\n\n\t\t.dropdown-toggle {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\twidth: 28px;\n\t\t\theight: 28px;\n\t\t\tpadding: 0;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 150ms ease;\n\t\t}\n\n\t\t.dropdown-toggle:hover {\n\t\t\tcolor: var(--fg);\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder-color: var(--fg-dim);\n\t\t}\n\n\t\t.dropdown-toggle:focus-visible {\n\t\t\toutline: 2px solid var(--blue);\n\t\t\toutline-offset: 2px;\n\t\t}\n\n\t\t.dropdown-toggle .icon {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t}\n\n\t\t/* Modals (P4390) */\n\t\t.modal-overlay {\n\t\t\tdisplay: none;\n\t\t\tposition: fixed;\n\t\t\tinset: 0;\n\t\t\tbackground: rgba(0, 0, 0, 0.6);\n\t\t\tz-index: 10000;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t}\n\n\t\t.modal-overlay.open {\n\t\t\tdisplay: flex;\n\t\t}\n\n\t\t.modal {\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 8px;\n\t\t\tpadding: 1.5rem;\n\t\t\tmax-width: 400px;\n\t\t\twidth: 90%;\n\t\t\tbox-shadow: 0 8px 24px rgba(0, 0, 0, 0.4);\n\t\t}\n\n\t\t.modal-wide {\n\t\t\tmax-width: 500px;\n\t\t}\n\n\t\t.modal-title {\n\t\t\tfont-size: 1.1rem;\n\t\t\tfont-weight: 600;\n\t\t\tmargin-bottom: 1rem;\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.modal-body {\n\t\t\tmargin-bottom: 1.5rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.modal-actions {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.5rem;\n\t\t\tjustify-content: flex-end;\n\t\t}\n\n\t\t.modal-btn {\n\t\t\tpadding: 0.5rem 1rem;\n\t\t\tborder-radius: 6px;\n\t\t\tfont-size: 0.85rem;\n\t\t\tfont-family: inherit;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t.modal-btn-cancel {\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.modal-btn-cancel:hover {\n\t\t\tbackground: var(--bg);\n\t\t}\n\n\t\t.modal-btn-primary {\n\t\t\tbackground: var(--blue);\n\t\t\tborder: 1px solid var(--blue);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t.modal-btn-primary:hover {\n\t\t\tbackground: #8aacf7;\n\t\t}\n\n\t\t.modal-btn-danger {\n\t\t\tbackground: rgba(247, 118, 142, 0.15);\n\t\t\tborder: 1px solid rgba(247, 118, 142, 0.3);\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.modal-btn-danger:hover {\n\t\t\tbackground: rgba(247, 118, 142, 0.25);\n\t\t}\n\n\t\t/* Form styles for modals */\n\t\t.form-group {\n\t\t\tmargin-bottom: 1rem;\n\t\t}\n\n\t\t.form-group label {\n\t\t\tdisplay: block;\n\t\t\tmargin-bottom: 0.25rem;\n\t\t\tfont-size: 0.85rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.form-group input,\n\t\t.form-group select {\n\t\t\twidth: 100%;\n\t\t\tpadding: 0.5rem;\n\t\t\tbackground: var(--bg);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg);\n\t\t\tfont-family: inherit;\n\t\t\tfont-size: 0.9rem;\n\t\t}\n\n\t\t.form-group input:focus,\n\t\t.form-group select:focus {\n\t\t\toutline: none;\n\t\t\tborder-color: var(--blue);\n\t\t}\n\n\t\t.form-row {\n\t\t\tdisplay: grid;\n\t\t\tgrid-template-columns: 1fr 1fr;\n\t\t\tgap: 1rem;\n\t\t}\n\n\t\t/* P2950: Color Picker Styles */\n\t\t.color-picker-host {\n\t\t\tmargin-bottom: 1rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.color-picker-host code {\n\t\t\tcolor: var(--blue);\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t.color-presets {\n\t\t\tdisplay: flex;\n\t\t\tflex-wrap: wrap;\n\t\t\tgap: 0.5rem;\n\t\t}\n\n\t\t.color-preset {\n\t\t\twidth: 36px;\n\t\t\theight: 36px;\n\t\t\tborder: 2px solid transparent;\n\t\t\tborder-radius: 6px;\n\t\t\tpadding: 2px;\n\t\t\tcursor: pointer;\n\t\t\tbackground: var(--bg);\n\t\t\ttransition: all 0.15s ease;\n\t\t}\n\n\t\t.color-preset:hover {\n\t\t\tborder-color: var(--fg-dark);\n\t\t}\n\n\t\t.color-preset.selected {\n\t\t\tborder-color: var(--blue);\n\t\t\tbox-shadow: 0 0 0 2px rgba(122, 162, 247, 0.3);\n\t\t}\n\n\t\t.color-swatch {\n\t\t\tdisplay: block;\n\t\t\twidth: 100%;\n\t\t\theight: 100%;\n\t\t\tborder-radius: 4px;\n\t\t}\n\n\t\t.custom-color-row {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.75rem;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t.custom-color-row input[type=\"color\"] {\n\t\t\twidth: 48px;\n\t\t\theight: 36px;\n\t\t\tpadding: 0;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tcursor: pointer;\n\t\t\tbackground: transparent;\n\t\t}\n\n\t\t.custom-color-row input[type=\"color\"]::-webkit-color-swatch-wrapper {\n\t\t\tpadding: 2px;\n\t\t}\n\n\t\t.custom-color-row input[type=\"color\"]::-webkit-color-swatch {\n\t\t\tborder: none;\n\t\t\tborder-radius: 4px;\n\t\t}\n\n\t\t.custom-color-row input[type=\"text\"] {\n\t\t\twidth: 100px;\n\t\t\tfont-family: var(--mono-font, monospace);\n\t\t\ttext-transform: uppercase;\n\t\t}\n\n\t\t.color-preview-row {\n\t\t\tdisplay: flex;\n\t\t\theight: 32px;\n\t\t\tborder-radius: 6px;\n\t\t\toverflow: hidden;\n\t\t}\n\n\t\t.preview-segment {\n\t\t\tflex: 1;\n\t\t}\n\n\t\t/* Bulk Actions */\n\t\t.bulk-actions {\n\t\t\tdisplay: inline-flex;\n\t\t\tgap: 0.5rem;\n\t\t\tmargin-left: 1rem;\n\t\t}\n\n\t\t.bulk-btn {\n\t\t\tpadding: 0.4rem 0.75rem;\n\t\t\tfont-size: 0.75rem;\n\t\t}\n\n\t\t/* Loading spinner */\n\t\t.spinner {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tborder: 2px solid var(--border);\n\t\t\tborder-top-color: var(--blue);\n\t\t\tborder-radius: 50%;\n\t\t\tanimation: spin 0.8s linear infinite;\n\t\t}\n\n\t\t@keyframes spin {\n\t\t\tto {\n\t\t\t\ttransform: rotate(360deg);\n\t\t\t}\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   ROW SELECTION (P1030)\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t/* Checkbox Column */\n\t\t.col-select {\n\t\t\twidth: 40px;\n\t\t\tmin-width: 40px;\n\t\t\tmax-width: 40px;\n\t\t\tpadding: 0 8px !important;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t/* Header Toggle */\n\t\t.select-toggle {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tpadding: 4px;\n\t\t\tbackground: transparent;\n\t\t\tborder: none;\n\t\t\tborder-radius: 4px;\n\t\t\tcursor: pointer;\n\t\t\tcolor: var(--fg-dark);\n\t\t\ttransition: color 150ms ease, background 150ms ease;\n\t\t}\n\n\t\t.select-toggle:hover {\n\t\t\tcolor: var(--fg);\n\t\t\tbackground: var(--bg-highlight);\n\t\t}\n\n\t\t.select-toggle:focus-visible {\n\t\t\toutline: 2px solid var(--blue);\n\t\t\toutline-offset: 2px;\n\t\t}\n\n\t\t.select-toggle .icon {\n\t\t\twidth: 18px;\n\t\t\theight: 18px;\n\t\t}\n\n\t\t/* Row Toggle (button style, matching header) */\n\t\t/* P7230: Visible at 10% opacity when unchecked */\n\t\t.row-select-toggle {\n\t\t\topacity: 0.1;\n\t\t\ttransition: opacity 150ms ease;\n\t\t}\n\n\t\t/* Show toggle on row hover or when selected */\n\t\ttr:hover .row-select-toggle,\n\t\ttr.selected .row-select-toggle,\n\t\t.row-select-toggle.is-selected {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t/* Selected indicator */\n\t\t.row-select-toggle.is-selected {\n\t\t\tcolor: var(--blue);\n\t\t}\n\n\t\t/* Selected Row */\n\t\ttr.selected {\n\t\t\tbackground: var(--bg-highlight) !important;\n\t\t}\n\n\t\ttr.selected:hover {\n\t\t\tbackground: rgba(41, 46, 66, 0.9) !important;\n\t\t}\n\n\t\t/* Allow text selection everywhere (no row click selection) */\n\t\ttr[data-host-id] .host-name {\n\t\t\tuser-select: text;\n\t\t\tcursor: text;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   CLICKABLE COMPARTMENTS (P1020)\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.compartment-btn {\n\t\t\tappearance: none;\n\t\t\tborder-style: solid;\n\t\t\tborder-color: rgba(232, 236, 245, 0.05);\n\t\t\tmargin: 0;\n\t\t\tfont: inherit;\n\t\t\tcolor: inherit;\n\t\t\tposition: relative;\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\twidth: 38px;  /* P7300: ~30% bigger */\n\t\t\theight: 38px;\n\t\t\tpadding: 0;\n\t\t\tbackground: rgba(0, 0, 0, 0.1);\n\t\t\tborder-radius: 6px;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t.compartment-btn:hover {\n\t\t\ttransform: scale(1.08);\n\t\t\tbackground: rgba(0, 0, 0, 0.7);\n\t\t}\n\n\t\t.compartment-btn:active {\n\t\t\ttransform: scale(0.95);\n\t\t}\n\n\t\t.compartment-btn:focus-visible {\n\t\t\toutline: 2px solid var(--blue);\n\t\t\toutline-offset: 2px;\n\t\t}\n\n\t\t.compartment-btn .update-icon {\n\t\t\tposition: relative;\n\t\t\ttop: -4px;  /* Adjusted for 3-dot layout */\n\t\t\twidth: 14px;  /* Scaled up for bigger buttons */\n\t\t\theight: 14px;\n\t\t\tfill: var(--fg);\n\t\t\tstroke: var(--fg);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.compartment-btn.unknown {\n\t\t\topacity: 0.6;\n\t\t}\n\n\t\t.compartment-btn.info-only {\n\t\t\tcursor: help;\n\t\t}\n\n\t\t.compartment-btn.info-only:hover {\n\t\t\ttransform: none;\n\t\t\tbackground: rgba(0, 0, 0, 0.5);\n\t\t}\n\n\t\t.compartment-btn.info-only:active {\n\t\t\ttransform: none;\n\t\t}\n\n\t\t.compartment-btn.rate-limited {\n\t\t\tpointer-events: none;\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   TOAST NOTIFICATIONS (P1020)\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.toast {\n\t\t\tposition: fixed;\n\t\t\tbottom: 20px;\n\t\t\tleft: 50%;\n\t\t\ttransform: translateX(-50%) translateY(20px);\n\t\t\tbackground: rgba(0, 0, 0, 0.7);\n\t\t\tbackdrop-filter: blur(12px);\n\t\t\t-webkit-backdrop-filter: blur(12px);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 8px;\n\t\t\tpadding: 12px 20px;\n\t\t\tfont-size: 0.875rem;\n\t\t\topacity: 0;\n\t\t\ttransition: transform 300ms ease, opacity 300ms ease;\n\t\t\tz-index: 100000;\n\t\t\tmax-width: 90%;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.toast.show {\n\t\t\ttransform: translateX(-50%) translateY(0);\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.toast-info {\n\t\t\tborder-color: var(--blue);\n\t\t}\n\n\t\t.toast-error {\n\t\t\tborder-color: var(--red);\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.toast-success {\n\t\t\tborder-color: var(--green);\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t/* P7230: Warning toast for outdated status */\n\t\t.toast-warning {\n\t\t\tborder-color: var(--yellow);\n\t\t\tcolor: var(--yellow);\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   DEPENDENCY DIALOG (P1040)\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.dialog-modal {\n\t\t\tmax-width: 500px;\n\t\t\twidth: 90%;\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.dialog-header {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 12px;\n\t\t\tmargin-bottom: 16px;\n\t\t}\n\n\t\t.dialog-icon {\n\t\t\twidth: 24px;\n\t\t\theight: 24px;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.dialog-icon.warning {\n\t\t\tcolor: var(--yellow);\n\t\t}\n\n\t\t.dialog-title {\n\t\t\tfont-size: 1.125rem;\n\t\t\tfont-weight: 600;\n\t\t\tmargin: 0;\n\t\t}\n\n\t\t.dialog-body {\n\t\t\tmargin-bottom: 20px;\n\t\t}\n\n\t\t.dialog-message {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tmargin: 0 0 16px 0;\n\t\t\tline-height: 1.5;\n\t\t}\n\n\t\t.dialog-host-list {\n\t\t\tlist-style: none;\n\t\t\tpadding: 0;\n\t\t\tmargin: 0;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\toverflow: hidden;\n\t\t\tmax-height: 200px;\n\t\t\toverflow-y: auto;\n\t\t}\n\n\t\t.dialog-host-list li {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tpadding: 10px 12px;\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t}\n\n\t\t.dialog-host-list li:last-child {\n\t\t\tborder-bottom: none;\n\t\t}\n\n\t\t.dialog-host-list li.needs-action {\n\t\t\tbackground: rgba(250, 204, 21, 0.1);\n\t\t}\n\n\t\t.dialog-host-list .host-name {\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.dialog-host-list .host-status {\n\t\t\tfont-size: 0.8125rem;\n\t\t\tcolor: var(--fg-muted);\n\t\t}\n\n\t\t.dialog-footer {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tgap: 12px;\n\t\t}\n\n\t\t.dialog-action-group {\n\t\t\tdisplay: flex;\n\t\t\tgap: 8px;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t@media (max-width: 480px) {\n\t\t\t.dialog-footer {\n\t\t\t\tflex-direction: column;\n\t\t\t\talign-items: stretch;\n\t\t\t}\n\n\t\t\t.dialog-action-group {\n\t\t\t\tjustify-content: flex-end;\n\t\t\t}\n\n\t\t\t.btn-cancel {\n\t\t\t\torder: 1;\n\t\t\t}\n\t\t}\n\n\t\t.btn-primary {\n\t\t\tbackground: var(--blue);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t.btn-primary:hover {\n\t\t\tfilter: brightness(1.1);\n\t\t}\n\n\t\t.dialog-progress {\n\t\t\tposition: absolute;\n\t\t\tinset: 0;\n\t\t\tbackground: rgba(26, 27, 38, 0.95);\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tborder-radius: inherit;\n\t\t}\n\n\t\t.progress-content {\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t\talign-items: center;\n\t\t\tgap: 12px;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.progress-content .icon {\n\t\t\twidth: 32px;\n\t\t\theight: 32px;\n\t\t\tcolor: var(--blue);\n\t\t}\n\n\t\t.btn-cancel-small {\n\t\t\tfont-size: 0.8125rem;\n\t\t\tpadding: 4px 12px;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   CONTEXT BAR - Unified hover preview + selection actions\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   CONTEXT BAR - Stacked rows for PR, hover, and selection\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.context-bar {\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t\tpadding: 0.5rem 1.5rem;\n\t\t\tbackground: var(--bg-elevated);\n\t\t\tborder: 1px solid rgba(255, 255, 255, 0.08);\n\t\t\tborder-radius: 8px;\n\t\t\tgap: 0.375rem;\n\t\t\tmargin: 1rem 0 0 0;\n\t\t\tmin-height: 170px;\n\t\t\t/* Reserve space for 3 rows */\n\t\t}\n\n\t\t/* When empty, show subtle border outline */\n\t\t.context-bar-empty {\n\t\t\tborder-color: rgba(255, 255, 255, 0.03);\n\t\t\tbackground: transparent;\n\t\t}\n\n\t\t.context-bar-empty * {\n\t\t\topacity: 0;\n\t\t\tpointer-events: none;\n\t\t}\n\n\t\t@media (max-width: 768px) {\n\t\t\t.context-bar {\n\t\t\t\tpadding: 0.5rem 1rem;\n\t\t\t\tmin-height: 160px;\n\t\t\t}\n\t\t}\n\n\t\t/* Each row in the context bar */\n\t\t.context-row {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: space-between;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tborder-radius: 6px;\n\t\t\tgap: 1rem;\n\t\t\tmin-height: 36px;\n\t\t}\n\n\t\t.context-row-info {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tflex: 1;\n\t\t\tmin-width: 0;\n\t\t}\n\n\t\t/* Row 1: PR row styling */\n\t\t.context-row-pr {\n\t\t\tbackground: rgba(187, 154, 247, 0.08);\n\t\t\tborder: 1px solid rgba(187, 154, 247, 0.25);\n\t\t}\n\n\t\t.context-row-pr .icon-pr {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tcolor: var(--purple);\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.context-row-pr .pr-label {\n\t\t\tcolor: var(--purple);\n\t\t\tfont-weight: 600;\n\t\t\tfont-size: 0.875rem;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.context-row-pr .pr-detail {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.8125rem;\n\t\t\toverflow: hidden;\n\t\t\ttext-overflow: ellipsis;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.btn-merge {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.375rem;\n\t\t\tpadding: 0.375rem 0.75rem;\n\t\t\tfont-size: 0.8125rem;\n\t\t\tfont-weight: 600;\n\t\t\tbackground: var(--purple);\n\t\t\tcolor: var(--bg);\n\t\t\tborder: none;\n\t\t\tborder-radius: 5px;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 150ms;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.btn-merge .icon {\n\t\t\twidth: 14px;\n\t\t\theight: 14px;\n\t\t}\n\n\t\t.btn-merge:hover {\n\t\t\tfilter: brightness(1.1);\n\t\t}\n\n\t\t/* Row 2: Hover row styling */\n\t\t.context-row-hover {\n\t\t\tbackground: rgba(125, 211, 252, 0.05);\n\t\t\tborder: 1px solid rgba(125, 211, 252, 0.15);\n\t\t\tjustify-content: flex-start;\n\t\t\t/* Keep content left-aligned */\n\t\t\tgap: 0.5rem;\n\t\t}\n\n\t\t.context-row-hover .context-host {\n\t\t\tcolor: var(--cyan);\n\t\t\tfont-weight: 600;\n\t\t\tfont-family: 'JetBrains Mono', 'Fira Code', monospace;\n\t\t\tfont-size: 0.875rem;\n\t\t\twhite-space: nowrap;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.context-row-hover .context-host::after {\n\t\t\tcontent: ':';\n\t\t}\n\n\t\t.context-row-hover .context-description {\n\t\t\tcolor: var(--fg);\n\t\t\tfont-size: 0.875rem;\n\t\t}\n\n\t\t/* Row 3: Selection row styling */\n\t\t.context-row-selection {\n\t\t\tbackground: rgba(122, 162, 247, 0.08);\n\t\t\tborder: 1px solid rgba(122, 162, 247, 0.2);\n\t\t}\n\n\t\t.context-row-selection .icon-check {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tcolor: var(--blue);\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t/* P7240: Timeout notification row */\n\t\t.context-row-timeout {\n\t\t\tbackground: rgba(234, 179, 8, 0.12);\n\t\t\tborder: 1px solid rgba(234, 179, 8, 0.3);\n\t\t}\n\n\t\t.context-row-timeout .timeout-icon {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tcolor: var(--yellow);\n\t\t\tflex-shrink: 0;\n\t\t\tanimation: pulse-warning 1.5s ease-in-out infinite;\n\t\t}\n\n\t\t.context-row-timeout .timeout-host {\n\t\t\tfont-weight: 600;\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.context-row-timeout .timeout-detail {\n\t\t\tcolor: var(--fg-dark);\n\t\t\tfont-size: 0.875rem;\n\t\t}\n\n\t\t.context-row-timeout .timeout-elapsed {\n\t\t\tcolor: var(--yellow);\n\t\t\tfont-weight: 600;\n\t\t\tfont-variant-numeric: tabular-nums;\n\t\t}\n\n\t\t.timeout-actions {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.5rem;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t.btn-timeout {\n\t\t\tpadding: 0.25rem 0.5rem;\n\t\t\tfont-size: 0.75rem;\n\t\t\tborder-radius: 4px;\n\t\t\tborder: 1px solid transparent;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.15s ease;\n\t\t}\n\n\t\t.btn-timeout-wait {\n\t\t\tbackground: rgba(122, 162, 247, 0.15);\n\t\t\tborder-color: rgba(122, 162, 247, 0.3);\n\t\t\tcolor: var(--blue);\n\t\t}\n\n\t\t.btn-timeout-wait:hover {\n\t\t\tbackground: rgba(122, 162, 247, 0.25);\n\t\t}\n\n\t\t.btn-timeout-kill {\n\t\t\tbackground: rgba(239, 68, 68, 0.15);\n\t\t\tborder-color: rgba(239, 68, 68, 0.3);\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.btn-timeout-kill:hover {\n\t\t\tbackground: rgba(239, 68, 68, 0.25);\n\t\t}\n\n\t\t.btn-timeout-ignore {\n\t\t\tbackground: transparent;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.btn-timeout-ignore:hover {\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t@keyframes pulse-warning {\n\t\t\t0%, 100% { opacity: 0.6; transform: scale(1); }\n\t\t\t50% { opacity: 1; transform: scale(1.1); }\n\t\t}\n\n\t\t/* Actions in selection row */\n\t\t.context-actions {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-wrap: wrap;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t@media (max-width: 768px) {\n\t\t\t.context-row {\n\t\t\t\tflex-direction: column;\n\t\t\t\talign-items: stretch;\n\t\t\t\tgap: 0.5rem;\n\t\t\t}\n\n\t\t\t.context-actions {\n\t\t\t\tjustify-content: center;\n\t\t\t}\n\t\t}\n\n\t\t/* Context Buttons */\n\t\t.btn-context {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.375rem;\n\t\t\tpadding: 0.4rem 0.75rem;\n\t\t\tfont-size: 0.8125rem;\n\t\t\tfont-weight: 500;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tcolor: var(--fg);\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 150ms ease;\n\t\t\tfont-family: inherit;\n\t\t}\n\n\t\t.btn-context:hover:not(:disabled) {\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder-color: var(--fg-dim);\n\t\t}\n\n\t\t.btn-context:active:not(:disabled) {\n\t\t\tbackground: var(--bg);\n\t\t}\n\n\t\t.btn-context:disabled {\n\t\t\topacity: 0.4;\n\t\t\tcursor: not-allowed;\n\t\t}\n\n\t\t.btn-context .icon {\n\t\t\twidth: 14px;\n\t\t\theight: 14px;\n\t\t}\n\n\t\t@media (max-width: 480px) {\n\t\t\t.btn-context .btn-label {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\n\t\t\t.btn-context {\n\t\t\t\tpadding: 0.5rem;\n\t\t\t}\n\n\t\t\t.btn-context .icon {\n\t\t\t\twidth: 18px;\n\t\t\t\theight: 18px;\n\t\t\t}\n\t\t}\n\n\t\t/* DO ALL Button - matches other context buttons but green accent */\n\t\t.btn-do-all {\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--green);\n\t\t\tcolor: var(--green);\n\t\t\t/* Same padding as .btn-context */\n\t\t\tpadding: 0.4rem 0.75rem;\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.btn-do-all:hover:not(:disabled) {\n\t\t\tbackground: var(--green);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t.btn-do-all .icon {\n\t\t\twidth: 14px;\n\t\t\theight: 14px;\n\t\t}\n\n\t\t.btn-clear {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tpadding: 0.375rem;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid transparent;\n\t\t\tborder-radius: 4px;\n\t\t\tcursor: pointer;\n\t\t\tcolor: var(--fg-dark);\n\t\t\ttransition: color 150ms ease, background 150ms ease;\n\t\t}\n\n\t\t.btn-clear:hover {\n\t\t\tcolor: var(--red);\n\t\t\tbackground: var(--bg-highlight);\n\t\t}\n\n\t\t.btn-clear .icon {\n\t\t\twidth: 18px;\n\t\t\theight: 18px;\n\t\t}\n\n\t\t/* Alpine.js cloak for transition elements */\n\t\t[x-cloak] {\n\t\t\tdisplay: none !important;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   P2700: COMPOSITE TYPE COLUMN\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.col-type {\n\t\t\twidth: 40px;\n\t\t\tmin-width: 40px;\n\t\t\tmax-width: 40px;\n\t\t\tpadding: 0.25rem !important;\n\t\t}\n\n\t\t.type-composite {\n\t\t\tposition: relative;\n\t\t\tdisplay: inline-block;\n\t\t\twidth: 32px;\n\t\t\theight: 24px;\n\t\t\tmargin: 0 auto;\n\t\t}\n\n\t\t/* Compact layout: LOC + OS side by side */\n\t\t.type-composite.type-compact {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tgap: 0.25rem;\n\t\t\twidth: auto;\n\t\t\theight: auto;\n\t\t}\n\n\t\t.type-loc-icon,\n\t\t.type-os-icon {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t}\n\n\t\t.type-loc-icon .icon,\n\t\t.type-loc-icon .location-icon,\n\t\t.type-os-icon .icon,\n\t\t.type-os-icon .type-icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t\topacity: 0.85;\n\t\t}\n\n\t\t/* Main device icon - fills most of the space (legacy, kept for compatibility) */\n\t\t.type-dev-main {\n\t\t\tposition: absolute;\n\t\t\tleft: 0;\n\t\t\ttop: 50%;\n\t\t\ttransform: translateY(-50%);\n\t\t\twidth: 20px;\n\t\t\theight: 20px;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tz-index: 1;\n\t\t}\n\n\t\t.type-dev-main .icon,\n\t\t.type-dev-main .device-icon {\n\t\t\twidth: 18px;\n\t\t\theight: 18px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t}\n\n\t\t/* Location icon - top-right superscript (legacy) */\n\t\t.type-loc-super {\n\t\t\tposition: absolute;\n\t\t\ttop: 1px;\n\t\t\tright: 0;\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tz-index: 2;\n\t\t}\n\n\t\t.type-loc-super .icon,\n\t\t.type-loc-super .location-icon {\n\t\t\twidth: 9px;\n\t\t\theight: 9px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t}\n\n\t\t/* OS icon - bottom-right subscript (legacy) */\n\t\t.type-os-sub {\n\t\t\tposition: absolute;\n\t\t\tbottom: 0px;\n\t\t\tright: 0;\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tz-index: 2;\n\t\t}\n\n\t\t.type-os-sub .icon,\n\t\t.type-os-sub .type-icon {\n\t\t\twidth: 9px;\n\t\t\theight: 9px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t}\n\n\t\t/* Hover effect on composite type */\n\t\t.type-composite:hover .type-dev-main .icon,\n\t\t.type-composite:hover .type-dev-main .device-icon {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.type-composite:hover .type-loc-super .icon,\n\t\t.type-composite:hover .type-loc-super .location-icon,\n\t\t.type-composite:hover .type-os-sub .icon,\n\t\t.type-composite:hover .type-os-sub .type-icon {\n\t\t\topacity: 0.9;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   P2700: STATUS PROGRESS COLUMN\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.col-status {\n\t\t\tmin-width: 180px;\n\t\t\tpadding: 0.5rem !important;\n\t\t}\n\n\t\t.status-progress-cell {\n\t\t\tpadding: 0.25rem 0.5rem;\n\t\t}\n\n\t\t.status-progress {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tfont-family: 'JetBrains Mono', 'Fira Code', monospace;\n\t\t\tfont-size: 0.7rem;\n\t\t\tletter-spacing: 0.05em;\n\t\t}\n\n\t\t.progress-segment {\n\t\t\tdisplay: flex;\n\t\t\tgap: 1px;\n\t\t}\n\n\t\t.progress-dot {\n\t\t\tdisplay: inline-block;\n\t\t\twidth: 1em;\n\t\t\ttext-align: center;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t/* Dot states */\n\t\t.dot-pending {\n\t\t\tcolor: var(--fg-gutter);\n\t\t\topacity: 0.3;\n\t\t}\n\n\t\t.dot-complete {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.dot-idle {\n\t\t\tcolor: var(--green);\n\t\t\topacity: 0.2;\n\t\t}\n\n\t\t.dot-error {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.dot-in-progress {\n\t\t\tcolor: var(--cyan);\n\t\t\tanimation: shimmer 1.2s ease-in-out infinite;\n\t\t\ttext-shadow: 0 0 8px var(--cyan);\n\t\t}\n\n\t\t/* PS5-style shimmer animation */\n\t\t@keyframes shimmer {\n\t\t\t0% {\n\t\t\t\topacity: 0.4;\n\t\t\t\ttransform: scale(0.95);\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t\ttransform: scale(1.05);\n\t\t\t}\n\n\t\t\t100% {\n\t\t\t\topacity: 0.4;\n\t\t\t\ttransform: scale(0.95);\n\t\t\t}\n\t\t}\n\n\t\t.tests-dash {\n\t\t\tcolor: var(--fg-gutter);\n\t\t\topacity: 0.5;\n\t\t}\n\n\t\t/* Segment hover hints */\n\t\t.progress-segment:hover .progress-dot {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.progress-segment:hover .dot-idle {\n\t\t\topacity: 0.6;\n\t\t}\n\t</style></head><body data-csrf-token=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</title><link rel=\"icon\" type=\"image/png\" href=\"/static/nixfleet_favicon.png\"><link rel=\"preconnect\" href=\"https://fonts.googleapis.com\"><link rel=\"preconnect\" href=\"https://fonts.gstatic.com\" crossorigin><link href=\"https://fonts.googleapis.com/css2?family=JetBrains+Mono:wght@400;500;600&display=swap\" rel=\"stylesheet\"><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><script defer src=\"https://unpkg.com/alpinejs@3.13.3/dist/cdn.min.js\"></script><script src=\"/static/js/state-sync.js\"></script><script src=\"/static/js/webauthn.js\"></script><style>\n\t\t/* Tokyo Night Color Palette */\n\t\t:root {\n\t\t\t--bg: #1a1b26;\n\t\t\t--bg-dark: #16161e;\n\t\t\t--bg-highlight: #292e42;\n\t\t\t--bg-float: #24283b;\n\t\t\t--border: #3b4261;\n\t\t\t--fg: #e8ecf5;\n\t\t\t/* Brightened ~90% white */\n\t\t\t--fg-dark: #565f89;\n\t\t\t--fg-gutter: #3b4261;\n\t\t\t--blue: #7aa2f7;\n\t\t\t--cyan: #7dcfff;\n\t\t\t--green: #9ece6a;\n\t\t\t--yellow: #e0af68;\n\t\t\t--orange: #ff9e64;\n\t\t\t--red: #f7768e;\n\t\t\t--purple: #bb9af7;\n\t\t\t--magenta: #bb9af7;\n\t\t}\n\n\t\t* {\n\t\t\tmargin: 0;\n\t\t\tpadding: 0;\n\t\t\tbox-sizing: border-box;\n\t\t}\n\n\t\t/* Themed scrollbars - Tokyo Night style */\n\t\t::-webkit-scrollbar {\n\t\t\twidth: 10px;\n\t\t\theight: 10px;\n\t\t}\n\n\t\t::-webkit-scrollbar-track {\n\t\t\tbackground: var(--bg-dark);\n\t\t}\n\n\t\t::-webkit-scrollbar-thumb {\n\t\t\tbackground: var(--border);\n\t\t\tborder-radius: 5px;\n\t\t\tborder: 2px solid var(--bg-dark);\n\t\t}\n\n\t\t::-webkit-scrollbar-thumb:hover {\n\t\t\tbackground: var(--fg-dark);\n\t\t}\n\n\t\t::-webkit-scrollbar-corner {\n\t\t\tbackground: var(--bg-dark);\n\t\t}\n\n\t\t/* Firefox scrollbar theming */\n\t\t* {\n\t\t\tscrollbar-color: var(--border) var(--bg-dark);\n\t\t\tscrollbar-width: thin;\n\t\t}\n\n\t\t/* Always show scrollbars to prevent layout jump */\n\t\thtml {\n\t\t\toverflow-y: scroll !important;\n\t\t\tscrollbar-gutter: stable !important;\n\t\t}\n\t\t\n\t\t/* Prevent any element from hiding the scrollbar */\n\t\thtml, body {\n\t\t\tmin-height: 100%;\n\t\t}\n\n\t\tbody {\n\t\t\tfont-family: \"JetBrains Mono\", \"Fira Code\", \"SF Mono\", monospace;\n\t\t\tbackground: var(--bg);\n\t\t\tcolor: var(--fg);\n\t\t\tline-height: 1.6;\n\t\t\tmin-height: 100vh;\n\t\t}\n\n\t\t/* Background watermark - shows through semi-transparent table */\n\t\tbody::after {\n\t\t\tcontent: \"\";\n\t\t\tposition: fixed;\n\t\t\ttop: 50%;\n\t\t\tleft: 50%;\n\t\t\ttransform: translate(-50%, -50%);\n\t\t\twidth: 500px;\n\t\t\theight: 440px;\n\t\t\tbackground: url(\"/static/nixfleet_fade_1k.png\") no-repeat center center;\n\t\t\tbackground-size: contain;\n\t\t\topacity: 0.06;\n\t\t\tpointer-events: none;\n\t\t\tz-index: 100;\n\t\t}\n\n\t\t/* Container */\n\t\t.container {\n\t\t\tmax-width: 1400px;\n\t\t\tmargin: 0 auto;\n\t\t\tpadding: 1rem;\n\t\t}\n\n\t\t@media (min-width: 768px) {\n\t\t\t.container {\n\t\t\t\tpadding: 1.5rem 2rem;\n\t\t\t}\n\t\t}\n\n\t\t/* Header - Single line layout */\n\t\theader {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tpadding-bottom: 1rem;\n\t\t\tmargin-bottom: 1.5rem;\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t\tgap: 1rem;\n\t\t\tmin-height: 48px;\n\t\t}\n\n\t\t.header-brand {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.brand-title {\n\t\t\tcolor: var(--blue);\n\t\t\tfont-size: 1.25rem;\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t.brand-logo {\n\t\t\theight: 28px;\n\t\t\twidth: 28px;\n\t\t\tborder-radius: 4px;\n\t\t}\n\n\t\t.header-center {\n\t\t\tflex: 1;\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: center;\n\t\t\tmin-width: 0;\n\t\t}\n\n\t\t.header-actions {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t@media (min-width: 768px) {\n\t\t\t.brand-title {\n\t\t\t\tfont-size: 1.5rem;\n\t\t\t}\n\n\t\t\t.brand-logo {\n\t\t\t\theight: 32px;\n\t\t\t\twidth: 32px;\n\t\t\t}\n\t\t}\n\n\t\t@media (max-width: 900px) {\n\t\t\t.header-center {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\t\t}\n\n\t\t/* Fleet Target line (replaces subtitle) */\n\t\t.fleet-target {\n\t\t\tfont-size: 0.75rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.4rem;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t.target-label {\n\t\t\tcolor: var(--fg-muted);\n\t\t}\n\n\t\t.target-icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tfill: var(--cyan);\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t.target-commit {\n\t\t\tfont-family: 'JetBrains Mono', 'Fira Code', monospace;\n\t\t\tfont-size: 0.7rem;\n\t\t\tcolor: var(--cyan);\n\t\t\tbackground: rgba(125, 207, 255, 0.1);\n\t\t\tpadding: 0.15rem 0.4rem;\n\t\t\tborder-radius: 3px;\n\t\t\ttext-decoration: none;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t.target-commit:hover {\n\t\t\tbackground: rgba(125, 207, 255, 0.2);\n\t\t\tcolor: var(--cyan);\n\t\t}\n\n\t\t.target-branch {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.7rem;\n\t\t}\n\n\t\t.target-separator {\n\t\t\tcolor: var(--border);\n\t\t\tmargin: 0 0.1rem;\n\t\t}\n\n\t\t.target-agent-label {\n\t\t\tfont-size: 0.7rem;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tmargin-right: 0.25rem;\n\t\t}\n\n\t\t.target-agent {\n\t\t\tfont-family: 'JetBrains Mono', 'Fira Code', monospace;\n\t\t\tfont-size: 0.7rem;\n\t\t\tcolor: var(--purple);\n\t\t\tbackground: rgba(187, 154, 247, 0.15);\n\t\t\tpadding: 0.15rem 0.4rem;\n\t\t\tborder-radius: 3px;\n\t\t}\n\n\t\t.target-unavailable {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-style: italic;\n\t\t}\n\n\t\t/* Buttons */\n\t\t.btn {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.4rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tfont-size: 0.8rem;\n\t\t\tfont-family: inherit;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tcolor: var(--fg);\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.2s ease;\n\t\t\ttext-decoration: none;\n\t\t}\n\n\t\t.btn:hover {\n\t\t\tborder-color: var(--blue);\n\t\t\tbackground: rgba(122, 162, 247, 0.1);\n\t\t}\n\n\t\t.btn-primary {\n\t\t\tbackground: var(--blue);\n\t\t\tborder-color: var(--blue);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t.btn-primary:hover {\n\t\t\tbackground: #8aacf7;\n\t\t}\n\n\t\t.btn-danger {\n\t\t\tborder-color: var(--red);\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.btn-danger:hover {\n\t\t\tbackground: var(--red);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t/* Header actions container */\n\t\t.header-actions {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0;\n\t\t}\n\n\t\t.header-user {\n\t\t\tpadding: 0 0.75rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t\tfont-size: 0.85rem;\n\t\t}\n\n\t\t/* Header action buttons - consistent sizing */\n\t\t.btn-header {\n\t\t\theight: 36px;\n\t\t\tpadding: 0.5rem 1rem;\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tgap: 0.5rem;\n\t\t\tbox-sizing: border-box;\n\t\t}\n\n\t\t.bulk-actions-dropdown {\n\t\t\tmargin-right: 10px;\n\t\t}\n\n\t\t.header-actions form {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tmargin: 0;\n\t\t}\n\n\t\t.btn:disabled {\n\t\t\topacity: 0.5;\n\t\t\tcursor: not-allowed;\n\t\t}\n\n\t\t/* Cards (mobile-first) */\n\t\t.host-grid {\n\t\t\tdisplay: grid;\n\t\t\tgap: 1rem;\n\t\t}\n\n\t\t/* Desktop: table layout */\n\t\t@media (min-width: 1024px) {\n\t\t\t.host-grid {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\n\t\t\t.host-table {\n\t\t\t\tdisplay: table;\n\t\t\t}\n\t\t}\n\n\t\t/* Mobile: card layout */\n\t\t@media (max-width: 1023px) {\n\t\t\t.host-table {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\t\t}\n\n\t\t/* Host Card (mobile) */\n\t\t.host-card {\n\t\t\tbackground: rgba(36, 40, 59, 0.9);\n\t\t\t/* bg-float with 90% opacity */\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 8px;\n\t\t\toverflow: visible;\n\t\t\tposition: relative;\n\t\t\tz-index: 1;\n\t\t}\n\n\t\t.host-card-header {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tpadding: 0.75rem 1rem;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tcursor: pointer;\n\t\t}\n\n\t\t.host-card-header:hover {\n\t\t\tbackground: var(--bg);\n\t\t}\n\n\t\t.host-name {\n\t\t\tfont-weight: 600;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t}\n\n\t\t.host-card-body {\n\t\t\tpadding: 1rem;\n\t\t\tdisplay: none;\n\t\t}\n\n\t\t.host-card.expanded .host-card-body {\n\t\t\tdisplay: block;\n\t\t}\n\n\t\t.host-card-row {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\tpadding: 0.5rem 0;\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t}\n\n\t\t.host-card-row:last-child {\n\t\t\tborder-bottom: none;\n\t\t}\n\n\t\t.host-card-label {\n\t\t\tcolor: var(--fg-dark);\n\t\t\tfont-size: 0.8rem;\n\t\t}\n\n\t\t.host-card-actions {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-wrap: wrap;\n\t\t\tmargin-top: 1rem;\n\t\t}\n\n\t\t/* Host Table (desktop) */\n\t\t.host-table {\n\t\t\twidth: 100%;\n\t\t\tborder-collapse: collapse;\n\t\t\tbackground: rgba(36, 40, 59, 0.9);\n\t\t\t/* bg-float with 90% opacity */\n\t\t\tborder-radius: 8px;\n\t\t\toverflow: visible;\n\t\t\t/* Allow dropdown menus to extend beyond table */\n\t\t\tposition: relative;\n\t\t\tz-index: 1;\n\t\t\t/* NOTE: table-layout: fixed was removed - it broke auto-sizing and caused \n\t\t\t   rows to not fill table width. Content-based sizing is needed for hostnames. */\n\t\t}\n\n\t\t.host-table th,\n\t\t.host-table td {\n\t\t\tpadding: 0.75rem 1rem;\n\t\t\ttext-align: left;\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t\tfont-size: 13px;\n\t\t\tvertical-align: middle;\n\t\t}\n\n\t\t.host-table th {\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tfont-weight: 500;\n\t\t\tcolor: var(--fg-dark);\n\t\t\tfont-size: 0.8rem;\n\t\t\ttext-transform: uppercase;\n\t\t\tletter-spacing: 0.05em;\n\t\t}\n\n\t\t/* Column alignment classes */\n\t\t.col-center {\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.col-right {\n\t\t\ttext-align: right;\n\t\t}\n\n\t\t.col-hosts {\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t/* Online count highlight */\n\t\t.stat-online-positive {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.host-table tbody tr {\n\t\t\tposition: relative;\n\t\t\t/* Base dark background - gradient overlays this */\n\t\t\tbackground: rgba(10, 11, 16, 0.9);\n\t\t}\n\n\t\t.host-table tr:hover {\n\t\t\tbackground: rgba(30, 34, 48, 0.95);\n\t\t}\n\n\t\t.host-table tr:last-child td {\n\t\t\tborder-bottom: none;\n\t\t}\n\n\t\t/* Offline host row overlay */\n\t\t.host-table tr.host-offline {\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.host-table tr.host-offline::after {\n\t\t\tcontent: '';\n\t\t\tposition: absolute;\n\t\t\ttop: 0;\n\t\t\tleft: 0;\n\t\t\tright: 0;\n\t\t\tbottom: 0;\n\t\t\tbackground: rgba(0, 0, 0, 0.2);\n\t\t\tpointer-events: none;\n\t\t}\n\n\t\t/* Location, Device Type, and Host Type icons */\n\t\t.location-icon,\n\t\t.device-icon,\n\t\t.type-icon {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tcolor: var(--fg);\n\t\t\topacity: 0.85;\n\t\t}\n\n\t\t.location-icon:hover,\n\t\t.device-icon:hover,\n\t\t.type-icon:hover {\n\t\t\topacity: 1;\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t/* Tests cell */\n\t\t.tests-cell {\n\t\t\tfont-size: 0.85rem;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.test-progress {\n\t\t\tcolor: var(--yellow);\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.test-result {\n\t\t\tfont-weight: 500;\n\t\t\tpadding: 2px 6px;\n\t\t\tborder-radius: 4px;\n\t\t}\n\n\t\t.test-result.pass {\n\t\t\tcolor: var(--green);\n\t\t\tbackground: rgba(158, 206, 106, 0.15);\n\t\t}\n\n\t\t.test-result.fail {\n\t\t\tcolor: var(--red);\n\t\t\tbackground: rgba(247, 118, 142, 0.15);\n\t\t}\n\n\t\t.tests-na {\n\t\t\tcolor: var(--fg-dark);\n\t\t\topacity: 0.5;\n\t\t}\n\n\t\t/* Status indicators */\n\t\t.status-dot {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tmin-width: 12px;\n\t\t\tmin-height: 12px;\n\t\t\tborder-radius: 50%;\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tvertical-align: middle;\n\t\t\tflex-shrink: 0;\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.status-online {\n\t\t\tbackground: var(--green);\n\t\t\tbox-shadow: 0 0 6px var(--green);\n\t\t}\n\n\t\t.status-offline {\n\t\t\t/* Smaller muted dot - more visible */\n\t\t\tbackground: #6b7280;\n\t\t\twidth: 6px !important;\n\t\t\theight: 6px !important;\n\t\t\tmin-width: 6px !important;\n\t\t\tmin-height: 6px !important;\n\t\t\tmargin: 3px;\n\t\t\tbox-shadow: none;\n\t\t}\n\n\t\t.status-running {\n\t\t\tbackground: var(--yellow);\n\t\t\tanimation: pulse 1.5s infinite;\n\t\t\tbox-shadow: 0 0 4px var(--yellow);\n\t\t}\n\n\t\t.status-error {\n\t\t\tbackground: var(--red);\n\t\t}\n\n\t\t@keyframes pulse {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\topacity: 1;\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 0.5;\n\t\t\t}\n\t\t}\n\n\t/* Heartbeat indicator for online hosts */\n\t.status-ripple {\n\t\t/* Container is larger than the dot so the heartbeat glow can bloom outside */\n\t\twidth: 18px;\n\t\theight: 18px;\n\t\tposition: relative;\n\t\tdisplay: flex;\n\t\talign-items: center;\n\t\tjustify-content: center;\n\t\tcolor: var(--green);\n\t\t/* IMPORTANT: allow glow to render outside the box (overflow:hidden clips box-shadow) */\n\t\toverflow: visible;\n\t\t/* Keep it from affecting layout/scrollbars (NOTE: paint containment would CLIP glow) */\n\t\tcontain: layout;\n\t\tflex-shrink: 0;\n\t}\n\n\t\t/* Base state: small dot, minimal glow (same visual weight as offline dot) */\n\t\t.status-ripple .hb-core {\n\t\t\twidth: 6px;\n\t\t\theight: 6px;\n\t\t\tbackground: currentColor;\n\t\t\tborder-radius: 50%;\n\t\t\tposition: relative;\n\t\t\tz-index: 2;\n\t\t\tbox-shadow: 0 0 1px rgba(158, 206, 106, 0.25);\n\t\t}\n\n\t\t/* Waves hidden by default - only show on heartbeat */\n\t\t.status-ripple .hb-wave {\n\t\t\tposition: absolute;\n\t\t\ttop: 50%;\n\t\t\tleft: 50%;\n\t\t\twidth: 6px;\n\t\t\theight: 6px;\n\t\t\tmargin: -3px 0 0 -3px;\n\t\t\tbackground: currentColor;\n\t\t\tborder-radius: 50%;\n\t\t\topacity: 0;\n\t\t\t/* Use transform for GPU-accelerated animation (no layout recalc) */\n\t\t\twill-change: transform, opacity;\n\t\t\ttransform: scale(1);\n\t\t}\n\n\t\t/* Animate only when .heartbeat class is present */\n\t\t.status-ripple.heartbeat .hb-wave {\n\t\t\tanimation: ripple-wave 1.5s ease-out forwards;\n\t\t}\n\n\t\t/* P8800: Only shine on heartbeat (avoid constant glow) */\n\t\t.status-ripple.heartbeat .hb-core {\n\t\t\tbox-shadow:\n\t\t\t\t0 0 6px rgba(158, 206, 106, 0.65),\n\t\t\t\t0 0 14px rgba(158, 206, 106, 0.35);\n\t\t}\n\n\t\t.status-ripple.heartbeat .hb-wave:nth-child(2) {\n\t\t\tanimation-delay: 0.3s;\n\t\t}\n\n\t\t.status-ripple.heartbeat .hb-wave:nth-child(3) {\n\t\t\tanimation-delay: 0.6s;\n\t\t}\n\n\t\t@keyframes ripple-wave {\n\t\t\t0% {\n\t\t\t\ttransform: scale(1);\n\t\t\t\topacity: 0.8;\n\t\t\t}\n\n\t\t\t100% {\n\t\t\t\ttransform: scale(2.0); /* P8800: Fits within 16px container (8px * 2.0) */\n\t\t\t\topacity: 0;\n\t\t\t}\n\t\t}\n\n\t\t/* Offline host dimming */\n\t\ttr.host-offline,\n\t\t.host-card.host-offline {\n\t\t\topacity: 0.5;\n\t\t}\n\n\t\ttr.host-offline:hover,\n\t\t.host-card.host-offline:hover {\n\t\t\topacity: 0.8;\n\t\t}\n\n\t\t/* Log viewer */\n\t\t.log-panel {\n\t\t\tmargin-top: 1rem;\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\toverflow: hidden;\n\t\t}\n\n\t\t.log-header {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tpadding: 0.5rem 1rem;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tcursor: pointer;\n\t\t}\n\n\t\t.log-content {\n\t\t\tmax-height: 300px;\n\t\t\toverflow-y: auto;\n\t\t\tpadding: 0.75rem;\n\t\t\tfont-size: 0.8rem;\n\t\t\tline-height: 1.4;\n\t\t}\n\n\t\t.log-line {\n\t\t\tpadding: 0.1rem 0;\n\t\t\twhite-space: pre-wrap;\n\t\t\tword-break: break-all;\n\t\t\tdisplay: flex;\n\t\t\talign-items: flex-start;\n\t\t\tgap: 0.35rem;\n\t\t}\n\n\t\t.log-line.error {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.log-line.success {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t/* Small inline icon for host output lines */\n\t\t.log-line-icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tflex-shrink: 0;\n\t\t\tmargin-top: 0.15rem;\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t/* P4020: Tabbed Output Panel */\n\t\t.output-panel {\n\t\t\tmargin-top: 1rem;\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\toverflow: hidden;\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t}\n\n\t\t.output-panel.hidden {\n\t\t\tdisplay: none;\n\t\t}\n\n\t\t.output-panel.collapsed .output-content {\n\t\t\tdisplay: none;\n\t\t}\n\n\t\t.output-tabs {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t\toverflow: visible;  /* Allow dropdown to overflow */\n\t\t}\n\n\t\t.tab-list {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tflex: 1;\n\t\t\tmin-width: 0;\n\t\t\toverflow-x: auto;\n\t\t\tscrollbar-width: thin;\n\t\t}\n\n\t\t.tab-list::-webkit-scrollbar {\n\t\t\theight: 4px;\n\t\t}\n\n\t\t.tab-list::-webkit-scrollbar-thumb {\n\t\t\tbackground: var(--border);\n\t\t\tborder-radius: 2px;\n\t\t}\n\n\t\t.output-tab {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tbackground: transparent;\n\t\t\tborder: none;\n\t\t\tborder-bottom: 2px solid transparent;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.8rem;\n\t\t\tcursor: pointer;\n\t\t\twhite-space: nowrap;\n\t\t\ttransition: all 0.15s;\n\t\t}\n\n\t\t.output-tab:hover {\n\t\t\tbackground: rgba(255,255,255,0.05);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.output-tab.active {\n\t\t\tcolor: var(--fg);\n\t\t\tborder-bottom-color: var(--blue);\n\t\t\tbackground: rgba(122, 162, 247, 0.1);\n\t\t}\n\n\t\t.output-tab .tab-indicator {\n\t\t\twidth: 8px;\n\t\t\theight: 8px;\n\t\t\tborder-radius: 50%;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.output-tab .tab-indicator.running {\n\t\t\tbackground: var(--yellow);\n\t\t\tanimation: pulse 1.5s infinite;\n\t\t}\n\n\t\t.output-tab .tab-indicator.awaiting {\n\t\t\tbackground: var(--orange);\n\t\t\tanimation: pulse 1.5s infinite;\n\t\t}\n\n\t\t.output-tab .tab-indicator.success {\n\t\t\tbackground: var(--green);\n\t\t}\n\n\t\t.output-tab .tab-indicator.warning {\n\t\t\tbackground: var(--orange);\n\t\t}\n\n\t\t.output-tab .tab-indicator.error {\n\t\t\tbackground: var(--red);\n\t\t}\n\n\t\t.output-tab .tab-indicator.timeout {\n\t\t\tbackground: var(--yellow);\n\t\t}\n\n\t\t.output-tab .tab-indicator.unread {\n\t\t\tbackground: var(--blue);\n\t\t}\n\n\t\t@keyframes pulse {\n\t\t\t0%, 100% { opacity: 1; }\n\t\t\t50% { opacity: 0.5; }\n\t\t}\n\n\t\t.output-tab .tab-close {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tborder-radius: 3px;\n\t\t\tfont-size: 0.7rem;\n\t\t\tcolor: var(--fg-muted);\n\t\t\topacity: 0;\n\t\t\ttransition: opacity 0.15s;\n\t\t}\n\n\t\t.output-tab:hover .tab-close {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.output-tab .tab-close:hover {\n\t\t\tbackground: rgba(255,255,255,0.1);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.tab-actions {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0 0.75rem;\n\t\t\tborder-left: 1px solid var(--border);\n\t\t}\n\n\t\t.tab-action-btn {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.3rem;\n\t\t\tpadding: 0.3rem 0.5rem;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.7rem;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.15s;\n\t\t}\n\n\t\t.tab-action-btn:hover {\n\t\t\tbackground: rgba(255,255,255,0.05);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.output-content {\n\t\t\tmin-height: 50px;  /* Ensure resize handle works when empty */\n\t\t\tmax-height: 300px;\n\t\t\toverflow-y: scroll;  /* Always show scrollbar */\n\t\t\tpadding: 0.75rem;\n\t\t\tfont-size: 0.8rem;\n\t\t\tline-height: 1.4;\n\t\t}\n\n\t\t.output-content .command-separator {\n\t\t\tmargin: 0.75rem 0 0.25rem 0;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: inherit;  /* Inherit for A+/A- controls */\n\t\t\tfont-family: var(--font-mono);\n\t\t\ttext-align: center;\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t.output-content .command-separator.success {\n\t\t\tcolor: var(--success);\n\t\t\topacity: 0.9;\n\t\t}\n\n\t\t.output-content .command-separator.error {\n\t\t\tcolor: var(--error);\n\t\t\topacity: 0.9;\n\t\t}\n\n\t\t.output-content .status-line {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: inherit;  /* Inherit for A+/A- controls */\n\t\t\tpadding: 0.15rem 0;\n\t\t\topacity: 0.85;\n\t\t}\n\n\t\t/* Host output lines: icon provides visual distinction, small left margin */\n\t\t.output-content .host-output {\n\t\t\tmargin-left: 0.5rem;\n\t\t}\n\n\t\t.output-content .system-log-entry {\n\t\t\tdisplay: flex;\n\t\t\talign-items: flex-start;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.25rem 0;\n\t\t\tcolor: var(--fg-dark);  /* More gray than host log */\n\t\t}\n\n\t\t.output-content .system-log-entry .log-icon {\n\t\t\tflex-shrink: 0;\n\t\t\twidth: 1rem;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.output-content .system-log-entry .log-time {\n\t\t\tflex-shrink: 0;\n\t\t\tcolor: var(--fg-gutter);  /* Even more muted */\n\t\t\tfont-size: inherit;  /* Inherit for A+/A- controls */\n\t\t}\n\n\t\t.output-content .system-log-entry .log-message {\n\t\t\tflex: 1;\n\t\t}\n\n\t\t.output-content .system-log-entry.success .log-icon { color: var(--green); }\n\t\t.output-content .system-log-entry.warning .log-icon { color: var(--orange); }\n\t\t.output-content .system-log-entry.error .log-icon { color: var(--red); }\n\t\t.output-content .system-log-entry.info .log-icon { color: var(--blue); }\n\t\t.output-content .system-log-entry.pending .log-icon { color: var(--yellow); }\n\n\t\t.output-footer {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: flex-end;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder-top: 1px solid var(--border);\n\t\t}\n\n\t\t/* P4021: Tab overflow dropdown */\n\t\t.tab-overflow {\n\t\t\tposition: relative;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t.tab-overflow-btn {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t\tpadding: 0.4rem 0.6rem;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.75rem;\n\t\t\tcursor: pointer;\n\t\t\twhite-space: nowrap;\n\t\t\ttransition: all 0.15s;\n\t\t}\n\n\t\t.tab-overflow-btn:hover {\n\t\t\tbackground: rgba(255,255,255,0.05);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.tab-overflow-menu {\n\t\t\tposition: absolute;\n\t\t\ttop: 100%;  /* Show below the button, not above */\n\t\t\tright: 0;\n\t\t\tmargin-top: 4px;\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tmin-width: 200px;\n\t\t\tmax-height: 300px;\n\t\t\toverflow-y: auto;\n\t\t\tbox-shadow: 0 4px 12px rgba(0, 0, 0, 0.5);\n\t\t\tz-index: 1000;  /* Higher z-index to show above all content */\n\t\t}\n\n\t\t.tab-overflow-item {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tcursor: pointer;\n\t\t\twidth: 100%;\n\t\t\tbackground: none;\n\t\t\tborder: none;\n\t\t\tcolor: var(--fg);\n\t\t\tfont-size: 0.8rem;\n\t\t\tfont-family: inherit;\n\t\t\ttext-align: left;\n\t\t}\n\n\t\t.tab-overflow-item:hover {\n\t\t\tbackground: var(--bg-highlight);\n\t\t}\n\n\t\t.tab-overflow-item.active {\n\t\t\tbackground: rgba(122, 162, 247, 0.1);\n\t\t}\n\n\t\t.tab-overflow-item .tab-indicator {\n\t\t\twidth: 8px;\n\t\t\theight: 8px;\n\t\t\tborder-radius: 50%;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.tab-overflow-item .tab-toggle {\n\t\t\tmargin-left: auto;\n\t\t\tcolor: var(--green);\n\t\t\tfont-size: 0.9rem;\n\t\t}\n\n\t\t/* P4021: Resize handle (bottom of panel) */\n\t\t.output-resize-handle {\n\t\t\theight: 14px;\n\t\t\tbackground: var(--bg-secondary);\n\t\t\tcursor: ns-resize;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tborder-top: 1px solid var(--border);\n\t\t\ttransition: background 0.15s;\n\t\t}\n\n\t\t.output-resize-handle:hover,\n\t\t.output-resize-handle.resizing {\n\t\t\tbackground: rgba(122, 162, 247, 0.2);\n\t\t}\n\n\t\t.output-resize-handle .resize-grip {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 10px;\n\t\t\tletter-spacing: 2px;\n\t\t\topacity: 0.5;\n\t\t\ttransition: opacity 0.15s;\n\t\t}\n\n\t\t.output-resize-handle:hover .resize-grip {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t/* P4021: Mobile tab behavior */\n\t\t@media (max-width: 640px) {\n\t\t\t.tab-list .output-tab:not(.active) {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\t\t\t.tab-overflow {\n\t\t\t\tdisplay: flex;\n\t\t\t}\n\t\t}\n\n\t\t@media (min-width: 641px) {\n\t\t\t.tab-list .output-tab {\n\t\t\t\tdisplay: flex;\n\t\t\t}\n\t\t}\n\n\t\t/* P4021: Relative time styling */\n\t\t.log-time-relative {\n\t\t\tcolor: var(--fg-gutter);\n\t\t\tfont-size: 0.85em;  /* Proportionally smaller, scales with A+/A- */\n\t\t\tmargin-left: 0.25rem;\n\t\t}\n\n\t\t/* Progress indicator */\n\t\t.progress-badge {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.3rem;\n\t\t\tpadding: 0.25rem 0.5rem;\n\t\t\tbackground: rgba(224, 175, 104, 0.15);\n\t\t\tborder: 1px solid var(--yellow);\n\t\t\tborder-radius: 4px;\n\t\t\tfont-size: 0.75rem;\n\t\t\tcolor: var(--yellow);\n\t\t}\n\n\t\t/* Mini progress badge (next to status dot) */\n\t\t.status-with-badge {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t}\n\n\t/* For table cells: use flexbox for consistent status + badge alignment */\n\ttd.status-cell-with-badge {\n\t\tvertical-align: middle;\n\t\t/* Allow heartbeat glow to overdraw; rely on fixed table layout to prevent wiggle */\n\t\toverflow: visible;\n\t\t/* NOTE: paint containment would clip the glow; use layout containment only */\n\t\tcontain: layout;\n\t\twhite-space: nowrap; /* P8800: Prevent status + badge wrapping (wraps can change row height) */\n\t}\n\n\t\t/* P8800/P8900: Give STATUS column enough width so it can't overlap the menu (ellipsis) column. */\n\t\t.host-table th.col-status,\n\t\t.host-table td.status-cell {\n\t\t\t/* 5 compartments × 38px + gaps, plus cell padding. */\n\t\t\twidth: 280px;\n\t\t\tmin-width: 280px;\n\t\t\tmax-width: 280px;\n\t\t\twhite-space: nowrap;\n\t\t\toverflow: hidden; /* Prevent status content from painting into the menu column */\n\t\t\tpadding-left: 0.5rem;\n\t\t\tpadding-right: 0.5rem;\n\t\t}\n\n\t\t/* Status cell: left-align compartments (natural flow) */\n\t\t.host-table td.status-cell {\n\t\t\ttext-align: left;\n\t\t}\n\n\ttd.status-cell-with-badge .status-wrapper {\n\t\tdisplay: inline-flex;\n\t\talign-items: center;\n\t\tgap: 0.5rem;\n\t}\n\n\t\t.progress-badge-mini {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tpadding: 0.15rem 0.3rem;\n\t\t\tbackground: rgba(224, 175, 104, 0.15);\n\t\t\tborder: 1px solid var(--yellow);\n\t\t\tborder-radius: 3px;\n\t\t\tfont-size: 0.5rem;\n\t\t\tcolor: var(--yellow);\n\t\t\tline-height: 1;\n\t\t}\n\n\t\t/* Reboot-required badge next to hostname */\n\t\t.reboot-badge {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.2rem;\n\t\t\tmargin-left: 0.35rem;\n\t\t\tpadding: 0.15rem 0.3rem;\n\t\t\tbackground: rgba(224, 175, 104, 0.15);\n\t\t\tborder: 1px solid var(--yellow);\n\t\t\tborder-radius: 3px;\n\t\t\tfont-size: 0.5rem;\n\t\t\tcolor: var(--yellow);\n\t\t\tline-height: 1;\n\t\t\tcursor: help;\n\t\t}\n\n\t\t.reboot-badge[hidden] {\n\t\t\tdisplay: none;\n\t\t}\n\n\t\t.reboot-badge .icon {\n\t\t\twidth: 9px;\n\t\t\theight: 9px;\n\t\t}\n\n\t\t/* Build progress bar in Status column (parsed from nix's log) */\n\t\t.build-progress {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.4rem;\n\t\t\tmargin-top: 0.2rem;\n\t\t\tfont-family: 'JetBrains Mono', 'Fira Code', monospace;\n\t\t\tfont-size: 0.65rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.build-progress-track {\n\t\t\tflex: 1;\n\t\t\tmin-width: 60px;\n\t\t\theight: 4px;\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder-radius: 2px;\n\t\t\toverflow: hidden;\n\t\t}\n\n\t\t.build-progress-fill {\n\t\t\theight: 100%;\n\t\t\twidth: 0;\n\t\t\tbackground: var(--cyan);\n\t\t\ttransition: width 0.4s ease;\n\t\t}\n\n\t\t/* P7240: Timeout indicator in Status column */\n\t\t.timeout-status-indicator {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t\tpadding: 0.2rem 0.4rem;\n\t\t\tbackground: rgba(234, 179, 8, 0.15);\n\t\t\tborder: 1px solid rgba(234, 179, 8, 0.4);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--yellow);\n\t\t\tfont-size: 0.7rem;\n\t\t\tfont-weight: 600;\n\t\t\tanimation: pulse-timeout 1.5s ease-in-out infinite;\n\t\t\tcursor: pointer;\n\t\t}\n\n\t\t.timeout-status-indicator:hover {\n\t\t\tbackground: rgba(234, 179, 8, 0.25);\n\t\t}\n\n\t\t.timeout-status-indicator .icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t}\n\n\t\t@keyframes pulse-timeout {\n\t\t\t0%, 100% { opacity: 0.7; }\n\t\t\t50% { opacity: 1; }\n\t\t}\n\n\t\t.hostname {\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t/* Clickable hostname for copy-to-clipboard */\n\t\t.hostname-copyable {\n\t\t\tcursor: pointer;\n\t\t\ttransition: filter 0.15s ease, transform 0.1s ease;\n\t\t\tborder-radius: 3px;\n\t\t\tpadding: 0 0.2rem;\n\t\t\tmargin: 0 -0.2rem;\n\t\t}\n\n\t\t.hostname-copyable:hover {\n\t\t\tfilter: brightness(1.3);\n\t\t\tbackground: rgba(255, 255, 255, 0.08);\n\t\t}\n\n\t\t.hostname-copyable:active {\n\t\t\ttransform: scale(0.98);\n\t\t}\n\n\t\t/* P7230: Device type icon prefix before hostname */\n\t\t.hostname-device-icon {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tmargin-left: 0.5rem;\n\t\t\tmargin-right: 0.2rem;\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t.hostname-device-icon .icon,\n\t\t.hostname-device-icon .device-icon {\n\t\t\twidth: 10px;\n\t\t\theight: 10px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t}\n\n\t\t/* Footer */\n\t\tfooter {\n\t\t\tmargin-top: 2rem;\n\t\t\tpadding-top: 1rem;\n\t\t\tborder-top: 1px solid var(--border);\n\t\t\tcolor: var(--fg-dark);\n\t\t\tfont-size: 0.75rem;\n\t\t}\n\n\t\t.site-footer {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tflex-wrap: wrap;\n\t\t\tgap: 0.5rem;\n\t\t}\n\n\t\t.footer-left,\n\t\t.footer-right {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t.footer-sep {\n\t\t\tcolor: var(--fg-gutter);\n\t\t}\n\n\t\t.footer-link {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.2rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t\ttext-decoration: none;\n\t\t\ttransition: color 0.2s;\n\t\t}\n\n\t\t.footer-link:hover {\n\t\t\tcolor: var(--blue);\n\t\t}\n\n\t\t.footer-link .icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t}\n\n\t\t.made-with {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.3rem;\n\t\t}\n\n\t\t.made-with a {\n\t\t\tcolor: var(--blue);\n\t\t\ttext-decoration: none;\n\t\t}\n\n\t\t.made-with a:hover {\n\t\t\ttext-decoration: underline;\n\t\t}\n\n\t\t.made-with .heart {\n\t\t\tcolor: var(--red);\n\t\t\tanimation: heartbeat 1.5s ease-in-out infinite;\n\t\t}\n\n\t\t@keyframes heartbeat {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\ttransform: scale(1);\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\ttransform: scale(1.15);\n\t\t\t}\n\t\t}\n\n\t\t@media (max-width: 640px) {\n\t\t\t.site-footer {\n\t\t\t\tflex-direction: column;\n\t\t\t\ttext-align: center;\n\t\t\t}\n\n\t\t\t.footer-left,\n\t\t\t.footer-right {\n\t\t\t\tjustify-content: center;\n\t\t\t}\n\t\t}\n\n\t\t/* Stats bar */\n\t\t.stats-bar {\n\t\t\tdisplay: flex;\n\t\t\tgap: 1rem;\n\t\t\tmargin-bottom: 1rem;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t.stat {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 1rem;\n\t\t\tbackground: var(--bg-float);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tfont-size: 0.85rem;\n\t\t}\n\n\t\t.stat-value {\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t.stat-value.online {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.stat-value.offline {\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t/* Connection indicator */\n\t\t.connection-indicator {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t\tfont-size: 0.75rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.connection-indicator .status-dot {\n\t\t\twidth: 6px;\n\t\t\theight: 6px;\n\t\t\tmin-width: 6px;\n\t\t\tmin-height: 6px;\n\t\t\tbackground: currentColor;\n\t\t\tmargin: 0;\n\t\t}\n\n\t\t.connection-indicator.connected {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.connection-indicator.disconnected {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\n\t\t/* Chevron icon */\n\t\t.chevron {\n\t\t\ttransition: transform 0.2s ease;\n\t\t}\n\n\t\t.expanded .chevron {\n\t\t\ttransform: rotate(180deg);\n\t\t}\n\n\t\t/* Hide utility */\n\t\t.hidden {\n\t\t\tdisplay: none !important;\n\t\t}\n\n\t\t/* Icon styles */\n\t\t.icon {\n\t\t\twidth: 14px;\n\t\t\theight: 14px;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.btn .icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t}\n\n\t\t.metric-icon {\n\t\t\twidth: 10px;\n\t\t\theight: 10px;\n\t\t\topacity: 0.7;\n\t\t\tflex-shrink: 0;\n\t\t\tmargin-right: -10px;\n\t\t}\n\n\t\t/* Metrics display */\n\t\ttd.metrics-cell {\n\t\t\tfont-size: 0.85rem;\n\t\t\tfont-family: var(--font-mono);\n\t\t\tvertical-align: middle;\n\t\t}\n\n\t\ttd.metrics-cell>span,\n\t\tspan.metrics-cell {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 25px;\n\t\t}\n\n\t\t.metric {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0;\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.metric-val {\n\t\t\tdisplay: inline-block;\n\t\t\twidth: 4ch;\n\t\t\ttext-align: right;\n\t\t}\n\n\t\t.metric.high {\n\t\t\tcolor: var(--red);\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t.metric.high .metric-icon {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.metrics-na {\n\t\t\tcolor: var(--fg-gutter);\n\t\t}\n\n\t\t/* Last seen time colors */\n\t\t.last-seen-ok {\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.last-seen-warn {\n\t\t\tcolor: var(--yellow);\n\t\t}\n\n\t\t.last-seen-stale {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t/* P8800: Last Seen column must not resize when text changes (\"9s\" -> \"10s\" -> \"1m\"). */\n\t\t.host-table th.col-last-seen,\n\t\t.host-table td.col-last-seen {\n\t\t\twidth: 120px;\n\t\t\tmin-width: 120px;\n\t\t\tmax-width: 120px;\n\t\t\twhite-space: nowrap;\n\t\t\toverflow: hidden;\n\t\t\ttext-overflow: ellipsis;\n\t\t\tfont-variant-numeric: tabular-nums;\n\t\t}\n\n\t\t/* Agent Version Column (P7300) */\n\t\t.agent-version-cell {\n\t\t\tfont-size: 0.8rem;\n\t\t\tfont-family: var(--font-mono);\n\t\t\ttext-align: center;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.agent-version {\n\t\t\tpadding: 0.15rem 0.4rem;\n\t\t\tborder-radius: 4px;\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.agent-version--ok {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.agent-version--outdated {\n\t\t\tcolor: var(--red);\n\t\t\tbackground: rgba(247, 118, 142, 0.15);\n\t\t}\n\n\t\t.agent-version--unknown {\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t/* P4500: Generation Column */\n\t\t.gen-cell {\n\t\t\tfont-size: 0.8rem;\n\t\t\tfont-family: var(--font-mono);\n\t\t\ttext-align: center;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.gen-hash {\n\t\t\tpadding: 0.15rem 0.4rem;\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tcursor: default;\n\t\t}\n\n\t\t.gen-hash:hover {\n\t\t\tcolor: var(--fg);\n\t\t\tbackground: rgba(255, 255, 255, 0.05);\n\t\t}\n\n\t\t.gen-unknown {\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.gen-drift {\n\t\t\tcolor: var(--yellow);\n\t\t\tbackground: rgba(224, 175, 104, 0.15);\n\t\t}\n\n\t\t/* Update Status Compartments (P5000 / P7300) */\n\t\t.update-status {\n\t\t\tdisplay: inline-flex;\n\t\t\tgap: 4px;  /* Slightly more spacing for larger compartments */\n\t\t}\n\n\t\t.update-compartment {\n\t\t\tposition: relative;\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\twidth: 24px;\n\t\t\theight: 24px;\n\t\t\tbackground: #374151;\n\t\t\tborder-radius: 4px;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t.update-compartment:hover {\n\t\t\tbackground: #4b5563;\n\t\t}\n\n\t\t/* P7230: Icon 20% bigger (11→13px), centered */\n\t\t.update-compartment .update-icon {\n\t\t\twidth: 13px;\n\t\t\theight: 13px;\n\t\t\tfill: #1f2937;\n\t\t\tstroke: #1f2937;\n\t\t\tcolor: #1f2937;\n\t\t}\n\n\t\t/* P5100: Simplified - icon always dark, only indicator dot shows status */\n\t\t/* Unknown state: same background, slightly dimmed to hint at stale data */\n\t\t.update-compartment.unknown {\n\t\t\topacity: 0.6;\n\t\t}\n\n\n\t\t/* Flake Update Banner (P5300) */\n\t\t.flake-update-banner {\n\t\t\tbackground: linear-gradient(135deg, #1e3a5f, #0d1a2d);\n\t\t\tborder: 1px solid #3b82f6;\n\t\t\tborder-radius: 8px;\n\t\t\tmargin: 0 1rem 1rem;\n\t\t\tpadding: 0;\n\t\t\tbox-shadow: 0 4px 12px rgba(59, 130, 246, 0.2);\n\t\t}\n\n\t\t.flake-update-content {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 1rem;\n\t\t\tpadding: 0.75rem 1rem;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t.flake-update-icon {\n\t\t\tfont-size: 1.25rem;\n\t\t}\n\n\t\t.flake-update-text {\n\t\t\tflex: 1;\n\t\t\tmin-width: 200px;\n\t\t\tcolor: #e2e8f0;\n\t\t}\n\n\t\t.flake-update-text a {\n\t\t\tcolor: #60a5fa;\n\t\t\ttext-decoration: none;\n\t\t}\n\n\t\t.flake-update-text a:hover {\n\t\t\ttext-decoration: underline;\n\t\t}\n\n\t\t.flake-update-success {\n\t\t\tborder-color: #22c55e;\n\t\t\tbackground: linear-gradient(135deg, #14532d, #052e16);\n\t\t}\n\n\t\t.flake-update-error {\n\t\t\tborder-color: #ef4444;\n\t\t\tbackground: linear-gradient(135deg, #7f1d1d, #450a0a);\n\t\t}\n\n\t\t.flake-update-progress {\n\t\t\tanimation: flake-update-pulse 2s ease-in-out infinite;\n\t\t}\n\n\t\t.flake-update-spinner {\n\t\t\tanimation: flake-update-spin 1.5s linear infinite;\n\t\t}\n\n\t\t@keyframes flake-update-pulse {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\topacity: 0.9;\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t}\n\t\t}\n\n\t\t@keyframes flake-update-spin {\n\t\t\tfrom {\n\t\t\t\ttransform: rotate(0deg);\n\t\t\t}\n\n\t\t\tto {\n\t\t\t\ttransform: rotate(360deg);\n\t\t\t}\n\t\t}\n\n\t\t.btn-sm {\n\t\t\tpadding: 0.35rem 0.75rem;\n\t\t\tfont-size: 0.8rem;\n\t\t}\n\n\t\t@keyframes pulse-glow {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\topacity: 0.3;\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t}\n\t\t}\n\n\t\t@keyframes indicator-pulse {\n\n\t\t\t0%,\n\t\t\t100% {\n\t\t\t\topacity: 0.4;\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t}\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t   COMPARTMENT STATUS INDICATOR (P7300 simplified)\n\t\t   Single dot per compartment with 5 color states:\n\t\t   - gray: not checked / no data\n\t\t   - blue pulse: working / in progress\n\t\t   - green: ok / current\n\t\t   - yellow: warning / outdated\n\t\t   - red: error / failed\n\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\t\t/* P7230: Indicator dot 15% smaller (6→5px) */\n\t\t.compartment-indicator {\n\t\t\tposition: absolute;\n\t\t\tbottom: 4px;\n\t\t\tright: 4px;\n\t\t\twidth: 5px;\n\t\t\theight: 5px;\n\t\t\tborder-radius: 50%;\n\t\t\tpointer-events: none;\n\t\t}\n\n\t\t/* P1100: Status text label for accessibility (left of dot) */\n\t\t.compartment-indicator::before {\n\t\t\tcontent: attr(data-status);\n\t\t\tposition: absolute;\n\t\t\tright: 8px; /* left of the 5px dot + 3px gap */\n\t\t\ttop: 50%;\n\t\t\ttransform: translateY(-50%);\n\t\t\tfont-size: 6px;\n\t\t\tfont-weight: 600;\n\t\t\tletter-spacing: 0.5px;\n\t\t\ttext-transform: uppercase;\n\t\t\topacity: 0.35;\n\t\t\twhite-space: nowrap;\n\t\t\tcolor: currentColor;\n\t\t}\n\n\t\t/* Gray: Not checked / no data / offline */\n\t\t.compartment-indicator--gray,\n\t\t.compartment-indicator--unknown {\n\t\t\tbackground: hsl(220, 10%, 45%);\n\t\t\topacity: 0.5;\n\t\t}\n\n\t\t/* Blue pulse: Working / in progress */\n\t\t.compartment-indicator--working {\n\t\t\tbackground: hsl(210, 90%, 55%);\n\t\t\tbox-shadow: 0 0 4px hsla(210, 90%, 55%, 0.8);\n\t\t\tanimation: working-pulse 1.2s ease-in-out infinite;\n\t\t}\n\n\t\t@keyframes working-pulse {\n\t\t\t0%, 100% {\n\t\t\t\topacity: 0.5;\n\t\t\t\ttransform: scale(0.9);\n\t\t\t}\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t\ttransform: scale(1.1);\n\t\t\t}\n\t\t}\n\n\t\t/* Green: OK / current / up-to-date */\n\t\t.compartment-indicator--ok {\n\t\t\tbackground: hsl(142, 71%, 45%);\n\t\t\tbox-shadow: 0 0 3px hsla(142, 71%, 45%, 0.6);\n\t\t}\n\n\t\t/* Yellow: Warning / outdated but not critical */\n\t\t.compartment-indicator--warning {\n\t\t\tbackground: hsl(45, 90%, 50%);\n\t\t\tbox-shadow: 0 0 3px hsla(45, 90%, 50%, 0.6);\n\t\t}\n\n\t\t/* Red: Error / failed / critical */\n\t\t.compartment-indicator--error {\n\t\t\tbackground: hsl(0, 70%, 55%);\n\t\t\tbox-shadow: 0 0 3px hsla(0, 70%, 55%, 0.6);\n\t\t}\n\n\t\t/* Amber ring: only known-flaky tests failed */\n\t\t.compartment-indicator--flaky {\n\t\t\tbackground: hsl(30, 90%, 55%);\n\t\t\tbox-shadow: 0 0 0 1px hsla(0, 70%, 55%, 0.8);\n\t\t}\n\n\n\t\t/* Action Dropdown (P4380) */\n\t\t.action-buttons {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.25rem;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t.dropdown {\n\t\t\tposition: relative;\n\t\t\tdisplay: inline-block;\n\t\t}\n\n\t\t.btn-more {\n\t\t\tpadding: 0.4rem;\n\t\t\tmargin-left: 10px;\n\t\t\tmin-width: 30px;\n\t\t\tmin-height: 30px;\n\t\t\theight: 30px;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t}\n\n\t\t/* Stop button - replaces cmd buttons when command running */\n\t\t.btn-stop {\n\t\t\tbackground: var(--red);\n\t\t\tcolor: white;\n\t\t\tborder: none;\n\t\t\tborder-radius: 4px;\n\t\t\tpadding: 0.25rem 0.5rem;\n\t\t\tcursor: pointer;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.25rem;\n\t\t\tfont-size: 0.75rem;\n\t\t}\n\n\t\t.btn-stop:hover {\n\t\t\tbackground: hsl(0, 70%, 50%);\n\t\t}\n\n\t\t/* P7000: PR indicator on Lock compartment */\n\t\t.update-compartment.has-pr {\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.update-compartment.has-pr::after {\n\t\t\tcontent: \"\";\n\t\t\tposition: absolute;\n\t\t\ttop: -2px;\n\t\t\tright: -2px;\n\t\t\twidth: 6px;\n\t\t\theight: 6px;\n\t\t\tbackground: var(--color-blue);\n\t\t\tborder-radius: 50%;\n\t\t}\n\n\t\t.dropdown-menu {\n\t\t\tposition: absolute;\n\t\t\tright: 0;\n\t\t\ttop: 100%;\n\t\t\tmargin-top: 4px;\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tmin-width: 200px;\n\t\t\tmax-width: calc(100vw - 2rem);\n\t\t\tmax-height: calc(100vh - 100px);\n\t\t\toverflow-y: auto;\n\t\t\tbox-shadow: 0 4px 12px rgba(0, 0, 0, 0.3);\n\t\t\tz-index: 99999;\n\t\t}\n\n\t\t/* Ensure dropdown parent creates stacking context */\n\t\t.dropdown {\n\t\t\tposition: relative;\n\t\t\tz-index: 100;\n\t\t}\n\n\t\t.dropdown:has(.dropdown-menu[x-show=\"true\"]),\n\t\t.dropdown:has(.dropdown-menu:not([style*=\"display: none\"])) {\n\t\t\tz-index: 99999;\n\t\t}\n\n\t\t/* Header dropdown (id-based) starts hidden, uses .open class */\n\t\t#bulk-actions-menu {\n\t\t\tdisplay: none;\n\t\t}\n\n\t\t#bulk-actions-menu.open {\n\t\t\tdisplay: block;\n\t\t}\n\n\t\t/* x-cloak hides Alpine elements until initialized */\n\t\t[x-cloak] {\n\t\t\tdisplay: none !important;\n\t\t}\n\n\t\t/* Bulk Actions dropdown in header */\n\t\t.bulk-actions-dropdown {\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.bulk-actions-dropdown .dropdown-menu {\n\t\t\tright: auto;\n\t\t\tleft: 0;\n\t\t}\n\n\t\t.dropdown-item {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tcursor: pointer;\n\t\t\twidth: 100%;\n\t\t\tbackground: none;\n\t\t\tborder: none;\n\t\t\tcolor: var(--fg);\n\t\t\tfont-size: 0.85rem;\n\t\t\tfont-family: inherit;\n\t\t\ttext-align: left;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.dropdown-item:hover {\n\t\t\tbackground: var(--bg-highlight);\n\t\t}\n\n\t\t.dropdown-item.danger {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.dropdown-item.danger:hover {\n\t\t\tbackground: rgba(247, 118, 142, 0.1);\n\t\t}\n\n\t\t.dropdown-item:disabled {\n\t\t\topacity: 0.5;\n\t\t\tcursor: not-allowed;\n\t\t}\n\n\t\t.dropdown-item .icon {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tflex-shrink: 0;\n\t\t\tcolor: var(--fg-muted);\n\t\t}\n\n\t\t.dropdown-item:hover:not(:disabled) .icon {\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.dropdown-divider {\n\t\t\theight: 1px;\n\t\t\tbackground: var(--border);\n\t\t\tmargin: 0.25rem 0;\n\t\t\tborder: none;\n\t\t}\n\n\t\t/* P1060: Dropdown toggle button */\n\t\t.col-menu {\n\t\t\twidth: 70px;\n\t\t\tmin-width: 70px;\n\t\t\ttext-align: center;\n\t\t\tvertical-align: middle;\n\t\t\tpadding: 0.5rem !important;\n\t\t}\n\n\t\t.dropdown-toggle {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\twidth: 28px;\n\t\t\theight: 28px;\n\t\t\tpadding: 0;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg-muted);\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 150ms ease;\n\t\t}\n\n\t\t.dropdown-toggle:hover {\n\t\t\tcolor: var(--fg);\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder-color: var(--fg-dim);\n\t\t}\n\n\t\t.dropdown-toggle:focus-visible {\n\t\t\toutline: 2px solid var(--blue);\n\t\t\toutline-offset: 2px;\n\t\t}\n\n\t\t.dropdown-toggle .icon {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t}\n\n\t\t/* Modals (P4390) */\n\t\t.modal-overlay {\n\t\t\tdisplay: none;\n\t\t\tposition: fixed;\n\t\t\tinset: 0;\n\t\t\tbackground: rgba(0, 0, 0, 0.6);\n\t\t\tz-index: 10000;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t}\n\n\t\t.modal-overlay.open {\n\t\t\tdisplay: flex;\n\t\t}\n\n\t\t.modal {\n\t\t\tbackground: var(--bg-dark);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 8px;\n\t\t\tpadding: 1.5rem;\n\t\t\tmax-width: 400px;\n\t\t\twidth: 90%;\n\t\t\tbox-shadow: 0 8px 24px rgba(0, 0, 0, 0.4);\n\t\t}\n\n\t\t.modal-wide {\n\t\t\tmax-width: 500px;\n\t\t}\n\n\t\t.modal-xwide {\n\t\t\tmax-width: 720px;\n\t\t}\n\n\t\t/* API tokens modal */\n\t\t.token-table {\n\t\t\twidth: 100%;\n\t\t\tborder-collapse: collapse;\n\t\t\tmargin-bottom: 1rem;\n\t\t\tfont-size: 0.85rem;\n\t\t}\n\n\t\t.token-table th,\n\t\t.token-table td {\n\t\t\tpadding: 0.35rem 0.5rem;\n\t\t\ttext-align: left;\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t}\n\n\t\t.token-table .modal-btn {\n\t\t\tpadding: 0.2rem 0.6rem;\n\t\t}\n\n\t\t.token-secret {\n\t\t\tmargin-bottom: 1rem;\n\t\t\tpadding: 0.75rem;\n\t\t\tborder: 1px solid var(--green);\n\t\t\tborder-radius: 6px;\n\t\t\tfont-size: 0.85rem;\n\t\t}\n\n\t\t.token-secret code {\n\t\t\tdisplay: block;\n\t\t\tmargin-top: 0.5rem;\n\t\t\tcolor: var(--green);\n\t\t\tword-break: break-all;\n\t\t\tuser-select: all;\n\t\t}\n\n\t\t.modal-title {\n\t\t\tfont-size: 1.1rem;\n\t\t\tfont-weight: 600;\n\t\t\tmargin-bottom: 1rem;\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.modal-body {\n\t\t\tmargin-bottom: 1.5rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.modal-actions {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.5rem;\n\t\t\tjustify-content: flex-end;\n\t\t}\n\n\t\t.modal-btn {\n\t\t\tpadding: 0.5rem 1rem;\n\t\t\tborder-radius: 6px;\n\t\t\tfont-size: 0.85rem;\n\t\t\tfont-family: inherit;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t.modal-btn-cancel {\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.modal-btn-cancel:hover {\n\t\t\tbackground: var(--bg);\n\t\t}\n\n\t\t.modal-btn-primary {\n\t\t\tbackground: var(--blue);\n\t\t\tborder: 1px solid var(--blue);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t.modal-btn-primary:hover {\n\t\t\tbackground: #8aacf7;\n\t\t}\n\n\t\t.modal-btn-danger {\n\t\t\tbackground: rgba(247, 118, 142, 0.15);\n\t\t\tborder: 1px solid rgba(247, 118, 142, 0.3);\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.modal-btn-danger:hover {\n\t\t\tbackground: rgba(247, 118, 142, 0.25);\n\t\t}\n\n\t\t/* Form styles for modals */\n\t\t.form-group {\n\t\t\tmargin-bottom: 1rem;\n\t\t}\n\n\t\t.form-group label {\n\t\t\tdisplay: block;\n\t\t\tmargin-bottom: 0.25rem;\n\t\t\tfont-size: 0.85rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.form-group input,\n\t\t.form-group select {\n\t\t\twidth: 100%;\n\t\t\tpadding: 0.5rem;\n\t\t\tbackground: var(--bg);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 4px;\n\t\t\tcolor: var(--fg);\n\t\t\tfont-family: inherit;\n\t\t\tfont-size: 0.9rem;\n\t\t}\n\n\t\t.form-group input:focus,\n\t\t.form-group select:focus {\n\t\t\toutline: none;\n\t\t\tborder-color: var(--blue);\n\t\t}\n\n\t\t.form-row {\n\t\t\tdisplay: grid;\n\t\t\tgrid-template-columns: 1fr 1fr;\n\t\t\tgap: 1rem;\n\t\t}\n\n\t\t/* P2950: Color Picker Styles */\n\t\t.color-picker-host {\n\t\t\tmargin-bottom: 1rem;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.color-picker-host code {\n\t\t\tcolor: var(--blue);\n\t\t\tfont-weight: 600;\n\t\t}\n\n\t\t.color-presets {\n\t\t\tdisplay: flex;\n\t\t\tflex-wrap: wrap;\n\t\t\tgap: 0.5rem;\n\t\t}\n\n\t\t.color-preset {\n\t\t\twidth: 36px;\n\t\t\theight: 36px;\n\t\t\tborder: 2px solid transparent;\n\t\t\tborder-radius: 6px;\n\t\t\tpadding: 2px;\n\t\t\tcursor: pointer;\n\t\t\tbackground: var(--bg);\n\t\t\ttransition: all 0.15s ease;\n\t\t}\n\n\t\t.color-preset:hover {\n\t\t\tborder-color: var(--fg-dark);\n\t\t}\n\n\t\t.color-preset.selected {\n\t\t\tborder-color: var(--blue);\n\t\t\tbox-shadow: 0 0 0 2px rgba(122, 162, 247, 0.3);\n\t\t}\n\n\t\t.color-swatch {\n\t\t\tdisplay: block;\n\t\t\twidth: 100%;\n\t\t\theight: 100%;\n\t\t\tborder-radius: 4px;\n\t\t}\n\n\t\t.custom-color-row {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.75rem;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t.custom-color-row input[type=\"color\"] {\n\t\t\twidth: 48px;\n\t\t\theight: 36px;\n\t\t\tpadding: 0;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tcursor: pointer;\n\t\t\tbackground: transparent;\n\t\t}\n\n\t\t.custom-color-row input[type=\"color\"]::-webkit-color-swatch-wrapper {\n\t\t\tpadding: 2px;\n\t\t}\n\n\t\t.custom-color-row input[type=\"color\"]::-webkit-color-swatch {\n\t\t\tborder: none;\n\t\t\tborder-radius: 4px;\n\t\t}\n\n\t\t.custom-color-row input[type=\"text\"] {\n\t\t\twidth: 100px;\n\t\t\tfont-family: var(--mono-font, monospace);\n\t\t\ttext-transform: uppercase;\n\t\t}\n\n\t\t.color-preview-row {\n\t\t\tdisplay: flex;\n\t\t\theight: 32px;\n\t\t\tborder-radius: 6px;\n\t\t\toverflow: hidden;\n\t\t}\n\n\t\t.preview-segment {\n\t\t\tflex: 1;\n\t\t}\n\n\t\t/* Bulk Actions */\n\t\t.bulk-actions {\n\t\t\tdisplay: inline-flex;\n\t\t\tgap: 0.5rem;\n\t\t\tmargin-left: 1rem;\n\t\t}\n\n\t\t.bulk-btn {\n\t\t\tpadding: 0.4rem 0.75rem;\n\t\t\tfont-size: 0.75rem;\n\t\t}\n\n\t\t/* Loading spinner */\n\t\t.spinner {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tborder: 2px solid var(--border);\n\t\t\tborder-top-color: var(--blue);\n\t\t\tborder-radius: 50%;\n\t\t\tanimation: spin 0.8s linear infinite;\n\t\t}\n\n\t\t@keyframes spin {\n\t\t\tto {\n\t\t\t\ttransform: rotate(360deg);\n\t\t\t}\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   ROW SELECTION (P1030)\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t/* Checkbox Column */\n\t\t.col-select {\n\t\t\twidth: 40px;\n\t\t\tmin-width: 40px;\n\t\t\tmax-width: 40px;\n\t\t\tpadding: 0 8px !important;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t/* Header Toggle */\n\t\t.select-toggle {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tpadding: 4px;\n\t\t\tbackground: transparent;\n\t\t\tborder: none;\n\t\t\tborder-radius: 4px;\n\t\t\tcursor: pointer;\n\t\t\tcolor: var(--fg-dark);\n\t\t\ttransition: color 150ms ease, background 150ms ease;\n\t\t}\n\n\t\t.select-toggle:hover {\n\t\t\tcolor: var(--fg);\n\t\t\tbackground: var(--bg-highlight);\n\t\t}\n\n\t\t.select-toggle:focus-visible {\n\t\t\toutline: 2px solid var(--blue);\n\t\t\toutline-offset: 2px;\n\t\t}\n\n\t\t.select-toggle .icon {\n\t\t\twidth: 18px;\n\t\t\theight: 18px;\n\t\t}\n\n\t\t/* Row Toggle (button style, matching header) */\n\t\t/* P7230: Visible at 10% opacity when unchecked */\n\t\t.row-select-toggle {\n\t\t\topacity: 0.1;\n\t\t\ttransition: opacity 150ms ease;\n\t\t}\n\n\t\t/* Show toggle on row hover or when selected */\n\t\ttr:hover .row-select-toggle,\n\t\ttr.selected .row-select-toggle,\n\t\t.row-select-toggle.is-selected {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t/* Selected indicator */\n\t\t.row-select-toggle.is-selected {\n\t\t\tcolor: var(--blue);\n\t\t}\n\n\t\t/* Selected Row */\n\t\ttr.selected {\n\t\t\tbackground: var(--bg-highlight) !important;\n\t\t}\n\n\t\ttr.selected:hover {\n\t\t\tbackground: rgba(41, 46, 66, 0.9) !important;\n\t\t}\n\n\t\t/* Allow text selection everywhere (no row click selection) */\n\t\ttr[data-host-id] .host-name {\n\t\t\tuser-select: text;\n\t\t\tcursor: text;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   CLICKABLE COMPARTMENTS (P1020)\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.compartment-btn {\n\t\t\tappearance: none;\n\t\t\tborder-style: solid;\n\t\t\tborder-color: rgba(232, 236, 245, 0.05);\n\t\t\tmargin: 0;\n\t\t\tfont: inherit;\n\t\t\tcolor: inherit;\n\t\t\tposition: relative;\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\twidth: 38px;  /* P7300: ~30% bigger */\n\t\t\theight: 38px;\n\t\t\tpadding: 0;\n\t\t\tbackground: rgba(0, 0, 0, 0.1);\n\t\t\tborder-radius: 6px;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t.compartment-btn:hover {\n\t\t\ttransform: scale(1.08);\n\t\t\tbackground: rgba(0, 0, 0, 0.7);\n\t\t}\n\n\t\t.compartment-btn:active {\n\t\t\ttransform: scale(0.95);\n\t\t}\n\n\t\t.compartment-btn:focus-visible {\n\t\t\toutline: 2px solid var(--blue);\n\t\t\toutline-offset: 2px;\n\t\t}\n\n\t\t.compartment-btn .update-icon {\n\t\t\tposition: relative;\n\t\t\ttop: -4px;  /* Adjusted for 3-dot layout */\n\t\t\twidth: 14px;  /* Scaled up for bigger buttons */\n\t\t\theight: 14px;\n\t\t\tfill: var(--fg);\n\t\t\tstroke: var(--fg);\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.compartment-btn.unknown {\n\t\t\topacity: 0.6;\n\t\t}\n\n\t\t.compartment-btn.info-only {\n\t\t\tcursor: help;\n\t\t}\n\n\t\t.compartment-btn.info-only:hover {\n\t\t\ttransform: none;\n\t\t\tbackground: rgba(0, 0, 0, 0.5);\n\t\t}\n\n\t\t.compartment-btn.info-only:active {\n\t\t\ttransform: none;\n\t\t}\n\n\t\t.compartment-btn.rate-limited {\n\t\t\tpointer-events: none;\n\t\t\topacity: 0.7;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   TOAST NOTIFICATIONS (P1020)\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.toast {\n\t\t\tposition: fixed;\n\t\t\tbottom: 20px;\n\t\t\tleft: 50%;\n\t\t\ttransform: translateX(-50%) translateY(20px);\n\t\t\tbackground: rgba(0, 0, 0, 0.7);\n\t\t\tbackdrop-filter: blur(12px);\n\t\t\t-webkit-backdrop-filter: blur(12px);\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 8px;\n\t\t\tpadding: 12px 20px;\n\t\t\tfont-size: 0.875rem;\n\t\t\topacity: 0;\n\t\t\ttransition: transform 300ms ease, opacity 300ms ease;\n\t\t\tz-index: 100000;\n\t\t\tmax-width: 90%;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.toast.show {\n\t\t\ttransform: translateX(-50%) translateY(0);\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.toast-info {\n\t\t\tborder-color: var(--blue);\n\t\t}\n\n\t\t.toast-error {\n\t\t\tborder-color: var(--red);\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.toast-success {\n\t\t\tborder-color: var(--green);\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t/* P7230: Warning toast for outdated status */\n\t\t.toast-warning {\n\t\t\tborder-color: var(--yellow);\n\t\t\tcolor: var(--yellow);\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   DEPENDENCY DIALOG (P1040)\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.dialog-modal {\n\t\t\tmax-width: 500px;\n\t\t\twidth: 90%;\n\t\t\tposition: relative;\n\t\t}\n\n\t\t.dialog-header {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 12px;\n\t\t\tmargin-bottom: 16px;\n\t\t}\n\n\t\t.dialog-icon {\n\t\t\twidth: 24px;\n\t\t\theight: 24px;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.dialog-icon.warning {\n\t\t\tcolor: var(--yellow);\n\t\t}\n\n\t\t.dialog-title {\n\t\t\tfont-size: 1.125rem;\n\t\t\tfont-weight: 600;\n\t\t\tmargin: 0;\n\t\t}\n\n\t\t.dialog-body {\n\t\t\tmargin-bottom: 20px;\n\t\t}\n\n\t\t.dialog-message {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tmargin: 0 0 16px 0;\n\t\t\tline-height: 1.5;\n\t\t}\n\n\t\t.dialog-host-list {\n\t\t\tlist-style: none;\n\t\t\tpadding: 0;\n\t\t\tmargin: 0;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\toverflow: hidden;\n\t\t\tmax-height: 200px;\n\t\t\toverflow-y: auto;\n\t\t}\n\n\t\t.dialog-host-list li {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tpadding: 10px 12px;\n\t\t\tborder-bottom: 1px solid var(--border);\n\t\t}\n\n\t\t.dialog-host-list li:last-child {\n\t\t\tborder-bottom: none;\n\t\t}\n\n\t\t.dialog-host-list li.needs-action {\n\t\t\tbackground: rgba(250, 204, 21, 0.1);\n\t\t}\n\n\t\t.dialog-host-list .host-name {\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.dialog-host-list .host-status {\n\t\t\tfont-size: 0.8125rem;\n\t\t\tcolor: var(--fg-muted);\n\t\t}\n\n\t\t.dialog-footer {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\talign-items: center;\n\t\t\tgap: 12px;\n\t\t}\n\n\t\t.dialog-action-group {\n\t\t\tdisplay: flex;\n\t\t\tgap: 8px;\n\t\t\tflex-wrap: wrap;\n\t\t}\n\n\t\t@media (max-width: 480px) {\n\t\t\t.dialog-footer {\n\t\t\t\tflex-direction: column;\n\t\t\t\talign-items: stretch;\n\t\t\t}\n\n\t\t\t.dialog-action-group {\n\t\t\t\tjustify-content: flex-end;\n\t\t\t}\n\n\t\t\t.btn-cancel {\n\t\t\t\torder: 1;\n\t\t\t}\n\t\t}\n\n\t\t.btn-primary {\n\t\t\tbackground: var(--blue);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t.btn-primary:hover {\n\t\t\tfilter: brightness(1.1);\n\t\t}\n\n\t\t.dialog-progress {\n\t\t\tposition: absolute;\n\t\t\tinset: 0;\n\t\t\tbackground: rgba(26, 27, 38, 0.95);\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tborder-radius: inherit;\n\t\t}\n\n\t\t.progress-content {\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t\talign-items: center;\n\t\t\tgap: 12px;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t.progress-content .icon {\n\t\t\twidth: 32px;\n\t\t\theight: 32px;\n\t\t\tcolor: var(--blue);\n\t\t}\n\n\t\t.btn-cancel-small {\n\t\t\tfont-size: 0.8125rem;\n\t\t\tpadding: 4px 12px;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   CONTEXT BAR - Unified hover preview + selection actions\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   CONTEXT BAR - Stacked rows for PR, hover, and selection\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.context-bar {\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t\tpadding: 0.5rem 1.5rem;\n\t\t\tbackground: var(--bg-elevated);\n\t\t\tborder: 1px solid rgba(255, 255, 255, 0.08);\n\t\t\tborder-radius: 8px;\n\t\t\tgap: 0.375rem;\n\t\t\tmargin: 1rem 0 0 0;\n\t\t\tmin-height: 170px;\n\t\t\t/* Reserve space for 3 rows */\n\t\t}\n\n\t\t/* When empty, show subtle border outline */\n\t\t.context-bar-empty {\n\t\t\tborder-color: rgba(255, 255, 255, 0.03);\n\t\t\tbackground: transparent;\n\t\t}\n\n\t\t.context-bar-empty * {\n\t\t\topacity: 0;\n\t\t\tpointer-events: none;\n\t\t}\n\n\t\t@media (max-width: 768px) {\n\t\t\t.context-bar {\n\t\t\t\tpadding: 0.5rem 1rem;\n\t\t\t\tmin-height: 160px;\n\t\t\t}\n\t\t}\n\n\t\t/* Each row in the context bar */\n\t\t.context-row {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: space-between;\n\t\t\tpadding: 0.5rem 0.75rem;\n\t\t\tborder-radius: 6px;\n\t\t\tgap: 1rem;\n\t\t\tmin-height: 36px;\n\t\t}\n\n\t\t.context-row-info {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tflex: 1;\n\t\t\tmin-width: 0;\n\t\t}\n\n\t\t/* Row 1: PR row styling */\n\t\t.context-row-pr {\n\t\t\tbackground: rgba(187, 154, 247, 0.08);\n\t\t\tborder: 1px solid rgba(187, 154, 247, 0.25);\n\t\t}\n\n\t\t.context-row-pr .icon-pr {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tcolor: var(--purple);\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.context-row-pr .pr-label {\n\t\t\tcolor: var(--purple);\n\t\t\tfont-weight: 600;\n\t\t\tfont-size: 0.875rem;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.context-row-pr .pr-detail {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.8125rem;\n\t\t\toverflow: hidden;\n\t\t\ttext-overflow: ellipsis;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.btn-merge {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.375rem;\n\t\t\tpadding: 0.375rem 0.75rem;\n\t\t\tfont-size: 0.8125rem;\n\t\t\tfont-weight: 600;\n\t\t\tbackground: var(--purple);\n\t\t\tcolor: var(--bg);\n\t\t\tborder: none;\n\t\t\tborder-radius: 5px;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 150ms;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t.btn-merge .icon {\n\t\t\twidth: 14px;\n\t\t\theight: 14px;\n\t\t}\n\n\t\t.btn-merge:hover {\n\t\t\tfilter: brightness(1.1);\n\t\t}\n\n\t\t/* Row 2: Hover row styling */\n\t\t.context-row-hover {\n\t\t\tbackground: rgba(125, 211, 252, 0.05);\n\t\t\tborder: 1px solid rgba(125, 211, 252, 0.15);\n\t\t\tjustify-content: flex-start;\n\t\t\t/* Keep content left-aligned */\n\t\t\tgap: 0.5rem;\n\t\t}\n\n\t\t.context-row-hover .context-host {\n\t\t\tcolor: var(--cyan);\n\t\t\tfont-weight: 600;\n\t\t\tfont-family: 'JetBrains Mono', 'Fira Code', monospace;\n\t\t\tfont-size: 0.875rem;\n\t\t\twhite-space: nowrap;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.context-row-hover .context-host::after {\n\t\t\tcontent: ':';\n\t\t}\n\n\t\t.context-row-hover .context-description {\n\t\t\tcolor: var(--fg);\n\t\t\tfont-size: 0.875rem;\n\t\t}\n\n\t\t/* Row 3: Selection row styling */\n\t\t.context-row-selection {\n\t\t\tbackground: rgba(122, 162, 247, 0.08);\n\t\t\tborder: 1px solid rgba(122, 162, 247, 0.2);\n\t\t}\n\n\t\t.context-row-selection .icon-check {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tcolor: var(--blue);\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t/* Reboot-required row (stale kernel after switch) */\n\t\t.context-row-reboot {\n\t\t\tbackground: rgba(224, 175, 104, 0.08);\n\t\t\tborder: 1px solid rgba(224, 175, 104, 0.25);\n\t\t}\n\n\t\t.context-row-reboot .icon-reboot {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tcolor: var(--yellow);\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.context-row-reboot .reboot-detail {\n\t\t\tcolor: var(--fg-muted);\n\t\t\tfont-size: 0.8125rem;\n\t\t\toverflow: hidden;\n\t\t\ttext-overflow: ellipsis;\n\t\t\twhite-space: nowrap;\n\t\t}\n\n\t\t/* P7240: Timeout notification row */\n\t\t.context-row-timeout {\n\t\t\tbackground: rgba(234, 179, 8, 0.12);\n\t\t\tborder: 1px solid rgba(234, 179, 8, 0.3);\n\t\t}\n\n\t\t.context-row-timeout .timeout-icon {\n\t\t\twidth: 16px;\n\t\t\theight: 16px;\n\t\t\tcolor: var(--yellow);\n\t\t\tflex-shrink: 0;\n\t\t\tanimation: pulse-warning 1.5s ease-in-out infinite;\n\t\t}\n\n\t\t.context-row-timeout .timeout-host {\n\t\t\tfont-weight: 600;\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t.context-row-timeout .timeout-detail {\n\t\t\tcolor: var(--fg-dark);\n\t\t\tfont-size: 0.875rem;\n\t\t}\n\n\t\t.context-row-timeout .timeout-elapsed {\n\t\t\tcolor: var(--yellow);\n\t\t\tfont-weight: 600;\n\t\t\tfont-variant-numeric: tabular-nums;\n\t\t}\n\n\t\t.timeout-actions {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.5rem;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t.btn-timeout {\n\t\t\tpadding: 0.25rem 0.5rem;\n\t\t\tfont-size: 0.75rem;\n\t\t\tborder-radius: 4px;\n\t\t\tborder: 1px solid transparent;\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 0.15s ease;\n\t\t}\n\n\t\t.btn-timeout-wait {\n\t\t\tbackground: rgba(122, 162, 247, 0.15);\n\t\t\tborder-color: rgba(122, 162, 247, 0.3);\n\t\t\tcolor: var(--blue);\n\t\t}\n\n\t\t.btn-timeout-wait:hover {\n\t\t\tbackground: rgba(122, 162, 247, 0.25);\n\t\t}\n\n\t\t.btn-timeout-kill {\n\t\t\tbackground: rgba(239, 68, 68, 0.15);\n\t\t\tborder-color: rgba(239, 68, 68, 0.3);\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.btn-timeout-kill:hover {\n\t\t\tbackground: rgba(239, 68, 68, 0.25);\n\t\t}\n\n\t\t.btn-timeout-ignore {\n\t\t\tbackground: transparent;\n\t\t\tcolor: var(--fg-dark);\n\t\t}\n\n\t\t.btn-timeout-ignore:hover {\n\t\t\tcolor: var(--fg);\n\t\t}\n\n\t\t@keyframes pulse-warning {\n\t\t\t0%, 100% { opacity: 0.6; transform: scale(1); }\n\t\t\t50% { opacity: 1; transform: scale(1.1); }\n\t\t}\n\n\t\t/* Actions in selection row */\n\t\t.context-actions {\n\t\t\tdisplay: flex;\n\t\t\tgap: 0.5rem;\n\t\t\tflex-wrap: wrap;\n\t\t\talign-items: center;\n\t\t}\n\n\t\t@media (max-width: 768px) {\n\t\t\t.context-row {\n\t\t\t\tflex-direction: column;\n\t\t\t\talign-items: stretch;\n\t\t\t\tgap: 0.5rem;\n\t\t\t}\n\n\t\t\t.context-actions {\n\t\t\t\tjustify-content: center;\n\t\t\t}\n\t\t}\n\n\t\t/* Context Buttons */\n\t\t.btn-context {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.375rem;\n\t\t\tpadding: 0.4rem 0.75rem;\n\t\t\tfont-size: 0.8125rem;\n\t\t\tfont-weight: 500;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--border);\n\t\t\tborder-radius: 6px;\n\t\t\tcolor: var(--fg);\n\t\t\tcursor: pointer;\n\t\t\ttransition: all 150ms ease;\n\t\t\tfont-family: inherit;\n\t\t}\n\n\t\t.btn-context:hover:not(:disabled) {\n\t\t\tbackground: var(--bg-highlight);\n\t\t\tborder-color: var(--fg-dim);\n\t\t}\n\n\t\t.btn-context:active:not(:disabled) {\n\t\t\tbackground: var(--bg);\n\t\t}\n\n\t\t.btn-context:disabled {\n\t\t\topacity: 0.4;\n\t\t\tcursor: not-allowed;\n\t\t}\n\n\t\t.btn-context .icon {\n\t\t\twidth: 14px;\n\t\t\theight: 14px;\n\t\t}\n\n\t\t.btn-context-danger:hover:not(:disabled) {\n\t\t\tborder-color: var(--red);\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t@media (max-width: 480px) {\n\t\t\t.btn-context .btn-label {\n\t\t\t\tdisplay: none;\n\t\t\t}\n\n\t\t\t.btn-context {\n\t\t\t\tpadding: 0.5rem;\n\t\t\t}\n\n\t\t\t.btn-context .icon {\n\t\t\t\twidth: 18px;\n\t\t\t\theight: 18px;\n\t\t\t}\n\t\t}\n\n\t\t/* DO ALL Button - matches other context buttons but green accent */\n\t\t.btn-do-all {\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid var(--green);\n\t\t\tcolor: var(--green);\n\t\t\t/* Same padding as .btn-context */\n\t\t\tpadding: 0.4rem 0.75rem;\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.btn-do-all:hover:not(:disabled) {\n\t\t\tbackground: var(--green);\n\t\t\tcolor: var(--bg);\n\t\t}\n\n\t\t.btn-do-all .icon {\n\t\t\twidth: 14px;\n\t\t\theight: 14px;\n\t\t}\n\n\t\t.btn-clear {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tpadding: 0.375rem;\n\t\t\tbackground: transparent;\n\t\t\tborder: 1px solid transparent;\n\t\t\tborder-radius: 4px;\n\t\t\tcursor: pointer;\n\t\t\tcolor: var(--fg-dark);\n\t\t\ttransition: color 150ms ease, background 150ms ease;\n\t\t}\n\n\t\t.btn-clear:hover {\n\t\t\tcolor: var(--red);\n\t\t\tbackground: var(--bg-highlight);\n\t\t}\n\n\t\t.btn-clear .icon {\n\t\t\twidth: 18px;\n\t\t\theight: 18px;\n\t\t}\n\n\t\t/* Alpine.js cloak for transition elements */\n\t\t[x-cloak] {\n\t\t\tdisplay: none !important;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   P2700: COMPOSITE TYPE COLUMN\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.col-type {\n\t\t\twidth: 40px;\n\t\t\tmin-width: 40px;\n\t\t\tmax-width: 40px;\n\t\t\tpadding: 0.25rem !important;\n\t\t}\n\n\t\t.type-composite {\n\t\t\tposition: relative;\n\t\t\tdisplay: inline-block;\n\t\t\twidth: 32px;\n\t\t\theight: 24px;\n\t\t\tmargin: 0 auto;\n\t\t}\n\n\t\t/* Compact layout: LOC + OS side by side */\n\t\t.type-composite.type-compact {\n\t\t\tdisplay: inline-flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tgap: 0.25rem;\n\t\t\twidth: auto;\n\t\t\theight: auto;\n\t\t}\n\n\t\t.type-loc-icon,\n\t\t.type-os-icon {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t}\n\n\t\t.type-loc-icon .icon,\n\t\t.type-loc-icon .location-icon,\n\t\t.type-os-icon .icon,\n\t\t.type-os-icon .type-icon {\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t\topacity: 0.85;\n\t\t}\n\n\t\t/* Main device icon - fills most of the space (legacy, kept for compatibility) */\n\t\t.type-dev-main {\n\t\t\tposition: absolute;\n\t\t\tleft: 0;\n\t\t\ttop: 50%;\n\t\t\ttransform: translateY(-50%);\n\t\t\twidth: 20px;\n\t\t\theight: 20px;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tz-index: 1;\n\t\t}\n\n\t\t.type-dev-main .icon,\n\t\t.type-dev-main .device-icon {\n\t\t\twidth: 18px;\n\t\t\theight: 18px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t}\n\n\t\t/* Location icon - top-right superscript (legacy) */\n\t\t.type-loc-super {\n\t\t\tposition: absolute;\n\t\t\ttop: 1px;\n\t\t\tright: 0;\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tz-index: 2;\n\t\t}\n\n\t\t.type-loc-super .icon,\n\t\t.type-loc-super .location-icon {\n\t\t\twidth: 9px;\n\t\t\theight: 9px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t}\n\n\t\t/* OS icon - bottom-right subscript (legacy) */\n\t\t.type-os-sub {\n\t\t\tposition: absolute;\n\t\t\tbottom: 0px;\n\t\t\tright: 0;\n\t\t\twidth: 12px;\n\t\t\theight: 12px;\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tz-index: 2;\n\t\t}\n\n\t\t.type-os-sub .icon,\n\t\t.type-os-sub .type-icon {\n\t\t\twidth: 9px;\n\t\t\theight: 9px;\n\t\t\tcolor: var(--type-color, var(--fg));\n\t\t\tfill: var(--type-color, var(--fg));\n\t\t\tstroke: var(--type-color, var(--fg));\n\t\t}\n\n\t\t/* Hover effect on composite type */\n\t\t.type-composite:hover .type-dev-main .icon,\n\t\t.type-composite:hover .type-dev-main .device-icon {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.type-composite:hover .type-loc-super .icon,\n\t\t.type-composite:hover .type-loc-super .location-icon,\n\t\t.type-composite:hover .type-os-sub .icon,\n\t\t.type-composite:hover .type-os-sub .type-icon {\n\t\t\topacity: 0.9;\n\t\t}\n\n\t\t/* ═══════════════════════════════════════════════════════════════════════════\n\t\t\t   P2700: STATUS PROGRESS COLUMN\n\t\t\t   ═══════════════════════════════════════════════════════════════════════════ */\n\n\t\t.col-status {\n\t\t\tmin-width: 180px;\n\t\t\tpadding: 0.5rem !important;\n\t\t}\n\n\t\t.status-progress-cell {\n\t\t\tpadding: 0.25rem 0.5rem;\n\t\t}\n\n\t\t.status-progress {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tfont-family: 'JetBrains Mono', 'Fira Code', monospace;\n\t\t\tfont-size: 0.7rem;\n\t\t\tletter-spacing: 0.05em;\n\t\t}\n\n\t\t.progress-segment {\n\t\t\tdisplay: flex;\n\t\t\tgap: 1px;\n\t\t}\n\n\t\t.progress-dot {\n\t\t\tdisplay: inline-block;\n\t\t\twidth: 1em;\n\t\t\ttext-align: center;\n\t\t\ttransition: all 0.2s ease;\n\t\t}\n\n\t\t/* Dot states */\n\t\t.dot-pending {\n\t\t\tcolor: var(--fg-gutter);\n\t\t\topacity: 0.3;\n\t\t}\n\n\t\t.dot-complete {\n\t\t\tcolor: var(--green);\n\t\t}\n\n\t\t.dot-idle {\n\t\t\tcolor: var(--green);\n\t\t\topacity: 0.2;\n\t\t}\n\n\t\t.dot-error {\n\t\t\tcolor: var(--red);\n\t\t}\n\n\t\t.dot-in-progress {\n\t\t\tcolor: var(--cyan);\n\t\t\tanimation: shimmer 1.2s ease-in-out infinite;\n\t\t\ttext-shadow: 0 0 8px var(--cyan);\n\t\t}\n\n\t\t/* PS5-style shimmer animation */\n\t\t@keyframes shimmer {\n\t\t\t0% {\n\t\t\t\topacity: 0.4;\n\t\t\t\ttransform: scale(0.95);\n\t\t\t}\n\n\t\t\t50% {\n\t\t\t\topacity: 1;\n\t\t\t\ttransform: scale(1.05);\n\t\t\t}\n\n\t\t\t100% {\n\t\t\t\topacity: 0.4;\n\t\t\t\ttransform: scale(0.95);\n\t\t\t}\n\t\t}\n\n\t\t.tests-dash {\n\t\t\tcolor: var(--fg-gutter);\n\t\t\topacity: 0.5;\n\t\t}\n\n\t\t/* Segment hover hints */\n\t\t.progress-segment:hover .progress-dot {\n\t\t\topacity: 1;\n\t\t}\n\n\t\t.progress-segment:hover .dot-idle {\n\t\t\topacity: 0.6;\n\t\t}\n\t</style></head><body data-csrf-token=\"")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var3 string
 		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(csrfToken)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/templates/base.templ`, Line: 3120, Col: 33}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/templates/base.templ`, Line: 3250, Col: 33}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\"><!-- SVG icon definitions --><svg class=\"svg-defs\" aria-hidden=\"true\" style=\"display: none;\"><!-- OS Types --><symbol id=\"icon-nixos\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 2L2.5 7v10L12 22l9.5-5V7L12 2zm0 2.16l6.86 3.62-2.14 1.13-4.72-2.5-4.72 2.5-2.14-1.13L12 4.16zM5.5 8.84l2.14 1.13v5l4.36 2.3 4.36-2.3v-5l2.14-1.13v7.32L12 19.84l-6.5-3.68V8.84z\"></path></symbol> <symbol id=\"icon-apple\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M18.71 19.5c-.83 1.24-1.71 2.45-3.05 2.47-1.34.03-1.77-.79-3.29-.79-1.53 0-2 .77-3.27.82-1.31.05-2.3-1.32-3.14-2.53C4.25 17 2.94 12.45 4.7 9.39c.87-1.52 2.43-2.48 4.12-2.51 1.28-.02 2.5.87 3.29.87.78 0 2.26-1.07 3.81-.91.65.03 2.47.26 3.64 1.98-.09.06-2.17 1.28-2.15 3.81.03 3.02 2.65 4.03 2.68 4.04-.03.07-.42 1.44-1.38 2.83M13 3.5c.73-.83 1.94-1.46 2.94-1.5.13 1.17-.34 2.35-1.04 3.19-.69.85-1.83 1.51-2.95 1.42-.15-1.15.41-2.35 1.05-3.11z\"></path></symbol><!-- Locations --><symbol id=\"icon-cloud\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M19.35 10.04A7.49 7.49 0 0 0 12 4C9.11 4 6.6 5.64 5.35 8.04A5.994 5.994 0 0 0 0 14c0 3.31 2.69 6 6 6h13c2.76 0 5-2.24 5-5 0-2.64-2.05-4.78-4.65-4.96z\"></path></symbol> <symbol id=\"icon-home\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M10 20v-6h4v6h5v-8h3L12 3 2 12h3v8z\"></path></symbol> <symbol id=\"icon-office\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 7V3H2v18h20V7H12zM6 19H4v-2h2v2zm0-4H4v-2h2v2zm0-4H4V9h2v2zm0-4H4V5h2v2zm4 12H8v-2h2v2zm0-4H8v-2h2v2zm0-4H8V9h2v2zm0-4H8V5h2v2zm10 12h-8v-2h2v-2h-2v-2h2v-2h-2V9h8v10zm-2-8h-2v2h2v-2zm0 4h-2v2h2v-2z\"></path></symbol><!-- Device Types --><symbol id=\"icon-server\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M2 2h20v6H2V2zm2 2v2h16V4H4zm-2 8h20v6H2v-6zm2 2v2h16v-2H4zm-2 8h20v2H2v-2z\"></path></symbol> <symbol id=\"icon-desktop\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M21 2H3c-1.1 0-2 .9-2 2v12c0 1.1.9 2 2 2h7v2H8v2h8v-2h-2v-2h7c1.1 0 2-.9 2-2V4c0-1.1-.9-2-2-2zm0 14H3V4h18v12z\"></path></symbol> <symbol id=\"icon-laptop\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M20 18c1.1 0 2-.9 2-2V6c0-1.1-.9-2-2-2H4c-1.1 0-2 .9-2 2v10c0 1.1.9 2 2 2H0v2h24v-2h-4zM4 6h16v10H4V6z\"></path></symbol> <symbol id=\"icon-game\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M21 6H3c-1.1 0-2 .9-2 2v8c0 1.1.9 2 2 2h18c1.1 0 2-.9 2-2V8c0-1.1-.9-2-2-2zm-10 7H8v3H6v-3H3v-2h3V8h2v3h3v2zm4.5 2c-.83 0-1.5-.67-1.5-1.5s.67-1.5 1.5-1.5 1.5.67 1.5 1.5-.67 1.5-1.5 1.5zm4-3c-.83 0-1.5-.67-1.5-1.5S18.67 9 19.5 9s1.5.67 1.5 1.5-.67 1.5-1.5 1.5z\"></path></symbol><!-- Actions --><symbol id=\"icon-download\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M19 9h-4V3H9v6H5l7 7 7-7zM5 18v2h14v-2H5z\"></path></symbol> <symbol id=\"icon-refresh\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M17.65 6.35A7.958 7.958 0 0 0 12 4c-4.42 0-7.99 3.58-7.99 8s3.57 8 7.99 8c3.73 0 6.84-2.55 7.73-6h-2.08A5.99 5.99 0 0 1 12 18c-3.31 0-6-2.69-6-6s2.69-6 6-6c1.66 0 3.14.69 4.22 1.78L13 11h7V4l-2.35 2.35z\"></path></symbol> <symbol id=\"icon-flask\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M6 22a3 3 0 0 1-3-3c0-.6.18-1.16.5-1.63L9 7.81V6a1 1 0 0 1-1-1V4a2 2 0 0 1 2-2h4a2 2 0 0 1 2 2v1a1 1 0 0 1-1 1v1.81l5.5 9.56c.32.47.5 1.03.5 1.63a3 3 0 0 1-3 3H6zm1.77-6h8.46l-3.23-5.6V6h-2v4.4L7.77 16z\"></path></symbol> <symbol id=\"icon-stop\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M6 6h12v12H6z\"></path></symbol><!-- P6900: Power/Reboot icon --><symbol id=\"icon-power\" viewBox=\"0 0 24 24\"><path fill=\"none\" stroke=\"currentColor\" stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M12 2v10m6.4-5.4a9 9 0 1 1-12.8 0\"></path></symbol><!-- P7300: Robot icon for Agent compartment --><symbol id=\"icon-robot\" viewBox=\"0 0 24 24\"><path fill=\"none\" stroke=\"currentColor\" stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M12 2v2m0 0a3 3 0 0 0-3 3v1H6a2 2 0 0 0-2 2v8a2 2 0 0 0 2 2h12a2 2 0 0 0 2-2v-8a2 2 0 0 0-2-2h-3V7a3 3 0 0 0-3-3z\"></path> <circle fill=\"currentColor\" cx=\"9\" cy=\"13\" r=\"1.5\"></circle> <circle fill=\"currentColor\" cx=\"15\" cy=\"13\" r=\"1.5\"></circle> <path fill=\"none\" stroke=\"currentColor\" stroke-linecap=\"round\" stroke-width=\"2\" d=\"M9 17h6\"></path></symbol> <symbol id=\"icon-plus\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M19 13h-6v6h-2v-6H5v-2h6V5h2v6h6v2z\"></path></symbol> <symbol id=\"icon-trash\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M6 19c0 1.1.9 2 2 2h8c1.1 0 2-.9 2-2V7H6v12zM19 4h-3.5l-1-1h-5l-1 1H5v2h14V4z\"></path></symbol> <symbol id=\"icon-more\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 8c1.1 0 2-.9 2-2s-.9-2-2-2-2 .9-2 2 .9 2 2 2zm0 2c-1.1 0-2 .9-2 2s.9 2 2 2 2-.9 2-2-.9-2-2-2zm0 6c-1.1 0-2 .9-2 2s.9 2 2 2 2-.9 2-2-.9-2-2-2z\"></path></symbol><!-- Fleet/grid icon for bulk actions --><symbol id=\"icon-fleet\" viewBox=\"0 0 24 24\"><rect x=\"3\" y=\"3\" width=\"7\" height=\"7\" rx=\"1\" fill=\"currentColor\"></rect> <rect x=\"14\" y=\"3\" width=\"7\" height=\"7\" rx=\"1\" fill=\"currentColor\"></rect> <rect x=\"3\" y=\"14\" width=\"7\" height=\"7\" rx=\"1\" fill=\"currentColor\"></rect> <rect x=\"14\" y=\"14\" width=\"7\" height=\"7\" rx=\"1\" fill=\"currentColor\"></rect></symbol> <symbol id=\"icon-check\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M9 16.17L4.83 12l-1.42 1.41L9 19 21 7l-1.41-1.41z\"></path></symbol> <symbol id=\"icon-chevron\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M7.41 8.59L12 13.17l4.59-4.58L18 10l-6 6-6-6 1.41-1.41z\"></path></symbol> <symbol id=\"icon-file\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M14 2H6c-1.1 0-1.99.9-1.99 2L4 20c0 1.1.89 2 1.99 2H18c1.1 0 2-.9 2-2V8l-6-6zm2 16H8v-2h8v2zm0-4H8v-2h8v2zm-3-5V3.5L18.5 9H13z\"></path></symbol><!-- Update Status Icons (P5000) --><!-- Git icon - branch with connecting lines --><symbol id=\"icon-git-branch\" viewBox=\"0 0 24 24\"><circle cx=\"7\" cy=\"5\" r=\"2\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <circle cx=\"7\" cy=\"19\" r=\"2\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <circle cx=\"17\" cy=\"12\" r=\"2\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle><!-- Vertical line from top to bottom circle --><path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M7 7v10\"></path><!-- Branch line from main to side circle --><path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M7 9c0 2 3 3 8 3\"></path></symbol><!-- P2500: Git pull request icon for PR section in context bar --><symbol id=\"icon-git-pull-request\" viewBox=\"0 0 24 24\"><circle cx=\"6\" cy=\"6\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <circle cx=\"6\" cy=\"18\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M6 9v6\"></path> <circle cx=\"18\" cy=\"18\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M18 9v6M18 9a3 3 0 0 0-3-3h-4\"></path></symbol><!-- P2500: Git merge icon for PR merge button --><symbol id=\"icon-git-merge\" viewBox=\"0 0 24 24\"><circle cx=\"18\" cy=\"18\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <circle cx=\"6\" cy=\"6\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M6 21V9a9 9 0 0 0 9 9\"></path></symbol> <symbol id=\"icon-lock\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M18 8h-1V6c0-2.76-2.24-5-5-5S7 3.24 7 6v2H6c-1.1 0-2 .9-2 2v10c0 1.1.9 2 2 2h12c1.1 0 2-.9 2-2V10c0-1.1-.9-2-2-2zm-6 9c-1.1 0-2-.9-2-2s.9-2 2-2 2 .9 2 2-.9 2-2 2zm3.1-9H8.9V6c0-1.71 1.39-3.1 3.1-3.1 1.71 0 3.1 1.39 3.1 3.1v2z\"></path></symbol><!-- Metrics --><symbol id=\"icon-cpu\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M6 18h12V6H6v12zm4-8h4v4h-4v-4zM1 9h2V7H1v2zm0 4h2v-2H1v2zm0 4h2v-2H1v2zm0-12h2V3H1v2zm20 8h2v-2h-2v2zm0 4h2v-2h-2v2zM21 5h2V3h-2v2zm0 12h2v-2h-2v2zm0 4h2v-2h-2v2zM9 1v2h2V1H9zm4 0v2h2V1h-2zm0 20v2h2v-2h-2zM9 21v2h2v-2H9zm8 0v2h2v-2h-2zM5 1v2h2V1H5zM5 21v2h2v-2H5zM17 1v2h2V1h-2z\"></path></symbol> <symbol id=\"icon-ram\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M2 7v9a2 2 0 0 0 2 2h16a2 2 0 0 0 2-2V7a2 2 0 0 0-2-2H4a2 2 0 0 0-2 2zm3 2h2v4H5V9zm4 0h2v4H9V9zm4 0h2v4h-2V9zm4 0h2v4h-2V9z\"></path></symbol><!-- Branding --><symbol id=\"icon-github\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 2A10 10 0 0 0 2 12c0 4.42 2.87 8.17 6.84 9.5.5.08.66-.23.66-.5v-1.69c-2.77.6-3.36-1.34-3.36-1.34-.46-1.16-1.11-1.47-1.11-1.47-.91-.62.07-.6.07-.6 1 .07 1.53 1.03 1.53 1.03.87 1.52 2.34 1.07 2.91.83.09-.65.35-1.09.63-1.34-2.22-.25-4.55-1.11-4.55-4.92 0-1.11.38-2 1.03-2.71-.1-.25-.45-1.29.1-2.64 0 0 .84-.27 2.75 1.02.79-.22 1.65-.33 2.5-.33.85 0 1.71.11 2.5.33 1.91-1.29 2.75-1.02 2.75-1.02.55 1.35.2 2.39.1 2.64.65.71 1.03 1.6 1.03 2.71 0 3.82-2.34 4.66-4.57 4.91.36.31.69.92.69 1.85V21c0 .27.16.59.67.5C19.14 20.16 22 16.42 22 12A10 10 0 0 0 12 2z\"></path></symbol> <symbol id=\"icon-heart\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 21.35l-1.45-1.32C5.4 15.36 2 12.28 2 8.5 2 5.42 4.42 3 7.5 3c1.74 0 3.41.81 4.5 2.09C13.09 3.81 14.76 3 16.5 3 19.58 3 22 5.42 22 8.5c0 3.78-3.4 6.86-8.55 11.54L12 21.35z\"></path></symbol> <symbol id=\"icon-license\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 1L3 5v6c0 5.55 3.84 10.74 9 12 5.16-1.26 9-6.45 9-12V5l-9-4zm0 10.99h7c-.53 4.12-3.28 7.79-7 8.94V12H5V6.3l7-3.11v8.8z\"></path></symbol><!-- P1030: Selection icons --><symbol id=\"icon-square\" viewBox=\"0 0 24 24\"><rect x=\"3\" y=\"3\" width=\"18\" height=\"18\" rx=\"2\" ry=\"2\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></rect></symbol> <symbol id=\"icon-check-square\" viewBox=\"0 0 24 24\"><rect x=\"3\" y=\"3\" width=\"18\" height=\"18\" rx=\"2\" ry=\"2\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></rect> <polyline points=\"9 11 12 14 22 4\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></polyline></symbol> <symbol id=\"icon-minus-square\" viewBox=\"0 0 24 24\"><rect x=\"3\" y=\"3\" width=\"18\" height=\"18\" rx=\"2\" ry=\"2\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></rect> <line x1=\"8\" y1=\"12\" x2=\"16\" y2=\"12\" stroke=\"currentColor\" stroke-width=\"2\"></line></symbol><!-- P1015: X icon for clear selection --><symbol id=\"icon-x\" viewBox=\"0 0 24 24\"><line x1=\"18\" y1=\"6\" x2=\"6\" y2=\"18\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"6\" y1=\"6\" x2=\"18\" y2=\"18\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line></symbol><!-- P1010: Action Bar icons --><symbol id=\"icon-play\" viewBox=\"0 0 24 24\"><polygon points=\"5 3 19 12 5 21 5 3\" fill=\"currentColor\"></polygon></symbol><!-- P1040: Alert triangle for dependency dialog --><symbol id=\"icon-alert-triangle\" viewBox=\"0 0 24 24\"><path d=\"M10.29 3.86L1.82 18a2 2 0 0 0 1.71 3h16.94a2 2 0 0 0 1.71-3L13.71 3.86a2 2 0 0 0-3.42 0z\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></path> <line x1=\"12\" y1=\"9\" x2=\"12\" y2=\"13\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"12\" y1=\"17\" x2=\"12.01\" y2=\"17\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line></symbol><!-- P1060: Dropdown menu icons --><symbol id=\"icon-copy\" viewBox=\"0 0 24 24\"><rect x=\"9\" y=\"9\" width=\"13\" height=\"13\" rx=\"2\" ry=\"2\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></rect> <path d=\"M5 15H4a2 2 0 0 1-2-2V4a2 2 0 0 1 2-2h9a2 2 0 0 1 2 2v1\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></path></symbol> <symbol id=\"icon-terminal\" viewBox=\"0 0 24 24\"><polyline points=\"4 17 10 11 4 5\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></polyline> <line x1=\"12\" y1=\"19\" x2=\"20\" y2=\"19\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line></symbol> <symbol id=\"icon-more-vertical\" viewBox=\"0 0 24 24\"><circle cx=\"12\" cy=\"12\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"12\" cy=\"5\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"12\" cy=\"19\" r=\"1.5\" fill=\"currentColor\"></circle></symbol> <symbol id=\"icon-loader\" viewBox=\"0 0 24 24\"><line x1=\"12\" y1=\"2\" x2=\"12\" y2=\"6\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"12\" y1=\"18\" x2=\"12\" y2=\"22\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"4.93\" y1=\"4.93\" x2=\"7.76\" y2=\"7.76\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"16.24\" y1=\"16.24\" x2=\"19.07\" y2=\"19.07\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"2\" y1=\"12\" x2=\"6\" y2=\"12\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"18\" y1=\"12\" x2=\"22\" y2=\"12\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"4.93\" y1=\"19.07\" x2=\"7.76\" y2=\"16.24\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"16.24\" y1=\"7.76\" x2=\"19.07\" y2=\"4.93\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line></symbol> <symbol id=\"icon-refresh-cw\" viewBox=\"0 0 24 24\"><polyline points=\"23 4 23 10 17 10\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></polyline> <polyline points=\"1 20 1 14 7 14\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></polyline> <path d=\"M3.51 9a9 9 0 0 1 14.85-3.36L23 10M1 14l4.64 4.36A9 9 0 0 0 20.49 15\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></path></symbol><!-- P2950: Palette icon for color picker --><symbol id=\"icon-palette\" viewBox=\"0 0 24 24\"><circle cx=\"12\" cy=\"12\" r=\"10\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></circle> <circle cx=\"12\" cy=\"7\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"7.5\" cy=\"10.5\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"8.5\" cy=\"15\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"16.5\" cy=\"10.5\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"15\" cy=\"15\" r=\"2\" fill=\"currentColor\"></circle></symbol></svg><div class=\"container\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\"><!-- SVG icon definitions --><svg class=\"svg-defs\" aria-hidden=\"true\" style=\"display: none;\"><!-- OS Types --><symbol id=\"icon-nixos\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 2L2.5 7v10L12 22l9.5-5V7L12 2zm0 2.16l6.86 3.62-2.14 1.13-4.72-2.5-4.72 2.5-2.14-1.13L12 4.16zM5.5 8.84l2.14 1.13v5l4.36 2.3 4.36-2.3v-5l2.14-1.13v7.32L12 19.84l-6.5-3.68V8.84z\"></path></symbol> <symbol id=\"icon-apple\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M18.71 19.5c-.83 1.24-1.71 2.45-3.05 2.47-1.34.03-1.77-.79-3.29-.79-1.53 0-2 .77-3.27.82-1.31.05-2.3-1.32-3.14-2.53C4.25 17 2.94 12.45 4.7 9.39c.87-1.52 2.43-2.48 4.12-2.51 1.28-.02 2.5.87 3.29.87.78 0 2.26-1.07 3.81-.91.65.03 2.47.26 3.64 1.98-.09.06-2.17 1.28-2.15 3.81.03 3.02 2.65 4.03 2.68 4.04-.03.07-.42 1.44-1.38 2.83M13 3.5c.73-.83 1.94-1.46 2.94-1.5.13 1.17-.34 2.35-1.04 3.19-.69.85-1.83 1.51-2.95 1.42-.15-1.15.41-2.35 1.05-3.11z\"></path></symbol><!-- Locations --><symbol id=\"icon-cloud\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M19.35 10.04A7.49 7.49 0 0 0 12 4C9.11 4 6.6 5.64 5.35 8.04A5.994 5.994 0 0 0 0 14c0 3.31 2.69 6 6 6h13c2.76 0 5-2.24 5-5 0-2.64-2.05-4.78-4.65-4.96z\"></path></symbol> <symbol id=\"icon-home\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M10 20v-6h4v6h5v-8h3L12 3 2 12h3v8z\"></path></symbol> <symbol id=\"icon-office\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 7V3H2v18h20V7H12zM6 19H4v-2h2v2zm0-4H4v-2h2v2zm0-4H4V9h2v2zm0-4H4V5h2v2zm4 12H8v-2h2v2zm0-4H8v-2h2v2zm0-4H8V9h2v2zm0-4H8V5h2v2zm10 12h-8v-2h2v-2h-2v-2h2v-2h-2V9h8v10zm-2-8h-2v2h2v-2zm0 4h-2v2h2v-2z\"></path></symbol><!-- Device Types --><symbol id=\"icon-server\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M2 2h20v6H2V2zm2 2v2h16V4H4zm-2 8h20v6H2v-6zm2 2v2h16v-2H4zm-2 8h20v2H2v-2z\"></path></symbol> <symbol id=\"icon-desktop\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M21 2H3c-1.1 0-2 .9-2 2v12c0 1.1.9 2 2 2h7v2H8v2h8v-2h-2v-2h7c1.1 0 2-.9 2-2V4c0-1.1-.9-2-2-2zm0 14H3V4h18v12z\"></path></symbol> <symbol id=\"icon-laptop\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M20 18c1.1 0 2-.9 2-2V6c0-1.1-.9-2-2-2H4c-1.1 0-2 .9-2 2v10c0 1.1.9 2 2 2H0v2h24v-2h-4zM4 6h16v10H4V6z\"></path></symbol> <symbol id=\"icon-game\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M21 6H3c-1.1 0-2 .9-2 2v8c0 1.1.9 2 2 2h18c1.1 0 2-.9 2-2V8c0-1.1-.9-2-2-2zm-10 7H8v3H6v-3H3v-2h3V8h2v3h3v2zm4.5 2c-.83 0-1.5-.67-1.5-1.5s.67-1.5 1.5-1.5 1.5.67 1.5 1.5-.67 1.5-1.5 1.5zm4-3c-.83 0-1.5-.67-1.5-1.5S18.67 9 19.5 9s1.5.67 1.5 1.5-.67 1.5-1.5 1.5z\"></path></symbol><!-- Actions --><symbol id=\"icon-download\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M19 9h-4V3H9v6H5l7 7 7-7zM5 18v2h14v-2H5z\"></path></symbol> <symbol id=\"icon-refresh\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M17.65 6.35A7.958 7.958 0 0 0 12 4c-4.42 0-7.99 3.58-7.99 8s3.57 8 7.99 8c3.73 0 6.84-2.55 7.73-6h-2.08A5.99 5.99 0 0 1 12 18c-3.31 0-6-2.69-6-6s2.69-6 6-6c1.66 0 3.14.69 4.22 1.78L13 11h7V4l-2.35 2.35z\"></path></symbol> <symbol id=\"icon-flask\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M6 22a3 3 0 0 1-3-3c0-.6.18-1.16.5-1.63L9 7.81V6a1 1 0 0 1-1-1V4a2 2 0 0 1 2-2h4a2 2 0 0 1 2 2v1a1 1 0 0 1-1 1v1.81l5.5 9.56c.32.47.5 1.03.5 1.63a3 3 0 0 1-3 3H6zm1.77-6h8.46l-3.23-5.6V6h-2v4.4L7.77 16z\"></path></symbol> <symbol id=\"icon-stop\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M6 6h12v12H6z\"></path></symbol><!-- P6900: Power/Reboot icon --><symbol id=\"icon-power\" viewBox=\"0 0 24 24\"><path fill=\"none\" stroke=\"currentColor\" stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M12 2v10m6.4-5.4a9 9 0 1 1-12.8 0\"></path></symbol><!-- P7300: Robot icon for Agent compartment --><symbol id=\"icon-robot\" viewBox=\"0 0 24 24\"><path fill=\"none\" stroke=\"currentColor\" stroke-linecap=\"round\" stroke-linejoin=\"round\" stroke-width=\"2\" d=\"M12 2v2m0 0a3 3 0 0 0-3 3v1H6a2 2 0 0 0-2 2v8a2 2 0 0 0 2 2h12a2 2 0 0 0 2-2v-8a2 2 0 0 0-2-2h-3V7a3 3 0 0 0-3-3z\"></path> <circle fill=\"currentColor\" cx=\"9\" cy=\"13\" r=\"1.5\"></circle> <circle fill=\"currentColor\" cx=\"15\" cy=\"13\" r=\"1.5\"></circle> <path fill=\"none\" stroke=\"currentColor\" stroke-linecap=\"round\" stroke-width=\"2\" d=\"M9 17h6\"></path></symbol> <symbol id=\"icon-plus\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M19 13h-6v6h-2v-6H5v-2h6V5h2v6h6v2z\"></path></symbol> <symbol id=\"icon-trash\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M6 19c0 1.1.9 2 2 2h8c1.1 0 2-.9 2-2V7H6v12zM19 4h-3.5l-1-1h-5l-1 1H5v2h14V4z\"></path></symbol> <symbol id=\"icon-more\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 8c1.1 0 2-.9 2-2s-.9-2-2-2-2 .9-2 2 .9 2 2 2zm0 2c-1.1 0-2 .9-2 2s.9 2 2 2 2-.9 2-2-.9-2-2-2zm0 6c-1.1 0-2 .9-2 2s.9 2 2 2 2-.9 2-2-.9-2-2-2z\"></path></symbol><!-- Fleet/grid icon for bulk actions --><symbol id=\"icon-fleet\" viewBox=\"0 0 24 24\"><rect x=\"3\" y=\"3\" width=\"7\" height=\"7\" rx=\"1\" fill=\"currentColor\"></rect> <rect x=\"14\" y=\"3\" width=\"7\" height=\"7\" rx=\"1\" fill=\"currentColor\"></rect> <rect x=\"3\" y=\"14\" width=\"7\" height=\"7\" rx=\"1\" fill=\"currentColor\"></rect> <rect x=\"14\" y=\"14\" width=\"7\" height=\"7\" rx=\"1\" fill=\"currentColor\"></rect></symbol> <symbol id=\"icon-check\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M9 16.17L4.83 12l-1.42 1.41L9 19 21 7l-1.41-1.41z\"></path></symbol> <symbol id=\"icon-chevron\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M7.41 8.59L12 13.17l4.59-4.58L18 10l-6 6-6-6 1.41-1.41z\"></path></symbol> <symbol id=\"icon-file\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M14 2H6c-1.1 0-1.99.9-1.99 2L4 20c0 1.1.89 2 1.99 2H18c1.1 0 2-.9 2-2V8l-6-6zm2 16H8v-2h8v2zm0-4H8v-2h8v2zm-3-5V3.5L18.5 9H13z\"></path></symbol><!-- Update Status Icons (P5000) --><!-- Git icon - branch with connecting lines --><symbol id=\"icon-git-branch\" viewBox=\"0 0 24 24\"><circle cx=\"7\" cy=\"5\" r=\"2\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <circle cx=\"7\" cy=\"19\" r=\"2\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <circle cx=\"17\" cy=\"12\" r=\"2\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle><!-- Vertical line from top to bottom circle --><path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M7 7v10\"></path><!-- Branch line from main to side circle --><path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M7 9c0 2 3 3 8 3\"></path></symbol><!-- P2500: Git pull request icon for PR section in context bar --><symbol id=\"icon-git-pull-request\" viewBox=\"0 0 24 24\"><circle cx=\"6\" cy=\"6\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <circle cx=\"6\" cy=\"18\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M6 9v6\"></path> <circle cx=\"18\" cy=\"18\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M18 9v6M18 9a3 3 0 0 0-3-3h-4\"></path></symbol><!-- P2500: Git merge icon for PR merge button --><symbol id=\"icon-git-merge\" viewBox=\"0 0 24 24\"><circle cx=\"18\" cy=\"18\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <circle cx=\"6\" cy=\"6\" r=\"3\" stroke=\"currentColor\" stroke-width=\"2\" fill=\"none\"></circle> <path stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" d=\"M6 21V9a9 9 0 0 0 9 9\"></path></symbol> <symbol id=\"icon-lock\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M18 8h-1V6c0-2.76-2.24-5-5-5S7 3.24 7 6v2H6c-1.1 0-2 .9-2 2v10c0 1.1.9 2 2 2h12c1.1 0 2-.9 2-2V10c0-1.1-.9-2-2-2zm-6 9c-1.1 0-2-.9-2-2s.9-2 2-2 2 .9 2 2-.9 2-2 2zm3.1-9H8.9V6c0-1.71 1.39-3.1 3.1-3.1 1.71 0 3.1 1.39 3.1 3.1v2z\"></path></symbol><!-- Metrics --><symbol id=\"icon-cpu\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M6 18h12V6H6v12zm4-8h4v4h-4v-4zM1 9h2V7H1v2zm0 4h2v-2H1v2zm0 4h2v-2H1v2zm0-12h2V3H1v2zm20 8h2v-2h-2v2zm0 4h2v-2h-2v2zM21 5h2V3h-2v2zm0 12h2v-2h-2v2zm0 4h2v-2h-2v2zM9 1v2h2V1H9zm4 0v2h2V1h-2zm0 20v2h2v-2h-2zM9 21v2h2v-2H9zm8 0v2h2v-2h-2zM5 1v2h2V1H5zM5 21v2h2v-2H5zM17 1v2h2V1h-2z\"></path></symbol> <symbol id=\"icon-ram\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M2 7v9a2 2 0 0 0 2 2h16a2 2 0 0 0 2-2V7a2 2 0 0 0-2-2H4a2 2 0 0 0-2 2zm3 2h2v4H5V9zm4 0h2v4H9V9zm4 0h2v4h-2V9zm4 0h2v4h-2V9z\"></path></symbol> <symbol id=\"icon-disk\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M4 5a2 2 0 0 1 2-2h12a2 2 0 0 1 2 2v14a2 2 0 0 1-2 2H6a2 2 0 0 1-2-2V5zm2 9v5h12v-5H6zm9 1.5a1 1 0 1 1 0 2 1 1 0 0 1 0-2zM6 5v7h12V5H6z\"></path></symbol> <symbol id=\"icon-services\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 15.5A3.5 3.5 0 0 1 8.5 12 3.5 3.5 0 0 1 12 8.5a3.5 3.5 0 0 1 3.5 3.5 3.5 3.5 0 0 1-3.5 3.5m7.43-2.53c.04-.32.07-.64.07-.97 0-.33-.03-.66-.07-1l2.11-1.63c.19-.15.24-.42.12-.64l-2-3.46c-.12-.22-.39-.31-.61-.22l-2.49 1c-.52-.39-1.06-.73-1.69-.98l-.37-2.65A.506.506 0 0 0 14 2h-4c-.25 0-.46.18-.5.42l-.37 2.65c-.63.25-1.17.59-1.69.98l-2.49-1c-.22-.09-.49 0-.61.22l-2 3.46c-.13.22-.07.49.12.64L4.57 11c-.04.34-.07.67-.07 1 0 .33.03.65.07.97l-2.11 1.66c-.19.15-.25.42-.12.64l2 3.46c.12.22.39.3.61.22l2.49-1.01c.52.4 1.06.74 1.69.99l.37 2.65c.04.24.25.42.5.42h4c.25 0 .46-.18.5-.42l.37-2.65c.63-.26 1.17-.59 1.69-.99l2.49 1.01c.22.08.49 0 .61-.22l2-3.46c.12-.22.07-.49-.12-.64l-2.11-1.66z\"></path></symbol><!-- Branding --><symbol id=\"icon-github\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 2A10 10 0 0 0 2 12c0 4.42 2.87 8.17 6.84 9.5.5.08.66-.23.66-.5v-1.69c-2.77.6-3.36-1.34-3.36-1.34-.46-1.16-1.11-1.47-1.11-1.47-.91-.62.07-.6.07-.6 1 .07 1.53 1.03 1.53 1.03.87 1.52 2.34 1.07 2.91.83.09-.65.35-1.09.63-1.34-2.22-.25-4.55-1.11-4.55-4.92 0-1.11.38-2 1.03-2.71-.1-.25-.45-1.29.1-2.64 0 0 .84-.27 2.75 1.02.79-.22 1.65-.33 2.5-.33.85 0 1.71.11 2.5.33 1.91-1.29 2.75-1.02 2.75-1.02.55 1.35.2 2.39.1 2.64.65.71 1.03 1.6 1.03 2.71 0 3.82-2.34 4.66-4.57 4.91.36.31.69.92.69 1.85V21c0 .27.16.59.67.5C19.14 20.16 22 16.42 22 12A10 10 0 0 0 12 2z\"></path></symbol> <symbol id=\"icon-heart\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 21.35l-1.45-1.32C5.4 15.36 2 12.28 2 8.5 2 5.42 4.42 3 7.5 3c1.74 0 3.41.81 4.5 2.09C13.09 3.81 14.76 3 16.5 3 19.58 3 22 5.42 22 8.5c0 3.78-3.4 6.86-8.55 11.54L12 21.35z\"></path></symbol> <symbol id=\"icon-license\" viewBox=\"0 0 24 24\"><path fill=\"currentColor\" d=\"M12 1L3 5v6c0 5.55 3.84 10.74 9 12 5.16-1.26 9-6.45 9-12V5l-9-4zm0 10.99h7c-.53 4.12-3.28 7.79-7 8.94V12H5V6.3l7-3.11v8.8z\"></path></symbol><!-- P1030: Selection icons --><symbol id=\"icon-square\" viewBox=\"0 0 24 24\"><rect x=\"3\" y=\"3\" width=\"18\" height=\"18\" rx=\"2\" ry=\"2\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></rect></symbol> <symbol id=\"icon-check-square\" viewBox=\"0 0 24 24\"><rect x=\"3\" y=\"3\" width=\"18\" height=\"18\" rx=\"2\" ry=\"2\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></rect> <polyline points=\"9 11 12 14 22 4\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></polyline></symbol> <symbol id=\"icon-minus-square\" viewBox=\"0 0 24 24\"><rect x=\"3\" y=\"3\" width=\"18\" height=\"18\" rx=\"2\" ry=\"2\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></rect> <line x1=\"8\" y1=\"12\" x2=\"16\" y2=\"12\" stroke=\"currentColor\" stroke-width=\"2\"></line></symbol><!-- P1015: X icon for clear selection --><symbol id=\"icon-x\" viewBox=\"0 0 24 24\"><line x1=\"18\" y1=\"6\" x2=\"6\" y2=\"18\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"6\" y1=\"6\" x2=\"18\" y2=\"18\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line></symbol><!-- P1010: Action Bar icons --><symbol id=\"icon-play\" viewBox=\"0 0 24 24\"><polygon points=\"5 3 19 12 5 21 5 3\" fill=\"currentColor\"></polygon></symbol><!-- P1040: Alert triangle for dependency dialog --><symbol id=\"icon-alert-triangle\" viewBox=\"0 0 24 24\"><path d=\"M10.29 3.86L1.82 18a2 2 0 0 0 1.71 3h16.94a2 2 0 0 0 1.71-3L13.71 3.86a2 2 0 0 0-3.42 0z\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></path> <line x1=\"12\" y1=\"9\" x2=\"12\" y2=\"13\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"12\" y1=\"17\" x2=\"12.01\" y2=\"17\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line></symbol><!-- P1060: Dropdown menu icons --><symbol id=\"icon-copy\" viewBox=\"0 0 24 24\"><rect x=\"9\" y=\"9\" width=\"13\" height=\"13\" rx=\"2\" ry=\"2\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></rect> <path d=\"M5 15H4a2 2 0 0 1-2-2V4a2 2 0 0 1 2-2h9a2 2 0 0 1 2 2v1\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></path></symbol> <symbol id=\"icon-terminal\" viewBox=\"0 0 24 24\"><polyline points=\"4 17 10 11 4 5\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></polyline> <line x1=\"12\" y1=\"19\" x2=\"20\" y2=\"19\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line></symbol> <symbol id=\"icon-more-vertical\" viewBox=\"0 0 24 24\"><circle cx=\"12\" cy=\"12\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"12\" cy=\"5\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"12\" cy=\"19\" r=\"1.5\" fill=\"currentColor\"></circle></symbol> <symbol id=\"icon-loader\" viewBox=\"0 0 24 24\"><line x1=\"12\" y1=\"2\" x2=\"12\" y2=\"6\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"12\" y1=\"18\" x2=\"12\" y2=\"22\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"4.93\" y1=\"4.93\" x2=\"7.76\" y2=\"7.76\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"16.24\" y1=\"16.24\" x2=\"19.07\" y2=\"19.07\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"2\" y1=\"12\" x2=\"6\" y2=\"12\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"18\" y1=\"12\" x2=\"22\" y2=\"12\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"4.93\" y1=\"19.07\" x2=\"7.76\" y2=\"16.24\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line> <line x1=\"16.24\" y1=\"7.76\" x2=\"19.07\" y2=\"4.93\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\"></line></symbol> <symbol id=\"icon-refresh-cw\" viewBox=\"0 0 24 24\"><polyline points=\"23 4 23 10 17 10\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></polyline> <polyline points=\"1 20 1 14 7 14\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></polyline> <path d=\"M3.51 9a9 9 0 0 1 14.85-3.36L23 10M1 14l4.64 4.36A9 9 0 0 0 20.49 15\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\"></path></symbol><!-- P2950: Palette icon for color picker --><symbol id=\"icon-palette\" viewBox=\"0 0 24 24\"><circle cx=\"12\" cy=\"12\" r=\"10\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\"></circle> <circle cx=\"12\" cy=\"7\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"7.5\" cy=\"10.5\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"8.5\" cy=\"15\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"16.5\" cy=\"10.5\" r=\"1.5\" fill=\"currentColor\"></circle> <circle cx=\"15\" cy=\"15\" r=\"2\" fill=\"currentColor\"></circle></symbol></svg><div class=\"container\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
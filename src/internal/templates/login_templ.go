@@ -9,7 +9,7 @@ import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
 // Login renders the login page
-func Login(errorMsg string, version string) templ.Component {
+func Login(errorMsg string, version string, sso bool) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -88,7 +88,17 @@ func Login(errorMsg string, version string) templ.Component {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<form method=\"POST\" action=\"/login\" style=\"background: var(--bg-float); border: 1px solid var(--border); border-radius: 8px; padding: 1.5rem;\"><div style=\"margin-bottom: 1rem;\"><label for=\"password\" style=\"display: block; color: var(--fg-dark); font-size: 0.85rem; margin-bottom: 0.5rem;\">Password</label> <input type=\"password\" id=\"password\" name=\"password\" required autofocus style=\"width: 100%; padding: 0.75rem; background: var(--bg); border: 1px solid var(--border); border-radius: 6px; color: var(--fg); font-family: inherit; font-size: 1rem;\" placeholder=\"Enter password\"></div><div style=\"margin-bottom: 1.5rem;\"><label for=\"totp\" style=\"display: block; color: var(--fg-dark); font-size: 0.85rem; margin-bottom: 0.5rem;\">TOTP Code (if enabled)</label> <input type=\"text\" id=\"totp\" name=\"totp\" autocomplete=\"one-time-code\" inputmode=\"numeric\" pattern=\"[0-9]*\" maxlength=\"6\" style=\"width: 100%; padding: 0.75rem; background: var(--bg); border: 1px solid var(--border); border-radius: 6px; color: var(--fg); font-family: inherit; font-size: 1rem; letter-spacing: 0.2em;\" placeholder=\"000000\"></div><button type=\"submit\" class=\"btn btn-primary\" style=\"width: 100%; justify-content: center; padding: 0.75rem; font-size: 1rem;\">Login</button></form></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<form method=\"POST\" action=\"/login\" style=\"background: var(--bg-float); border: 1px solid var(--border); border-radius: 8px; padding: 1.5rem;\"><div style=\"margin-bottom: 1rem;\"><label for=\"username\" style=\"display: block; color: var(--fg-dark); font-size: 0.85rem; margin-bottom: 0.5rem;\">Username</label> <input type=\"text\" id=\"username\" name=\"username\" autocomplete=\"username\" autocapitalize=\"none\" autofocus style=\"width: 100%; padding: 0.75rem; background: var(--bg); border: 1px solid var(--border); border-radius: 6px; color: var(--fg); font-family: inherit; font-size: 1rem;\" placeholder=\"admin\"></div><div style=\"margin-bottom: 1rem;\"><label for=\"password\" style=\"display: block; color: var(--fg-dark); font-size: 0.85rem; margin-bottom: 0.5rem;\">Password</label> <input type=\"password\" id=\"password\" name=\"password\" required autocomplete=\"current-password\" style=\"width: 100%; padding: 0.75rem; background: var(--bg); border: 1px solid var(--border); border-radius: 6px; color: var(--fg); font-family: inherit; font-size: 1rem;\" placeholder=\"Enter password\"></div><div style=\"margin-bottom: 1.5rem;\"><label for=\"totp\" style=\"display: block; color: var(--fg-dark); font-size: 0.85rem; margin-bottom: 0.5rem;\">TOTP Code (if enabled)</label> <input type=\"text\" id=\"totp\" name=\"totp\" autocomplete=\"one-time-code\" inputmode=\"numeric\" pattern=\"[0-9]*\" maxlength=\"6\" style=\"width: 100%; padding: 0.75rem; background: var(--bg); border: 1px solid var(--border); border-radius: 6px; color: var(--fg); font-family: inherit; font-size: 1rem; letter-spacing: 0.2em;\" placeholder=\"000000\"></div><button type=\"submit\" class=\"btn btn-primary\" style=\"width: 100%; justify-content: center; padding: 0.75rem; font-size: 1rem;\">Login</button></form>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if sso {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<a href=\"/login/oidc\" class=\"btn\" style=\"width: 100%; justify-content: center; padding: 0.75rem; font-size: 1rem; margin-top: 1rem; text-decoration: none;\">Sign in with single sign-on</a> ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "<button type=\"button\" id=\"passkeyLogin\" class=\"btn\" style=\"display: none; width: 100%; justify-content: center; padding: 0.75rem; font-size: 1rem; margin-top: 1rem;\">Sign in with a passkey</button><script>\n\t\t\t\t\t(function () {\n\t\t\t\t\t\tif (!Passkeys.supported()) return;\n\t\t\t\t\t\tconst btn = document.getElementById('passkeyLogin');\n\t\t\t\t\t\tbtn.style.display = 'flex';\n\t\t\t\t\t\tbtn.addEventListener('click', async () => {\n\t\t\t\t\t\t\ttry {\n\t\t\t\t\t\t\t\tawait Passkeys.login();\n\t\t\t\t\t\t\t\twindow.location.href = '/';\n\t\t\t\t\t\t\t} catch (err) {\n\t\t\t\t\t\t\t\tif (err.name === 'NotAllowedError') return; // dismissed\n\t\t\t\t\t\t\t\twindow.location.href = '/login?error=' + encodeURIComponent('Passkey sign-in failed: ' + err.message);\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t});\n\t\t\t\t\t})();\n\t\t\t\t</script></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -63,7 +63,9 @@ func (m *MockDashboard) Close() {
 
 // SetAuthToken sets the expected auth token.
 func (m *MockDashboard) SetAuthToken(token string) {
+	m.mu.Lock()
 	m.authToken = token
+	m.mu.Unlock()
 }
 
 // Messages returns all received messages.
@@ -125,6 +127,12 @@ func (m *MockDashboard) WaitForNMessages(ctx context.Context, msgType string, n
 
 // SendCommand sends a command to the first connected agent.
 func (m *MockDashboard) SendCommand(command string) error {
+	return m.SendCommandPayload(protocol.CommandPayload{Command: command})
+}
+
+// SendCommandPayload sends a command with ID and args to the first
+// connected agent.
+func (m *MockDashboard) SendCommandPayload(payload protocol.CommandPayload) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -132,9 +140,7 @@ func (m *MockDashboard) SendCommand(command string) error {
 		return nil
 	}
 
-	msg, _ := protocol.NewMessage(protocol.TypeCommand, protocol.CommandPayload{
-		Command: command,
-	})
+	msg, _ := protocol.NewMessage(protocol.TypeCommand, payload)
 	data, _ := json.Marshal(msg)
 	return m.conns[0].WriteMessage(websocket.TextMessage, data)
 }
@@ -162,7 +168,9 @@ func (m *MockDashboard) ConnectionCount() int {
 func (m *MockDashboard) handleWS(w http.ResponseWriter, r *http.Request) {
 	// Check auth token
 	authHeader := r.Header.Get("Authorization")
+	m.mu.Lock()
 	expectedAuth := "Bearer " + m.authToken
+	m.mu.Unlock()
 	if authHeader != expectedAuth {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
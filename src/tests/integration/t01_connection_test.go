@@ -75,6 +75,15 @@ func TestAgentConnection_Success(t *testing.T) {
 	if payload.HostType != "nixos" && payload.HostType != "macos" {
 		t.Errorf("expected host_type 'nixos' or 'macos', got '%s'", payload.HostType)
 	}
+	if payload.ProtocolVersion != protocol.ProtocolVersion || payload.Capabilities == nil {
+		t.Fatalf("expected protocol v%d with capabilities, got v%d %+v", protocol.ProtocolVersion, payload.ProtocolVersion, payload.Capabilities)
+	}
+	if !payload.Capabilities.HasCommand("switch") || !payload.Capabilities.HasFeature(protocol.FeatureCommandIDs) {
+		t.Errorf("capabilities missing switch / command IDs: %+v", payload.Capabilities)
+	}
+	if f := payload.Facts; f == nil || f.System == "" || f.CPUCores == 0 || f.Disks == nil || f.Interfaces == nil {
+		t.Errorf("registration without host facts: %+v", payload.Facts)
+	}
 
 	// Shutdown
 	a.Shutdown()
@@ -200,6 +209,67 @@ func TestAgentConnection_Reconnect(t *testing.T) {
 	a.Shutdown()
 }
 
+// TestAgentConnection_Failover tests Scenario 5: Multiple Dashboards
+// Given: agent configured with a primary and a fallback dashboard
+// When: the primary rejects the agent, then accepts it again
+// Then: agent registers with the fallback, then fails back to the primary
+func TestAgentConnection_Failover(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := initTestGitRepo(tmpDir); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+
+	primary := NewMockDashboard(t)
+	defer primary.Close()
+	primary.SetAuthToken("other-token") // rejects the agent with 401
+	fallback := NewMockDashboard(t)
+	defer fallback.Close()
+
+	cfg := &config.Config{
+		DashboardURL:      primary.URL(),
+		FallbackURLs:      []string{fallback.URL()},
+		Token:             "test-token",
+		RepoDir:           tmpDir,
+		HeartbeatInterval: 1 * time.Second,
+		Hostname:          "test-host",
+		LogLevel:          "debug",
+	}
+
+	a := agent.New(cfg, zerolog.Nop())
+	go func() {
+		_ = a.Run() // Ignore error in test goroutine
+	}()
+	defer a.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	msg, err := fallback.WaitForMessage(ctx, protocol.TypeRegister)
+	if err != nil {
+		t.Fatalf("failed to register with fallback: %v", err)
+	}
+	var payload protocol.RegisterPayload
+	if err := msg.ParsePayload(&payload); err != nil {
+		t.Fatalf("failed to parse registration payload: %v", err)
+	}
+	if payload.DashboardURL != fallback.URL() {
+		t.Errorf("expected dashboard_url %q, got %q", fallback.URL(), payload.DashboardURL)
+	}
+
+	// Primary is back: the agent should fail back to it
+	primary.SetAuthToken("test-token")
+	msg, err = primary.WaitForMessage(ctx, protocol.TypeRegister)
+	if err != nil {
+		t.Fatalf("failed to fail back to primary: %v", err)
+	}
+	if err := msg.ParsePayload(&payload); err != nil {
+		t.Fatalf("failed to parse registration payload: %v", err)
+	}
+	if payload.DashboardURL != primary.URL() {
+		t.Errorf("expected dashboard_url %q, got %q", primary.URL(), payload.DashboardURL)
+	}
+}
+
 // TestAgentConnection_MalformedMessage tests Scenario 6: Malformed Messages
 // Given: agent connected
 // When: dashboard sends malformed JSON
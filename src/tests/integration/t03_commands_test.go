@@ -4,9 +4,11 @@ package integration
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -105,6 +107,299 @@ echo "line 5"
 	}
 }
 
+// TestAgentCommand_EchoesCommandID tests that output and status carry the
+// command's ID, and that arguments a command does not take are rejected.
+func TestAgentCommand_EchoesCommandID(t *testing.T) {
+	dashboard := NewMockDashboard(t)
+	defer dashboard.Close()
+
+	cfg := &config.Config{
+		DashboardURL:      dashboard.URL(),
+		Token:             "test-token",
+		RepoDir:           t.TempDir(),
+		HeartbeatInterval: 5 * time.Second,
+		Hostname:          "test-host",
+		LogLevel:          "debug",
+	}
+	a := agent.New(cfg, zerolog.Nop())
+	go func() { _ = a.Run() }()
+	defer a.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if _, err := dashboard.WaitForMessage(ctx, protocol.TypeRegister); err != nil {
+		t.Fatalf("failed to receive registration: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	waitStatus := func(id string) protocol.StatusPayload {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			for _, msg := range dashboard.MessagesOfType(protocol.TypeStatus) {
+				var p protocol.StatusPayload
+				if msg.ParsePayload(&p) == nil && p.CommandID == id {
+					return p
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("no status with command_id %s", id)
+		return protocol.StatusPayload{}
+	}
+
+	if err := dashboard.SendCommandPayload(protocol.CommandPayload{ID: "cmd-1", Command: "refresh-git"}); err != nil {
+		t.Fatal(err)
+	}
+	if st := waitStatus("cmd-1"); st.Command != "refresh-git" || st.ExitCode != 0 {
+		t.Errorf("status = %+v", st)
+	}
+	outputs := dashboard.MessagesOfType(protocol.TypeOutput)
+	if len(outputs) == 0 {
+		t.Fatal("no output")
+	}
+	for _, msg := range outputs {
+		var p protocol.OutputPayload
+		if err := msg.ParsePayload(&p); err != nil || p.CommandID != "cmd-1" || p.Command != "refresh-git" {
+			t.Errorf("output %+v does not name cmd-1 (%v)", p, err)
+		}
+	}
+
+	// pull takes no target
+	if err := dashboard.SendCommandPayload(protocol.CommandPayload{
+		ID: "cmd-2", Command: "pull", Args: protocol.CommandArgs{Target: "system"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if st := waitStatus("cmd-2"); st.Status != "error" {
+		t.Errorf("pull with target: status = %+v", st)
+	}
+}
+
+// TestAgentCommand_Exec tests that exec runs only allowlisted commands with
+// valid params, streams their output and stops them at their timeout.
+func TestAgentCommand_Exec(t *testing.T) {
+	dashboard := NewMockDashboard(t)
+	defer dashboard.Close()
+
+	cfg := &config.Config{
+		DashboardURL:      dashboard.URL(),
+		Token:             "test-token",
+		RepoDir:           t.TempDir(),
+		HeartbeatInterval: 5 * time.Second,
+		Hostname:          "test-host",
+		LogLevel:          "debug",
+		ExecCommands: map[string]protocol.ExecCommand{
+			"greet": {Command: []string{"echo", "hello", "{name}"}, Params: map[string]string{"name": "[a-z]+"}},
+			"slow":  {Command: []string{"sleep", "30"}, Timeout: 1},
+		},
+	}
+	a := agent.New(cfg, zerolog.Nop())
+	go func() { _ = a.Run() }()
+	defer a.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	msg, err := dashboard.WaitForMessage(ctx, protocol.TypeRegister)
+	if err != nil {
+		t.Fatalf("failed to receive registration: %v", err)
+	}
+	var reg protocol.RegisterPayload
+	if err := msg.ParsePayload(&reg); err != nil {
+		t.Fatal(err)
+	}
+	if reg.Capabilities == nil || !reg.Capabilities.HasCommand("exec") || len(reg.Capabilities.Exec) != 2 {
+		t.Fatalf("capabilities do not advertise the allowlist: %+v", reg.Capabilities)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	run := func(id, name string, params map[string]string) protocol.StatusPayload {
+		t.Helper()
+		if err := dashboard.SendCommandPayload(protocol.CommandPayload{
+			ID: id, Command: "exec", Args: protocol.CommandArgs{Exec: name, Params: params},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			for _, msg := range dashboard.MessagesOfType(protocol.TypeStatus) {
+				var p protocol.StatusPayload
+				if msg.ParsePayload(&p) == nil && p.CommandID == id {
+					return p
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("no status for %s", id)
+		return protocol.StatusPayload{}
+	}
+
+	if st := run("exec-1", "greet", map[string]string{"name": "fleet"}); st.Status != "ok" || st.Message != "echo hello fleet" {
+		t.Errorf("greet: status = %+v", st)
+	}
+	var lines []string
+	for _, msg := range dashboard.MessagesOfType(protocol.TypeOutput) {
+		var p protocol.OutputPayload
+		if msg.ParsePayload(&p) == nil && p.CommandID == "exec-1" {
+			lines = append(lines, p.Line)
+		}
+	}
+	if len(lines) != 2 || lines[0] != "$ echo hello fleet" || lines[1] != "hello fleet" {
+		t.Errorf("greet output = %q", lines)
+	}
+
+	if st := run("exec-2", "greet", map[string]string{"name": "-n"}); st.Status != "error" {
+		t.Errorf("option as param: status = %+v", st)
+	}
+	if st := run("exec-3", "rm", nil); st.Status != "error" {
+		t.Errorf("command not in allowlist: status = %+v", st)
+	}
+
+	start := time.Now()
+	if st := run("exec-4", "slow", nil); st.Status != "error" || st.ExitCode != 124 {
+		t.Errorf("slow: status = %+v", st)
+	}
+	if time.Since(start) > 8*time.Second {
+		t.Errorf("timeout took %s", time.Since(start))
+	}
+}
+
+// TestAgentCommand_ServiceUnit tests that the service ops advertise the
+// host's units and refuse any other unit without running systemctl.
+func TestAgentCommand_ServiceUnit(t *testing.T) {
+	dashboard := NewMockDashboard(t)
+	defer dashboard.Close()
+
+	cfg := &config.Config{
+		DashboardURL:      dashboard.URL(),
+		Token:             "test-token",
+		RepoDir:           t.TempDir(),
+		HeartbeatInterval: 5 * time.Second,
+		Hostname:          "test-host",
+		LogLevel:          "debug",
+		ServiceUnits:      []string{"app.service"},
+	}
+	a := agent.New(cfg, zerolog.Nop())
+	go func() { _ = a.Run() }()
+	defer a.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	msg, err := dashboard.WaitForMessage(ctx, protocol.TypeRegister)
+	if err != nil {
+		t.Fatalf("failed to receive registration: %v", err)
+	}
+	var reg protocol.RegisterPayload
+	if err := msg.ParsePayload(&reg); err != nil {
+		t.Fatal(err)
+	}
+	if reg.Capabilities == nil || !reg.Capabilities.HasCommand("service-restart") ||
+		len(reg.Capabilities.ServiceUnits) != 1 || reg.Capabilities.ServiceUnits[0] != "app.service" {
+		t.Fatalf("capabilities do not advertise the service units: %+v", reg.Capabilities)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	if err := dashboard.SendCommandPayload(protocol.CommandPayload{
+		ID: "svc-1", Command: "service-restart", Args: protocol.CommandArgs{Unit: "sshd.service"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, msg := range dashboard.MessagesOfType(protocol.TypeStatus) {
+			var p protocol.StatusPayload
+			if msg.ParsePayload(&p) != nil || p.CommandID != "svc-1" {
+				continue
+			}
+			if p.Status != "error" || !strings.Contains(p.Message, "sshd.service") {
+				t.Errorf("unit not in allowlist: status = %+v", p)
+			}
+			for _, msg := range dashboard.MessagesOfType(protocol.TypeOutput) {
+				var out protocol.OutputPayload
+				if msg.ParsePayload(&out) == nil && out.CommandID == "svc-1" {
+					t.Errorf("refused unit produced output %q", out.Line)
+				}
+			}
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("no status for svc-1")
+}
+
+// TestAgentLogStream tests that log stream sessions run next to commands:
+// they do not make the agent busy and end with log_stream_end.
+func TestAgentLogStream(t *testing.T) {
+	dashboard := NewMockDashboard(t)
+	defer dashboard.Close()
+
+	cfg := &config.Config{
+		DashboardURL:      dashboard.URL(),
+		Token:             "test-token",
+		RepoDir:           t.TempDir(),
+		HeartbeatInterval: 5 * time.Second,
+		Hostname:          "test-host",
+		LogLevel:          "debug",
+	}
+	a := agent.New(cfg, zerolog.Nop())
+	go func() { _ = a.Run() }()
+	defer a.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	msg, err := dashboard.WaitForMessage(ctx, protocol.TypeRegister)
+	if err != nil {
+		t.Fatalf("failed to receive registration: %v", err)
+	}
+	var reg protocol.RegisterPayload
+	if err := msg.ParsePayload(&reg); err != nil {
+		t.Fatal(err)
+	}
+	if reg.Capabilities == nil || !reg.Capabilities.HasFeature(protocol.FeatureLogStream) {
+		t.Fatalf("capabilities do not announce log streams: %+v", reg.Capabilities)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	send := func(msgType string, payload any) {
+		t.Helper()
+		msg, _ := protocol.NewMessage(msgType, payload)
+		data, _ := json.Marshal(msg)
+		if err := dashboard.SendRaw(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitEnd := func(streamID string) protocol.LogStreamEndPayload {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			for _, msg := range dashboard.MessagesOfType(protocol.TypeLogStreamEnd) {
+				var p protocol.LogStreamEndPayload
+				if msg.ParsePayload(&p) == nil && p.StreamID == streamID {
+					return p
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("no log_stream_end for %s", streamID)
+		return protocol.LogStreamEndPayload{}
+	}
+
+	send(protocol.TypeLogStreamStart, protocol.LogStreamStartPayload{StreamID: "ls-bad", Unit: "-k"})
+	if end := waitEnd("ls-bad"); !strings.Contains(end.Error, "invalid unit") {
+		t.Errorf("option as unit: end = %+v", end)
+	}
+
+	// Whether journalctl can follow here depends on the host; either way the
+	// session must not take the command slot and must end when stopped.
+	send(protocol.TypeLogStreamStart, protocol.LogStreamStartPayload{StreamID: "ls-1", Unit: "nixfleet-agent.service", Lines: 5})
+	time.Sleep(300 * time.Millisecond)
+	if a.IsBusy() {
+		t.Error("log stream made the agent busy")
+	}
+	send(protocol.TypeLogStreamStop, protocol.LogStreamStopPayload{StreamID: "ls-1"})
+	waitEnd("ls-1")
+}
+
 // TestAgentCommand_Stop tests Scenario 5: Stop Running Command
 // Given: command is executing
 // When: dashboard sends stop
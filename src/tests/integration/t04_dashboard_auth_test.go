@@ -3,6 +3,7 @@ package integration
 import (
 	"database/sql"
 	"io"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
@@ -12,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/markus-barta/nixfleet/internal/dashboard"
 	"github.com/rs/zerolog"
 	"golang.org/x/crypto/bcrypt"
@@ -64,8 +66,14 @@ func setupTestDashboard(t *testing.T, opts ...func(*testDashboardOpts)) *testDas
 	log := zerolog.New(io.Discard)
 	server := dashboard.New(cfg, db, log)
 
-	// Start test server
-	ts := httptest.NewServer(server.Router())
+	// Start test server, on the given listener if the test set its URL
+	// as NIXFLEET_BASE_URL
+	ts := httptest.NewUnstartedServer(server.Router())
+	if o.listener != nil {
+		_ = ts.Listener.Close()
+		ts.Listener = o.listener
+	}
+	ts.Start()
 
 	return &testDashboard{
 		t:        t,
@@ -78,6 +86,7 @@ func setupTestDashboard(t *testing.T, opts ...func(*testDashboardOpts)) *testDas
 type testDashboardOpts struct {
 	password   string
 	totpSecret string
+	listener   net.Listener
 }
 
 type testDashboard struct {
@@ -343,6 +352,251 @@ func TestDashboardAuth_CSRF(t *testing.T) {
 	t.Logf("CSRF protection working correctly (status without CSRF: 403, with CSRF: %d)", resp.StatusCode)
 }
 
+// TestDashboardAuth_APIToken tests bearer tokens: scopes, no CSRF, host
+// restriction, expiry and the token name as event actor.
+func TestDashboardAuth_APIToken(t *testing.T) {
+	td := setupTestDashboard(t)
+	defer td.Close()
+
+	secrets := map[string]string{}
+	for _, n := range []dashboard.NewAPIToken{
+		{Name: "reader", Scope: dashboard.ScopeRead},
+		{Name: "deployer", Scope: dashboard.ScopeDispatch, HostSelector: "hosts:web1"},
+		{Name: "admin", Scope: dashboard.ScopeAdmin, Kind: "personal"},
+		{Name: "old", Scope: dashboard.ScopeAdmin, ExpiresDays: 1},
+	} {
+		_, secret, err := dashboard.CreateAPIToken(td.db, n)
+		if err != nil {
+			t.Fatalf("create %s: %v", n.Name, err)
+		}
+		secrets[n.Name] = secret
+	}
+	if _, err := td.db.Exec(`UPDATE api_tokens SET expires_at = ? WHERE name = 'old'`, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(token, method, path, body string) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, td.URL()+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	for _, tc := range []struct {
+		token, method, path, body string
+		want                      int
+	}{
+		{"reader", "GET", "/api/hosts", "", http.StatusOK},
+		{"reader", "POST", "/api/dispatch", `{"op":"pull","hosts":["web1"]}`, http.StatusForbidden},
+		{"deployer", "POST", "/api/hosts", `{"hostname":"web2"}`, http.StatusForbidden},
+		{"deployer", "GET", "/api/tokens", "", http.StatusForbidden},
+		{"deployer", "GET", "/api/hosts/web2/facts", "", http.StatusForbidden},
+		{"deployer", "POST", "/api/dispatch/pipeline", `{"pipeline":"do-all","hosts":["web2"]}`, http.StatusForbidden},
+		{"admin", "POST", "/api/hosts", `{"hostname":"web2","host_type":"nixos"}`, http.StatusOK},
+		{"old", "GET", "/api/hosts", "", http.StatusUnauthorized},
+		{"nft_not-a-token", "GET", "/api/hosts", "", http.StatusUnauthorized},
+	} {
+		token := secrets[tc.token]
+		if token == "" {
+			token = tc.token
+		}
+		if code, body := call(token, tc.method, tc.path, tc.body); code != tc.want {
+			t.Errorf("%s %s %s: got %d, want %d (%s)", tc.token, tc.method, tc.path, code, tc.want, body)
+		}
+	}
+
+	// Hosts outside the token's selector are blocked per host
+	_, body := call(secrets["deployer"], "POST", "/api/dispatch", `{"op":"pull","hosts":["web2"]}`)
+	if !strings.Contains(body, "host_not_allowed") {
+		t.Errorf("dispatch outside host selector: %s", body)
+	}
+
+	// The selector matches hosts that are offline, and limits the host list
+	if _, err := td.db.Exec(`INSERT INTO hosts (id, hostname, host_type, status) VALUES ('web1', 'web1', 'nixos', 'offline')`); err != nil {
+		t.Fatal(err)
+	}
+	if code, body := call(secrets["deployer"], "GET", "/api/hosts/web1/events", ""); code != http.StatusOK {
+		t.Errorf("offline host in token's selector: got %d (%s)", code, body)
+	}
+	_, body = call(secrets["deployer"], "GET", "/api/hosts", "")
+	if !strings.Contains(body, `"hostname":"web1"`) || strings.Contains(body, `"hostname":"web2"`) {
+		t.Errorf("host list for token restricted to web1: %s", body)
+	}
+
+	// Token management through the API is recorded with the token as actor
+	code, body := call(secrets["admin"], "POST", "/api/tokens", `{"name":"ci","scope":"read","expires_days":30}`)
+	if code != http.StatusCreated || !strings.Contains(body, `"secret":"nft_`) {
+		t.Fatalf("create token: %d %s", code, body)
+	}
+	_, body = call(secrets["admin"], "GET", "/api/events", "")
+	if !strings.Contains(body, `"actor":"token:admin"`) || !strings.Contains(body, "token:create") {
+		t.Errorf("token creation not in event log as token:admin: %s", body)
+	}
+	if code, _ := call(secrets["admin"], "DELETE", "/api/tokens/ci", ""); code != http.StatusOK {
+		t.Errorf("revoke token: %d", code)
+	}
+	if _, body := call(secrets["admin"], "GET", "/api/tokens", ""); strings.Contains(body, `"ci"`) || strings.Contains(body, "token_hash") {
+		t.Errorf("token list after revoke: %s", body)
+	}
+}
+
+// loginAs logs in with a username and returns the session client and its
+// CSRF token.
+func loginAs(t *testing.T, td *testDashboard, username, password string) (*http.Client, string) {
+	t.Helper()
+	client := newClientWithCookiesFollowRedirects(t)
+	resp, err := client.PostForm(td.URL()+"/login", url.Values{"username": {username}, "password": {password}})
+	if err != nil {
+		t.Fatalf("login as %s: %v", username, err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	bodyStr := string(body)
+	csrfStart := strings.Index(bodyStr, `data-csrf-token="`)
+	if csrfStart == -1 {
+		t.Fatalf("login as %s failed: %s", username, resp.Request.URL)
+	}
+	csrfStart += len(`data-csrf-token="`)
+	csrfEnd := strings.Index(bodyStr[csrfStart:], `"`)
+	return client, bodyStr[csrfStart : csrfStart+csrfEnd]
+}
+
+// TestDashboardAuth_Users tests user accounts: per-user login, role checks
+// on ops, the username as event actor, and role changes and deletion taking
+// effect on open sessions.
+func TestDashboardAuth_Users(t *testing.T) {
+	td := setupTestDashboard(t)
+	defer td.Close()
+
+	_, adminToken, err := dashboard.CreateAPIToken(td.db, dashboard.NewAPIToken{Name: "setup", Scope: dashboard.ScopeAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []string{
+		`{"username":"vera","password":"viewer-pass","role":"viewer"}`,
+		`{"username":"otto","password":"operator-pass","role":"operator"}`,
+		`{"username":"ada","password":"admin-pass","role":"admin","totp":true}`,
+	} {
+		req, _ := http.NewRequest("POST", td.URL()+"/api/users", strings.NewReader(u))
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated || strings.Contains(string(body), "password") {
+			t.Fatalf("create user %s: %d %s", u, resp.StatusCode, body)
+		}
+	}
+
+	call := func(client *http.Client, csrf, method, path, body string) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, td.URL()+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-CSRF-Token", csrf)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	// Wrong password
+	resp, err := newClientWithCookies(t).PostForm(td.URL()+"/login", url.Values{"username": {"otto"}, "password": {"viewer-pass"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if loc := resp.Header.Get("Location"); !strings.Contains(loc, "error=") {
+		t.Errorf("wrong password: redirected to %q", loc)
+	}
+
+	vera, veraCSRF := loginAs(t, td, "vera", "viewer-pass")
+	otto, ottoCSRF := loginAs(t, td, "otto", "operator-pass")
+	admin, adminCSRF := loginAs(t, td, "", td.password) // built-in admin, no username
+
+	for _, tc := range []struct {
+		name         string
+		client       *http.Client
+		csrf         string
+		method, path string
+		body         string
+		want         int
+	}{
+		{"viewer reads", vera, veraCSRF, "GET", "/api/hosts", "", http.StatusOK},
+		{"viewer dispatches", vera, veraCSRF, "POST", "/api/dispatch", `{"op":"pull","hosts":["test"]}`, http.StatusForbidden},
+		{"operator dispatches", otto, ottoCSRF, "POST", "/api/dispatch", `{"op":"pull","hosts":["test"]}`, http.StatusOK},
+		{"operator reboots", otto, ottoCSRF, "POST", "/api/dispatch", `{"op":"reboot","hosts":["test"]}`, http.StatusForbidden},
+		{"operator reboots host", otto, ottoCSRF, "POST", "/api/hosts/test/reboot", `{"totp":"000000"}`, http.StatusForbidden},
+		{"operator removes host", otto, ottoCSRF, "DELETE", "/api/hosts/test", "", http.StatusForbidden},
+		{"operator lists users", otto, ottoCSRF, "GET", "/api/users", "", http.StatusForbidden},
+		{"admin lists users", admin, adminCSRF, "GET", "/api/users", "", http.StatusOK},
+	} {
+		if code, body := call(tc.client, tc.csrf, tc.method, tc.path, tc.body); code != tc.want {
+			t.Errorf("%s: got %d, want %d (%s)", tc.name, code, tc.want, body)
+		}
+	}
+
+	// Actions are logged with the username
+	if code, body := call(admin, adminCSRF, "POST", "/api/tokens", `{"name":"ci","scope":"read"}`); code != http.StatusCreated {
+		t.Fatalf("create token: %d %s", code, body)
+	}
+	if _, body := call(admin, adminCSRF, "GET", "/api/events", ""); !strings.Contains(body, `"actor":"user:admin"`) {
+		t.Errorf("token creation not logged as user:admin: %s", body)
+	}
+
+	// A role change applies to open sessions
+	if code, body := call(admin, adminCSRF, "PATCH", "/api/users/otto", `{"role":"viewer"}`); code != http.StatusOK {
+		t.Fatalf("demote otto: %d %s", code, body)
+	}
+	if code, _ := call(otto, ottoCSRF, "POST", "/api/dispatch", `{"op":"pull","hosts":["test"]}`); code != http.StatusForbidden {
+		t.Errorf("demoted operator dispatches: got %d, want 403", code)
+	}
+
+	// Deleting a user ends their sessions, including open dashboards
+	base, _ := url.Parse(td.URL())
+	header := http.Header{}
+	for _, c := range vera.Jar.Cookies(base) {
+		header.Add("Cookie", c.String())
+	}
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(td.URL(), "http")+"/ws", header)
+	if err != nil {
+		t.Fatalf("vera's dashboard websocket: %v", err)
+	}
+	defer func() { _ = ws.Close() }()
+	if code, body := call(admin, adminCSRF, "DELETE", "/api/users/vera", ""); code != http.StatusOK {
+		t.Fatalf("delete vera: %d %s", code, body)
+	}
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := ws.ReadMessage()
+		if err == nil {
+			continue
+		}
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			t.Error("deleted user's dashboard websocket still open")
+		}
+		break
+	}
+	vera.CheckRedirect = func(_ *http.Request, _ []*http.Request) error { return http.ErrUseLastResponse }
+	if code, _ := call(vera, veraCSRF, "GET", "/api/hosts", ""); code != http.StatusFound {
+		t.Errorf("deleted user's session: got %d, want redirect to login", code)
+	}
+}
+
 // TestDashboardAuth_UnauthenticatedAccess tests redirect for unauthenticated access.
 func TestDashboardAuth_UnauthenticatedAccess(t *testing.T) {
 	td := setupTestDashboard(t)
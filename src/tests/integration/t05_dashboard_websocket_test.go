@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -343,6 +344,88 @@ func TestDashboardWebSocket_MessageRouting(t *testing.T) {
 	}
 }
 
+// TestDashboardWebSocket_OutputBatch tests compression negotiation and that
+// batched agent output reaches browsers as one message.
+func TestDashboardWebSocket_OutputBatch(t *testing.T) {
+	td := setupDashboardForWS(t)
+	defer td.Close()
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+td.agentToken)
+	dialer := websocket.Dialer{EnableCompression: true}
+	agentConn, resp, err := dialer.Dial(td.WSURL(), header)
+	if err != nil {
+		t.Fatalf("failed to connect agent: %v", err)
+	}
+	defer func() { _ = agentConn.Close() }()
+	if ext := resp.Header.Get("Sec-Websocket-Extensions"); !strings.Contains(ext, "permessage-deflate") {
+		t.Errorf("compression not negotiated: %q", ext)
+	}
+
+	regMsg, _ := protocol.NewMessage(protocol.TypeRegister, protocol.RegisterPayload{
+		Hostname:          "batch-test-host",
+		HostType:          "nixos",
+		AgentVersion:      "2.0.0-test",
+		HeartbeatInterval: 30,
+	})
+	data, _ := json.Marshal(regMsg)
+	if err := agentConn.WriteMessage(websocket.TextMessage, data); err != nil {
+		t.Fatalf("failed to send registration: %v", err)
+	}
+	_ = agentConn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var registered protocol.Message
+	if err := agentConn.ReadJSON(&registered); err != nil {
+		t.Fatalf("failed to read registered response: %v", err)
+	}
+	var regPayload protocol.RegisteredPayload
+	if err := registered.ParsePayload(&regPayload); err != nil || !slices.Contains(regPayload.Features, protocol.FeatureOutputBatch) {
+		t.Fatalf("dashboard does not announce output batches: %+v, %v", regPayload, err)
+	}
+
+	browserConn := td.connectBrowser(t)
+	defer func() { _ = browserConn.Close() }()
+	time.Sleep(200 * time.Millisecond)
+
+	batchMsg, _ := protocol.NewMessage(protocol.TypeOutputBatch, protocol.OutputBatchPayload{
+		CommandID: "cmd-1",
+		Command:   "switch",
+		Lines: []protocol.OutputLine{
+			{Line: "building the system configuration...", Stream: "stdout", Time: time.Now().UnixMilli()},
+			{Line: "error: oops", Stream: "stderr", Time: time.Now().UnixMilli()},
+		},
+	})
+	data, _ = json.Marshal(batchMsg)
+	if err := agentConn.WriteMessage(websocket.TextMessage, data); err != nil {
+		t.Fatalf("failed to send output batch: %v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		_ = browserConn.SetReadDeadline(deadline)
+		var msg struct {
+			Type    string `json:"type"`
+			Payload struct {
+				HostID string `json:"host_id"`
+				Lines  []struct {
+					Line    string `json:"line"`
+					IsError bool   `json:"is_error"`
+				} `json:"lines"`
+			} `json:"payload"`
+		}
+		if err := browserConn.ReadJSON(&msg); err != nil {
+			t.Fatalf("browser did not receive command_output_batch: %v", err)
+		}
+		if msg.Type != "command_output_batch" {
+			continue
+		}
+		if msg.Payload.HostID != "batch-test-host" || len(msg.Payload.Lines) != 2 ||
+			msg.Payload.Lines[0].IsError || !msg.Payload.Lines[1].IsError {
+			t.Errorf("unexpected batch: %+v", msg.Payload)
+		}
+		return
+	}
+}
+
 // TestDashboardWebSocket_MultipleBrowsers tests that multiple browsers receive updates.
 func TestDashboardWebSocket_MultipleBrowsers(t *testing.T) {
 	td := setupDashboardForWS(t)
@@ -429,3 +512,109 @@ func TestDashboardWebSocket_MultipleBrowsers(t *testing.T) {
 	t.Logf("all %d browsers received init + host_updated delta", numBrowsers)
 }
 
+
+// TestDashboardWebSocket_LogStream tests that a log stream's lines reach only
+// the browser that started it, and that closing the browser stops it.
+func TestDashboardWebSocket_LogStream(t *testing.T) {
+	td := setupDashboardForWS(t)
+	defer td.Close()
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+td.agentToken)
+	agentConn, _, err := websocket.DefaultDialer.Dial(td.WSURL(), header)
+	if err != nil {
+		t.Fatalf("failed to connect agent: %v", err)
+	}
+	defer func() { _ = agentConn.Close() }()
+	regMsg, _ := protocol.NewMessage(protocol.TypeRegister, protocol.RegisterPayload{
+		Hostname:          "journal-host",
+		HostType:          "nixos",
+		AgentVersion:      "2.0.0-test",
+		HeartbeatInterval: 30,
+		Capabilities:      &protocol.Capabilities{Features: []string{protocol.FeatureLogStream}},
+	})
+	if err := agentConn.WriteJSON(regMsg); err != nil {
+		t.Fatalf("failed to send registration: %v", err)
+	}
+	var agentMsg protocol.Message
+	readAgent := func(want string) {
+		t.Helper()
+		_ = agentConn.SetReadDeadline(time.Now().Add(3 * time.Second))
+		for {
+			if err := agentConn.ReadJSON(&agentMsg); err != nil {
+				t.Fatalf("agent did not receive %s: %v", want, err)
+			}
+			if agentMsg.Type == want {
+				return
+			}
+		}
+	}
+	readAgent(protocol.TypeRegistered)
+
+	owner := td.connectBrowser(t)
+	defer func() { _ = owner.Close() }()
+	other := td.connectBrowser(t)
+	defer func() { _ = other.Close() }()
+	time.Sleep(200 * time.Millisecond)
+
+	type browserMsg struct {
+		Type    string `json:"type"`
+		Payload struct {
+			StreamID string   `json:"stream_id"`
+			Lines    []string `json:"lines"`
+			Error    string   `json:"error"`
+		} `json:"payload"`
+	}
+	readBrowser := func(conn *websocket.Conn, want string) (browserMsg, error) {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		for {
+			var msg browserMsg
+			if err := conn.ReadJSON(&msg); err != nil {
+				return msg, err
+			}
+			if msg.Type == want {
+				return msg, nil
+			}
+		}
+	}
+
+	// Options are refused before anything reaches the agent
+	if err := owner.WriteJSON(map[string]any{"type": "log_stream_start", "payload": map[string]any{"host_id": "journal-host", "unit": "--since=yesterday"}}); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := readBrowser(owner, "log_stream_end"); err != nil || !strings.Contains(msg.Payload.Error, "invalid unit") {
+		t.Fatalf("log_stream_end = %+v, %v", msg, err)
+	}
+
+	if err := owner.WriteJSON(map[string]any{"type": "log_stream_start", "payload": map[string]any{"host_id": "journal-host", "unit": "nginx.service"}}); err != nil {
+		t.Fatal(err)
+	}
+	started, err := readBrowser(owner, "log_stream_started")
+	if err != nil {
+		t.Fatalf("browser did not receive log_stream_started: %v", err)
+	}
+	readAgent(protocol.TypeLogStreamStart)
+	var start protocol.LogStreamStartPayload
+	if err := agentMsg.ParsePayload(&start); err != nil || start.StreamID != started.Payload.StreamID || start.Unit != "nginx.service" {
+		t.Fatalf("log_stream_start = %+v, %v", start, err)
+	}
+
+	lines, _ := protocol.NewMessage(protocol.TypeLogStream, protocol.LogStreamPayload{StreamID: start.StreamID, Lines: []string{"nginx: started"}})
+	if err := agentConn.WriteJSON(lines); err != nil {
+		t.Fatal(err)
+	}
+	if msg, err := readBrowser(owner, "log_stream"); err != nil || len(msg.Payload.Lines) != 1 || msg.Payload.Lines[0] != "nginx: started" {
+		t.Fatalf("log_stream = %+v, %v", msg, err)
+	}
+	if msg, err := readBrowser(other, "log_stream"); err == nil {
+		t.Errorf("another browser received %+v", msg)
+	}
+
+	// Closing the browser stops the stream on the agent
+	_ = owner.Close()
+	readAgent(protocol.TypeLogStreamStop)
+	var stop protocol.LogStreamStopPayload
+	if err := agentMsg.ParsePayload(&stop); err != nil || stop.StreamID != start.StreamID {
+		t.Fatalf("log_stream_stop = %+v, %v", stop, err)
+	}
+}
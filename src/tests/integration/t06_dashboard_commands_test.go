@@ -543,3 +543,34 @@ func TestDashboardCommand_StopCommand(t *testing.T) {
 	}
 }
 
+
+// TestDashboardCommand_RefusedPullKeepsPin tests that a pull naming a git
+// ref only moves the host's pin once it is dispatched.
+func TestDashboardCommand_RefusedPullKeepsPin(t *testing.T) {
+	td := setupTestDashboard(t)
+	defer td.Close()
+	if _, err := td.db.Exec(`INSERT INTO hosts (id, hostname, host_type, status, git_ref) VALUES ('web1', 'web1', 'nixos', 'offline', 'v1.0.0')`); err != nil {
+		t.Fatal(err)
+	}
+	client, csrf := loginAs(t, td, "", td.password)
+
+	req, _ := http.NewRequest("POST", td.URL()+"/api/dispatch",
+		strings.NewReader(`{"op": "pull", "hosts": ["web1"], "ref": "v2.0.0"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-CSRF-Token", csrf)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), `"status":"failed"`) {
+		t.Fatalf("pull on an offline host was not refused: %s", body)
+	}
+
+	var pin string
+	_ = td.db.QueryRow(`SELECT git_ref FROM hosts WHERE id = 'web1'`).Scan(&pin)
+	if pin != "v1.0.0" {
+		t.Errorf("refused pull moved the pin to %q", pin)
+	}
+}
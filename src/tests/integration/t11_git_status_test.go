@@ -62,19 +62,19 @@ func TestGitStatus_VersionFetcher(t *testing.T) {
 	}
 
 	// Test status comparison - matching generation
-	status, msg, _ := fetcher.GetGitStatus("abc1234567890def")
+	status, msg, _ := fetcher.GetGitStatus("abc1234567890def", dashboard.GitPin{})
 	if status != "ok" {
 		t.Errorf("expected status ok, got %s: %s", status, msg)
 	}
 
 	// Test status comparison - matching short prefix
-	status, msg, _ = fetcher.GetGitStatus("abc1234")
+	status, msg, _ = fetcher.GetGitStatus("abc1234", dashboard.GitPin{})
 	if status != "ok" {
 		t.Errorf("expected status ok for short prefix, got %s: %s", status, msg)
 	}
 
 	// Test status comparison - outdated
-	status, msg, _ = fetcher.GetGitStatus("xyz9876543210abc")
+	status, msg, _ = fetcher.GetGitStatus("xyz9876543210abc", dashboard.GitPin{})
 	if status != "outdated" {
 		t.Errorf("expected status outdated, got %s", status)
 	}
@@ -83,7 +83,7 @@ func TestGitStatus_VersionFetcher(t *testing.T) {
 	}
 
 	// Test status comparison - empty generation
-	status, msg, _ = fetcher.GetGitStatus("")
+	status, msg, _ = fetcher.GetGitStatus("", dashboard.GitPin{})
 	if status != "unknown" {
 		t.Errorf("expected status unknown for empty generation, got %s", status)
 	}
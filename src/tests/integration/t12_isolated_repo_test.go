@@ -4,9 +4,11 @@ package integration
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -199,6 +201,88 @@ func TestIsolatedRepo_PullCleansSlate(t *testing.T) {
 	t.Log("pull correctly cleaned slate")
 }
 
+// TestIsolatedRepo_PullPinnedRef tests pulls of a pinned ref: a tag named
+// like a short SHA is fetched as the tag, and a short SHA the remote has no
+// tag or branch for is resolved as a commit.
+func TestIsolatedRepo_PullPinnedRef(t *testing.T) {
+	remoteDir := t.TempDir()
+	if err := initGitRepo(remoteDir); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", remoteDir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(content string) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(remoteDir, "test.txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("commit", "-am", content)
+		return git("rev-parse", "HEAD")
+	}
+
+	tagged := git("rev-parse", "HEAD")
+	git("tag", "20241018")
+	commit("second")
+
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	if err := exec.Command("git", "clone", remoteDir, repoDir).Run(); err != nil {
+		t.Fatalf("failed to clone: %v", err)
+	}
+	unfetched := commit("third") // only on the remote
+
+	dashboard := NewMockDashboard(t)
+	defer dashboard.Close()
+	cfg := &config.Config{
+		DashboardURL:      dashboard.URL(),
+		Token:             "test-token",
+		RepoURL:           remoteDir,
+		RepoDir:           repoDir,
+		Branch:            "main",
+		HeartbeatInterval: 5 * time.Second,
+		Hostname:          "test-host",
+		LogLevel:          "debug",
+	}
+	a := agent.New(cfg, zerolog.Nop())
+	go func() { _ = a.Run() }()
+	defer a.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := dashboard.WaitForMessage(ctx, protocol.TypeRegister); err != nil {
+		t.Fatalf("failed to receive registration: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	for i, tt := range []struct{ ref, want string }{
+		{"20241018", tagged},
+		{unfetched[:8], unfetched},
+	} {
+		if err := dashboard.SendCommandPayload(protocol.CommandPayload{
+			ID: fmt.Sprintf("pull-%d", i), Command: "pull", Args: protocol.CommandArgs{Ref: tt.ref},
+		}); err != nil {
+			t.Fatalf("failed to send pull: %v", err)
+		}
+		msgs, err := dashboard.WaitForNMessages(ctx, protocol.TypeStatus, i+1)
+		if err != nil {
+			t.Fatalf("pull %s: no status: %v", tt.ref, err)
+		}
+		var payload protocol.StatusPayload
+		if err := msgs[i].ParsePayload(&payload); err != nil {
+			t.Fatal(err)
+		}
+		head, _ := exec.Command("git", "-C", repoDir, "rev-parse", "HEAD").Output()
+		if payload.Status != "ok" || strings.TrimSpace(string(head)) != tt.want {
+			t.Errorf("pull %s: status %s, HEAD %s, want %s", tt.ref, payload.Status, head, tt.want)
+		}
+	}
+}
+
 // TestIsolatedRepo_GenerationDetection tests T09-04: Generation Detection Uses Isolated Path
 // Given: Agent is running in isolated mode
 // When: Heartbeat is sent
@@ -0,0 +1,273 @@
+package integration
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockOIDCProvider is a minimal OpenID Connect provider: it signs whoever
+// is set as next in, checking the PKCE verifier like a real one would.
+type mockOIDCProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	next  mockOIDCUser
+	codes map[string]mockOIDCCode
+}
+
+type mockOIDCUser struct {
+	sub, username string
+	groups        []string
+}
+
+type mockOIDCCode struct {
+	user                          mockOIDCUser
+	challenge, nonce, redirectURI string
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &mockOIDCProvider{t: t, key: key, codes: make(map[string]mockOIDCCode)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "use": "sig", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/authorize", p.handleAuthorize)
+	mux.HandleFunc("/token", p.handleToken)
+	p.server = httptest.NewServer(mux)
+	return p
+}
+
+func (p *mockOIDCProvider) Close() { p.server.Close() }
+
+func (p *mockOIDCProvider) signInAs(sub, username string, groups ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.next = mockOIDCUser{sub: sub, username: username, groups: groups}
+}
+
+func (p *mockOIDCProvider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != "nixfleet" || q.Get("response_type") != "code" ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "bad authorization request: "+r.URL.RawQuery, http.StatusBadRequest)
+		return
+	}
+	code := randomMockString()
+	p.mu.Lock()
+	p.codes[code] = mockOIDCCode{user: p.next, challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), redirectURI: q.Get("redirect_uri")}
+	p.mu.Unlock()
+
+	target, _ := url.Parse(q.Get("redirect_uri"))
+	target.RawQuery = url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (p *mockOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	id, secret, _ := r.BasicAuth()
+	if id != "nixfleet" || secret != "client-secret" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	_ = r.ParseForm()
+	p.mu.Lock()
+	c, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("redirect_uri") != c.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != c.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := map[string]any{
+		"iss": p.server.URL, "aud": "nixfleet", "sub": c.user.sub, "nonce": c.nonce,
+		"iat": time.Now().Unix(), "exp": time.Now().Add(5 * time.Minute).Unix(),
+		"preferred_username": c.user.username, "groups": c.user.groups,
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"id_token": p.sign(claims), "token_type": "Bearer", "access_token": "unused"})
+}
+
+func (p *mockOIDCProvider) sign(claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func randomMockString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ssoLogin runs the whole sign-on in a fresh browser and returns it with
+// the page it ended on.
+func ssoLogin(t *testing.T, td *testDashboard) (*http.Client, *http.Response, string) {
+	t.Helper()
+	client := newClientWithCookiesFollowRedirects(t)
+	resp, err := client.Get(td.URL() + "/login/oidc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	return client, resp, string(body)
+}
+
+// TestDashboardAuth_OIDC tests single sign-on against a mock provider:
+// role mapping from groups, role updates on later sign-ons, and refusing
+// unmapped users, clashing usernames and forged callbacks.
+func TestDashboardAuth_OIDC(t *testing.T) {
+	idp := newMockOIDCProvider(t)
+	defer idp.Close()
+
+	t.Setenv("NIXFLEET_OIDC_ISSUER", idp.server.URL)
+	t.Setenv("NIXFLEET_OIDC_CLIENT_ID", "nixfleet")
+	t.Setenv("NIXFLEET_OIDC_CLIENT_SECRET", "client-secret")
+	t.Setenv("NIXFLEET_OIDC_ROLES", "fleet-admins=admin, fleet-ops=operator")
+	// The redirect URL is built from the base URL, so it is set up front
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NIXFLEET_BASE_URL", "http://"+ln.Addr().String())
+	td := setupTestDashboard(t, func(o *testDashboardOpts) { o.listener = ln })
+	defer td.Close()
+
+	t.Run("redirect URL ignores the Host header", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", td.URL()+"/login/oidc", nil)
+		req.Host = "evil.example"
+		resp, err := newClientWithCookies(t).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		target, _ := url.Parse(resp.Header.Get("Location"))
+		if got, want := target.Query().Get("redirect_uri"), td.URL()+"/login/oidc/callback"; got != want {
+			t.Errorf("redirect_uri = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("login page offers SSO", func(t *testing.T) {
+		resp, err := http.Get(td.URL() + "/login")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if !strings.Contains(string(body), `href="/login/oidc"`) {
+			t.Error("login page has no single sign-on link")
+		}
+	})
+
+	usersStatus := func(client *http.Client) int {
+		resp, err := client.Get(td.URL() + "/api/users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("group maps to role", func(t *testing.T) {
+		idp.signInAs("sub-ops", "Olga", "staff", "fleet-ops")
+		client, resp, body := ssoLogin(t, td)
+		if resp.Request.URL.Path != "/" || !strings.Contains(body, `title="Role: operator">olga<`) {
+			t.Fatalf("expected dashboard as operator olga, ended at %s", resp.Request.URL)
+		}
+		if code := usersStatus(client); code != http.StatusForbidden {
+			t.Errorf("operator GET /api/users = %d, want 403", code)
+		}
+
+		// Highest mapped group wins, and the same account follows the subject
+		idp.signInAs("sub-ops", "olga", "fleet-ops", "fleet-admins")
+		client, _, body = ssoLogin(t, td)
+		if !strings.Contains(body, `title="Role: admin">olga<`) {
+			t.Fatal("role was not raised to admin on the next sign-on")
+		}
+		if code := usersStatus(client); code != http.StatusOK {
+			t.Errorf("admin GET /api/users = %d, want 200", code)
+		}
+		var n int
+		_ = td.db.QueryRow(`SELECT COUNT(*) FROM users WHERE username = 'olga'`).Scan(&n)
+		if n != 1 {
+			t.Errorf("%d accounts for olga, want 1", n)
+		}
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		if _, err := td.db.Exec(`INSERT INTO users (username, password_hash, role, created_at) VALUES ('lena', 'x', 'viewer', ?)`, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+		for name, user := range map[string]mockOIDCUser{
+			"no mapped group": {sub: "sub-guest", username: "guest", groups: []string{"staff"}},
+			"local username":  {sub: "sub-lena", username: "lena", groups: []string{"fleet-admins"}},
+			"built-in admin":  {sub: "sub-root", username: "admin", groups: []string{"fleet-admins"}},
+		} {
+			idp.signInAs(user.sub, user.username, user.groups...)
+			client, resp, _ := ssoLogin(t, td)
+			if resp.Request.URL.Path != "/login" || resp.Request.URL.Query().Get("error") == "" {
+				t.Errorf("%s: ended at %s, want /login with an error", name, resp.Request.URL)
+			}
+			if resp, err := client.Get(td.URL() + "/"); err == nil {
+				_ = resp.Body.Close()
+				if resp.Request.URL.Path != "/login" {
+					t.Errorf("%s: got a session", name)
+				}
+			}
+		}
+
+		// A callback this browser did not start
+		client := newClientWithCookiesFollowRedirects(t)
+		resp, err := client.Get(td.URL() + "/login/oidc/callback?code=stolen&state=forged")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.Request.URL.Path != "/login" {
+			t.Errorf("forged callback ended at %s", resp.Request.URL)
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package integration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// softAuthenticator is a software passkey with an ES256 key, answering
+// WebAuthn ceremonies the way a browser would pass them on.
+type softAuthenticator struct {
+	t      *testing.T
+	key    *ecdsa.PrivateKey
+	credID []byte
+	count  uint32
+	origin string
+}
+
+func newSoftAuthenticator(t *testing.T, origin string) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credID := make([]byte, 16)
+	_, _ = rand.Read(credID)
+	return &softAuthenticator{t: t, key: key, credID: credID, origin: origin}
+}
+
+func b64url(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+// Minimal CBOR encoding for attestation objects and COSE keys
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 256:
+		return []byte{major<<5 | 24, byte(n)}
+	default:
+		return []byte{major<<5 | 25, byte(n >> 8), byte(n)}
+	}
+}
+
+func cborInt(i int64) []byte {
+	if i < 0 {
+		return cborHead(1, uint64(-1-i))
+	}
+	return cborHead(0, uint64(i))
+}
+
+func cborBytes(b []byte) []byte { return append(cborHead(2, uint64(len(b))), b...) }
+func cborText(s string) []byte  { return append(cborHead(3, uint64(len(s))), s...) }
+
+// cborMap encodes alternating, already encoded keys and values.
+func cborMap(kv ...[]byte) []byte {
+	out := cborHead(5, uint64(len(kv)/2))
+	for _, b := range kv {
+		out = append(out, b...)
+	}
+	return out
+}
+
+// withOrigin is the same passkey used through another site, e.g. a proxy.
+func (a *softAuthenticator) withOrigin(origin string) *softAuthenticator {
+	other := *a
+	other.origin = origin
+	return &other
+}
+
+func (a *softAuthenticator) authData(rpID string, attested bool) []byte {
+	rpHash := sha256.Sum256([]byte(rpID))
+	a.count++
+	data := append(rpHash[:], 0x05) // user present and verified
+	data = binary.BigEndian.AppendUint32(data, a.count)
+	if attested {
+		data[32] |= 0x40
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credID)))
+		data = append(data, a.credID...)
+		x, y := make([]byte, 32), make([]byte, 32)
+		a.key.X.FillBytes(x)
+		a.key.Y.FillBytes(y)
+		data = append(data, cborMap(
+			cborInt(1), cborInt(2), // kty: EC2
+			cborInt(3), cborInt(-7), // alg: ES256
+			cborInt(-1), cborInt(1), // crv: P-256
+			cborInt(-2), cborBytes(x),
+			cborInt(-3), cborBytes(y),
+		)...)
+	}
+	return data
+}
+
+func (a *softAuthenticator) clientData(typ, challenge string) []byte {
+	data, _ := json.Marshal(map[string]any{"type": typ, "challenge": challenge, "origin": a.origin, "crossOrigin": false})
+	return data
+}
+
+// create answers navigator.credentials.create() options.
+func (a *softAuthenticator) create(options map[string]any) map[string]string {
+	pk := options["publicKey"].(map[string]any)
+	rp := pk["rp"].(map[string]any)
+	attObj := cborMap(
+		cborText("fmt"), cborText("none"),
+		cborText("attStmt"), cborMap(),
+		cborText("authData"), cborBytes(a.authData(rp["id"].(string), true)),
+	)
+	return map[string]string{
+		"id":                b64url(a.credID),
+		"clientDataJSON":    b64url(a.clientData("webauthn.create", pk["challenge"].(string))),
+		"attestationObject": b64url(attObj),
+	}
+}
+
+// get answers navigator.credentials.get() options.
+func (a *softAuthenticator) get(options map[string]any) map[string]string {
+	pk := options["publicKey"].(map[string]any)
+	authData := a.authData(pk["rpId"].(string), false)
+	clientData := a.clientData("webauthn.get", pk["challenge"].(string))
+	clientHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return map[string]string{
+		"id":                b64url(a.credID),
+		"clientDataJSON":    b64url(clientData),
+		"authenticatorData": b64url(authData),
+		"signature":         b64url(sig),
+	}
+}
+
+// TestDashboardAuth_WebAuthn tests passkeys: registration, sign-in, and
+// step-up for a TOTP-gated op with the method in the audit log.
+func TestDashboardAuth_WebAuthn(t *testing.T) {
+	// The dashboard's public origin, not the test server's address
+	const origin = "https://fleet.example.com"
+	t.Setenv("NIXFLEET_WEBAUTHN_ORIGIN", origin)
+	td := setupTestDashboard(t)
+	defer td.Close()
+	if _, err := td.db.Exec(`INSERT INTO hosts (id, hostname, host_type, status) VALUES ('web1', 'web1', 'nixos', 'offline')`); err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(client *http.Client, csrf, method, path string, body any) (int, map[string]any) {
+		t.Helper()
+		var reader io.Reader
+		if body != nil {
+			data, _ := json.Marshal(body)
+			reader = strings.NewReader(string(data))
+		}
+		req, _ := http.NewRequest(method, td.URL()+path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		if csrf != "" {
+			req.Header.Set("X-CSRF-Token", csrf)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		var out map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+	execReq := func(assertion any) map[string]any {
+		return map[string]any{"op": "exec", "hosts": []string{"web1"}, "exec": "uptime", "webauthn": assertion}
+	}
+
+	admin, csrf := loginAs(t, td, "", td.password)
+	passkey := newSoftAuthenticator(t, origin)
+
+	// Without TOTP or a passkey there is no step-up
+	if code, body := call(admin, csrf, "POST", "/api/dispatch", execReq(nil)); code != http.StatusForbidden {
+		t.Errorf("exec without step-up configured: %d %v", code, body)
+	}
+
+	// Register
+	code, options := call(admin, csrf, "POST", "/webauthn/register/begin", nil)
+	if code != http.StatusOK {
+		t.Fatalf("register begin: %d %v", code, options)
+	}
+	attestation := passkey.create(options)
+	if code, body := call(admin, csrf, "POST", "/webauthn/register/finish", map[string]any{"name": "laptop", "credential": attestation}); code != http.StatusCreated {
+		t.Fatalf("register finish: %d %v", code, body)
+	}
+	if code, _ := call(admin, csrf, "POST", "/webauthn/register/finish", map[string]any{"credential": attestation}); code != http.StatusBadRequest {
+		t.Errorf("replayed registration: got %d, want 400", code)
+	}
+	if _, body := call(admin, csrf, "GET", "/webauthn/credentials", nil); len(body["credentials"].([]any)) != 1 {
+		t.Errorf("credentials = %v", body)
+	}
+
+	t.Run("login", func(t *testing.T) {
+		browser := newClientWithCookiesFollowRedirects(t)
+		_, options := call(browser, "", "POST", "/login/webauthn/begin", nil)
+		assertion := passkey.get(options)
+		if code, body := call(browser, "", "POST", "/login/webauthn/finish", assertion); code != http.StatusOK {
+			t.Fatalf("passkey login: %d %v", code, body)
+		}
+		resp, err := browser.Get(td.URL() + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.Request.URL.Path != "/" || !strings.Contains(string(page), `>admin<`) {
+			t.Errorf("not signed in after passkey login, ended at %s", resp.Request.URL)
+		}
+
+		// The challenge is single use
+		if code, _ := call(newClientWithCookiesFollowRedirects(t), "", "POST", "/login/webauthn/finish", assertion); code != http.StatusUnauthorized {
+			t.Errorf("replayed login assertion: got %d, want 401", code)
+		}
+	})
+
+	t.Run("other origin", func(t *testing.T) {
+		// A proxy passing requests through: the browser reports the proxy's
+		// origin, whatever Host header reaches the dashboard
+		proxied := passkey.withOrigin(td.URL())
+		_, options := call(newClientWithCookiesFollowRedirects(t), "", "POST", "/login/webauthn/begin", nil)
+		if rpID := options["publicKey"].(map[string]any)["rpId"]; rpID != "fleet.example.com" {
+			t.Errorf("rpId = %v, want the configured fleet.example.com", rpID)
+		}
+		if code, _ := call(newClientWithCookiesFollowRedirects(t), "", "POST", "/login/webauthn/finish", proxied.get(options)); code != http.StatusUnauthorized {
+			t.Errorf("assertion from another origin: got %d, want 401", code)
+		}
+
+		// Scoped to the proxy's domain instead
+		options["publicKey"].(map[string]any)["rpId"] = "127.0.0.1"
+		_, fresh := call(newClientWithCookiesFollowRedirects(t), "", "POST", "/login/webauthn/begin", nil)
+		options["publicKey"].(map[string]any)["challenge"] = fresh["publicKey"].(map[string]any)["challenge"]
+		if code, _ := call(newClientWithCookiesFollowRedirects(t), "", "POST", "/login/webauthn/finish", passkey.get(options)); code != http.StatusUnauthorized {
+			t.Errorf("assertion for another RP ID: got %d, want 401", code)
+		}
+	})
+
+	t.Run("step-up", func(t *testing.T) {
+		_, options := call(admin, csrf, "POST", "/webauthn/step-up/begin", nil)
+		assertion := passkey.get(options)
+		code, body := call(admin, csrf, "POST", "/api/dispatch", execReq(assertion))
+		if code == http.StatusUnauthorized || code == http.StatusForbidden {
+			t.Fatalf("exec with passkey step-up: %d %v", code, body)
+		}
+		if code, _ := call(admin, csrf, "POST", "/api/dispatch", execReq(assertion)); code != http.StatusUnauthorized {
+			t.Errorf("replayed step-up assertion: got %d, want 401", code)
+		}
+
+		// A login challenge does not confirm an op
+		_, options = call(admin, csrf, "POST", "/login/webauthn/begin", nil)
+		if code, _ := call(admin, csrf, "POST", "/api/dispatch", execReq(passkey.get(options))); code != http.StatusUnauthorized {
+			t.Errorf("login assertion as step-up: got %d, want 401", code)
+		}
+
+		var withPasskey, refused int
+		_ = td.db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE action = 'exec' AND details LIKE '%"step_up":"webauthn"%'`).Scan(&withPasskey)
+		_ = td.db.QueryRow(`SELECT COUNT(*) FROM audit_log WHERE action = 'exec' AND details LIKE '%"result":"invalid_webauthn"%'`).Scan(&refused)
+		if withPasskey != 1 || refused != 2 {
+			t.Errorf("audit log: %d exec with passkey step-up (want 1), %d refused (want 2)", withPasskey, refused)
+		}
+	})
+}